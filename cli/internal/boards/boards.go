@@ -0,0 +1,246 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: boards  —  pluggable board/FQBN registry
+//
+//  Replaces a hardcoded board-id table with a merged registry built from,
+//  in increasing precedence:
+//    1. the default registry embedded in this binary (boards.toml)
+//    2. ~/.tsuki/boards/*.toml                 — user-defined boards
+//    3. installed tsukilib packages' own [[board]] entries
+//    4. boards discovered live from arduino-cli (see Registry.AddDiscovered)
+//
+//  Among sources 1–3, a later source with the same id replaces an earlier
+//  one outright, so a user or package can override f_cpu/defines/variant
+//  for a board this package already knows about, not just add new ones.
+//  Source 4 only fills in ids that none of the others define.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package boards
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed boards.toml
+var embeddedDefaults embed.FS
+
+// Board is one entry in the registry: an id, optional aliases, its FQBN,
+// and the metadata needed to synthesize compiler flags for it (see
+// cli/compiledb.go) without requiring arduino-cli to be installed.
+type Board struct {
+	ID         string   `toml:"id"`
+	Aliases    []string `toml:"aliases"`
+	FQBN       string   `toml:"fqbn"`
+	Compiler   string   `toml:"compiler"` // e.g. "avr-g++" — empty means "unknown, caller decides"
+	FCPU       string   `toml:"f_cpu"`
+	Variant    string   `toml:"variant"`
+	Defines    []string `toml:"defines"`
+	ExtraFlags []string `toml:"extra_flags"`
+	Source     string   `toml:"-"` // "embedded", "user:<path>", "package:<name>", "arduino-cli"
+}
+
+type tomlRegistry struct {
+	Boards []Board `toml:"board"`
+}
+
+// Registry is a merged, queryable set of Boards.
+type Registry struct {
+	byKey map[string]*Board // id and every alias, lowercased, -> board
+	all   []*Board          // insertion order, for List()
+}
+
+func newRegistry() *Registry {
+	return &Registry{byKey: map[string]*Board{}}
+}
+
+// add inserts or replaces a board by id, indexing it under its id and all
+// its aliases.
+func (r *Registry) add(b Board) {
+	if existing, ok := r.byKey[strings.ToLower(b.ID)]; ok {
+		*existing = b
+		r.index(existing)
+		return
+	}
+	stored := b
+	r.all = append(r.all, &stored)
+	r.index(&stored)
+}
+
+func (r *Registry) index(b *Board) {
+	r.byKey[strings.ToLower(b.ID)] = b
+	for _, alias := range b.Aliases {
+		r.byKey[strings.ToLower(alias)] = b
+	}
+}
+
+// Resolve looks up id (a board id, alias, or full/partial FQBN) and
+// returns its Board. A value containing ":" is treated as an FQBN passed
+// straight through — arduino-cli accepts menu options baked into it
+// (vendor:arch:board:menu=value), so tsuki does too.
+func (r *Registry) Resolve(id string) (Board, bool) {
+	if strings.Contains(id, ":") {
+		return Board{ID: id, FQBN: id, Source: "fqbn"}, true
+	}
+	if b, ok := r.byKey[strings.ToLower(id)]; ok {
+		return *b, true
+	}
+	return Board{}, false
+}
+
+// List returns every board, sorted by id, for `tsuki boards list`.
+func (r *Registry) List() []Board {
+	out := make([]Board, len(r.all))
+	for i, b := range r.all {
+		out[i] = *b
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Suggest returns the closest known id/alias to id (by edit distance), or
+// "" if nothing is close enough to be worth suggesting.
+func (r *Registry) Suggest(id string) string {
+	const maxDistance = 3
+	best, bestDist := "", maxDistance+1
+	for key, b := range r.byKey {
+		if d := levenshtein(strings.ToLower(id), key); d < bestDist {
+			best, bestDist = b.ID, d
+		}
+	}
+	if bestDist > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// AddDiscovered merges in boards found some other way (e.g. `arduino-cli
+// board listall`) that aren't already in the registry — it never
+// overrides a richer entry (embedded/user/package) the registry already
+// has for that id, since those carry f_cpu/defines this source can't
+// supply.
+func (r *Registry) AddDiscovered(discovered []Board) {
+	for _, b := range discovered {
+		if _, ok := r.byKey[strings.ToLower(b.ID)]; ok {
+			continue
+		}
+		b.Source = "arduino-cli"
+		r.add(b)
+	}
+}
+
+// Load builds the merged registry from the embedded defaults, the user's
+// ~/.tsuki/boards/*.toml files, and every installed tsukilib package's own
+// [[board]] entries (read via readPackageBoards, which the cli package
+// supplies so this package doesn't need to import pkgmgr's install-path
+// conventions directly).
+func Load(readPackageBoards func() []Board) *Registry {
+	r := newRegistry()
+
+	if data, err := embeddedDefaults.ReadFile("boards.toml"); err == nil {
+		if tr, err := decode(data); err == nil {
+			for _, b := range tr.Boards {
+				b.Source = "embedded"
+				r.add(b)
+			}
+		}
+	}
+
+	for _, path := range userRegistryFiles() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		tr, err := decode(data)
+		if err != nil {
+			continue
+		}
+		for _, b := range tr.Boards {
+			b.Source = "user:" + path
+			r.add(b)
+		}
+	}
+
+	if readPackageBoards != nil {
+		for _, b := range readPackageBoards() {
+			r.add(b)
+		}
+	}
+
+	return r
+}
+
+func decode(data []byte) (tomlRegistry, error) {
+	var tr tomlRegistry
+	_, err := toml.Decode(string(data), &tr)
+	return tr, err
+}
+
+// Decode parses boards TOML (an embedded/user/package registry file —
+// they all share the same [[board]] array-of-tables shape) into a slice
+// of Board. The caller sets Source on each returned Board.
+func Decode(data []byte) ([]Board, error) {
+	tr, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return tr.Boards, nil
+}
+
+// userRegistryFiles globs ~/.tsuki/boards/*.toml.
+func userRegistryFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(home, ".tsuki", "boards", "*.toml"))
+	return matches
+}
+
+// ParseBoardOption parses one --board-option key=value argument.
+func ParseBoardOption(s string) (key, value string, err error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("--board-option must be key=value, got %q", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+// ── Levenshtein distance (for Suggest) ────────────────────────────────────────
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(curr[j-1]+1, prev[j]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
@@ -0,0 +1,197 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cache  —  content-addressed object store for incremental builds
+//
+//  A Store is just a directory of sha256-addressed files, sharded two
+//  hex-chars deep (objects/<key[:2]>/<key>) the way git and most build
+//  caches lay theirs out, so no single directory ends up with thousands of
+//  entries. Key construction (what goes into the hash) is the caller's
+//  business — see cli/build.go's transpileCacheKey — this package only
+//  knows how to store and retrieve bytes by a key it's handed.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is a content-addressed object store rooted at Dir.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns ~/.cache/tsuki/objects.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".cache", "tsuki", "objects")
+	}
+	return filepath.Join(home, ".cache", "tsuki", "objects")
+}
+
+// DefaultBuildCacheDir returns ~/.cache/tsuki/build-cache — handed to
+// arduino-cli as --build-cache-path so it can skip re-linking object
+// files that haven't changed between builds, the same way this package's
+// Store skips re-transpiling.
+func DefaultBuildCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".cache", "tsuki", "build-cache")
+	}
+	return filepath.Join(home, ".cache", "tsuki", "build-cache")
+}
+
+// New returns a Store rooted at dir (DefaultDir() if dir is "").
+func New(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Store{Dir: dir}
+}
+
+// Key hashes parts together (each joined with a NUL separator so "ab"+"c"
+// and "a"+"bc" don't collide) into a hex sha256 digest.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the on-disk path for key, sharded two hex chars deep.
+func (s *Store) path(key string) string {
+	if len(key) < 2 {
+		return filepath.Join(s.Dir, key)
+	}
+	return filepath.Join(s.Dir, key[:2], key)
+}
+
+// Lookup returns the stored path for key and whether it exists. Touches
+// the file's mtime on a hit so Prune's --older-than measures last-use,
+// not creation time.
+func (s *Store) Lookup(key string) (string, bool) {
+	p := s.path(key)
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return p, true
+}
+
+// Store copies srcFile into the object store under key and returns the
+// stored path. A copy (not a rename) because the caller's srcFile usually
+// still needs to exist at its original location (e.g. the sketch dir).
+func (s *Store) Store(key, srcFile string) (string, error) {
+	dst := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	in, err := os.Open(srcFile)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Copy copies the cached object for key to destFile, preserving destFile's
+// usual permissions (0644).
+func (s *Store) Copy(key, destFile string) error {
+	src, ok := s.Lookup(key)
+	if !ok {
+		return os.ErrNotExist
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destFile, data, 0644)
+}
+
+// Stats summarizes the store's current contents.
+type Stats struct {
+	Objects   int
+	TotalSize int64
+}
+
+func (s *Store) Stats() (Stats, error) {
+	var st Stats
+	err := filepath.Walk(s.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		st.Objects++
+		st.TotalSize += info.Size()
+		return nil
+	})
+	return st, err
+}
+
+// Prune deletes every object whose mtime is older than olderThan, and
+// returns how many objects it removed plus the bytes freed.
+func (s *Store) Prune(olderThan time.Duration) (removed int, freed int64, err error) {
+	cutoff := time.Now().Add(-olderThan)
+	var stale []string
+	walkErr := filepath.Walk(s.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		if info.ModTime().Before(cutoff) {
+			stale = append(stale, path)
+			freed += info.Size()
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return 0, 0, walkErr
+	}
+
+	sort.Strings(stale) // deterministic order, easier to eyeball in --verbose logs
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return removed, freed, err
+		}
+		removed++
+	}
+	return removed, freed, nil
+}
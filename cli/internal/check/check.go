@@ -13,6 +13,7 @@ import (
 	"github.com/godotino/cli/internal/core"
 	"github.com/godotino/cli/internal/manifest"
 	"github.com/godotino/cli/internal/ui"
+	"github.com/tsuki/cli/internal/flash/diag"
 )
 
 // Options controls the check command.
@@ -142,14 +143,23 @@ func PrintReport(report *Report) {
 			}
 		}
 
-		// Rich traceback for errors
+		// Rich traceback for errors, reusing flash/diag's classify-then-hint
+		// parser so a recognized failure (e.g. a permission-denied error
+		// surfacing from the transpiler's own serial probing) gets the same
+		// remediation hint tsuki upload's errors do.
 		frames := make([]ui.Frame, 0, len(report.Errors))
 		for _, e := range report.Errors {
+			code := []ui.CodeLine{{Number: e.Line, Text: e.Message, IsPointer: true}}
+			for _, d := range diag.Parse(e.Message, "") {
+				if d.Code != "unclassified" && d.Hint != "" {
+					code = append(code, ui.CodeLine{Number: e.Line + 1, Text: "hint: " + d.Hint})
+				}
+			}
 			frames = append(frames, ui.Frame{
 				File: e.File,
 				Line: e.Line,
 				Func: "check",
-				Code: []ui.CodeLine{{Number: e.Line, Text: e.Message, IsPointer: true}},
+				Code: code,
 			})
 		}
 		if len(frames) > 0 {
@@ -0,0 +1,190 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: autoinstall  —  detect + install packages imported by src/
+//
+//  Mirrors arduino-cli's SketchLibrariesDetector/LibrariesLoader: rather
+//  than requiring every dependency to be hand-declared, scan the actual Go
+//  imports and resolve what's missing before transpiling.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// importScanCache persists, per source file, the set of package imports
+// detected by scanImportedPackages so an unchanged file skips go/parser on
+// the next build.
+type importScanCache struct {
+	Files map[string]importScanEntry `json:"files"` // keyed by file path
+}
+
+type importScanEntry struct {
+	Hash    string   `json:"hash"`
+	Imports []string `json:"imports"`
+}
+
+func importScanCachePath(projectDir string) string {
+	return filepath.Join(projectDir, ".tsuki", "import-scan-cache.json")
+}
+
+func loadImportScanCache(projectDir string) *importScanCache {
+	data, err := os.ReadFile(importScanCachePath(projectDir))
+	if err != nil {
+		return &importScanCache{Files: map[string]importScanEntry{}}
+	}
+	var c importScanCache
+	if err := json.Unmarshal(data, &c); err != nil || c.Files == nil {
+		return &importScanCache{Files: map[string]importScanEntry{}}
+	}
+	return &c
+}
+
+func (c *importScanCache) save(projectDir string) error {
+	path := importScanCachePath(projectDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// scanImportedPackages parses every file in goFiles, collects the set of
+// tsuki package names they import (domain-style import paths such as
+// tsuki.dev/ws2812 — anything that isn't a plain stdlib import), and
+// returns the deduplicated, sorted package names. Results are cached per
+// file by content hash so an unchanged file isn't re-parsed.
+func scanImportedPackages(projectDir string, goFiles []string) ([]string, error) {
+	cache := loadImportScanCache(projectDir)
+	seen := map[string]bool{}
+	dirty := false
+
+	for _, goFile := range goFiles {
+		data, err := os.ReadFile(goFile)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		entry, ok := cache.Files[goFile]
+		if !ok || entry.Hash != hash {
+			imports, err := parseGoImports(goFile, data)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", goFile, err)
+			}
+			entry = importScanEntry{Hash: hash, Imports: imports}
+			cache.Files[goFile] = entry
+			dirty = true
+		}
+		for _, name := range entry.Imports {
+			seen[name] = true
+		}
+	}
+
+	if dirty {
+		if err := cache.save(projectDir); err != nil {
+			return nil, fmt.Errorf("saving import scan cache: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseGoImports returns the tsuki package names (not full import paths)
+// imported by a single Go source file.
+func parseGoImports(path string, data []byte) ([]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, data, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, imp := range file.Imports {
+		importPath := strings.Trim(imp.Path.Value, `"`)
+		if !isRegistryImport(importPath) {
+			continue
+		}
+		names = append(names, packageNameFromImport(importPath))
+	}
+	return names, nil
+}
+
+// isRegistryImport reports whether importPath looks like a tsuki registry
+// package rather than a standard-library import: the standard library
+// never puts a dot in the first path segment, so a domain-style first
+// segment (tsuki.dev/ws2812, github.com/...) means "fetch this elsewhere" —
+// which, for a tsuki sketch, means the tsuki package registry.
+func isRegistryImport(importPath string) bool {
+	first := strings.SplitN(importPath, "/", 2)[0]
+	return strings.Contains(first, ".")
+}
+
+// packageNameFromImport maps an import path to the tsukilib package name
+// pkgmgr installs under — the last path segment, matching how `tsuki
+// install <name>` and [dependencies] entries name packages.
+func packageNameFromImport(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	return parts[len(parts)-1]
+}
+
+// ensurePackagesInstalled scans goFiles for tsuki package imports and
+// installs whatever pkgmgr doesn't already have. With noAutoInstall, it
+// instead returns a structured error listing exactly what's missing and
+// the `tsuki install` commands to fix it.
+func ensurePackagesInstalled(projectDir string, goFiles []string, noAutoInstall bool) error {
+	imported, err := scanImportedPackages(projectDir, goFiles)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, name := range imported {
+		if ok, _ := pkgmgr.IsInstalled(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if noAutoInstall {
+		var cmds []string
+		for _, name := range missing {
+			cmds = append(cmds, "tsuki install "+name)
+		}
+		return fmt.Errorf(
+			"missing package(s) imported by src/: %s\n  Run:\n    %s",
+			strings.Join(missing, ", "), strings.Join(cmds, "\n    "),
+		)
+	}
+
+	for _, name := range missing {
+		ui.Step("auto-install", name)
+		if _, err := pkgmgr.Install(pkgmgr.InstallOptions{Spec: name, Dir: projectDir}); err != nil {
+			return fmt.Errorf("auto-installing %q (imported by src/): %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: boards  —  board id → FQBN resolution
+//
+//  Board metadata (FQBN, f_cpu, variant, extra flags) comes from
+//  internal/boards' merged registry: the boards this binary ships with,
+//  ~/.tsuki/boards/*.toml, and installed tsukilib packages' own [[board]]
+//  entries. Boards discovered live from arduino-cli (board listall) are
+//  folded in too, cached for a day so cold startup doesn't pay for a
+//  subprocess every build — but only to fill in ids the registry doesn't
+//  already know, since a `board listall` entry carries an FQBN and nothing
+//  else.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/boards"
+	"github.com/tsuki/cli/internal/flash"
+	"github.com/tsuki/cli/internal/fwindex"
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+const boardsCacheTTL = 24 * time.Hour
+
+// boardEntry is one discovered board: its short alias (the last FQBN
+// segment, e.g. "uno"), arduino-cli's own display name (e.g. "Arduino
+// Uno"), and its full FQBN.
+type boardEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	FQBN string `json:"fqbn"`
+}
+
+// boardsCache is the on-disk shape of ~/.cache/tsuki/boards.json.
+type boardsCache struct {
+	FetchedAt time.Time    `json:"fetched_at"`
+	Boards    []boardEntry `json:"boards"`
+}
+
+func boardsCachePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "tsuki", "boards.json")
+}
+
+func loadBoardsCache() (*boardsCache, bool) {
+	data, err := os.ReadFile(boardsCachePath())
+	if err != nil {
+		return nil, false
+	}
+	var c boardsCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, false
+	}
+	if time.Since(c.FetchedAt) > boardsCacheTTL {
+		return nil, false
+	}
+	return &c, true
+}
+
+func saveBoardsCache(boards []boardEntry) error {
+	path := boardsCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(boardsCache{FetchedAt: time.Now(), Boards: boards}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// arduinoListAllBoard is one entry of `arduino-cli board listall --format json`.
+type arduinoListAllBoard struct {
+	Name string `json:"name"`
+	FQBN string `json:"fqbn"`
+}
+
+type arduinoListAllOutput struct {
+	Boards []arduinoListAllBoard `json:"boards"`
+}
+
+// fetchInstalledBoards asks arduino-cli for every board its installed
+// cores support and derives a short alias per board from the last FQBN
+// segment (arduino:avr:uno → "uno").
+func fetchInstalledBoards(arduinoCLI string) ([]boardEntry, error) {
+	cmd := exec.Command(arduinoCLI, "board", "listall", "--format", "json")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("arduino-cli board listall: %w", err)
+	}
+
+	var parsed arduinoListAllOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing board listall output: %w", err)
+	}
+
+	boards := make([]boardEntry, 0, len(parsed.Boards))
+	for _, b := range parsed.Boards {
+		parts := strings.Split(b.FQBN, ":")
+		if len(parts) < 3 {
+			continue
+		}
+		boards = append(boards, boardEntry{ID: strings.ToLower(parts[2]), Name: b.Name, FQBN: b.FQBN})
+	}
+	return boards, nil
+}
+
+// resolveInstalledBoards returns the cached board list if it's still
+// fresh, otherwise refreshes it from arduino-cli.
+func resolveInstalledBoards(arduinoCLI string) ([]boardEntry, error) {
+	if cached, ok := loadBoardsCache(); ok {
+		return cached.Boards, nil
+	}
+	boards, err := fetchInstalledBoards(arduinoCLI)
+	if err != nil {
+		return nil, err
+	}
+	_ = saveBoardsCache(boards)
+	return boards, nil
+}
+
+// readPackageBoards reads the [[board]] entries out of every installed
+// tsukilib package's own tsukilib.toml, so a package (e.g. a board-support
+// package for a less common core) can register boards tsuki doesn't know
+// about out of the box.
+func readPackageBoards() []boards.Board {
+	pkgs, err := pkgmgr.ListInstalled()
+	if err != nil {
+		return nil
+	}
+	var out []boards.Board
+	for _, p := range pkgs {
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			continue
+		}
+		parsed, err := boards.Decode(data)
+		if err != nil {
+			continue
+		}
+		for _, b := range parsed {
+			b.Source = "package:" + p.Name
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// loadBoardRegistry builds the merged board registry: embedded defaults +
+// user files + installed packages, topped up with whatever arduino-cli
+// itself knows about for ids the registry doesn't already cover.
+func loadBoardRegistry() *boards.Registry {
+	reg := boards.Load(readPackageBoards)
+
+	if discovered, err := resolveInstalledBoards(resolvedArduinoCLI()); err == nil {
+		db := make([]boards.Board, 0, len(discovered))
+		for _, b := range discovered {
+			db = append(db, boards.Board{ID: b.ID, FQBN: b.FQBN})
+		}
+		reg.AddDiscovered(db)
+	}
+
+	return reg
+}
+
+// resolvedArduinoCLI returns the configured arduino-cli binary, falling
+// back to whatever "arduino-cli" resolves to on PATH.
+func resolvedArduinoCLI() string {
+	if cfg != nil && cfg.ArduinoCLI != "" {
+		return cfg.ArduinoCLI
+	}
+	return "arduino-cli"
+}
+
+// boardFQBN resolves --board into a full FQBN. It accepts two forms:
+//
+//  1. A full or partial FQBN (anything containing a colon) — passed
+//     through untouched, menu options (vendor:arch:board:menu=value) and
+//     all, straight to arduino-cli compile.
+//  2. A short id or alias (e.g. "uno", "bluepill") — resolved against the
+//     merged board registry (see loadBoardRegistry). An unknown id gets a
+//     "did you mean" suggestion when one is close enough to be useful.
+func boardFQBN(id string) (string, error) {
+	reg := loadBoardRegistry()
+	if b, ok := reg.Resolve(id); ok {
+		return b.FQBN, nil
+	}
+	if suggestion := reg.Suggest(id); suggestion != "" {
+		return "", fmt.Errorf("unknown board %q — did you mean %q? (run `tsuki boards list`)", id, suggestion)
+	}
+	return "", fmt.Errorf("unknown board %q — run `tsuki boards list` or pass a full FQBN", id)
+}
+
+// boardMetadata resolves id the same way boardFQBN does, but returns the
+// full Board (f_cpu, defines, compiler, extra flags) rather than just its
+// FQBN — used by compiledb.go to synthesize compile commands.
+func boardMetadata(id string) (boards.Board, error) {
+	reg := loadBoardRegistry()
+	if b, ok := reg.Resolve(id); ok {
+		return b, nil
+	}
+	if suggestion := reg.Suggest(id); suggestion != "" {
+		return boards.Board{}, fmt.Errorf("unknown board %q — did you mean %q? (run `tsuki boards list`)", id, suggestion)
+	}
+	return boards.Board{}, fmt.Errorf("unknown board %q — run `tsuki boards list` or pass a full FQBN", id)
+}
+
+// ── Cobra command ─────────────────────────────────────────────────────────────
+
+func newBoardsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "boards",
+		Short: "Inspect and refresh the installed-board cache",
+	}
+	cmd.AddCommand(newBoardsSyncCmd())
+	cmd.AddCommand(newBoardsListCmd())
+	cmd.AddCommand(newBoardsToolsCmd())
+	cmd.AddCommand(newBoardsWatchCmd())
+	return cmd
+}
+
+// newBoardsWatchCmd streams serial port hotplug events (see
+// internal/flash.Watch) as a live table that redraws in place, the same
+// in-terminal-update trick ui.SpinnerGroup uses for parallel installs.
+func newBoardsWatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch",
+		Short: "Stream board connect/disconnect events live",
+		Long: `watch prints a live table of connected serial ports, updating in place as
+boards are plugged and unplugged. Each row shows the port, its USB
+VID:PID (when the OS exposes one), and tsuki's best guess at which board
+it is. Press Ctrl-C to stop.`,
+		Example: `  tsuki boards watch`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer cancel()
+
+			events, err := flash.Watch(ctx, flash.Options{ArduinoCLI: resolvedArduinoCLI()})
+			if err != nil {
+				return err
+			}
+
+			ports := map[string]flash.PortEvent{}
+			lastLines := 0
+			render := func() {
+				if lastLines > 0 {
+					fmt.Fprintf(os.Stdout, "\x1b[%dA", lastLines)
+					for i := 0; i < lastLines; i++ {
+						fmt.Fprint(os.Stdout, "\x1b[K\n")
+					}
+					fmt.Fprintf(os.Stdout, "\x1b[%dA", lastLines)
+				}
+
+				ids := make([]string, 0, len(ports))
+				for id := range ports {
+					ids = append(ids, id)
+				}
+				sort.Strings(ids)
+
+				ui.ColorTitle.Printf("  %-18s  %-9s  %-18s  %s\n", "PORT", "VID:PID", "BOARD GUESS", "SERIAL")
+				ui.ColorMuted.Println("  " + strings.Repeat("─", 66))
+				for _, id := range ids {
+					p := ports[id]
+					vidPid := "-"
+					if p.VID != "" {
+						vidPid = p.VID + ":" + p.PID
+					}
+					guess := p.BoardGuess
+					if guess == "" {
+						guess = "-"
+					}
+					ui.ColorInfo.Printf("  %-18s  ", p.Port)
+					fmt.Printf("%-9s  %-18s  ", vidPid, guess)
+					ui.ColorMuted.Printf("%s\n", p.Serial)
+				}
+				if len(ids) == 0 {
+					ui.ColorMuted.Println("  (no boards connected)")
+				}
+				lastLines = 2 + len(ids)
+				if len(ids) == 0 {
+					lastLines++
+				}
+			}
+
+			render()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case evt, ok := <-events:
+					if !ok {
+						return nil
+					}
+					switch evt.Type {
+					case flash.PortAdded:
+						ports[evt.Port] = evt
+					case flash.PortRemoved:
+						delete(ports, evt.Port)
+					}
+					render()
+				}
+			}
+		},
+	}
+}
+
+// newBoardsToolsCmd groups subcommands for the firmware/plugin index (see
+// internal/fwindex) — the uploader tools (avrdude, bossac, picotool, ...)
+// that let `tsuki upload` flash common boards without arduino-cli.
+func newBoardsToolsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Manage the firmware index's cached uploader tools",
+	}
+	cmd.AddCommand(newBoardsToolsSyncCmd())
+	return cmd
+}
+
+func newBoardsToolsSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Refresh the firmware index from firmware_index_url",
+		Long: `sync fetches cfg's firmware_index_url (verifying its signature against
+firmware_index_key_url when one is configured) and caches it under
+~/.cache/tsuki/fwindex/index.json, so 'tsuki upload' resolves board ->
+uploader tool without a network round-trip on every run.`,
+		Example: `  tsuki boards tools sync`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.FirmwareIndexURL == "" {
+				return fmt.Errorf("firmware_index_url is not set — run: tsuki config set firmware_index_url <url>")
+			}
+			idx, err := fwindex.Load(fwindex.LoadOptions{
+				IndexURL: cfg.FirmwareIndexURL,
+				KeyURL:   cfg.FirmwareIndexKeyURL,
+			})
+			if err != nil {
+				return err
+			}
+			ui.Success(fmt.Sprintf("cached %d tool(s) covering %d board(s) → %s", len(idx.Tools), len(idx.Boards), fwindex.CacheDir()))
+			return nil
+		},
+	}
+}
+
+func newBoardsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List every board tsuki knows --board can resolve",
+		Long: `list prints the merged board registry: the defaults this binary ships
+with, ~/.tsuki/boards/*.toml, installed tsukilib packages' own [[board]]
+entries, and whatever arduino-cli discovers from installed cores — in
+that precedence order, so "source" tells you where an entry came from.`,
+		Example: `  tsuki boards list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list := loadBoardRegistry().List()
+			ui.ColorTitle.Printf("  %-18s  %-38s  %-10s  %s\n", "ID", "FQBN", "F_CPU", "SOURCE")
+			ui.ColorMuted.Println("  " + strings.Repeat("─", 88))
+			for _, b := range list {
+				ui.ColorInfo.Printf("  %-18s  ", b.ID)
+				fmt.Printf("%-38s  %-10s  ", b.FQBN, b.FCPU)
+				ui.ColorMuted.Printf("%s\n", b.Source)
+			}
+			return nil
+		},
+	}
+}
+
+func newBoardsSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Refresh ~/.cache/tsuki/boards.json from arduino-cli",
+		Long: `sync eagerly runs arduino-cli board listall to rebuild the board id → FQBN
+cache, rather than waiting for the 24h TTL to expire on the next build.
+Run this after installing a new core (e.g. MegaCoreX, SparkFun SAMD) so
+its boards are usable by --board right away.`,
+		Example: `  tsuki boards sync`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			boards, err := fetchInstalledBoards(resolvedArduinoCLI())
+			if err != nil {
+				return err
+			}
+			if err := saveBoardsCache(boards); err != nil {
+				return fmt.Errorf("writing boards cache: %w", err)
+			}
+			ui.Success(fmt.Sprintf("cached %d board(s) from installed cores → %s", len(boards), boardsCachePath()))
+			return nil
+		},
+	}
+}
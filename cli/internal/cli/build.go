@@ -18,16 +18,22 @@ package cli
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/boards"
+	"github.com/tsuki/cli/internal/cache"
 	"github.com/tsuki/cli/internal/core"
 	"github.com/tsuki/cli/internal/manifest"
 	"github.com/tsuki/cli/internal/pkgmgr"
@@ -38,23 +44,44 @@ import (
 
 // Options controls the build pipeline.
 type Options struct {
-	Board          string
-	Compile        bool
-	PreparePackage bool
-	OutputDir      string
-	SourceMap      bool
-	Verbose        bool
-	CoreBin        string
-	ArduinoCLI     string
+	Board               string
+	Compile             bool
+	PreparePackage      bool
+	OutputDir           string
+	SourceMap           bool
+	Verbose             bool
+	CoreBin             string
+	ArduinoCLI          string
+	FlashBinary         string // tsuki-flash path; used for platform-pack compiles that bypass arduino-cli
+	CompilationDatabase bool
+	NoAutoInstall       bool
+	SignKey             string   // path to an encrypted signing key; signs the .tskp when set
+	NativePackages      []string // e.g. []string{"deb", "rpm", "apk"} — requires Compile
+	BoardOptions        []string // "key=value" pairs, parsed via boards.ParseBoardOption, forwarded as --build-property
+	CacheDir            string   // object store root for incremental builds; "" uses cache.DefaultDir()
+	Jobs                int      // max concurrent transpile workers; <1 uses runtime.NumCPU()
+	// DiagnosticsJSON requests --diagnostics=json from godotino-core and
+	// streams the decoded diagnostics as NDJSON on stdout instead of the
+	// human spinner output, for editor plugins / LSP-style consumers.
+	DiagnosticsJSON bool
+	// Progress, when set, receives ui.TaskProgress events for the compile
+	// step instead of Run driving its own ui.Spinner — the same channel
+	// type scaffold() pushes to, so progress reporting is uniform across
+	// the CLI. nil (the default) keeps today's spinner-only behavior.
+	Progress chan<- ui.TaskProgress
 }
 
 // Result holds the outputs of a successful build.
 type Result struct {
-	CppFiles    []string
-	SketchDir   string
-	FirmwareHex string
-	Warnings    []string
-	PackagePath string // set when --prepare-package is used
+	CppFiles          []string
+	SketchDir         string
+	FirmwareHex       string
+	Warnings          []string
+	PackagePath       string            // set when --prepare-package is used
+	CompileCommandsDB string            // path to compile_commands.json, set when CompilationDatabase is on
+	NativePackages    []string          // .deb/.rpm/.apk paths, set when --native-package is used
+	Diagnostics       []core.Diagnostic // set when --diagnostics=json is used
+	CompileOutput     string            // arduino-cli compile's combined stdout+stderr, set when --compile succeeds via arduino-cli
 }
 
 // ── Main build runner ─────────────────────────────────────────────────────────
@@ -95,6 +122,13 @@ func Run(projectDir string, m *manifest.Manifest, opts Options) (*Result, error)
 		return nil, fmt.Errorf("no .go files found in %s", srcDir)
 	}
 
+	// ── auto-detect + install packages imported by src/ ──────────────────
+	// Mirrors arduino-cli's sketch library auto-detection: scan the actual
+	// Go imports rather than relying solely on [dependencies] entries.
+	if err := ensurePackagesInstalled(projectDir, goFiles, opts.NoAutoInstall); err != nil {
+		return nil, err
+	}
+
 	pkgNames := m.PackageNames()
 	libsDir := pkgmgr.LibsDir()
 
@@ -114,89 +148,178 @@ func Run(projectDir string, m *manifest.Manifest, opts Options) (*Result, error)
 
 	result := &Result{SketchDir: sketchDir}
 
-	for _, goFile := range goFiles {
-		base := strings.TrimSuffix(filepath.Base(goFile), ".go")
-		cppFile := filepath.Join(sketchDir, base+".cpp")
-
-		sp := ui.NewSpinner(fmt.Sprintf("%s → %s", filepath.Base(goFile), filepath.Base(cppFile)))
-		sp.Start()
-
-		tr, err := transpiler.Transpile(core.TranspileRequest{
-			InputFile:  goFile,
-			OutputFile: cppFile,
-			Board:      board,
-			SourceMap:  opts.SourceMap || m.Build.SourceMap,
-			LibsDir:    libsDir,
-			PkgNames:   pkgNames,
-		})
-		if err != nil {
-			sp.Stop(false, fmt.Sprintf("failed: %s", filepath.Base(goFile)))
-			return nil, err
-		}
-
-		sp.Stop(true, fmt.Sprintf("%s  →  %s", filepath.Base(goFile), filepath.Base(cppFile)))
-		result.CppFiles = append(result.CppFiles, tr.OutputFile)
-		result.Warnings = append(result.Warnings, tr.Warnings...)
+	objCache := cache.New(opts.CacheDir)
+	transpilerVersion, _ := transpiler.Version() // "" on error just widens the cache key, doesn't break it
+	pkgDigest := transpileCacheDigest(pkgNames, m.Packages)
+	cppStd := m.Build.CppStd
+	sourceMap := opts.SourceMap || m.Build.SourceMap
+
+	cppFiles, warnings, cacheHits, cacheMisses, err := transpileAll(transpileParams{
+		goFiles:           goFiles,
+		sketchDir:         sketchDir,
+		board:             board,
+		libsDir:           libsDir,
+		pkgNames:          pkgNames,
+		sourceMap:         sourceMap,
+		diagnosticsJSON:   opts.DiagnosticsJSON,
+		jobs:              opts.Jobs,
+		transpiler:        transpiler,
+		objCache:          objCache,
+		transpilerVersion: transpilerVersion,
+		cppStd:            cppStd,
+		pkgDigest:         pkgDigest,
+		result:            result,
+	})
+	result.CppFiles = cppFiles
+	result.Warnings = warnings
+	if cacheHits > 0 {
+		ui.Step("cache", fmt.Sprintf("%d hit, %d miss", cacheHits, cacheMisses))
 	}
-
 	for _, w := range result.Warnings {
 		ui.Warn(w)
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	if err := writeInoStub(sketchDir, sketchName, result.CppFiles); err != nil {
 		return nil, fmt.Errorf("writing .ino stub: %w", err)
 	}
 	ui.Step("sketch", fmt.Sprintf("wrote %s/%s.ino", sketchName, sketchName))
 
-	// ── Optional: arduino-cli compile ─────────────────────────────────────
+	// ── compile_commands.json — synthesized now so clangd works even
+	// without --compile; replaced with arduino-cli's own output below if
+	// --compile runs (see "compile_commands.json" step after compiling).
+	if opts.CompilationDatabase {
+		if path, err := writeCompilationDatabase(sketchDir, result.CppFiles, board, m.Build.CppStd); err != nil {
+			ui.Warn(fmt.Sprintf("could not write compile_commands.json: %v", err))
+		} else {
+			result.CompileCommandsDB = path
+			ui.Step("compile_commands.json", filepath.Base(path))
+		}
+	}
+
+	// ── Detect + resolve #include'd Arduino libraries ─────────────────────
+	// Runs after transpile (so the .cpp files exist to scan) and before
+	// compiling, so a missing library is installed in time for the actual
+	// compile step rather than failing partway through it.
+	if err := detectAndResolveIncludes(projectDir, result.CppFiles, board, baseOutDir, opts.NoAutoInstall); err != nil {
+		return result, err
+	}
+
+	// ── Optional: compile ──────────────────────────────────────────────────
 	if opts.Compile {
 		ui.SectionTitle("Compiling")
-		fqbn, err := boardFQBN(board)
-		if err != nil {
-			return result, fmt.Errorf("unknown board %q — run `tsuki boards list`", board)
-		}
 
-		arduinoCLI := opts.ArduinoCLI
-		if arduinoCLI == "" {
-			arduinoCLI = "arduino-cli"
-		}
+		// A board id mapped by an installed platform pack (BluePill, a
+		// Teensy variant, ...) has no arduino-cli FQBN — bypass arduino-cli
+		// entirely and drive tsuki-flash with the pack's own variant,
+		// ldscript, and extra flags instead.
+		if plat, variant, ok := pkgmgr.ResolveBoardPlatform(board); ok {
+			if len(opts.NativePackages) > 0 {
+				return result, fmt.Errorf("--native-package is not supported for platform-pack board %q yet", board)
+			}
+			buildCacheDir := filepath.Join(baseOutDir, ".cache")
+			_ = os.MkdirAll(buildCacheDir, 0755)
 
-		buildCacheDir := filepath.Join(baseOutDir, ".cache")
-		_ = os.MkdirAll(buildCacheDir, 0755)
+			hexFile, err := compileWithPlatform(plat, variant, opts, sketchDir, buildCacheDir)
+			if err != nil {
+				return result, err
+			}
+			result.FirmwareHex = hexFile
+			if opts.CompilationDatabase {
+				ui.Warn("keeping synthesized compile_commands.json: not available for platform-pack builds")
+			}
+		} else {
+			fqbn, err := boardFQBN(board)
+			if err != nil {
+				return result, fmt.Errorf("unknown board %q — run `tsuki boards list`", board)
+			}
 
-		args := []string{
-			"compile",
-			"--fqbn", fqbn,
-			"--build-path", buildCacheDir,
-			"--warnings", "all",
-		}
-		if opts.Verbose {
-			args = append(args, "--verbose")
-		}
-		args = append(args, sketchDir)
-
-		sp := ui.NewSpinner(fmt.Sprintf("arduino-cli compile --fqbn %s", fqbn))
-		sp.Start()
-
-		cmd := exec.Command(arduinoCLI, args...)
-		cmd.Dir = sketchDir
-		out, cmdErr := cmd.CombinedOutput()
-		if cmdErr != nil {
-			sp.Stop(false, "compilation failed")
-			renderArduinoError(string(out))
-			return result, fmt.Errorf("arduino-cli compile failed")
-		}
-		sp.Stop(true, fmt.Sprintf("firmware written to %s", buildCacheDir))
+			arduinoCLI := opts.ArduinoCLI
+			if arduinoCLI == "" {
+				arduinoCLI = "arduino-cli"
+			}
+
+			buildCacheDir := filepath.Join(baseOutDir, ".cache")
+			_ = os.MkdirAll(buildCacheDir, 0755)
+
+			args := []string{
+				"compile",
+				"--fqbn", fqbn,
+				"--build-path", buildCacheDir,
+				"--build-cache-path", cache.DefaultBuildCacheDir(),
+				"--warnings", "all",
+			}
+			for _, opt := range opts.BoardOptions {
+				key, value, err := boards.ParseBoardOption(opt)
+				if err != nil {
+					return result, err
+				}
+				args = append(args, "--build-property", key+"="+value)
+			}
+			if opts.Verbose {
+				args = append(args, "--verbose")
+			}
+			args = append(args, sketchDir)
 
-		hexFiles, _ := filepath.Glob(filepath.Join(buildCacheDir, "*.hex"))
-		if len(hexFiles) > 0 {
-			result.FirmwareHex = hexFiles[0]
+			compileLabel := fmt.Sprintf("arduino-cli compile --fqbn %s", fqbn)
+
+			var sp *ui.Spinner
+			if opts.Progress != nil {
+				opts.Progress <- ui.TaskProgress{Name: "compile", Message: compileLabel}
+			} else {
+				sp = ui.NewSpinner(compileLabel)
+				sp.Start()
+			}
+
+			cmd := exec.Command(arduinoCLI, args...)
+			cmd.Dir = sketchDir
+			out, cmdErr := cmd.CombinedOutput()
+			if cmdErr != nil {
+				if opts.Progress != nil {
+					opts.Progress <- ui.TaskProgress{Name: "compile", Message: "compilation failed", Completed: true, Failed: true}
+				} else {
+					sp.Stop(false, "compilation failed")
+				}
+				renderArduinoError(string(out))
+				return result, fmt.Errorf("arduino-cli compile failed")
+			}
+			if opts.Progress != nil {
+				opts.Progress <- ui.TaskProgress{Name: "compile", Message: fmt.Sprintf("firmware written to %s", buildCacheDir), Completed: true}
+			} else {
+				sp.Stop(true, fmt.Sprintf("firmware written to %s", buildCacheDir))
+			}
+			result.CompileOutput = string(out)
+
+			hexFiles, _ := filepath.Glob(filepath.Join(buildCacheDir, "*.hex"))
+			if len(hexFiles) > 0 {
+				result.FirmwareHex = hexFiles[0]
+			}
+
+			if opts.CompilationDatabase {
+				if path, err := compilationDatabaseFromArduinoCLI(arduinoCLI, fqbn, sketchDir, buildCacheDir); err != nil {
+					ui.Warn(fmt.Sprintf("keeping synthesized compile_commands.json: %v", err))
+				} else {
+					result.CompileCommandsDB = path
+				}
+			}
+
+			// ── Optional: distro-native packages (.deb/.rpm/.apk) ─────────
+			if len(opts.NativePackages) > 0 {
+				ui.SectionTitle("Packaging native OS package(s)")
+				paths, err := buildNativePackages(projectDir, m, result, board, opts.NativePackages)
+				if err != nil {
+					return result, fmt.Errorf("native packaging failed: %w", err)
+				}
+				result.NativePackages = paths
+			}
 		}
 	}
 
 	// ── Optional: pack .tskp ──────────────────────────────────────────────
 	if opts.PreparePackage {
-		pkgPath, err := packTSKP(projectDir, m, result)
+		pkgPath, err := packTSKP(projectDir, m, result, opts.SignKey)
 		if err != nil {
 			return result, fmt.Errorf("packaging failed: %w", err)
 		}
@@ -219,8 +342,11 @@ type tskpMeta struct {
 	PackagedAt  string   `json:"packaged_at"`
 }
 
-// packTSKP creates <name>-<version>.tskp in the project root.
-func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string, error) {
+// packTSKP creates <name>-<version>.tskp in the project root. When
+// signKeyPath is set, it also signs the archive's entry manifest (see
+// sign.go) and writes tsuki-package.sig inside the archive plus a detached
+// <name>-<version>.tskp.minisig sidecar next to it.
+func packTSKP(projectDir string, m *manifest.Manifest, result *Result, signKeyPath string) (string, error) {
 	name := m.Name
 	if name == "" {
 		name = "project"
@@ -248,6 +374,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 	sp.Start()
 
 	var entries int
+	var signed []signedEntry
 
 	// ── tsuki-package.json metadata ───────────────────────────────────────
 	meta := tskpMeta{
@@ -260,7 +387,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 		PackagedAt:  time.Now().UTC().Format(time.RFC3339),
 	}
 	metaBytes, _ := json.MarshalIndent(meta, "", "  ")
-	if err := addBytesToZip(zw, "tsuki-package.json", metaBytes); err != nil {
+	if err := addBytesToZip(zw, "tsuki-package.json", metaBytes, &signed); err != nil {
 		sp.Stop(false, "failed writing metadata")
 		return "", err
 	}
@@ -270,7 +397,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 	for _, cfgName := range []string{manifest.TOMLFileName, manifest.JSONFileName} {
 		cfgPath := filepath.Join(projectDir, cfgName)
 		if _, err := os.Stat(cfgPath); err == nil {
-			if err := addFileToZip(zw, cfgPath, cfgName); err != nil {
+			if err := addFileToZip(zw, cfgPath, cfgName, &signed); err != nil {
 				sp.Stop(false, "failed writing config")
 				return "", err
 			}
@@ -283,7 +410,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 	for _, readmeName := range []string{"README.md", "readme.md", "Readme.md"} {
 		rp := filepath.Join(projectDir, readmeName)
 		if _, err := os.Stat(rp); err == nil {
-			_ = addFileToZip(zw, rp, readmeName)
+			_ = addFileToZip(zw, rp, readmeName, &signed)
 			entries++
 			break
 		}
@@ -291,7 +418,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 
 	// ── src/ — original Go sources ────────────────────────────────────────
 	srcDir := filepath.Join(projectDir, "src")
-	n, err := addDirToZip(zw, srcDir, "src")
+	n, err := addDirToZip(zw, srcDir, "src", &signed)
 	if err != nil {
 		sp.Stop(false, "failed adding src/")
 		return "", err
@@ -301,7 +428,7 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 	// ── build/<sketch>/ — generated C++ ──────────────────────────────────
 	if result.SketchDir != "" {
 		rel, _ := filepath.Rel(projectDir, result.SketchDir)
-		n, err = addDirToZip(zw, result.SketchDir, rel)
+		n, err = addDirToZip(zw, result.SketchDir, rel, &signed)
 		if err == nil {
 			entries += n
 		}
@@ -314,39 +441,75 @@ func packTSKP(projectDir string, m *manifest.Manifest, result *Result) (string,
 		hexFiles, _ := filepath.Glob(filepath.Join(cacheDir, "*.hex"))
 		for _, hf := range hexFiles {
 			r, _ := filepath.Rel(cacheDir, hf)
-			_ = addFileToZip(zw, hf, filepath.Join(rel, r))
+			_ = addFileToZip(zw, hf, filepath.Join(rel, r), &signed)
 			entries++
 		}
 	}
 
+	// ── tsuki-package.sig — optional, signs everything added above ───────
+	if signKeyPath != "" {
+		passphrase, err := readSignPassphrase()
+		if err != nil {
+			sp.Stop(false, "failed reading signing passphrase")
+			return "", err
+		}
+		sig, err := signEntries(signed, signKeyPath, passphrase)
+		if err != nil {
+			sp.Stop(false, "failed signing archive")
+			return "", fmt.Errorf("signing %s: %w", outName, err)
+		}
+		sigBytes, _ := json.MarshalIndent(sig, "", "  ")
+		if err := addBytesToZip(zw, "tsuki-package.sig", sigBytes, nil); err != nil {
+			sp.Stop(false, "failed writing signature")
+			return "", err
+		}
+		entries++
+		if err := os.WriteFile(outPath+".minisig", sigBytes, 0644); err != nil {
+			sp.Stop(false, "failed writing .minisig sidecar")
+			return "", err
+		}
+	}
+
 	sp.Stop(true, fmt.Sprintf("packed %d files → %s", entries, outName))
 	ui.Info(fmt.Sprintf("Archive: %s", outPath))
+	if signKeyPath != "" {
+		ui.Info(fmt.Sprintf("Signature: %s.minisig", outPath))
+	}
 
 	return outPath, nil
 }
 
 // ── zip helpers ───────────────────────────────────────────────────────────────
 
-func addBytesToZip(zw *zip.Writer, name string, data []byte) error {
+// addBytesToZip writes data as a zip entry. When track is non-nil, the
+// entry's path and content hash are recorded for .tskp signing (see
+// sign.go) — pass nil for entries that shouldn't be covered by the
+// signature, such as tsuki-package.sig itself.
+func addBytesToZip(zw *zip.Writer, name string, data []byte, track *[]signedEntry) error {
 	w, err := zw.Create(name)
 	if err != nil {
 		return err
 	}
-	_, err = w.Write(data)
-	return err
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if track != nil {
+		*track = append(*track, signedEntry{name: name, hash: sha256.Sum256(data)})
+	}
+	return nil
 }
 
-func addFileToZip(zw *zip.Writer, fsPath, zipPath string) error {
+func addFileToZip(zw *zip.Writer, fsPath, zipPath string, track *[]signedEntry) error {
 	data, err := os.ReadFile(fsPath)
 	if err != nil {
 		return err
 	}
-	return addBytesToZip(zw, zipPath, data)
+	return addBytesToZip(zw, zipPath, data, track)
 }
 
 // addDirToZip walks a directory and adds all files under zipRoot inside the archive.
 // Returns the number of files added.
-func addDirToZip(zw *zip.Writer, dir, zipRoot string) (int, error) {
+func addDirToZip(zw *zip.Writer, dir, zipRoot string, track *[]signedEntry) (int, error) {
 	var count int
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
@@ -358,20 +521,11 @@ func addDirToZip(zw *zip.Writer, dir, zipRoot string) (int, error) {
 		}
 		zipPath := filepath.Join(zipRoot, rel)
 
-		w, err := zw.Create(zipPath)
-		if err != nil {
-			return err
-		}
-		f, err := os.Open(path)
-		if err != nil {
+		if err := addFileToZip(zw, path, zipPath, track); err != nil {
 			return err
 		}
-		defer f.Close()
-		_, err = io.Copy(w, f)
-		if err == nil {
-			count++
-		}
-		return err
+		count++
+		return nil
 	})
 	return count, err
 }
@@ -380,20 +534,74 @@ func addDirToZip(zw *zip.Writer, dir, zipRoot string) (int, error) {
 
 func newBuildCmd() *cobra.Command {
 	var (
-		board          string
-		output         string
-		compile        bool
-		preparePackage bool
-		verbose        bool
+		board               string
+		output              string
+		compile             bool
+		preparePackage      bool
+		verbose             bool
+		compilationDatabase bool
+		noAutoInstall       bool
+		signKey             string
+		nativePackage       string
+		diagnostics         string
+		boardOptions        []string
+		jobs                int
+		report              bool
+		progress            string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "build",
 		Short: "Transpile and optionally compile the project",
+		Long: `build transpiles the project's Go sources to C++ and, alongside the
+generated sketch, writes a compile_commands.json so editors using clangd
+get completion and diagnostics on the C++ output. Pass --compile to also
+invoke arduino-cli and produce real firmware; in that case the
+compilation database is arduino-cli's own, not tsuki's synthesized one.
+
+build also scans src/*.go for imports of registry packages (e.g.
+tsuki.dev/ws2812) and installs anything missing before transpiling. Pass
+--no-auto-install to instead fail with the list of missing packages and
+the tsuki install commands to run.
+
+--sign <keyfile> additionally signs the .tskp produced by --prepare-package:
+the key is decrypted with the passphrase from TSUKI_SIGN_PASSPHRASE (or
+read from stdin), and the signature is both embedded as
+tsuki-package.sig and written as a detached <name>-<version>.tskp.minisig.
+Verify a signed package with 'tsuki verify'.
+
+--native-package deb,rpm,apk additionally packages the compiled firmware
+(requires --compile) plus a <name>-flash wrapper and a udev rule for the
+board's USB-serial chip into one distro-native package per format, via
+nfpm — useful for handing firmware to field technicians without the
+tsuki toolchain.
+
+--board-option key=value (repeatable, requires --compile) forwards extra
+build properties to arduino-cli as --build-property, for board cores
+whose menu options (e.g. CPU speed, USB stack) aren't captured by the FQBN
+alone.
+
+--jobs N caps how many source files transpile concurrently (default
+runtime.NumCPU()). A file that fails to transpile stops new files from
+starting, but files already in flight finish and their warnings are still
+reported.
+
+--diagnostics=json requests structured diagnostics from godotino-core
+(falls back to the legacy stderr format on core binaries too old to
+understand the flag) and streams them as one JSON object per line on
+stdout instead of the spinner output — for editor plugins and other
+LSP-style consumers, not interactive use.`,
 		Example: `  tsuki build
   tsuki build --board esp32
   tsuki build --compile
-  tsuki build --compile --prepare-package`,
+  tsuki build --compile --prepare-package
+  tsuki build --compilation-database=false
+  tsuki build --no-auto-install
+  tsuki build --prepare-package --sign ~/.config/tsuki/signing/tskp.key
+  tsuki build --compile --native-package deb,rpm
+  tsuki build --diagnostics=json
+  tsuki build --compile --board-option cpu_speed=240
+  tsuki build --compile --report`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dir := projectDir()
 			m, err := manifest.Load(dir)
@@ -401,22 +609,85 @@ func newBuildCmd() *cobra.Command {
 				return err
 			}
 
+			if diagnostics != "" && diagnostics != "json" {
+				return fmt.Errorf("--diagnostics only supports \"json\", got %q", diagnostics)
+			}
+
 			opts := Options{
-				Board:          board,
-				Compile:        compile,
-				PreparePackage: preparePackage,
-				OutputDir:      output,
-				Verbose:        verbose,
-				CoreBin:        cfg.CoreBinary,
-				ArduinoCLI:     cfg.ArduinoCLI,
-				SourceMap:      m.Build.SourceMap,
+				Board:               board,
+				Compile:             compile,
+				PreparePackage:      preparePackage,
+				OutputDir:           output,
+				Verbose:             verbose,
+				CoreBin:             cfg.CoreBinary,
+				ArduinoCLI:          cfg.ArduinoCLI,
+				FlashBinary:         cfg.FlashBinary,
+				SourceMap:           m.Build.SourceMap,
+				CompilationDatabase: compilationDatabase,
+				NoAutoInstall:       noAutoInstall,
+				SignKey:             signKey,
+				NativePackages:      parseCommaList(nativePackage),
+				DiagnosticsJSON:     diagnostics == "json",
+				BoardOptions:        boardOptions,
+				Jobs:                jobs,
+			}
+
+			if signKey != "" && !preparePackage {
+				return fmt.Errorf("--sign requires --prepare-package")
+			}
+			if len(opts.NativePackages) > 0 && !compile {
+				return fmt.Errorf("--native-package requires --compile")
+			}
+			if len(opts.BoardOptions) > 0 && !compile {
+				return fmt.Errorf("--board-option requires --compile")
+			}
+			if report && !compile {
+				return fmt.Errorf("--report requires --compile")
+			}
+			if progress != "" && !compile {
+				return fmt.Errorf("--progress requires --compile")
+			}
+
+			var progressCh chan ui.TaskProgress
+			var progressDone chan struct{}
+			if progress != "" {
+				progressCh = make(chan ui.TaskProgress)
+				progressDone = make(chan struct{})
+				go func() {
+					ui.RenderProgress(progressCh, ui.UseJSONProgress(progress), os.Stdout)
+					close(progressDone)
+				}()
+				opts.Progress = progressCh
 			}
 
 			res, err := Run(dir, m, opts)
+
+			if progressCh != nil {
+				close(progressCh)
+				<-progressDone
+			}
+
 			if err != nil {
 				return err
 			}
 
+			if report {
+				resolvedBoard := board
+				if resolvedBoard == "" {
+					resolvedBoard = m.Board
+				}
+				choice := findBoardChoice(boardChoices, resolvedBoard)
+				row := sizeReportRow{Target: choice.id, Status: "pass", FlashCapacity: choice.flashBytes, RAMCapacity: choice.ramBytes}
+				if flashUsed, ramUsed, ok := parseCompileSizes(res.CompileOutput); ok {
+					row.FlashBytes = flashUsed
+					row.RAMBytes = ramUsed
+				}
+				renderSizeDashboard([]sizeReportRow{row})
+				if err := writeSizeReport(dir, []sizeReportRow{row}); err != nil {
+					return fmt.Errorf("writing size report: %w", err)
+				}
+			}
+
 			if res.SketchDir != "" {
 				ui.Step("sketch", res.SketchDir)
 			}
@@ -426,6 +697,12 @@ func newBuildCmd() *cobra.Command {
 			if res.PackagePath != "" {
 				ui.Step("package", res.PackagePath)
 			}
+			if res.CompileCommandsDB != "" {
+				ui.Step("compile_commands.json", res.CompileCommandsDB)
+			}
+			for _, p := range res.NativePackages {
+				ui.Step("native package", p)
+			}
 			ui.Success("Build finished!")
 			return nil
 		},
@@ -436,9 +713,282 @@ func newBuildCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&compile, "compile", "c", false, "compile to firmware after transpile")
 	cmd.Flags().BoolVar(&preparePackage, "prepare-package", false, "pack project into a .tskp archive after build")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	cmd.Flags().BoolVar(&compilationDatabase, "compilation-database", true, "write compile_commands.json for clangd")
+	cmd.Flags().BoolVar(&noAutoInstall, "no-auto-install", false, "fail instead of auto-installing packages imported by src/")
+	cmd.Flags().StringVar(&signKey, "sign", "", "sign the .tskp with an encrypted Ed25519 key (requires --prepare-package)")
+	cmd.Flags().StringVar(&nativePackage, "native-package", "", "comma-separated native package formats to emit (deb,rpm,apk) — requires --compile")
+	cmd.Flags().StringVar(&diagnostics, "diagnostics", "", `diagnostics output format: "json" streams NDJSON on stdout for editor/LSP consumers`)
+	cmd.Flags().StringArrayVar(&boardOptions, "board-option", nil, "board build property as key=value (repeatable), forwarded to arduino-cli as --build-property (requires --compile)")
+	cmd.Flags().IntVar(&jobs, "jobs", runtime.NumCPU(), "max concurrent transpile workers")
+	cmd.Flags().BoolVar(&report, "report", false, "print the flash/RAM size dashboard and write build/size-report.json (requires --compile)")
+	cmd.Flags().StringVar(&progress, "progress", "", `stream compile progress as TaskProgress events: "auto" (spinner, or NDJSON when stdout isn't a TTY) or "json" to force NDJSON (requires --compile)`)
 	return cmd
 }
 
+// parseCommaList splits a comma-separated flag value, trimming whitespace
+// and dropping empty entries.
+func parseCommaList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ── Parallel transpile ────────────────────────────────────────────────────────
+
+// transpileParams bundles everything transpileAll's worker pool needs, so
+// the worker closure doesn't have to capture a dozen loose locals from Run.
+type transpileParams struct {
+	goFiles           []string
+	sketchDir         string
+	board             string
+	libsDir           string
+	pkgNames          []string
+	sourceMap         bool
+	diagnosticsJSON   bool
+	jobs              int
+	transpiler        *core.Transpiler
+	objCache          *cache.Store
+	transpilerVersion string
+	cppStd            string
+	pkgDigest         string
+	result            *Result // only Diagnostics is appended to, under mu
+}
+
+// transpileOutcome is one goFile's result, indexed by its position in
+// goFiles so the caller can rebuild CppFiles/Warnings in deterministic
+// (input) order regardless of which worker finished first.
+type transpileOutcome struct {
+	cppFile  string
+	warnings []string
+	cached   bool
+	err      error
+}
+
+// transpileAll runs p.transpiler.Transpile over p.goFiles through a bounded
+// worker pool (p.jobs workers, runtime.NumCPU() if unset), showing a
+// SpinnerGroup with one row per in-flight file. On the first error every
+// worker stops picking up new files — in-flight ones are allowed to finish
+// rather than killed outright — and that error is returned alongside every
+// warning collected from files that did complete.
+func transpileAll(p transpileParams) (cppFiles, warnings []string, cacheHits, cacheMisses int, err error) {
+	jobs := p.jobs
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(p.goFiles) {
+		jobs = len(p.goFiles)
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	outcomes := make([]transpileOutcome, len(p.goFiles))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex // guards outcomes' shared counters, result.Diagnostics, and stdout NDJSON writes
+	var firstErr error
+	var firstErrOnce sync.Once
+
+	type job struct {
+		idx    int
+		goFile string
+	}
+	queue := make(chan job)
+	group := ui.NewSpinnerGroup(jobs)
+
+	var wg sync.WaitGroup
+	for slot := 0; slot < jobs; slot++ {
+		wg.Add(1)
+		go func(slot int) {
+			defer wg.Done()
+			for j := range queue {
+				select {
+				case <-ctx.Done():
+					continue // a prior file failed — drain the queue without doing more work
+				default:
+				}
+
+				base := strings.TrimSuffix(filepath.Base(j.goFile), ".go")
+				cppFile := filepath.Join(p.sketchDir, base+".cpp")
+				group.Update(slot, filepath.Base(j.goFile))
+
+				key, keyErr := transpileCacheKey(j.goFile, p.transpilerVersion, p.board, p.cppStd, p.pkgDigest, p.sourceMap)
+				if keyErr == nil && !p.diagnosticsJSON {
+					if err := p.objCache.Copy(key, cppFile); err == nil {
+						mu.Lock()
+						cacheHits++
+						mu.Unlock()
+						outcomes[j.idx] = transpileOutcome{cppFile: cppFile, cached: true}
+						group.Finish(slot, true, fmt.Sprintf("%s  →  %s  (cached)", filepath.Base(j.goFile), filepath.Base(cppFile)))
+						continue
+					}
+				}
+				mu.Lock()
+				cacheMisses++
+				mu.Unlock()
+
+				tr, transpileErr := p.transpiler.Transpile(core.TranspileRequest{
+					InputFile:       j.goFile,
+					OutputFile:      cppFile,
+					Board:           p.board,
+					SourceMap:       p.sourceMap,
+					LibsDir:         p.libsDir,
+					PkgNames:        p.pkgNames,
+					DiagnosticsJSON: p.diagnosticsJSON,
+				})
+				if tr != nil {
+					mu.Lock()
+					p.result.Diagnostics = append(p.result.Diagnostics, tr.Diagnostics...)
+					if p.diagnosticsJSON {
+						for _, d := range tr.Diagnostics {
+							if line, marshalErr := json.Marshal(d); marshalErr == nil {
+								fmt.Fprintln(os.Stdout, string(line))
+							}
+						}
+					}
+					mu.Unlock()
+				}
+				if transpileErr != nil {
+					outcomes[j.idx] = transpileOutcome{err: transpileErr}
+					group.Finish(slot, false, fmt.Sprintf("failed: %s", filepath.Base(j.goFile)))
+					firstErrOnce.Do(func() {
+						firstErr = transpileErr
+						cancel()
+					})
+					continue
+				}
+
+				outcomes[j.idx] = transpileOutcome{cppFile: tr.OutputFile, warnings: tr.Warnings}
+				group.Finish(slot, true, fmt.Sprintf("%s  →  %s", filepath.Base(j.goFile), filepath.Base(cppFile)))
+
+				if keyErr == nil && !p.diagnosticsJSON {
+					if _, storeErr := p.objCache.Store(key, tr.OutputFile); storeErr != nil {
+						mu.Lock()
+						ui.Warn(fmt.Sprintf("could not cache %s: %v", filepath.Base(tr.OutputFile), storeErr))
+						mu.Unlock()
+					}
+				}
+			}
+		}(slot)
+	}
+
+	for i, goFile := range p.goFiles {
+		queue <- job{idx: i, goFile: goFile}
+	}
+	close(queue)
+	wg.Wait()
+	group.WaitLabeled("transpiled")
+
+	for _, out := range outcomes {
+		if out.cppFile == "" {
+			continue // skipped (cancelled) or errored — nothing to contribute
+		}
+		cppFiles = append(cppFiles, out.cppFile)
+		warnings = append(warnings, out.warnings...)
+	}
+	return cppFiles, warnings, cacheHits, cacheMisses, firstErr
+}
+
+// ── Incremental transpile cache ───────────────────────────────────────────────
+
+// transpileCacheDigest folds the manifest's declared package versions into
+// a single string (sorted by name, so dependency order in the manifest
+// doesn't change the key). pkgNames is the same slice already passed to
+// the transpiler, reused here so the digest only reflects packages this
+// build actually uses.
+func transpileCacheDigest(pkgNames []string, packages []manifest.Package) string {
+	versions := make(map[string]string, len(packages))
+	for _, p := range packages {
+		versions[p.Name] = p.Version
+	}
+	names := append([]string(nil), pkgNames...)
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"@"+versions[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// transpileCacheKey hashes everything that affects a single .go file's
+// transpiled .cpp output: the source itself, the transpiler version, the
+// target board, the C++ standard, the resolved package versions, and
+// whether source maps were requested (a cached .cpp from a non-source-map
+// build isn't reusable for a --source-map one, and vice versa).
+func transpileCacheKey(goFile, transpilerVersion, board, cppStd, pkgDigest string, sourceMap bool) (string, error) {
+	src, err := os.ReadFile(goFile)
+	if err != nil {
+		return "", err
+	}
+	return cache.Key(string(src), transpilerVersion, board, cppStd, pkgDigest, fmt.Sprintf("sourcemap=%v", sourceMap)), nil
+}
+
+// ── Compile via a platform pack ────────────────────────────────────────────────
+
+// compileWithPlatform builds sketchDir for a board id that an installed
+// platform pack maps to variant, bypassing arduino-cli entirely: it drives
+// tsuki-flash with the pack's variant, linker script, and extra flags
+// directly, the way PlatformIO drives its own platform backends.
+func compileWithPlatform(p pkgmgr.Platform, variant string, opts Options, sketchDir, buildCacheDir string) (string, error) {
+	flashBin := opts.FlashBinary
+	if flashBin == "" {
+		flashBin = "tsuki-flash"
+	}
+
+	args := []string{
+		"compile",
+		"--variant", variant,
+		"--build-path", buildCacheDir,
+	}
+	if p.MCU != "" {
+		args = append(args, "--mcu", p.MCU)
+	}
+	if p.FCPU != "" {
+		args = append(args, "--f-cpu", p.FCPU)
+	}
+	if p.Ldscript != "" {
+		args = append(args, "--ldscript", p.Ldscript)
+	}
+	if len(p.ExtraFlags) > 0 {
+		args = append(args, "--extra-flags", strings.Join(p.ExtraFlags, " "))
+	}
+	if opts.Verbose {
+		args = append(args, "--verbose")
+	}
+	args = append(args, sketchDir)
+
+	sp := ui.NewSpinner(fmt.Sprintf("%s compile --variant %s  [platform: %s]", flashBin, variant, p.Name))
+	sp.Start()
+
+	cmd := exec.Command(flashBin, args...)
+	cmd.Dir = sketchDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "compilation failed")
+		renderArduinoError(string(out))
+		return "", fmt.Errorf("%s compile failed", flashBin)
+	}
+	sp.Stop(true, fmt.Sprintf("firmware written to %s", buildCacheDir))
+
+	if hexFiles, _ := filepath.Glob(filepath.Join(buildCacheDir, "*.hex")); len(hexFiles) > 0 {
+		return hexFiles[0], nil
+	}
+	if binFiles, _ := filepath.Glob(filepath.Join(buildCacheDir, "*.bin")); len(binFiles) > 0 {
+		return binFiles[0], nil
+	}
+	return "", nil
+}
+
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
 func writeInoStub(sketchDir, sketchName string, _ []string) error {
@@ -505,23 +1055,5 @@ func renderArduinoError(output string) {
 	ui.Traceback("CompileError", errMsg, frames)
 }
 
-func boardFQBN(id string) (string, error) {
-	table := map[string]string{
-		"uno":      "arduino:avr:uno",
-		"nano":     "arduino:avr:nano",
-		"mega":     "arduino:avr:mega",
-		"leonardo": "arduino:avr:leonardo",
-		"micro":    "arduino:avr:micro",
-		"due":      "arduino:sam:arduino_due_x",
-		"mkr1000":  "arduino:samd:mkr1000",
-		"esp32":    "esp32:esp32:esp32",
-		"esp8266":  "esp8266:esp8266:generic",
-		"pico":     "rp2040:rp2040:rpipico",
-		"teensy40": "teensy:avr:teensy40",
-	}
-	fqbn, ok := table[strings.ToLower(id)]
-	if !ok {
-		return "", fmt.Errorf("unknown board")
-	}
-	return fqbn, nil
-}
\ No newline at end of file
+// boardFQBN is defined in boards.go — it used to be a static table here,
+// but now resolves dynamically against installed arduino-cli cores.
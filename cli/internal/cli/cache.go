@@ -0,0 +1,110 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: cache  —  inspect/prune the incremental transpile cache
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/cache"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and prune the incremental build cache",
+	}
+	cmd.AddCommand(newCacheStatsCmd())
+	cmd.AddCommand(newCachePruneCmd())
+	return cmd
+}
+
+func newCacheStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "stats",
+		Short:   "Show object count and size for the transpile cache",
+		Example: `  tsuki cache stats`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := cache.New("").Stats()
+			if err != nil {
+				return fmt.Errorf("reading cache: %w", err)
+			}
+			ui.Step("objects", fmt.Sprintf("%d", st.Objects))
+			ui.Step("size", formatCacheBytes(st.TotalSize))
+			ui.Step("location", cache.DefaultDir())
+			return nil
+		},
+	}
+}
+
+func newCachePruneCmd() *cobra.Command {
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete cached objects not touched in a while",
+		Long: `prune removes transpile-cache objects whose last lookup is older than
+--older-than (e.g. "30d", "12h", "90m"). A fresh cache is reseeded on the
+next build for anything it deletes — there's nothing destructive about
+over-pruning beyond losing the speedup.`,
+		Example: `  tsuki cache prune --older-than 30d
+  tsuki cache prune --older-than 12h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			removed, freed, err := cache.New("").Prune(age)
+			if err != nil {
+				return fmt.Errorf("pruning cache: %w", err)
+			}
+			ui.Success(fmt.Sprintf("removed %d object(s), freed %s", removed, formatCacheBytes(freed)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "30d", `prune objects not looked up in this long (e.g. "30d", "12h")`)
+	return cmd
+}
+
+// parseAge parses a duration with an additional "d" (day) unit on top of
+// what time.ParseDuration already accepts, since "--older-than 30d" reads
+// far more naturally than "--older-than 720h".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --older-than %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --older-than %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// formatCacheBytes renders a byte count the way ui's equivalent formatter
+// does, for the couple of lines this command prints outside ui.Step.
+func formatCacheBytes(n int64) string {
+	if n <= 0 {
+		return "0 B"
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
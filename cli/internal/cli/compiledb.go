@@ -0,0 +1,201 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: compiledb  —  compile_commands.json for clangd
+//
+//  Mirrors the shape arduino-cli's builder produces via its own
+//  SaveCompilationDatabase step, so editors configured against a real
+//  Arduino sketch and ones transpiled by tsuki behave the same way.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsuki/cli/internal/pkgmgr"
+)
+
+// compileCommand is one entry of a compile_commands.json (clangd/JSON
+// Compilation Database Format Specification). Both Command and Arguments
+// are populated (clangd accepts either; some other consumers only look
+// for one or the other) so downstream tooling doesn't need to re-split
+// Command on whitespace.
+type compileCommand struct {
+	Directory string   `json:"directory"`
+	Command   string   `json:"command"`
+	Arguments []string `json:"arguments"`
+	File      string   `json:"file"`
+}
+
+// toolchain holds the board-specific bits needed to synthesize a compiler
+// invocation for clangd: which cross-compiler, which extra flags (AVR's
+// -mmcu=... among them), the board's clock speed, and its ARDUINO_*
+// defines.
+type toolchain struct {
+	compiler   string
+	extraFlags []string
+	fcpu       string
+	defines    []string
+}
+
+// boardToolchain returns the synthesized-compile-command toolchain for a
+// board id (the same ids accepted by boardFQBN), sourced from the
+// internal/boards registry rather than a table of its own — so there's one
+// place that knows a board's compiler/f_cpu/defines, not two that can
+// drift apart.
+func boardToolchain(id string) (toolchain, error) {
+	b, err := boardMetadata(id)
+	if err != nil {
+		return toolchain{}, err
+	}
+	if b.Compiler == "" {
+		return toolchain{}, fmt.Errorf("board %q has no known compiler — pass a full FQBN and install its core", id)
+	}
+	return toolchain{
+		compiler:   b.Compiler,
+		extraFlags: b.ExtraFlags,
+		fcpu:       b.FCPU,
+		defines:    b.Defines,
+	}, nil
+}
+
+// arduinoCoreIncludePaths best-effort locates the installed Arduino core +
+// variant headers for fqbn under the standard ~/.arduino15 packages tree.
+// Returns nil (not an error) when the core isn't installed locally —
+// clangd will just report those headers as unresolved, same as it would
+// for any sketch opened before `arduino-cli core install` has run.
+func arduinoCoreIncludePaths(fqbn string) []string {
+	parts := strings.SplitN(fqbn, ":", 3)
+	if len(parts) != 3 {
+		return nil
+	}
+	vendor, arch := parts[0], parts[1]
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	base := filepath.Join(home, ".arduino15", "packages", vendor, "hardware", arch)
+
+	var paths []string
+	if dirs, _ := filepath.Glob(filepath.Join(base, "*", "cores", "arduino")); len(dirs) > 0 {
+		paths = append(paths, dirs...)
+	}
+	if dirs, _ := filepath.Glob(filepath.Join(base, "*", "variants", "*")); len(dirs) > 0 {
+		paths = append(paths, dirs...)
+	}
+	return paths
+}
+
+// packageIncludePaths returns one -I directory per installed tsukilib
+// package (the directory holding its tsukilib.toml + headers).
+func packageIncludePaths() []string {
+	pkgs, err := pkgmgr.ListInstalled()
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(pkgs))
+	for _, p := range pkgs {
+		paths = append(paths, filepath.Dir(p.Path))
+	}
+	return paths
+}
+
+// writeCompilationDatabase synthesizes a compile_commands.json for the
+// generated .cpp files in sketchDir, matching what arduino-cli's builder
+// would emit for the same board — without requiring avr-gcc/arduino-cli
+// to actually be installed. cppStd comes from the project manifest
+// (falls back to "c++11", the same default manifest.Load applies).
+func writeCompilationDatabase(sketchDir string, cppFiles []string, board, cppStd string) (string, error) {
+	fqbn, err := boardFQBN(board)
+	if err != nil {
+		return "", err
+	}
+	tc, err := boardToolchain(board)
+	if err != nil {
+		return "", err
+	}
+	if cppStd == "" {
+		cppStd = "c++11"
+	}
+
+	var includeFlags []string
+	for _, dir := range arduinoCoreIncludePaths(fqbn) {
+		includeFlags = append(includeFlags, "-I"+dir)
+	}
+	for _, dir := range packageIncludePaths() {
+		includeFlags = append(includeFlags, "-I"+dir)
+	}
+
+	defines := []string{"-DARDUINO=10819", "-DF_CPU=" + tc.fcpu}
+	for _, d := range tc.defines {
+		defines = append(defines, "-D"+d)
+	}
+
+	var entries []compileCommand
+	for _, cppFile := range cppFiles {
+		objFile := cppFile + ".o"
+		args := []string{tc.compiler}
+		args = append(args, tc.extraFlags...)
+		args = append(args, defines...)
+		args = append(args, includeFlags...)
+		args = append(args, "-std="+cppStd, "-o", objFile, "-c", cppFile)
+
+		entries = append(entries, compileCommand{
+			Directory: sketchDir,
+			Command:   strings.Join(args, " "),
+			Arguments: args,
+			File:      cppFile,
+		})
+	}
+
+	return writeCompileCommandsJSON(sketchDir, entries)
+}
+
+// compilationDatabaseFromArduinoCLI asks arduino-cli itself for the real
+// compile commands (it supports --only-compilation-database to emit
+// compile_commands.json into --build-path without compiling) and copies
+// the result into sketchDir. Used after a real --compile run so clangd
+// sees the exact flags arduino-cli would use, rather than tsuki's
+// synthesized approximation.
+func compilationDatabaseFromArduinoCLI(arduinoCLI, fqbn, sketchDir, buildCacheDir string) (string, error) {
+	if arduinoCLI == "" {
+		arduinoCLI = "arduino-cli"
+	}
+	cmd := exec.Command(arduinoCLI,
+		"compile",
+		"--fqbn", fqbn,
+		"--build-path", buildCacheDir,
+		"--only-compilation-database",
+		sketchDir,
+	)
+	cmd.Dir = sketchDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("arduino-cli --only-compilation-database: %w\n%s", err, out)
+	}
+
+	src := filepath.Join(buildCacheDir, "compile_commands.json")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("reading generated compile_commands.json: %w", err)
+	}
+
+	dst := filepath.Join(sketchDir, "compile_commands.json")
+	return dst, os.WriteFile(dst, data, 0644)
+}
+
+func writeCompileCommandsJSON(sketchDir string, entries []compileCommand) (string, error) {
+	if entries == nil {
+		entries = []compileCommand{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	outPath := filepath.Join(sketchDir, "compile_commands.json")
+	return outPath, os.WriteFile(outPath, data, 0644)
+}
@@ -0,0 +1,157 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: config  —  read/write persistent settings + registry tokens
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/credentials"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newConfigCmd() *cobra.Command {
+	var raw bool
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and change persistent tsuki settings",
+		Long: `config reads and writes the settings stored at ` + configPathHint() + `.
+
+Run with no subcommand to list every setting.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigList(raw)
+		},
+	}
+
+	cmd.Flags().BoolVar(&raw, "raw", false, "print as key=value pairs, no box drawing")
+
+	cmd.AddCommand(
+		newConfigGetCmd(),
+		newConfigSetCmd(),
+		newConfigLoginCmd(),
+		newConfigLogoutCmd(),
+	)
+	return cmd
+}
+
+func configPathHint() string {
+	path, err := config.Path()
+	if err != nil {
+		return "~/.config/tsuki/config.json"
+	}
+	return path
+}
+
+func runConfigList(raw bool) error {
+	entries := cfg.AllEntries()
+	uiEntries := make([]ui.ConfigEntry, len(entries))
+	for i, e := range entries {
+		uiEntries[i] = ui.ConfigEntry{Key: e.Key, Value: e.Value, Comment: e.Comment}
+	}
+	ui.PrintConfig("tsuki config", uiEntries, raw)
+	return nil
+}
+
+func newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a single config key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%v\n", v)
+			return nil
+		},
+	}
+}
+
+func newConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config key and persist it",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("saving config: %w", err)
+			}
+			ui.Success(fmt.Sprintf("%s = %s", args[0], args[1]))
+			return nil
+		},
+	}
+}
+
+// newConfigLoginCmd stores a token for host via the configured (or default)
+// credential helper, then records the helper choice in CredentialHelpers.
+func newConfigLoginCmd() *cobra.Command {
+	var helper, token string
+
+	cmd := &cobra.Command{
+		Use:   "login <host>",
+		Short: "Store a registry token via a credential helper (or OS keychain)",
+		Long: `login stores a token for <host> (e.g. github.com, gitlab.com) so
+push and package installs can authenticate without GITHUB_TOKEN or --token.
+
+By default the token is stored in the OS keychain (macOS Keychain, libsecret
+on Linux, Windows Credential Manager). Pass --helper to use a
+docker-credential-* helper binary instead (e.g. --helper pass).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host := args[0]
+			if token == "" {
+				return fmt.Errorf("--token is required (paste the value, or set it via your shell's read -s and pass $TOKEN)")
+			}
+			if err := credentials.Store(helper, host, token); err != nil {
+				return fmt.Errorf("storing credential for %s: %w", host, err)
+			}
+			if helper != "" {
+				if cfg.CredentialHelpers == nil {
+					cfg.CredentialHelpers = map[string]string{}
+				}
+				cfg.CredentialHelpers[host] = helper
+				if err := cfg.Save(); err != nil {
+					return fmt.Errorf("saving config: %w", err)
+				}
+			}
+			ui.Success(fmt.Sprintf("stored credential for %s", host))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&helper, "helper", "", "docker-credential-* helper name (default: OS keychain)")
+	cmd.Flags().StringVar(&token, "token", "", "token to store")
+	return cmd
+}
+
+func newConfigLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <host>",
+		Short: "Erase a stored registry token for host",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host := args[0]
+			helper := cfg.ResolvedCredentialHelper(host)
+			if err := credentials.Erase(helper, host); err != nil {
+				return fmt.Errorf("erasing credential for %s: %w", host, err)
+			}
+			if helper != "" {
+				delete(cfg.CredentialHelpers, host)
+				if err := cfg.Save(); err != nil {
+					return fmt.Errorf("saving config: %w", err)
+				}
+			}
+			ui.Success(fmt.Sprintf("removed credential for %s", host))
+			return nil
+		},
+	}
+}
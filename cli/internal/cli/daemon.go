@@ -0,0 +1,156 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: daemon  —  long-running service mode for editor plugins
+//
+//  daemonHandler implements rpc.Handler directly over the same Run/pkgmgr/
+//  boards functions the cobra commands call, so `tsuki daemon` and `tsuki
+//  build` behave identically by construction — there's one build pipeline,
+//  not a CLI copy and an RPC copy that can drift apart.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/manifest"
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/rpc"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// daemonHandler adapts the build pipeline to rpc.Handler.
+type daemonHandler struct{}
+
+func (daemonHandler) Transpile(req rpc.TranspileRequest, progress rpc.ProgressFunc) (rpc.TranspileResponse, error) {
+	progress(rpc.TaskProgress{Name: "transpile", Completed: false})
+	m, err := manifest.Load(req.ProjectDir)
+	if err != nil {
+		return rpc.TranspileResponse{}, err
+	}
+	res, err := Run(req.ProjectDir, m, Options{Board: req.Board, CompilationDatabase: true})
+	if err != nil {
+		return rpc.TranspileResponse{}, err
+	}
+	progress(rpc.TaskProgress{Name: "transpile", Completed: true, Percent: 100})
+	return rpc.TranspileResponse{CppFiles: res.CppFiles, SketchDir: res.SketchDir}, nil
+}
+
+func (daemonHandler) Build(req rpc.BuildRequest, progress rpc.ProgressFunc) (rpc.BuildResponse, error) {
+	progress(rpc.TaskProgress{Name: "build", Completed: false})
+	m, err := manifest.Load(req.ProjectDir)
+	if err != nil {
+		return rpc.BuildResponse{}, err
+	}
+	res, err := Run(req.ProjectDir, m, Options{
+		Board:               req.Board,
+		Compile:             req.Compile,
+		PreparePackage:      req.PreparePackage,
+		OutputDir:           req.OutputDir,
+		CompilationDatabase: req.CompilationDatabase,
+		NoAutoInstall:       req.NoAutoInstall,
+		BoardOptions:        req.BoardOptions,
+		ArduinoCLI:          cfg.ArduinoCLI,
+		CoreBin:             cfg.CoreBinary,
+		SourceMap:           m.Build.SourceMap,
+	})
+	if err != nil {
+		return rpc.BuildResponse{}, err
+	}
+	progress(rpc.TaskProgress{Name: "build", Completed: true, Percent: 100})
+	return rpc.BuildResponse{
+		SketchDir:         res.SketchDir,
+		FirmwareHex:       res.FirmwareHex,
+		Warnings:          res.Warnings,
+		PackagePath:       res.PackagePath,
+		CompileCommandsDB: res.CompileCommandsDB,
+	}, nil
+}
+
+func (daemonHandler) Compile(req rpc.CompileRequest, progress rpc.ProgressFunc) (rpc.CompileResponse, error) {
+	progress(rpc.TaskProgress{Name: "compile", Completed: false})
+	m, err := manifest.Load(req.ProjectDir)
+	if err != nil {
+		return rpc.CompileResponse{}, err
+	}
+	res, err := Run(req.ProjectDir, m, Options{
+		Board:      req.Board,
+		Compile:    true,
+		ArduinoCLI: cfg.ArduinoCLI,
+		CoreBin:    cfg.CoreBinary,
+	})
+	if err != nil {
+		return rpc.CompileResponse{}, err
+	}
+	progress(rpc.TaskProgress{Name: "compile", Completed: true, Percent: 100})
+	return rpc.CompileResponse{FirmwareHex: res.FirmwareHex, Warnings: res.Warnings}, nil
+}
+
+func (daemonHandler) PackageInstall(req rpc.PackageInstallRequest, progress rpc.ProgressFunc) (rpc.PackageInstallResponse, error) {
+	progress(rpc.TaskProgress{Name: "package_install", Completed: false})
+	pkg, err := pkgmgr.Install(pkgmgr.InstallOptions{
+		Spec:   req.Spec,
+		Dir:    req.Dir,
+		Global: req.Global,
+		Dev:    req.Dev,
+	})
+	if err != nil {
+		return rpc.PackageInstallResponse{}, err
+	}
+	progress(rpc.TaskProgress{Name: "package_install", Completed: true, Percent: 100})
+	return rpc.PackageInstallResponse{Name: pkg.Name, Version: pkg.Version}, nil
+}
+
+func (daemonHandler) BoardList(rpc.BoardListRequest) (rpc.BoardListResponse, error) {
+	list := loadBoardRegistry().List()
+	out := make([]rpc.BoardInfo, 0, len(list))
+	for _, b := range list {
+		out = append(out, rpc.BoardInfo{ID: b.ID, FQBN: b.FQBN, FCPU: b.FCPU, Aliases: b.Aliases, Source: b.Source})
+	}
+	return rpc.BoardListResponse{Boards: out}, nil
+}
+
+// ── Cobra command ─────────────────────────────────────────────────────────────
+
+func newDaemonCmd() *cobra.Command {
+	var transport string
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run tsuki as a long-lived service for editor integrations",
+		Long: `daemon runs the build pipeline as a long-lived service speaking a
+structured request/response protocol, instead of a one-shot process editors
+shell out to and scrape stderr from. It exposes Transpile, Build, Compile,
+PackageInstall, and BoardList.
+
+The default transport is JSON lines on stdin/stdout: one JSON object per
+line in, one or more out (zero or more {"progress": ...} lines reporting
+TaskProgress as the call runs, so a plugin can show real progress instead
+of a spinner it can't see, followed by one {"result": ...} or {"error":
+...} line per request). --transport grpc serves the same methods over
+gRPC on --addr instead, for plugins that want a long-lived socket and a
+typed client stub rather than hand-decoding JSON off stdout — each call
+there runs to completion and returns its result in one response, without
+the intermediate progress events the JSON-lines transport streams.`,
+		Example: `  tsuki daemon
+  tsuki daemon --transport jsonl
+  tsuki daemon --transport grpc --addr localhost:50051`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch transport {
+			case "", "jsonl":
+				return rpc.ServeJSONLines(os.Stdin, os.Stdout, daemonHandler{})
+			case "grpc":
+				ui.Info(fmt.Sprintf("listening on %s (grpc)", addr))
+				return rpc.ServeGRPC(addr, daemonHandler{})
+			default:
+				return fmt.Errorf("unknown --transport %q (want jsonl or grpc)", transport)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&transport, "transport", "jsonl", "protocol to serve: jsonl (stdin/stdout) or grpc")
+	cmd.Flags().StringVar(&addr, "addr", "localhost:50051", "address to listen on for --transport grpc")
+	return cmd
+}
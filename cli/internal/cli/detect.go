@@ -0,0 +1,150 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: detect  —  wire internal/detector into the build pipeline
+//
+//  internal/detector only resolves headers; this file owns the UI (confirm,
+//  traceback) and the actual `lib install` invocation, the same split
+//  autoinstall.go draws between scanning Go imports and pkgmgr.Install.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tsuki/cli/internal/detector"
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// detectAndResolveIncludes scans cppFiles for #include directives and makes
+// sure every header is satisfied — by an installed tsuki package, by the
+// board core's own headers, or by an Arduino library this auto-installs via
+// `arduino-cli lib install` / `tsuki-flash lib install`. With noAutoInstall
+// it reports what's missing instead of installing anything.
+func detectAndResolveIncludes(projectDir string, cppFiles []string, board, baseOutDir string, noAutoInstall bool) error {
+	if len(cppFiles) == 0 {
+		return nil
+	}
+
+	var coreIncludeDirs []string
+	if fqbn, err := boardFQBN(board); err == nil {
+		coreIncludeDirs = arduinoCoreIncludePaths(fqbn)
+	}
+
+	installed, _ := pkgmgr.ListInstalled()
+	tsukiPackages := make([]detector.TsukiPackage, 0, len(installed))
+	for _, p := range installed {
+		tsukiPackages = append(tsukiPackages, detector.TsukiPackage{Name: p.Name, CppHeader: p.CppHeader})
+	}
+
+	cachePath := filepath.Join(baseOutDir, ".cache", "includes.json")
+	results, err := detector.Scan(cppFiles, tsukiPackages, coreIncludeDirs, cachePath)
+	if err != nil {
+		return fmt.Errorf("scanning #include directives: %w", err)
+	}
+
+	var unresolved []detector.Resolution
+	for _, r := range results {
+		if !r.Resolved {
+			unresolved = append(unresolved, r)
+		}
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	var unresolvable []detector.Resolution
+	for _, r := range unresolved {
+		if r.SuggestedLib == "" {
+			unresolvable = append(unresolvable, r)
+			continue
+		}
+
+		if noAutoInstall {
+			return fmt.Errorf(
+				"missing Arduino library %q (provides <%s>)\n  Run:\n    %s",
+				r.SuggestedLib, r.Header, libInstallHint(r.SuggestedLib),
+			)
+		}
+
+		if !ui.Confirm(fmt.Sprintf("Install Arduino library %q for <%s>?", r.SuggestedLib, r.Header), true) {
+			unresolvable = append(unresolvable, r)
+			continue
+		}
+
+		ui.Step("auto-install", r.SuggestedLib)
+		if err := installArduinoLib(r.SuggestedLib); err != nil {
+			return fmt.Errorf("installing library %q (for <%s>): %w", r.SuggestedLib, r.Header, err)
+		}
+	}
+
+	if len(unresolvable) > 0 {
+		renderUnresolvedIncludes(unresolvable)
+		return fmt.Errorf("%d #include'd header(s) could not be resolved", len(unresolvable))
+	}
+	return nil
+}
+
+// installArduinoLib installs name via tsuki-flash or arduino-cli, using the
+// same backend-selection rule pkg.go's ArduinoLib auto-install uses: prefer
+// tsuki-flash when the config says so or the binary is on PATH.
+func installArduinoLib(name string) error {
+	flashBin := cfg.FlashBinary
+	if flashBin == "" {
+		flashBin = "tsuki-flash"
+	}
+
+	useTsukiFlash := cfg.Backend == "tsuki-flash"
+	if !useTsukiFlash {
+		if _, err := exec.LookPath(flashBin); err == nil {
+			useTsukiFlash = true
+		}
+	}
+
+	bin, args := arduinoCLIBinary(), []string{"lib", "install", name}
+	if useTsukiFlash {
+		bin = flashBin
+	}
+
+	cmd := exec.Command(bin, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// arduinoCLIBinary returns the configured arduino-cli binary, defaulting
+// to "arduino-cli" on PATH.
+func arduinoCLIBinary() string {
+	if cfg.ArduinoCLI != "" {
+		return cfg.ArduinoCLI
+	}
+	return "arduino-cli"
+}
+
+// libInstallHint formats the manual install command for the backend
+// detectAndResolveIncludes would otherwise have run automatically.
+func libInstallHint(lib string) string {
+	if cfg.Backend == "tsuki-flash" {
+		return fmt.Sprintf("tsuki-flash lib install %q", lib)
+	}
+	return fmt.Sprintf("arduino-cli lib install %q", lib)
+}
+
+// renderUnresolvedIncludes reports headers detectAndResolveIncludes
+// couldn't satisfy via any of its three sources, one traceback frame per
+// header, mirroring renderArduinoError/renderTsukiFlashError's style.
+func renderUnresolvedIncludes(unresolved []detector.Resolution) {
+	var frames []ui.Frame
+	for _, r := range unresolved {
+		frames = append(frames, ui.Frame{
+			File: "detector", Func: "Resolve",
+			Code: []ui.CodeLine{{Number: 0, Text: fmt.Sprintf("#include <%s>", r.Header), IsPointer: true}},
+		})
+	}
+	ui.Traceback("UnresolvedIncludeError",
+		"no installed tsuki package, board core header, or known Arduino library satisfies this #include",
+		frames)
+}
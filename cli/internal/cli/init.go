@@ -16,14 +16,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
-	"unsafe"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/tsuki/cli/internal/manifest"
+	"github.com/tsuki/cli/internal/templates"
 	"github.com/tsuki/cli/internal/ui"
+	"github.com/tsuki/cli/internal/ui/tty"
 )
 
 // ── Color aliases for the wizard ─────────────────────────────────────────────
@@ -55,19 +55,32 @@ type boardChoice struct {
 	id   string
 	name string
 	note string
+
+	// pioPlatform/pioEnv are PlatformIO's names for this board — used only
+	// by the optional platformio.ini step in scaffold(). Boards discovered
+	// live from arduino-cli (see wizardBoardChoices) don't have a PlatformIO
+	// equivalent on file, so these are left blank for those.
+	pioPlatform string
+	pioEnv      string
+
+	// flashBytes/ramBytes are the board's known program storage and
+	// dynamic memory capacity, used only to compute the percentages shown
+	// by the post-scaffold smoke-build dashboard (see sizereport.go).
+	flashBytes int64
+	ramBytes   int64
 }
 
 var boardChoices = []boardChoice{
-	{"uno", "Arduino Uno", "ATmega328P · 16 MHz · 32 KB"},
-	{"nano", "Arduino Nano", "ATmega328P · 16 MHz · compact"},
-	{"mega", "Arduino Mega 2560", "ATmega2560 · 16 MHz · 256 KB"},
-	{"leonardo", "Arduino Leonardo", "ATmega32u4 · 16 MHz · native USB"},
-	{"micro", "Arduino Micro", "ATmega32u4 · 16 MHz · native USB"},
-	{"pro_mini_5v", "Pro Mini 5 V", "ATmega328P · 16 MHz · breadboard"},
-	{"esp32", "ESP32 Dev Module", "Dual-core · 240 MHz · WiFi + BT"},
-	{"esp8266", "ESP8266 Generic", "Single-core · 80 MHz · WiFi"},
-	{"d1_mini", "Wemos D1 Mini", "ESP8266 · compact · popular"},
-	{"pico", "Raspberry Pi Pico", "RP2040 · 133 MHz · 2 MB"},
+	{id: "uno", name: "Arduino Uno", note: "ATmega328P · 16 MHz · 32 KB", pioPlatform: "atmelavr", pioEnv: "uno", flashBytes: 32768, ramBytes: 2048},
+	{id: "nano", name: "Arduino Nano", note: "ATmega328P · 16 MHz · compact", pioPlatform: "atmelavr", pioEnv: "nanoatmega328", flashBytes: 32768, ramBytes: 2048},
+	{id: "mega", name: "Arduino Mega 2560", note: "ATmega2560 · 16 MHz · 256 KB", pioPlatform: "atmelavr", pioEnv: "megaatmega2560", flashBytes: 262144, ramBytes: 8192},
+	{id: "leonardo", name: "Arduino Leonardo", note: "ATmega32u4 · 16 MHz · native USB", pioPlatform: "atmelavr", pioEnv: "leonardo", flashBytes: 32256, ramBytes: 2560},
+	{id: "micro", name: "Arduino Micro", note: "ATmega32u4 · 16 MHz · native USB", pioPlatform: "atmelavr", pioEnv: "micro", flashBytes: 32256, ramBytes: 2560},
+	{id: "pro_mini_5v", name: "Pro Mini 5 V", note: "ATmega328P · 16 MHz · breadboard", pioPlatform: "atmelavr", pioEnv: "pro8MHzatmega328", flashBytes: 32768, ramBytes: 2048},
+	{id: "esp32", name: "ESP32 Dev Module", note: "Dual-core · 240 MHz · WiFi + BT", pioPlatform: "espressif32", pioEnv: "esp32dev", flashBytes: 4194304, ramBytes: 327680},
+	{id: "esp8266", name: "ESP8266 Generic", note: "Single-core · 80 MHz · WiFi", pioPlatform: "espressif8266", pioEnv: "esp8285", flashBytes: 1044464, ramBytes: 81920},
+	{id: "d1_mini", name: "Wemos D1 Mini", note: "ESP8266 · compact · popular", pioPlatform: "espressif8266", pioEnv: "d1_mini", flashBytes: 4194304, ramBytes: 81920},
+	{id: "pico", name: "Raspberry Pi Pico", note: "RP2040 · 133 MHz · 2 MB", pioPlatform: "raspberrypi", pioEnv: "pico", flashBytes: 2097152, ramBytes: 264192},
 }
 
 // ── Compiler backend choices ──────────────────────────────────────────────────
@@ -83,78 +96,22 @@ var backendChoices = []backendChoice{
 	{"arduino-cli", "arduino-cli", "classic · requires arduino-cli install"},
 }
 
-// ── Template choices ──────────────────────────────────────────────────────────
-
-type templateChoice struct {
-	id   string
-	name string
-	code string
-}
-
-var templateChoices = []templateChoice{
-	{
-		id:   "blink",
-		name: "Blink  (LED)",
-		code: `package main
-
-import "arduino"
-
-func setup() {
-	arduino.PinMode(arduino.LED_BUILTIN, arduino.OUTPUT)
-}
-
-func loop() {
-	arduino.DigitalWrite(arduino.LED_BUILTIN, arduino.HIGH)
-	arduino.Delay(500)
-	arduino.DigitalWrite(arduino.LED_BUILTIN, arduino.LOW)
-	arduino.Delay(500)
-}
-`,
-	},
-	{
-		id:   "serial",
-		name: "Serial Hello",
-		code: `package main
-
-import "arduino"
-
-func setup() {
-	arduino.SerialBegin(9600)
-}
-
-func loop() {
-	arduino.SerialPrintln("Hello from tsuki!")
-	arduino.Delay(1000)
-}
-`,
-	},
-	{
-		id:   "empty",
-		name: "Empty project",
-		code: `package main
-
-import "arduino"
-
-func setup() {
-}
-
-func loop() {
-}
-`,
-	},
-}
-
 // ─────────────────────────────────────────────────────────────────────────────
 //  Command
 // ─────────────────────────────────────────────────────────────────────────────
 
 func newInitCmd() *cobra.Command {
 	var (
-		flagBoard    string
-		flagName     string
-		flagYes      bool
-		flagBackend  string
-		flagLanguage string
+		flagBoard        string
+		flagName         string
+		flagYes          bool
+		flagBackend      string
+		flagLanguage     string
+		flagRefreshBoard bool
+		flagPlatformIO   bool
+		flagExtraBoards  []string
+		flagProgress     string
+		flagTemplate     string
 	)
 
 	cmd := &cobra.Command{
@@ -163,12 +120,13 @@ func newInitCmd() *cobra.Command {
 		Args:  cobra.MaximumNArgs(1),
 		Example: `  tsuki init
   tsuki init my-robot
-  tsuki init my-robot --board esp32 --yes`,
+  tsuki init my-robot --board esp32 --yes
+  tsuki init my-robot --template github.com/user/repo@main --yes`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				flagName = args[0]
 			}
-			return runWizard(flagName, flagBoard, flagBackend, flagLanguage, flagYes)
+			return runWizard(flagName, flagBoard, flagBackend, flagLanguage, flagTemplate, flagYes, flagRefreshBoard, flagPlatformIO, flagExtraBoards, flagProgress)
 		},
 	}
 
@@ -177,6 +135,11 @@ func newInitCmd() *cobra.Command {
 	cmd.Flags().StringVar(&flagBackend, "backend", "", "compiler backend: tsuki-flash or arduino-cli")
 	cmd.Flags().StringVarP(&flagLanguage, "language", "l", "", "programming language (go)")
 	cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "accept all defaults")
+	cmd.Flags().BoolVar(&flagRefreshBoard, "refresh-boards", false, "re-run arduino-cli board listall instead of using the (up to 24h stale) boards.json cache")
+	cmd.Flags().BoolVar(&flagPlatformIO, "platformio", false, "also emit a platformio.ini, so the project opens in PlatformIO too")
+	cmd.Flags().StringArrayVar(&flagExtraBoards, "extra-board", nil, "additional board id to smoke-build against (repeatable), alongside --board")
+	cmd.Flags().StringVar(&flagProgress, "progress", "auto", `progress output: "auto" (spinners on a TTY, NDJSON otherwise) or "json" to force NDJSON`)
+	cmd.Flags().StringVar(&flagTemplate, "template", "", "remote starter template as github.com/user/repo@ref, bypassing the template prompt/registry entirely")
 	return cmd
 }
 
@@ -184,11 +147,20 @@ func newInitCmd() *cobra.Command {
 //  Wizard runner
 // ─────────────────────────────────────────────────────────────────────────────
 
-func runWizard(prefillName, prefillBoard, prefillBackend, prefillLanguage string, acceptDefaults bool) error {
+func runWizard(prefillName, prefillBoard, prefillBackend, prefillLanguage, prefillTemplate string, acceptDefaults, refreshBoards, platformIO bool, extraBoards []string, progressFlag string) error {
 	printIntro()
 
 	reader := bufio.NewReader(os.Stdin)
 
+	// boards defaults to the hardcoded catalog (works fully offline) and
+	// is swapped for arduino-cli's own board listall — real FQBNs, every
+	// installed core's boards, not just the ten tsuki ships with — when
+	// that's available. See wizardBoardChoices.
+	boards := boardChoices
+	if discovered, err := wizardBoardChoices(refreshBoards); err == nil && len(discovered) > 0 {
+		boards = discovered
+	}
+
 	// ── 1. Project name ────────────────────────────────────────────────────
 	var projectName string
 	if prefillName != "" {
@@ -218,14 +190,14 @@ func runWizard(prefillName, prefillBoard, prefillBackend, prefillLanguage string
 	// ── 3. Board ────────────────────────────────────────────────────────────
 	var board boardChoice
 	if prefillBoard != "" {
-		board = findBoardChoice(prefillBoard)
+		board = findBoardChoice(boards, prefillBoard)
 		stepDone(3, "Target board", board.name)
 	} else if acceptDefaults {
-		board = boardChoices[0]
+		board = boards[0]
 		stepDone(3, "Target board", board.name+" (default)")
 	} else {
-		idx := promptArrowSelect(3, "Which board are you targeting?", boardChoicesLabels(), 0)
-		board = boardChoices[idx]
+		idx := promptArrowSelect(3, "Which board are you targeting?", boardChoicesLabels(boards), 0)
+		board = boards[idx]
 	}
 
 	// ── 4. Compiler backend ─────────────────────────────────────────────────
@@ -242,13 +214,25 @@ func runWizard(prefillName, prefillBoard, prefillBackend, prefillLanguage string
 	}
 
 	// ── 5. Starter template ─────────────────────────────────────────────────
-	var tmpl templateChoice
-	if acceptDefaults {
-		tmpl = templateChoices[0]
-		stepDone(5, "Starter template", tmpl.name+" (default)")
+	// --template bypasses the registry entirely with an ad-hoc git source,
+	// the same as `tsuki init --template github.com/user/repo@ref`.
+	var tmpl templates.Template
+	if prefillTemplate != "" {
+		var err error
+		tmpl, err = adhocGitTemplate(prefillTemplate)
+		if err != nil {
+			return err
+		}
+		stepDone(5, "Starter template", tmpl.Name)
 	} else {
-		idx := promptArrowSelect(5, "How should we start your project?", templateLabels(), 0)
-		tmpl = templateChoices[idx]
+		choices := templates.Load().List()
+		if acceptDefaults {
+			tmpl = choices[0]
+			stepDone(5, "Starter template", tmpl.Name+" (default)")
+		} else {
+			idx := promptArrowSelect(5, "How should we start your project?", templateChoicesLabels(choices), 0)
+			tmpl = choices[idx]
+		}
 	}
 
 	// ── 6. Git init ──────────────────────────────────────────────────────────
@@ -259,24 +243,53 @@ func runWizard(prefillName, prefillBoard, prefillBackend, prefillLanguage string
 		stepDone(6, "Git repository", "yes (default)")
 	}
 
+	// ── 7. PlatformIO project ───────────────────────────────────────────────
+	pio := platformIO
+	if !pio {
+		if !acceptDefaults {
+			pio = promptYesNo(reader, 7, "Also emit a PlatformIO project?", false)
+		} else {
+			stepDone(7, "PlatformIO project", "no (default)")
+		}
+	} else {
+		stepDone(7, "PlatformIO project", "yes (--platformio)")
+	}
+
+	// ── 8. Smoke build ──────────────────────────────────────────────────────
+	verify := false
+	if !acceptDefaults {
+		verify = promptYesNo(reader, 8, "Verify the starter compiles?", false)
+	} else {
+		stepDone(8, "Verify starter compiles", "no (default)")
+	}
+
 	// ── Scaffold ─────────────────────────────────────────────────────────────
 	fmt.Println()
 	printLine()
 	fmt.Println()
 
-	return scaffold(projectName, lang, board, backend, tmpl, gitInit)
+	if err := scaffold(projectName, lang, board, backend, tmpl, gitInit, pio, ui.UseJSONProgress(progressFlag)); err != nil {
+		return err
+	}
+
+	if verify {
+		targets := []boardChoice{board}
+		for _, id := range extraBoards {
+			targets = append(targets, findBoardChoice(boards, id))
+		}
+		return smokeBuildAndReport(filepath.Join(projectDir(), projectName), targets, backend)
+	}
+	return nil
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
 //  Scaffold
 // ─────────────────────────────────────────────────────────────────────────────
 
-func scaffold(name string, lang langChoice, board boardChoice, backend backendChoice, tmpl templateChoice, gitInit bool) error {
+func scaffold(name string, lang langChoice, board boardChoice, backend backendChoice, tmpl templates.Template, gitInit, platformIO, jsonProgress bool) error {
 	dir := filepath.Join(projectDir(), name)
 	srcDir := filepath.Join(dir, "src")
 
-	mainFile := "main.go"
-
 	steps := []struct {
 		label string
 		fn    func() error
@@ -289,12 +302,10 @@ func scaffold(name string, lang langChoice, board boardChoice, backend backendCh
 			}
 			return m.Save(dir)
 		}},
-		{fmt.Sprintf("Writing src/%s", mainFile), func() error {
-			p := filepath.Join(srcDir, mainFile)
-			if _, err := os.Stat(p); os.IsNotExist(err) {
-				return os.WriteFile(p, []byte(tmpl.code), 0644)
-			}
-			return nil
+		{"Writing starter template", func() error {
+			fqbn, _ := boardFQBN(board.id)
+			data := templateData{ProjectName: name, Board: board.id, BoardFQBN: fqbn, Backend: backend.id}
+			return writeStarterTemplate(dir, srcDir, tmpl, data)
 		}},
 		{"Writing .gitignore", func() error {
 			p := filepath.Join(dir, ".gitignore")
@@ -318,51 +329,74 @@ func scaffold(name string, lang langChoice, board boardChoice, backend backendCh
 		}})
 	}
 
-	for _, step := range steps {
-		sp := ui.NewSpinner(step.label)
-		sp.Start()
+	if platformIO {
+		steps = append(steps, struct {
+			label string
+			fn    func() error
+		}{"Writing platformio.ini", func() error {
+			return writePlatformioIni(dir, board)
+		}})
+	}
+
+	progress := make(chan ui.TaskProgress)
+	renderDone := make(chan struct{})
+	go func() {
+		ui.RenderProgress(progress, jsonProgress, os.Stdout)
+		close(renderDone)
+	}()
+
+	var stepErr error
+	for i, step := range steps {
+		percent := ui.PercentOfStep(i, len(steps))
+		progress <- ui.TaskProgress{Name: step.label, Message: step.label, Percent: percent}
 		time.Sleep(60 * time.Millisecond)
 		if err := step.fn(); err != nil {
-			sp.Stop(false, step.label)
-			return err
+			progress <- ui.TaskProgress{Name: step.label, Message: step.label, Percent: percent, Completed: true, Failed: true}
+			stepErr = err
+			break
 		}
-		sp.Stop(true, step.label)
+		progress <- ui.TaskProgress{Name: step.label, Message: step.label, Percent: percent, Completed: true}
+	}
+	close(progress)
+	<-renderDone
+
+	if stepErr != nil {
+		return stepErr
 	}
 
 	printSuccess(name, lang, board, backend)
 	return nil
 }
 
-// ─────────────────────────────────────────────────────────────────────────────
-//  Arrow-key interactive select (raw terminal mode)
-// ─────────────────────────────────────────────────────────────────────────────
+// writePlatformioIni writes a minimal platformio.ini alongside goduino.json,
+// so the scaffolded project also opens in PlatformIO with no manual
+// fixup — just the one [env:<board>] block tsuki itself needs. Boards
+// discovered live from arduino-cli (see wizardBoardChoices) have no known
+// PlatformIO platform/env, so this falls back to the board's tsuki id for
+// both, which the user will need to correct by hand.
+func writePlatformioIni(dir string, board boardChoice) error {
+	platform := board.pioPlatform
+	if platform == "" {
+		platform = board.id
+	}
+	env := board.pioEnv
+	if env == "" {
+		env = board.id
+	}
 
-// termios mirrors the Linux termios struct for raw-mode manipulation.
-type termios struct {
-	Iflag  uint32
-	Oflag  uint32
-	Cflag  uint32
-	Lflag  uint32
-	Cc     [20]byte
-	Ispeed uint32
-	Ospeed uint32
-}
+	contents := fmt.Sprintf(`[env:%s]
+platform = %s
+board = %s
+framework = arduino
+src_dir = src
+`, env, platform, env)
 
-func tcgetattr(fd uintptr, t *termios) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(t)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
+	return os.WriteFile(filepath.Join(dir, "platformio.ini"), []byte(contents), 0644)
 }
 
-func tcsetattr(fd uintptr, t *termios) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(t)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
-}
+// ─────────────────────────────────────────────────────────────────────────────
+//  Arrow-key interactive select (raw terminal mode, via internal/ui/tty)
+// ─────────────────────────────────────────────────────────────────────────────
 
 // promptArrowSelect shows a live arrow-key navigable menu.
 // Falls back to a numbered list when stdin is not a TTY (e.g. pipes, CI).
@@ -370,8 +404,9 @@ func promptArrowSelect(step int, question string, choices []string, defaultIdx i
 	stepLabel(step, question)
 	fmt.Println()
 
-	// ── Non-interactive fallback ──────────────────────────────────────────
-	if !isatty() {
+	term, ok := tty.Open()
+	if !ok {
+		// ── Non-interactive fallback ──────────────────────────────────────
 		for i, c := range choices {
 			if i == defaultIdx {
 				wGreen.Printf("   %s %d. %s\n", "●", i+1, c)
@@ -399,22 +434,14 @@ func promptArrowSelect(step int, question string, choices []string, defaultIdx i
 		return idx
 	}
 
-	// ── Raw-mode setup ────────────────────────────────────────────────────
-	fd := os.Stdin.Fd()
-	var orig termios
-	if err := tcgetattr(fd, &orig); err != nil {
+	restore, err := term.MakeRaw()
+	if err != nil {
 		return defaultIdx
 	}
-	raw := orig
-	raw.Lflag &^= syscall.ICANON | syscall.ECHO
-	raw.Cc[syscall.VMIN] = 1
-	raw.Cc[syscall.VTIME] = 0
-	_ = tcsetattr(fd, &raw)
-	defer tcsetattr(fd, &orig)
+	defer restore()
 
-	// Hide cursor while navigating.
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h")
+	term.HideCursor()
+	defer term.ShowCursor()
 
 	cur := defaultIdx
 	n := len(choices)
@@ -435,39 +462,38 @@ func promptArrowSelect(step int, question string, choices []string, defaultIdx i
 		fmt.Printf("\033[%dA", n)
 	}
 
+	// Re-render in place on a terminal resize, so the menu never ends up
+	// drawn against stale line widths.
+	ui.OnResize(func(int) { renderMenu() })
+
 	renderMenu()
 
-	buf := make([]byte, 3)
 	for {
-		nread, _ := os.Stdin.Read(buf)
-		if nread == 0 {
+		key, err := term.ReadKey()
+		if err != nil {
 			continue
 		}
 
-		switch {
-		// Enter / carriage-return → confirm.
-		case buf[0] == '\r' || buf[0] == '\n':
+		switch key {
+		case tty.KeyEnter:
 			// Move cursor below the list before printing stepDone.
 			fmt.Printf("\033[%dB", n)
 			fmt.Println()
 			stepDone(step, question, choices[cur])
 			return cur
 
-		// Ctrl-C → restore terminal and exit cleanly.
-		case buf[0] == 3:
+		case tty.KeyCtrlC:
 			fmt.Printf("\033[%dB", n)
 			fmt.Println()
-			tcsetattr(fd, &orig)
+			restore()
 			os.Exit(1)
 
-		// Escape sequences (arrow keys: ESC [ A/B).
-		case nread >= 3 && buf[0] == 27 && buf[1] == '[':
-			switch buf[2] {
-			case 'A': // ↑
-				cur = (cur - 1 + n) % n
-			case 'B': // ↓
-				cur = (cur + 1) % n
-			}
+		case tty.KeyUp:
+			cur = (cur - 1 + n) % n
+			renderMenu()
+
+		case tty.KeyDown:
+			cur = (cur + 1) % n
 			renderMenu()
 		}
 	}
@@ -475,6 +501,10 @@ func promptArrowSelect(step int, question string, choices []string, defaultIdx i
 
 // ─────────────────────────────────────────────────────────────────────────────
 //  Text + yes/no prompts
+//
+//  These read a whole line via bufio.Reader rather than individual raw
+//  keypresses, so — unlike promptArrowSelect — they never needed raw mode
+//  or ioctls in the first place and are already portable as written.
 // ─────────────────────────────────────────────────────────────────────────────
 
 func promptText(r *bufio.Reader, step int, question, defaultVal string) string {
@@ -618,9 +648,9 @@ func langChoicesLabels() []string {
 	return out
 }
 
-func boardChoicesLabels() []string {
-	out := make([]string, len(boardChoices))
-	for i, b := range boardChoices {
+func boardChoicesLabels(choices []boardChoice) []string {
+	out := make([]string, len(choices))
+	for i, b := range choices {
 		out[i] = fmt.Sprintf("%-22s  %s", b.name, b.note)
 	}
 	return out
@@ -634,10 +664,10 @@ func backendChoicesLabels() []string {
 	return out
 }
 
-func templateLabels() []string {
-	out := make([]string, len(templateChoices))
-	for i, t := range templateChoices {
-		out[i] = t.name
+func templateChoicesLabels(choices []templates.Template) []string {
+	out := make([]string, len(choices))
+	for i, t := range choices {
+		out[i] = fmt.Sprintf("%-22s  %s", t.Name, t.Description)
 	}
 	return out
 }
@@ -655,13 +685,49 @@ func findLangChoice(id string) langChoice {
 	return langChoices[0]
 }
 
-func findBoardChoice(id string) boardChoice {
-	for _, b := range boardChoices {
+func findBoardChoice(choices []boardChoice, id string) boardChoice {
+	for _, b := range choices {
 		if strings.EqualFold(b.id, id) {
 			return b
 		}
 	}
-	return boardChoices[0]
+	return choices[0]
+}
+
+// wizardBoardChoices asks arduino-cli for every board its installed cores
+// support (via the same ~/.cache/tsuki/boards.json cache `tsuki boards
+// sync` refreshes), turning each into a boardChoice whose id is the real
+// FQBN — not a short alias — so the manifest scaffold writes carries it
+// straight through, and tsuki build/upload never have to re-map it. An
+// error here (arduino-cli missing, listall failing, stale cache with no
+// network to refresh it) is expected whenever arduino-cli isn't set up;
+// callers fall back to the hardcoded boardChoices in that case.
+func wizardBoardChoices(refresh bool) ([]boardChoice, error) {
+	arduinoCLI := resolvedArduinoCLI()
+
+	var discovered []boardEntry
+	var err error
+	if refresh {
+		discovered, err = fetchInstalledBoards(arduinoCLI)
+		if err == nil {
+			_ = saveBoardsCache(discovered)
+		}
+	} else {
+		discovered, err = resolveInstalledBoards(arduinoCLI)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]boardChoice, len(discovered))
+	for i, b := range discovered {
+		name := b.Name
+		if name == "" {
+			name = b.ID
+		}
+		choices[i] = boardChoice{id: b.FQBN, name: name, note: b.FQBN}
+	}
+	return choices, nil
 }
 
 func findBackendChoice(id string) backendChoice {
@@ -688,12 +754,3 @@ func sanitizeName(s string) string {
 	}
 	return string(out)
 }
-
-// isatty reports whether stdin is an interactive terminal.
-func isatty() bool {
-	fi, err := os.Stdin.Stat()
-	if err != nil {
-		return false
-	}
-	return (fi.Mode() & os.ModeCharDevice) != 0
-}
\ No newline at end of file
@@ -13,8 +13,11 @@ package cli
 
 import (
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/i18n"
 	"github.com/tsuki/cli/internal/manifest"
 	"github.com/tsuki/cli/internal/pkgmgr"
 	"github.com/tsuki/cli/internal/ui"
@@ -24,6 +27,7 @@ func newInstallCmd() *cobra.Command {
 	var (
 		global bool
 		dev    bool
+		jobs   int
 	)
 
 	cmd := &cobra.Command{
@@ -47,11 +51,11 @@ Package spec format:
 
 			// No args = pull everything from manifest.
 			if len(args) == 0 {
-				return runPullAll(dir)
+				return runPullAll(dir, jobs)
 			}
 
 			spec := args[0]
-			ui.SectionTitle(fmt.Sprintf("Installing  %s", spec))
+			ui.SectionTitle(i18n.T("install.installing", spec))
 
 			opts := pkgmgr.InstallOptions{
 				Spec:   spec,
@@ -62,11 +66,11 @@ Package spec format:
 
 			pkg, err := pkgmgr.Install(opts)
 			if err != nil {
-				ui.Fail(fmt.Sprintf("install failed: %v", err))
+				ui.Fail(i18n.T("install.failed", err))
 				return err
 			}
 
-			ui.Success(fmt.Sprintf("installed %s @ %s", pkg.Name, pkg.Version))
+			ui.Success(i18n.T("install.success", pkg.Name, pkg.Version))
 
 			// Update the manifest unless global.
 			if !global {
@@ -81,9 +85,9 @@ Package spec format:
 						m.AddPackage(pkg.Name, pkg.Version)
 					}
 					if saveErr := m.Save(dir); saveErr != nil {
-						ui.Warn(fmt.Sprintf("could not update manifest: %v", saveErr))
+						ui.Warn(i18n.T("install.manifest_save_failed", saveErr))
 					} else {
-						ui.Step("manifest", fmt.Sprintf("added %s = %q", pkg.Name, pkg.Version))
+						ui.Step("manifest", i18n.T("install.manifest_added", pkg.Name, pkg.Version))
 					}
 				}
 			}
@@ -94,40 +98,89 @@ Package spec format:
 
 	cmd.Flags().BoolVar(&global, "global", false, "install globally (~/.local/share/tsuki/global/)")
 	cmd.Flags().BoolVar(&dev, "dev", false, "add to [dev-dependencies]")
+	cmd.Flags().IntVar(&jobs, "jobs", runtime.NumCPU(), "max concurrent dependency installs (manifest pulls only)")
 	return cmd
 }
 
-// runPullAll installs every dependency listed in the project manifest.
-func runPullAll(dir string) error {
+// runPullAll installs every dependency listed in the project manifest, up
+// to jobs at a time, via a ui.SpinnerGroup fed by pkgmgr.InstallAsync. Each
+// worker owns one spinner slot for its whole lifetime, reusing it across
+// however many dependencies it's handed from the shared queue.
+func runPullAll(dir string, jobs int) error {
 	m, err := manifest.Load(dir)
 	if err != nil {
 		return err
 	}
 
 	if len(m.Dependencies) == 0 && len(m.Packages) == 0 {
-		ui.Info("No dependencies listed in manifest.")
+		ui.Info(i18n.T("install.no_dependencies"))
 		return nil
 	}
 
-	ui.SectionTitle("Installing dependencies")
+	ui.SectionTitle(i18n.T("install.installing_deps"))
 
-	var count int
+	type depJob struct{ name, spec string }
+	var queue []depJob
 	for name, dep := range m.Dependencies {
 		spec := name
 		if dep.Version != "" {
 			spec = name + ":" + dep.Version
 		}
-		sp := ui.NewSpinner(spec)
-		sp.Start()
-		pkg, err := pkgmgr.Install(pkgmgr.InstallOptions{Spec: spec, Dir: dir})
-		if err != nil {
-			sp.Stop(false, fmt.Sprintf("%s — %v", name, err))
-		} else {
-			sp.Stop(true, fmt.Sprintf("%s @ %s", pkg.Name, pkg.Version))
-			count++
-		}
+		queue = append(queue, depJob{name: name, spec: spec})
+	}
+
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(queue) {
+		jobs = len(queue)
 	}
 
-	ui.Success(fmt.Sprintf("installed %d package(s)", count))
+	group := ui.NewSpinnerGroup(jobs)
+	work := make(chan depJob)
+	var wg sync.WaitGroup
+	for slot := 0; slot < jobs; slot++ {
+		wg.Add(1)
+		go func(slot int) {
+			defer wg.Done()
+			for j := range work {
+				pkg, err := installOneDep(group, slot, j.name, j.spec, dir)
+				if err != nil {
+					group.Finish(slot, false, fmt.Sprintf("%s — %v", j.name, err))
+				} else {
+					group.Finish(slot, true, fmt.Sprintf("%s @ %s", pkg.Name, pkg.Version))
+				}
+			}
+		}(slot)
+	}
+	for _, j := range queue {
+		work <- j
+	}
+	close(work)
+	wg.Wait()
+	group.Wait()
+
 	return nil
+}
+
+// installOneDep drives one dependency's pkgmgr.InstallAsync event stream,
+// forwarding started/progress events to the SpinnerGroup slot its worker
+// owns.
+func installOneDep(group *ui.SpinnerGroup, slot int, name, spec, dir string) (*pkgmgr.InstalledPackage, error) {
+	events := pkgmgr.InstallAsync(pkgmgr.InstallOptions{Spec: spec, Dir: dir})
+	var pkg *pkgmgr.InstalledPackage
+	var err error
+	for ev := range events {
+		switch ev.Type {
+		case pkgmgr.EventStarted:
+			group.Update(slot, name)
+		case pkgmgr.EventProgress:
+			group.Update(slot, fmt.Sprintf("%s — %d%%", name, ev.Progress))
+		case pkgmgr.EventFinishedOK:
+			pkg = ev.Pkg
+		case pkgmgr.EventFinishedErr:
+			err = ev.Err
+		}
+	}
+	return pkg, err
 }
\ No newline at end of file
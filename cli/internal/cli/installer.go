@@ -0,0 +1,379 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: installer  —  native installers for `push --publish.installers`
+//
+//  Linux .deb packages are assembled directly (ar + tar.gz are simple
+//  enough to write without a third-party dependency). Windows .msi and
+//  macOS .pkg both have binary formats that are impractical to reimplement
+//  correctly, so — following the same pattern tsuki already uses for
+//  arduino-cli and OS keychains — we shell out to the platform's own
+//  packaging tool (msitools' `wixl`, Apple's `pkgbuild`) when it's on
+//  PATH, and otherwise fall back to a plain .zip/.tar.gz so push still has
+//  something to upload instead of silently dropping the target.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tsuki/cli/internal/manifest"
+)
+
+// generateInstaller produces a native installer (or, failing that, a
+// plain archive) for tgt ("linux-amd64", etc.) out of the binaries built
+// for it. native is false when no platform packaging tool was available
+// and a fallback archive was produced instead.
+func generateInstaller(projectDir string, m *manifest.Manifest, tgt string, binaries []string) (path string, native bool, err error) {
+	if len(binaries) == 0 {
+		return "", false, fmt.Errorf("no [[bin]] targets declared — nothing to package")
+	}
+
+	parts := strings.SplitN(tgt, "-", 2)
+	goos, goarch := parts[0], parts[1]
+
+	name := m.Name
+	version := m.Version
+	if version == "" {
+		version = "0.0.0"
+	}
+
+	switch goos {
+	case "linux":
+		path, err := buildDeb(projectDir, name, version, goarch, binaries)
+		return path, true, err
+	case "windows":
+		return buildMSI(projectDir, name, version, tgt, binaries)
+	case "darwin":
+		return buildPKG(projectDir, name, version, tgt, binaries)
+	default:
+		return "", false, fmt.Errorf("no installer format known for %q", goos)
+	}
+}
+
+// ── linux: .deb ────────────────────────────────────────────────────────────
+
+// debArch maps a Go GOARCH to the architecture name Debian package tooling
+// expects (dpkg refuses a .deb whose control file names an unknown arch).
+func debArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "amd64"
+	case "386":
+		return "i386"
+	case "arm64":
+		return "arm64"
+	case "arm":
+		return "armhf"
+	default:
+		return goarch
+	}
+}
+
+// buildDeb assembles a minimal but valid .deb: the "!<arch>\n" ar magic
+// followed by debian-binary, control.tar.gz, and data.tar.gz members —
+// exactly what dpkg itself produces, just without shelling out to it.
+func buildDeb(projectDir, name, version, goarch string, binaries []string) (string, error) {
+	arch := debArch(goarch)
+
+	var dataFiles []tarEntry
+	for _, bp := range binaries {
+		data, err := os.ReadFile(bp)
+		if err != nil {
+			return "", err
+		}
+		dataFiles = append(dataFiles, tarEntry{
+			name: "./usr/bin/" + filepath.Base(bp),
+			mode: 0755,
+			data: data,
+		})
+	}
+	dataTarGz, err := tarGz(dataFiles)
+	if err != nil {
+		return "", fmt.Errorf("building data.tar.gz: %w", err)
+	}
+
+	installedSize := 0
+	for _, f := range dataFiles {
+		installedSize += len(f.data)
+	}
+	control := fmt.Sprintf(`Package: %s
+Version: %s
+Architecture: %s
+Maintainer: %s
+Installed-Size: %d
+Section: utils
+Priority: optional
+Description: %s
+`, debPackageName(name), version, arch, name, (installedSize+1023)/1024, debSummary(name))
+
+	controlTarGz, err := tarGz([]tarEntry{{name: "./control", mode: 0644, data: []byte(control)}})
+	if err != nil {
+		return "", fmt.Errorf("building control.tar.gz: %w", err)
+	}
+
+	outPath := filepath.Join(projectDir, fmt.Sprintf("%s_%s_%s.deb", debPackageName(name), version, arch))
+	f, err := os.Create(outPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("!<arch>\n"); err != nil {
+		return "", err
+	}
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", controlTarGz},
+		{"data.tar.gz", dataTarGz},
+	} {
+		if err := writeArMember(f, member.name, member.data); err != nil {
+			return "", fmt.Errorf("writing %s: %w", member.name, err)
+		}
+	}
+
+	return outPath, nil
+}
+
+// debPackageName lowercases and strips anything dpkg's package-name regex
+// (^[a-z0-9][a-z0-9+.-]*$) would reject.
+func debPackageName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '+', r == '.', r == '-':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	out := strings.Trim(sb.String(), "-")
+	if out == "" {
+		out = "tsuki-package"
+	}
+	return out
+}
+
+func debSummary(name string) string {
+	return name + " (packaged by tsuki push)"
+}
+
+// writeArMember writes one 60-byte ar(1) header followed by data, padded
+// to an even length as the format requires.
+func writeArMember(w *os.File, name string, data []byte) error {
+	header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n",
+		name, time.Now().Unix(), 0, 0, "100644", len(data))
+	if len(header) != 60 {
+		return fmt.Errorf("internal error: ar header for %q is %d bytes, want 60", name, len(header))
+	}
+	if _, err := w.WriteString(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 == 1 {
+		_, err := w.WriteString("\n")
+		return err
+	}
+	return nil
+}
+
+// ── tar.gz helper (shared by .deb and the fallback archives) ───────────────
+
+type tarEntry struct {
+	name string
+	mode int64
+	data []byte
+}
+
+func tarGz(entries []tarEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name: e.name,
+			Mode: e.mode,
+			Size: int64(len(e.data)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ── windows: .msi via wixl (msitools), fallback .zip ────────────────────────
+
+func buildMSI(projectDir, name, version, tgt string, binaries []string) (string, bool, error) {
+	outPath := filepath.Join(projectDir, fmt.Sprintf("%s-%s-%s.msi", name, version, tgt))
+
+	if _, err := exec.LookPath("wixl"); err == nil {
+		wxsPath, err := writeWxs(projectDir, name, version, binaries)
+		if err != nil {
+			return "", false, err
+		}
+		defer os.Remove(wxsPath)
+
+		cmd := exec.Command("wixl", "-o", outPath, wxsPath)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", false, fmt.Errorf("wixl: %w\n%s", err, out)
+		}
+		return outPath, true, nil
+	}
+
+	zipPath, err := buildZipFallback(projectDir, name, version, tgt, binaries)
+	return zipPath, false, err
+}
+
+// writeWxs emits a minimal WiX source file installing every binary under
+// Program Files\<name>. Component/upgrade GUIDs are derived deterministically
+// from the project name so repeated builds of the same project keep a
+// stable upgrade identity instead of registering as unrelated products.
+func writeWxs(projectDir, name, version string, binaries []string) (string, error) {
+	var components strings.Builder
+	var refs strings.Builder
+	for i, bp := range binaries {
+		compID := fmt.Sprintf("BinComponent%d", i)
+		fmt.Fprintf(&components, `        <Component Id="%s" Guid="%s">
+          <File Id="Bin%d" Source="%s" KeyPath="yes"/>
+        </Component>
+`, compID, pseudoGUID(name+":component:"+bp), i, bp)
+		fmt.Fprintf(&refs, `      <ComponentRef Id="%s"/>
+`, compID)
+	}
+
+	wxs := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="%[1]s" Version="%[2]s" Manufacturer="%[1]s"
+           UpgradeCode="%[3]s" Language="1033">
+    <Package InstallerVersion="200" Compressed="yes"/>
+    <Media Id="1" Cabinet="product.cab" EmbedCab="yes"/>
+    <Directory Id="TARGETDIR" Name="SourceDir">
+      <Directory Id="ProgramFilesFolder">
+        <Directory Id="INSTALLDIR" Name="%[1]s">
+%[4]s        </Directory>
+      </Directory>
+    </Directory>
+    <Feature Id="MainFeature" Title="%[1]s" Level="1">
+%[5]s    </Feature>
+  </Product>
+</Wix>
+`, name, version, pseudoGUID(name+":upgrade"), components.String(), refs.String())
+
+	path := filepath.Join(projectDir, ".tsuki-"+name+".wxs")
+	return path, os.WriteFile(path, []byte(wxs), 0644)
+}
+
+// pseudoGUID derives a stable, syntactically valid GUID from seed so the
+// same project/component always gets the same identity across builds.
+func pseudoGUID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	hexStr := hex.EncodeToString(sum[:16])
+	return strings.ToUpper(fmt.Sprintf("%s-%s-%s-%s-%s",
+		hexStr[0:8], hexStr[8:12], hexStr[12:16], hexStr[16:20], hexStr[20:32]))
+}
+
+// ── darwin: .pkg via pkgbuild, fallback .tar.gz ──────────────────────────────
+
+func buildPKG(projectDir, name, version, tgt string, binaries []string) (string, bool, error) {
+	outPath := filepath.Join(projectDir, fmt.Sprintf("%s-%s-%s.pkg", name, version, tgt))
+
+	if _, err := exec.LookPath("pkgbuild"); err == nil {
+		root, err := os.MkdirTemp("", "tsuki-pkgroot-*")
+		if err != nil {
+			return "", false, err
+		}
+		defer os.RemoveAll(root)
+
+		binDir := filepath.Join(root, "usr", "local", "bin")
+		if err := os.MkdirAll(binDir, 0755); err != nil {
+			return "", false, err
+		}
+		for _, bp := range binaries {
+			data, err := os.ReadFile(bp)
+			if err != nil {
+				return "", false, err
+			}
+			if err := os.WriteFile(filepath.Join(binDir, filepath.Base(bp)), data, 0755); err != nil {
+				return "", false, err
+			}
+		}
+
+		cmd := exec.Command("pkgbuild",
+			"--root", root,
+			"--identifier", "com."+debPackageName(name)+".pkg",
+			"--version", version,
+			"--install-location", "/",
+			outPath,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", false, fmt.Errorf("pkgbuild: %w\n%s", err, out)
+		}
+		return outPath, true, nil
+	}
+
+	tarGzPath, err := buildTarGzFallback(projectDir, name, version, tgt, binaries)
+	return tarGzPath, false, err
+}
+
+// ── fallbacks ────────────────────────────────────────────────────────────────
+
+func buildZipFallback(projectDir, name, version, tgt string, binaries []string) (string, error) {
+	outPath := filepath.Join(projectDir, fmt.Sprintf("%s-%s-%s.zip", name, version, tgt))
+	f, err := os.Create(outPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+	for _, bp := range binaries {
+		if err := addFileToZip(zw, bp, filepath.Base(bp), nil); err != nil {
+			return "", err
+		}
+	}
+	return outPath, nil
+}
+
+func buildTarGzFallback(projectDir, name, version, tgt string, binaries []string) (string, error) {
+	var entries []tarEntry
+	for _, bp := range binaries {
+		data, err := os.ReadFile(bp)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, tarEntry{name: filepath.Base(bp), mode: 0755, data: data})
+	}
+	data, err := tarGz(entries)
+	if err != nil {
+		return "", err
+	}
+	outPath := filepath.Join(projectDir, fmt.Sprintf("%s-%s-%s.tar.gz", name, version, tgt))
+	return outPath, os.WriteFile(outPath, data, 0644)
+}
@@ -0,0 +1,68 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: keys  —  TUF trust bootstrapping for the signing-key index
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsuki/cli/internal/tuf"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the trusted signing-key metadata (TUF)",
+	}
+	cmd.AddCommand(newKeysInitCmd())
+	return cmd
+}
+
+func newKeysInitCmd() *cobra.Command {
+	var rootURL string
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Bootstrap trust by pinning a root.json",
+		Long: `init fetches root.json from --root, checks that it is signed by its own
+declared root-key threshold, and pins it to ` + "`" + `<keys dir>/root.json` + "`" + `.
+
+This is the one trust-on-first-use step TUF cannot make safe on its own —
+verify the root key fingerprints out of band (e.g. against a published
+checksum or a second communication channel) before running this.
+
+After init, package installs with verify_signatures enabled resolve
+per-package keys from the TUF targets role instead of the flat key index.`,
+		Example: `  tsuki keys init --root https://example.com/tuf/root.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if rootURL == "" {
+				return fmt.Errorf("--root <url> is required")
+			}
+			baseURL := strings.TrimSuffix(rootURL, "/root.json")
+			if baseURL == rootURL {
+				return fmt.Errorf("--root must point at a root.json file")
+			}
+
+			client := tuf.NewClient(baseURL, cfg.ResolvedKeysDir())
+			if err := client.Init(rootURL); err != nil {
+				return fmt.Errorf("initializing trust root: %w", err)
+			}
+
+			cfg.TUFMetadataURL = baseURL
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("saving config: %w", err)
+			}
+
+			ui.Success(fmt.Sprintf("pinned root.json from %s to %s", rootURL, cfg.ResolvedKeysDir()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rootURL, "root", "", "URL of the initial root.json to pin")
+	return cmd
+}
@@ -0,0 +1,188 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: nativepkg  —  distro-native packages for flashed firmware
+//
+//  One manifest, many package formats — the same idea as LURE/nfpm itself:
+//  describe the package once, let nfpm's per-format packagers (deb/rpm/apk)
+//  handle the archive layout and control metadata. Useful for handing field
+//  technicians a firmware + flashing tool without the tsuki toolchain.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+
+	_ "github.com/goreleaser/nfpm/v2/apk"
+	_ "github.com/goreleaser/nfpm/v2/deb"
+	_ "github.com/goreleaser/nfpm/v2/rpm"
+
+	"github.com/tsuki/cli/internal/manifest"
+)
+
+// boardUSBIDs returns the USB vendor:product id of the board's onboard
+// USB-serial chip, for the udev rule that lets non-root users flash it.
+func boardUSBIDs(board string) (vendor, product string, err error) {
+	switch board {
+	case "uno", "mega":
+		return "2341", "0043", nil
+	case "nano":
+		return "0403", "6001", nil // FTDI FT232
+	case "leonardo", "micro":
+		return "2341", "8036", nil
+	case "due":
+		return "2341", "003e", nil
+	case "mkr1000":
+		return "2341", "804e", nil
+	case "esp32":
+		return "10c4", "ea60", nil // Silicon Labs CP210x
+	case "esp8266":
+		return "1a86", "7523", nil // CH340
+	case "pico":
+		return "2e8a", "0003", nil
+	case "teensy40":
+		return "16c0", "0478", nil
+	default:
+		return "", "", fmt.Errorf("no known USB ids for board %q", board)
+	}
+}
+
+// buildNativePackages packages the compiled firmware plus a flashing
+// wrapper into one distro-native package per requested format, using
+// nfpm so each packager (deb/rpm/apk) handles its own archive layout.
+func buildNativePackages(projectDir string, m *manifest.Manifest, result *Result, board string, formats []string) ([]string, error) {
+	if result.FirmwareHex == "" {
+		return nil, fmt.Errorf("--native-package requires --compile (no firmware built)")
+	}
+
+	stageDir, err := os.MkdirTemp("", "tsuki-nativepkg-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stageDir)
+
+	name := m.Name
+	if name == "" {
+		name = "project"
+	}
+	version := m.Version
+	if version == "" {
+		version = "0.0.0"
+	}
+	fqbn, err := boardFQBN(board)
+	if err != nil {
+		return nil, err
+	}
+
+	// ── /usr/bin/<name>-flash — thin wrapper around arduino-cli upload ────
+	flashScript := fmt.Sprintf("#!/bin/sh\n"+
+		"# Installed by tsuki build --native-package — flashes the firmware\n"+
+		"# bundled in this package onto a connected %s board.\n"+
+		"exec arduino-cli upload --fqbn %s --port \"${1:-/dev/ttyACM0}\" /usr/share/%s/sketch\n",
+		board, fqbn, name)
+	flashPath := filepath.Join(stageDir, name+"-flash")
+	if err := os.WriteFile(flashPath, []byte(flashScript), 0755); err != nil {
+		return nil, fmt.Errorf("writing flash wrapper: %w", err)
+	}
+
+	// ── udev rule — lets non-root users access the board's USB-serial port ─
+	var udevPath string
+	if vendor, product, err := boardUSBIDs(board); err == nil {
+		udevRule := fmt.Sprintf(
+			"SUBSYSTEM==\"usb\", ATTR{idVendor}==\"%s\", ATTR{idProduct}==\"%s\", MODE=\"0666\", GROUP=\"dialout\"\n",
+			vendor, product,
+		)
+		udevPath = filepath.Join(stageDir, "99-"+name+".rules")
+		if err := os.WriteFile(udevPath, []byte(udevRule), 0644); err != nil {
+			return nil, fmt.Errorf("writing udev rule: %w", err)
+		}
+	}
+
+	// ── changelog — PackagedAt flows in as the only entry's date ──────────
+	packagedAt := time.Now().UTC()
+	changelogYAML := fmt.Sprintf("---\n- semver: %s\n  date: %s\n  changes:\n    - note: Packaged by tsuki build --native-package\n",
+		version, packagedAt.Format(time.RFC3339))
+	changelogPath := filepath.Join(stageDir, "changelog.yaml")
+	if err := os.WriteFile(changelogPath, []byte(changelogYAML), 0644); err != nil {
+		return nil, fmt.Errorf("writing changelog: %w", err)
+	}
+
+	contents := files.Contents{
+		&files.Content{
+			Source:      result.FirmwareHex,
+			Destination: fmt.Sprintf("/usr/share/%s/firmware.hex", name),
+		},
+		&files.Content{
+			Source:      flashPath,
+			Destination: "/usr/bin/" + name + "-flash",
+			FileInfo:    &files.ContentFileInfo{Mode: 0755},
+		},
+	}
+	if result.SketchDir != "" {
+		contents = append(contents, &files.Content{
+			Source:      result.SketchDir,
+			Destination: fmt.Sprintf("/usr/share/%s/sketch", name),
+		})
+	}
+	if udevPath != "" {
+		contents = append(contents, &files.Content{
+			Source:      udevPath,
+			Destination: "/usr/lib/udev/rules.d/99-" + name + ".rules",
+			Type:        "config|noreplace",
+		})
+	}
+
+	authors := m.Project.Authors
+	maintainer := ""
+	if len(authors) > 0 {
+		maintainer = authors[0]
+	}
+
+	info := &nfpm.Info{
+		Name:        name,
+		Arch:        "amd64",
+		Platform:    "linux",
+		Version:     version,
+		Maintainer:  maintainer,
+		Description: m.Description,
+		License:     m.Project.License,
+		Changelog:   changelogPath,
+		Overridables: nfpm.Overridables{
+			Contents: contents,
+		},
+	}
+
+	var outPaths []string
+	for _, format := range formats {
+		packager, err := nfpm.Get(format)
+		if err != nil {
+			return outPaths, fmt.Errorf("unknown native package format %q: %w", format, err)
+		}
+
+		ext := format
+		if pe, ok := packager.(nfpm.PackagerWithExtension); ok {
+			ext = strings.TrimPrefix(pe.ConventionalExtension(), ".")
+		}
+		outName := fmt.Sprintf("%s_%s_%s.%s", name, version, info.Arch, ext)
+		outPath := filepath.Join(projectDir, outName)
+
+		out, err := os.Create(outPath)
+		if err != nil {
+			return outPaths, fmt.Errorf("creating %s: %w", outPath, err)
+		}
+		err = packager.Package(info, out)
+		out.Close()
+		if err != nil {
+			return outPaths, fmt.Errorf("packaging %s: %w", format, err)
+		}
+		outPaths = append(outPaths, outPath)
+	}
+
+	return outPaths, nil
+}
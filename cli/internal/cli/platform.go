@@ -0,0 +1,175 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: platform  —  install / remove / list platform packs
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newPlatformCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "platform",
+		Short: "Manage platform/variant packs (STM32, Teensy, custom MCUs, ...)",
+		Long: `Install, remove, and list platform packs — tsukilib packages that
+declare kind = "platform" in their [package] table.
+
+A platform pack describes how to build for one MCU family without a
+pre-installed arduino-cli core: its core name, variant folder, linker
+script, clock speed, extra compiler flags, and a board_mappings table
+(short board id -> variant folder). 'tsuki build --board <id>' picks a
+matching platform pack up transparently the same way it resolves
+arduino-cli FQBNs.
+
+Packs are stored at: ` + pkgmgr.PlatformsDir(),
+	}
+
+	cmd.AddCommand(
+		newPlatformInstallCmd(),
+		newPlatformRemoveCmd(),
+		newPlatformListCmd(),
+	)
+	return cmd
+}
+
+// ── platform install ──────────────────────────────────────────────────────────
+
+func newPlatformInstallCmd() *cobra.Command {
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "install <source>",
+		Short: "Install a platform pack from a local path or URL",
+		Long: `<source> can be:
+  - A local file path:   ./stm32-bluepill/tsukilib.toml
+  - An HTTPS URL:        https://example.com/stm32-bluepill/tsukilib.toml
+
+The tsukilib.toml must declare kind = "platform" in its [package] table.`,
+		Example: `  tsuki platform install ./stm32-bluepill/tsukilib.toml
+  tsuki platform install https://raw.githubusercontent.com/tsuki/packages/main/stm32-bluepill/1.0.0/tsukilib.toml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := args[0]
+
+			sp := ui.NewSpinner(fmt.Sprintf("Installing %s…", source))
+			sp.Start()
+
+			p, err := pkgmgr.InstallPlatform(pkgmgr.InstallOptions{
+				Source:  source,
+				Version: version,
+			})
+			if err != nil {
+				sp.Stop(false, "installation failed")
+				return err
+			}
+			sp.Stop(true, fmt.Sprintf("Installed %s@%s", p.Name, p.Version))
+			fmt.Println()
+
+			mappings := make([]string, 0, len(p.BoardMappings))
+			for id, variant := range p.BoardMappings {
+				mappings = append(mappings, fmt.Sprintf("%s=%s", id, variant))
+			}
+			ui.PrintConfig("Platform installed", []ui.ConfigEntry{
+				{Key: "name", Value: p.Name},
+				{Key: "version", Value: p.Version},
+				{Key: "core", Value: p.Core},
+				{Key: "variant", Value: p.Variant},
+				{Key: "f_cpu", Value: p.FCPU},
+				{Key: "board_mappings", Value: strings.Join(mappings, ", ")},
+				{Key: "path", Value: p.Path},
+			}, false)
+
+			fmt.Println()
+			ui.Info(fmt.Sprintf("Build with: tsuki build --board <id> --compile  (any of: %s)", strings.Join(mappings, ", ")))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "override version from TOML")
+	return cmd
+}
+
+// ── platform remove ───────────────────────────────────────────────────────────
+
+func newPlatformRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm", "uninstall"},
+		Short:   "Remove an installed platform pack",
+		Example: `  tsuki platform remove stm32-bluepill`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			platforms, err := pkgmgr.ListPlatforms()
+			if err != nil {
+				return err
+			}
+			var found *pkgmgr.Platform
+			for i := range platforms {
+				if platforms[i].Name == name {
+					found = &platforms[i]
+					break
+				}
+			}
+			if found == nil {
+				return fmt.Errorf("platform %q is not installed", name)
+			}
+
+			sp := ui.NewSpinner(fmt.Sprintf("Removing %s@%s…", found.Name, found.Version))
+			sp.Start()
+			if err := pkgmgr.RemovePlatform(found.Name, found.Version); err != nil {
+				sp.Stop(false, "removal failed")
+				return err
+			}
+			sp.Stop(true, fmt.Sprintf("Removed %s@%s", found.Name, found.Version))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// ── platform list ─────────────────────────────────────────────────────────────
+
+func newPlatformListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List installed platform packs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			platforms, err := pkgmgr.ListPlatforms()
+			if err != nil {
+				return err
+			}
+			if len(platforms) == 0 {
+				ui.Info("No platform packs installed — run `tsuki platform install <source>` to add one")
+				return nil
+			}
+
+			ui.ColorTitle.Printf("  %-20s  %-10s  %-14s  %-12s  %s\n", "NAME", "VERSION", "CORE", "F_CPU", "BOARD IDS")
+			ui.ColorMuted.Println("  " + strings.Repeat("─", 88))
+			for _, p := range platforms {
+				ids := make([]string, 0, len(p.BoardMappings))
+				for id := range p.BoardMappings {
+					ids = append(ids, id)
+				}
+				sort.Strings(ids)
+				ui.ColorKey.Printf("  %-20s", p.Name)
+				ui.ColorNumber.Printf("  %-10s", p.Version)
+				fmt.Printf("  %-14s  %-12s  ", p.Core, p.FCPU)
+				ui.ColorMuted.Printf("%s\n", strings.Join(ids, ", "))
+			}
+			ui.Info(fmt.Sprintf("Platforms directory: %s", pkgmgr.PlatformsDir()))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,549 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: publisher  —  pluggable release-publisher backends
+//
+//  `push` used to hardcode GitHub REST calls. Publisher abstracts "create (or
+//  find) a release, then upload assets to it" so the same push pipeline can
+//  target GitHub Releases, GitLab Releases, Gitea, or a generic S3/HTTP PUT
+//  bucket. Selection is driven by the scheme/host of [publish] registry, or
+//  the explicit --publisher flag.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tsuki/cli/internal/manifest"
+)
+
+// Publisher creates releases and uploads build artifacts to them.
+type Publisher interface {
+	// FindRelease returns an existing release's identifier for tag, if any.
+	FindRelease(tag string) (releaseRef string, ok bool, err error)
+	// CreateRelease creates a new release for tag and returns its identifier.
+	CreateRelease(tag string, m *manifest.Manifest) (releaseRef string, err error)
+	// UploadAsset uploads the file at path to the release identified by releaseRef.
+	UploadAsset(releaseRef, path string) error
+	// ReleaseURL returns a human-facing URL for the published release.
+	ReleaseURL(tag string) string
+}
+
+// resolvePublisher selects a Publisher based on the explicit --publisher
+// override, falling back to sniffing the scheme/host of registryURL.
+//
+//	https://github.com/owner/repo     → github
+//	https://gitlab.com/owner/repo     → gitlab
+//	https://gitea.example.com/o/r     → gitea (any URL containing "gitea")
+//	s3://bucket/prefix                → s3
+//	anything else                     → github
+func resolvePublisher(explicit, registryURL, token string) (Publisher, error) {
+	kind := strings.ToLower(explicit)
+	if kind == "" {
+		switch {
+		case strings.HasPrefix(registryURL, "s3://"):
+			kind = "s3"
+		case strings.Contains(registryURL, "gitlab.com") || strings.Contains(registryURL, "/gitlab/"):
+			kind = "gitlab"
+		case strings.Contains(registryURL, "github.com"):
+			kind = "github"
+		case strings.Contains(registryURL, "gitea"):
+			kind = "gitea"
+		default:
+			kind = "github"
+		}
+	}
+
+	switch kind {
+	case "github":
+		repo := strings.TrimPrefix(registryURL, "https://github.com/")
+		repo = strings.TrimSuffix(repo, ".git")
+		if repo == "" {
+			return nil, fmt.Errorf("GitHub repo not specified — add to [publish] registry or pass --repo owner/name")
+		}
+		return &githubPublisher{repo: repo, token: token, client: httpClient(30 * time.Second)}, nil
+	case "gitlab":
+		return newGitLabPublisher(registryURL, token)
+	case "gitea":
+		return newGiteaPublisher(registryURL, token)
+	case "s3":
+		return newS3Publisher(registryURL, token)
+	default:
+		return nil, fmt.Errorf("unknown publisher %q (want github, gitlab, gitea, or s3)", kind)
+	}
+}
+
+func httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// ── GitHub Releases ───────────────────────────────────────────────────────────
+
+type githubPublisher struct {
+	repo   string // owner/name
+	token  string
+	client *http.Client
+	// uploadURL is cached after CreateRelease/FindRelease.
+	uploadURL string
+}
+
+func (p *githubPublisher) authedJSON(method, url string, body interface{}) (*http.Response, error) {
+	var payload io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return p.client.Do(req)
+}
+
+func (p *githubPublisher) FindRelease(tag string) (string, bool, error) {
+	resp, err := p.authedJSON("GET", fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", p.repo, tag), nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	var result struct {
+		ID        int64  `json:"id"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", false, fmt.Errorf("parsing GitHub response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", false, fmt.Errorf("GitHub API error %d", resp.StatusCode)
+	}
+	p.uploadURL = strings.Split(result.UploadURL, "{")[0]
+	return fmt.Sprintf("%d", result.ID), true, nil
+}
+
+func (p *githubPublisher) CreateRelease(tag string, m *manifest.Manifest) (string, error) {
+	body := map[string]interface{}{
+		"tag_name":   tag,
+		"name":       fmt.Sprintf("%s %s", m.Name, tag),
+		"body":       fmt.Sprintf("Release %s\n\n%s", tag, m.Description),
+		"draft":      false,
+		"prerelease": false,
+	}
+	resp, err := p.authedJSON("POST", fmt.Sprintf("https://api.github.com/repos/%s/releases", p.repo), body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ID        int64  `json:"id"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("parsing GitHub response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("GitHub API error %d", resp.StatusCode)
+	}
+	p.uploadURL = strings.Split(result.UploadURL, "{")[0]
+	return fmt.Sprintf("%d", result.ID), nil
+}
+
+func (p *githubPublisher) UploadAsset(releaseRef, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s?name=%s", p.uploadURL, name), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("upload error %d for %s", resp.StatusCode, name)
+	}
+	return nil
+}
+
+func (p *githubPublisher) ReleaseURL(tag string) string {
+	return fmt.Sprintf("https://github.com/%s/releases/tag/%s", p.repo, tag)
+}
+
+// ── GitLab Releases ───────────────────────────────────────────────────────────
+
+type gitlabPublisher struct {
+	host       string
+	projectRef string // URL-encoded "owner/repo"
+	token      string
+	client     *http.Client
+}
+
+func newGitLabPublisher(registryURL, token string) (*gitlabPublisher, error) {
+	host, path, err := splitHostPath(registryURL, "gitlab.com")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("GitLab project not specified in [publish] registry")
+	}
+	return &gitlabPublisher{
+		host:       host,
+		projectRef: strings.ReplaceAll(path, "/", "%2F"),
+		token:      token,
+		client:     httpClient(30 * time.Second),
+	}, nil
+}
+
+func (p *gitlabPublisher) apiBase() string {
+	return fmt.Sprintf("https://%s/api/v4/projects/%s", p.host, p.projectRef)
+}
+
+func (p *gitlabPublisher) authedJSON(method, url string, body interface{}) (*http.Response, error) {
+	var payload io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", p.token)
+	req.Header.Set("Content-Type", "application/json")
+	return p.client.Do(req)
+}
+
+func (p *gitlabPublisher) FindRelease(tag string) (string, bool, error) {
+	resp, err := p.authedJSON("GET", p.apiBase()+"/releases/"+tag, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode >= 400 {
+		return "", false, fmt.Errorf("GitLab API error %d", resp.StatusCode)
+	}
+	return tag, true, nil
+}
+
+func (p *gitlabPublisher) CreateRelease(tag string, m *manifest.Manifest) (string, error) {
+	body := map[string]interface{}{
+		"tag_name":    tag,
+		"name":        fmt.Sprintf("%s %s", m.Name, tag),
+		"description": m.Description,
+	}
+	resp, err := p.authedJSON("POST", p.apiBase()+"/releases", body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("GitLab API error %d", resp.StatusCode)
+	}
+	return tag, nil
+}
+
+// UploadAsset uploads the file as a generic package registry package, then
+// links it into the release via the "links" API so it shows up as an asset.
+func (p *gitlabPublisher) UploadAsset(releaseRef, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+
+	uploadURL := fmt.Sprintf("%s/packages/generic/tsuki/%s/%s", p.apiBase(), releaseRef, name)
+	req, err := http.NewRequest("PUT", uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", p.token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("upload error %d for %s", resp.StatusCode, name)
+	}
+
+	linkBody := map[string]interface{}{"name": name, "url": uploadURL}
+	linkResp, err := p.authedJSON("POST", p.apiBase()+"/releases/"+releaseRef+"/assets/links", linkBody)
+	if err != nil {
+		return err
+	}
+	defer linkResp.Body.Close()
+	if linkResp.StatusCode >= 400 {
+		return fmt.Errorf("linking asset %s to release: HTTP %d", name, linkResp.StatusCode)
+	}
+	return nil
+}
+
+func (p *gitlabPublisher) ReleaseURL(tag string) string {
+	return fmt.Sprintf("https://%s/%s/-/releases/%s", p.host, strings.ReplaceAll(p.projectRef, "%2F", "/"), tag)
+}
+
+// ── Gitea Releases ────────────────────────────────────────────────────────────
+// Gitea's release API is REST-compatible with GitHub's, just rooted at a
+// different host and API prefix.
+
+type giteaPublisher struct {
+	host      string
+	repo      string // owner/name
+	token     string
+	client    *http.Client
+	releaseID int64
+}
+
+func newGiteaPublisher(registryURL, token string) (*giteaPublisher, error) {
+	host, repo, err := splitHostPath(registryURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("Gitea repo not specified in [publish] registry")
+	}
+	return &giteaPublisher{host: host, repo: repo, token: token, client: httpClient(30 * time.Second)}, nil
+}
+
+func (p *giteaPublisher) apiBase() string {
+	return fmt.Sprintf("https://%s/api/v1/repos/%s", p.host, p.repo)
+}
+
+func (p *giteaPublisher) authedJSON(method, url string, body interface{}) (*http.Response, error) {
+	var payload io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+	return p.client.Do(req)
+}
+
+func (p *giteaPublisher) FindRelease(tag string) (string, bool, error) {
+	resp, err := p.authedJSON("GET", p.apiBase()+"/releases/tags/"+tag, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", false, fmt.Errorf("parsing Gitea response: %w", err)
+	}
+	p.releaseID = result.ID
+	return fmt.Sprintf("%d", result.ID), true, nil
+}
+
+func (p *giteaPublisher) CreateRelease(tag string, m *manifest.Manifest) (string, error) {
+	body := map[string]interface{}{
+		"tag_name": tag,
+		"name":     fmt.Sprintf("%s %s", m.Name, tag),
+		"body":     fmt.Sprintf("Release %s\n\n%s", tag, m.Description),
+	}
+	resp, err := p.authedJSON("POST", p.apiBase()+"/releases", body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("parsing Gitea response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("Gitea API error %d", resp.StatusCode)
+	}
+	p.releaseID = result.ID
+	return fmt.Sprintf("%d", result.ID), nil
+}
+
+func (p *giteaPublisher) UploadAsset(releaseRef, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	url := fmt.Sprintf("%s/releases/%s/assets?name=%s", p.apiBase(), releaseRef, name)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+p.token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("upload error %d for %s", resp.StatusCode, name)
+	}
+	return nil
+}
+
+func (p *giteaPublisher) ReleaseURL(tag string) string {
+	return fmt.Sprintf("https://%s/%s/releases/tag/%s", p.host, p.repo, tag)
+}
+
+// ── Generic S3 / HTTP PUT ─────────────────────────────────────────────────────
+// No "release" object exists in S3 — assets are simply namespaced under
+// <bucket>/<prefix>/<tag>/<file>, alongside a manifest.json listing them.
+
+type s3Publisher struct {
+	endpoint string // https://<bucket>.s3.amazonaws.com or custom endpoint via token
+	prefix   string
+	token    string // expected to be a presigned-URL base or bearer token for a PUT-capable proxy
+	client   *http.Client
+	assets   []string
+}
+
+func newS3Publisher(registryURL, token string) (*s3Publisher, error) {
+	rest := strings.TrimPrefix(registryURL, "s3://")
+	if rest == registryURL {
+		return nil, fmt.Errorf("not an s3:// registry URL: %s", registryURL)
+	}
+	parts := strings.SplitN(rest, "/", 2)
+	bucket := parts[0]
+	prefix := ""
+	if len(parts) > 1 {
+		prefix = parts[1]
+	}
+	return &s3Publisher{
+		endpoint: fmt.Sprintf("https://%s.s3.amazonaws.com", bucket),
+		prefix:   prefix,
+		token:    token,
+		client:   httpClient(120 * time.Second),
+	}, nil
+}
+
+func (p *s3Publisher) FindRelease(tag string) (string, bool, error) {
+	// S3 has no release concept — always "create" (overwrite) on push.
+	return tag, false, nil
+}
+
+func (p *s3Publisher) CreateRelease(tag string, m *manifest.Manifest) (string, error) {
+	p.assets = nil
+	return tag, nil
+}
+
+func (p *s3Publisher) objectURL(releaseRef, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", p.endpoint, strings.Trim(p.prefix, "/"), releaseRef, name)
+}
+
+func (p *s3Publisher) UploadAsset(releaseRef, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	req, err := http.NewRequest("PUT", p.objectURL(releaseRef, name), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT error %d for %s", resp.StatusCode, name)
+	}
+	p.assets = append(p.assets, name)
+
+	// Best-effort manifest.json listing every asset uploaded this release.
+	manifestBody, _ := json.Marshal(map[string]interface{}{"tag": releaseRef, "assets": p.assets})
+	manifestReq, err := http.NewRequest("PUT", p.objectURL(releaseRef, "manifest.json"), bytes.NewReader(manifestBody))
+	if err != nil {
+		return nil // manifest is best-effort; the asset itself uploaded fine
+	}
+	if p.token != "" {
+		manifestReq.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	manifestReq.Header.Set("Content-Type", "application/json")
+	if resp, err := p.client.Do(manifestReq); err == nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+func (p *s3Publisher) ReleaseURL(tag string) string {
+	return p.objectURL(tag, "manifest.json")
+}
+
+// ── Shared helpers ─────────────────────────────────────────────────────────────
+
+// splitHostPath extracts (host, path) from an "https://host/path" registry
+// URL. If defaultHost is non-empty and the URL has no explicit host (a bare
+// "owner/repo" style string), defaultHost is used.
+func splitHostPath(registryURL, defaultHost string) (host, path string, err error) {
+	rest := registryURL
+	rest = strings.TrimPrefix(rest, "https://")
+	rest = strings.TrimPrefix(rest, "http://")
+	rest = strings.TrimSuffix(rest, ".git")
+	if rest == registryURL && defaultHost != "" {
+		// No scheme stripped — treat the whole string as "owner/repo".
+		return defaultHost, strings.Trim(rest, "/"), nil
+	}
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		return "", "", fmt.Errorf("registry URL %q has no repository path", registryURL)
+	}
+	return rest[:slash], strings.Trim(rest[slash+1:], "/"), nil
+}
+
+// registryHost returns the bare host a [publish] registry URL points at, for
+// use as a credential-lookup key (e.g. "github.com", "gitlab.example.com").
+// Returns "" if no host can be determined (e.g. an s3:// bucket, where the
+// bucket itself isn't a useful credential key).
+func registryHost(registryURL string) string {
+	if strings.HasPrefix(registryURL, "s3://") {
+		return ""
+	}
+	host, _, err := splitHostPath(registryURL, "github.com")
+	if err != nil {
+		return ""
+	}
+	return host
+}
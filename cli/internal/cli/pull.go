@@ -26,7 +26,7 @@ This is equivalent to running 'tsuki install' with no arguments.`,
 
 			ui.SectionTitle("Pulling dependencies")
 
-			results, err := pkgmgr.PullAll(dir)
+			results, err := pkgmgr.PullAll(dir, pkgmgr.PullOptions{})
 			if err != nil {
 				return fmt.Errorf("pull failed: %w", err)
 			}
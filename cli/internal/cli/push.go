@@ -1,28 +1,41 @@
 // ─────────────────────────────────────────────────────────────────────────────
-//  tsuki :: cli :: push  —  build for all publish targets + upload to GitHub
+//  tsuki :: cli :: push  —  build for all publish targets + upload a release
 //
 //  Reads [publish] from tsuki-config.toml:
 //    [publish]
 //    registry = "https://github.com/tsuki-team/registry"
 //    targets  = ["linux-amd64", "linux-arm64", "windows-amd64", "darwin-amd64"]
 //
+//  The registry URL's scheme/host selects the Publisher (see publisher.go):
+//  GitHub, GitLab, Gitea, or a generic s3:// bucket. Override with --publisher.
+//
 //  Requires GITHUB_TOKEN env var (or stored token via `tsuki config`).
+//
+//  Each run is a small DAG of named steps (build × target → checksums →
+//  sign → create release → upload × asset), tracked in
+//  .tsuki/push-state/<tag>.json (see push_workflow.go). Pass --resume to
+//  pick a prior run back up — steps whose inputs are unchanged are skipped —
+//  or --force to redo every step regardless. Independent steps (builds,
+//  uploads) run in parallel, bounded by --concurrency.
 // ─────────────────────────────────────────────────────────────────────────────
 
 package cli
 
 import (
-	"bytes"
-	"encoding/json"
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/credentials"
 	"github.com/tsuki/cli/internal/manifest"
 	"github.com/tsuki/cli/internal/ui"
 )
@@ -35,24 +48,39 @@ type artifact struct {
 
 func newPushCmd() *cobra.Command {
 	var (
-		dryRun  bool
-		tag     string
-		token   string
-		repo    string
+		dryRun      bool
+		tag         string
+		token       string
+		repo        string
+		publisher   string
+		resume      bool
+		force       bool
+		concurrency int
 	)
 
 	cmd := &cobra.Command{
 		Use:   "push",
-		Short: "Build release artifacts and upload to GitHub Releases",
+		Short: "Build release artifacts and publish a release",
 		Long: `push compiles the project for every target listed in [publish.targets],
 packs each into a .tskp archive, generates a checksums.txt, then creates
-(or updates) a GitHub Release and uploads all assets.
+(or updates) a release via the selected publisher and uploads all assets.
+
+The publisher is chosen from the [publish] registry URL (github.com,
+gitlab.com, a Gitea host, or s3://) unless overridden with --publisher.
+
+Builds and uploads run in parallel (--concurrency). Progress is tracked
+in .tsuki/push-state so a failed or interrupted run can be picked back
+up with --resume instead of redoing every target from scratch; --force
+ignores that tracking and redoes every step.
 
 Set GITHUB_TOKEN env var or pass --token to authenticate.`,
 		Example: `  tsuki push
   tsuki push --tag v2.0.0
   tsuki push --dry-run
-  tsuki push --repo tsuki-team/tsuki-core`,
+  tsuki push --repo tsuki-team/tsuki-core
+  tsuki push --publisher gitlab --repo tsuki-team/tsuki-core
+  tsuki push --resume
+  tsuki push --concurrency 8`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dir := projectDir()
 			m, err := manifest.Load(dir)
@@ -60,28 +88,43 @@ Set GITHUB_TOKEN env var or pass --token to authenticate.`,
 				return err
 			}
 
-			// Resolve token.
-			if token == "" {
-				token = os.Getenv("GITHUB_TOKEN")
+			// --repo overrides the owner/name derived from [publish] registry
+			// (applies to the github/gitea publishers, which are repo-scoped).
+			registryURL := m.Publish.Registry
+			if repo != "" {
+				host := registryHost(registryURL)
+				if host == "" {
+					host = "github.com"
+				}
+				registryURL = "https://" + host + "/" + repo
 			}
-			if token == "" && !dryRun {
+			if registryURL == "" && !dryRun {
 				return fmt.Errorf(
-					"GitHub token required — set GITHUB_TOKEN or pass --token\n" +
-						"  Get one at: https://github.com/settings/tokens",
+					"publish target not specified — add [publish] registry in tsuki-config.toml\n" +
+						"  or pass --repo owner/name",
 				)
 			}
 
-			// Resolve repo (owner/name).
-			if repo == "" {
-				repo = m.Publish.Registry
-				// Strip https://github.com/ prefix if present.
-				repo = strings.TrimPrefix(repo, "https://github.com/")
-				repo = strings.TrimSuffix(repo, ".git")
+			// Resolve token: --token flag, then the configured credential
+			// helper / OS keychain for the registry's host, then the
+			// GITHUB_TOKEN env var (kept for backward compatibility).
+			if token == "" {
+				if host := registryHost(registryURL); host != "" {
+					stored, err := credentials.Get(cfg.ResolvedCredentialHelper(host), host)
+					if err != nil {
+						ui.Warn(fmt.Sprintf("credential lookup for %s failed: %v", host, err))
+					}
+					token = stored
+				}
 			}
-			if repo == "" && !dryRun {
+			if token == "" {
+				token = os.Getenv("GITHUB_TOKEN")
+			}
+			if token == "" && !dryRun {
 				return fmt.Errorf(
-					"GitHub repo not specified — add to [publish] registry in tsuki-config.toml\n" +
-						"  or pass --repo owner/name",
+					"publisher token required — run `tsuki config login <host> --token ...`,\n" +
+						"  set GITHUB_TOKEN, or pass --token\n" +
+						"  Get one at: https://github.com/settings/tokens",
 				)
 			}
 
@@ -96,40 +139,111 @@ Set GITHUB_TOKEN env var or pass --token to authenticate.`,
 			}
 
 			ui.SectionTitle(fmt.Sprintf("Push  [%s]  tag: %s", m.Name, tag))
-			ui.Step("repo", repo)
+			ui.Step("registry", registryURL)
 			ui.Step("targets", strings.Join(targets, ", "))
 			if dryRun {
 				ui.Warn("dry-run mode — no files will be uploaded")
 			}
 			fmt.Println()
 
-			// ── Build each target ─────────────────────────────────────────────
-			var artifacts []artifact
+			// state.path is keyed by tag; --resume picks up a prior run's step
+			// records, otherwise we start from a clean slate (default today's
+			// non-resumed behaviour, but still write the state file so a later
+			// --resume has something to pick up).
+			statePath := pushStatePath(dir, tag)
+			state := loadPushState(statePath, tag)
+			if !resume {
+				state.Steps = map[string]*pushStepState{}
+			}
+			if concurrency < 1 {
+				concurrency = 4
+			}
 
-			for _, tgt := range targets {
-				sp := ui.NewSpinner(fmt.Sprintf("Building %s…", tgt))
-				sp.Start()
+			// ── Build each target (parallel, resumable) ─────────────────────────
+			// A step's output is the .tskp path plus any installer path,
+			// joined by a separator byte that can't appear in a filesystem
+			// path, so the pair survives a round trip through the state file.
+			const pathSep = "\x1f"
+			built := make([]artifact, len(targets))
+			builtInstallers := make([][]artifact, len(targets))
+			buildJobs := make([]func() error, len(targets))
+			for i, tgt := range targets {
+				i, tgt := i, tgt
+				buildJobs[i] = func() error {
+					inputHash := hashString(m.Name, m.Version, tgt, fmt.Sprint(m.Publish.Installers))
+					joined, err := state.runStep("build:"+tgt, inputHash, force, func() (string, string, error) {
+						tskpPath, installerPaths, err := buildForTarget(dir, m, tgt)
+						if err != nil {
+							return "", "", err
+						}
+						allPaths := append([]string{tskpPath}, installerPaths...)
+						outHash, err := hashFiles(allPaths)
+						return strings.Join(allPaths, pathSep), outHash, err
+					})
+					if err != nil {
+						return err
+					}
+					paths := strings.Split(joined, pathSep)
+					built[i] = artifact{target: tgt, path: paths[0]}
+					for _, ip := range paths[1:] {
+						builtInstallers[i] = append(builtInstallers[i], artifact{target: tgt, path: ip})
+					}
+					return nil
+				}
+			}
+			buildErrs := runPool(concurrency, buildJobs)
 
-				tskpPath, err := buildForTarget(dir, m, tgt)
-				if err != nil {
-					sp.Stop(false, fmt.Sprintf("failed: %s — %v", tgt, err))
+			var artifacts []artifact
+			for i, tgt := range targets {
+				if err := buildErrs[i]; err != nil {
+					ui.Warn(fmt.Sprintf("failed: %s — %v", tgt, err))
 					continue
 				}
-				sp.Stop(true, fmt.Sprintf("%s → %s", tgt, filepath.Base(tskpPath)))
-				artifacts = append(artifacts, artifact{target: tgt, path: tskpPath})
+				ui.Step("built", fmt.Sprintf("%s → %s", tgt, filepath.Base(built[i].path)))
+				artifacts = append(artifacts, built[i])
+				for _, ia := range builtInstallers[i] {
+					ui.Step("installer", fmt.Sprintf("%s → %s", tgt, filepath.Base(ia.path)))
+					artifacts = append(artifacts, ia)
+				}
 			}
 
 			if len(artifacts) == 0 {
 				return fmt.Errorf("no artifacts produced — check build errors above")
 			}
 
-			// ── Generate checksums.txt ─────────────────────────────────────────
-			checksumsPath, err := generateChecksums(dir, artifacts)
+			// ── Generate SHA256SUMS + detached signature ───────────────────────
+			checksumInput := make([]string, 0, len(artifacts))
+			for _, a := range artifacts {
+				checksumInput = append(checksumInput, a.path)
+			}
+			sumsPath, err := state.runStep("checksums", hashString(checksumInput...), force, func() (string, string, error) {
+				path, err := generateChecksums(dir, artifacts)
+				if err != nil {
+					return "", "", err
+				}
+				outHash, err := hashFile(path)
+				return path, outHash, err
+			})
 			if err != nil {
 				ui.Warn(fmt.Sprintf("could not generate checksums: %v", err))
 			} else {
-				ui.Step("checksums", filepath.Base(checksumsPath))
-				artifacts = append(artifacts, artifact{target: "checksums", path: checksumsPath})
+				ui.Step("checksums", filepath.Base(sumsPath))
+				artifacts = append(artifacts, artifact{target: "checksums", path: sumsPath})
+
+				sigPath, err := state.runStep("signature", hashString(sumsPath), force, func() (string, string, error) {
+					path, err := signSums(sumsPath)
+					if err != nil {
+						return "", "", err
+					}
+					outHash, err := hashFile(path)
+					return path, outHash, err
+				})
+				if err != nil {
+					ui.Warn(fmt.Sprintf("could not sign %s: %v — install will skip verification for this release", filepath.Base(sumsPath), err))
+				} else {
+					ui.Step("signature", filepath.Base(sigPath))
+					artifacts = append(artifacts, artifact{target: "signature", path: sigPath})
+				}
 			}
 
 			if dryRun {
@@ -138,45 +252,89 @@ Set GITHUB_TOKEN env var or pass --token to authenticate.`,
 				return nil
 			}
 
-			// ── Create GitHub Release ─────────────────────────────────────────
-			ui.SectionTitle("Uploading to GitHub Releases")
-
-			releaseID, uploadURL, err := createGitHubRelease(repo, tag, m, token)
+			// ── Create (or find) the release ──────────────────────────────────
+			pub, err := resolvePublisher(publisher, registryURL, token)
 			if err != nil {
-				return fmt.Errorf("creating release: %w", err)
+				return fmt.Errorf("resolving publisher: %w", err)
 			}
-			ui.Success(fmt.Sprintf("created release %s (id: %d)", tag, releaseID))
 
-			// ── Upload assets ─────────────────────────────────────────────────
-			for _, a := range artifacts {
-				sp := ui.NewSpinner(fmt.Sprintf("Uploading %s…", filepath.Base(a.path)))
-				sp.Start()
-				if err := uploadAsset(uploadURL, a.path, token); err != nil {
-					sp.Stop(false, fmt.Sprintf("%s — %v", filepath.Base(a.path), err))
+			ui.SectionTitle("Publishing release")
+
+			releaseRef, err := state.runStep("release", hashString(tag, registryURL), force, func() (string, string, error) {
+				ref, exists, err := pub.FindRelease(tag)
+				if err != nil {
+					return "", "", fmt.Errorf("checking for existing release: %w", err)
+				}
+				if !exists {
+					ref, err = pub.CreateRelease(tag, m)
+					if err != nil {
+						return "", "", fmt.Errorf("creating release: %w", err)
+					}
+				}
+				return ref, ref, nil
+			})
+			if err != nil {
+				return err
+			}
+			ui.Success(fmt.Sprintf("release %s ready (ref: %s)", tag, releaseRef))
+
+			// ── Upload assets (parallel, resumable) ─────────────────────────────
+			uploadJobs := make([]func() error, len(artifacts))
+			uploadErrs := make([]error, len(artifacts))
+			for i, a := range artifacts {
+				i, a := i, a
+				uploadJobs[i] = func() error {
+					inputHash, err := hashFile(a.path)
+					if err != nil {
+						return err
+					}
+					_, err = state.runStep("upload:"+filepath.Base(a.path), hashString(releaseRef, inputHash), force, func() (string, string, error) {
+						if err := pub.UploadAsset(releaseRef, a.path); err != nil {
+							return "", "", err
+						}
+						return filepath.Base(a.path), inputHash, nil
+					})
+					return err
+				}
+			}
+			for i, job := range runPool(concurrency, uploadJobs) {
+				uploadErrs[i] = job
+			}
+			for i, a := range artifacts {
+				if err := uploadErrs[i]; err != nil {
+					ui.Warn(fmt.Sprintf("%s — %v", filepath.Base(a.path), err))
 				} else {
-					sp.Stop(true, filepath.Base(a.path))
+					ui.Step("uploaded", filepath.Base(a.path))
 				}
 			}
 
 			fmt.Println()
-			ui.Success(fmt.Sprintf("release %s published at https://github.com/%s/releases/tag/%s", tag, repo, tag))
+			ui.Success(fmt.Sprintf("release %s published at %s", tag, pub.ReleaseURL(tag)))
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "build artifacts but do not upload")
 	cmd.Flags().StringVar(&tag, "tag", "", "release tag (default: v<version>)")
-	cmd.Flags().StringVar(&token, "token", "", "GitHub token (overrides GITHUB_TOKEN env var)")
-	cmd.Flags().StringVar(&repo, "repo", "", "GitHub repo in owner/name format")
+	cmd.Flags().StringVar(&token, "token", "", "access token (overrides GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&repo, "repo", "", "repo in owner/name format (github/gitea publishers)")
+	cmd.Flags().StringVar(&publisher, "publisher", "", "publisher backend: github, gitlab, gitea, or s3 (default: inferred from registry URL)")
+	cmd.Flags().BoolVar(&resume, "resume", false, "resume a previous push, skipping steps whose inputs are unchanged")
+	cmd.Flags().BoolVar(&force, "force", false, "redo every step even if --resume would otherwise skip it")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "number of targets/assets to build or upload in parallel")
 	return cmd
 }
 
-// buildForTarget runs a build for GOOS/GOARCH derived from tgt ("linux-amd64", etc.)
-// and returns the path to the .tskp archive.
-func buildForTarget(projectDir string, m *manifest.Manifest, tgt string) (string, error) {
+// buildForTarget cross-compiles every [[bin]] target for the GOOS/GOARCH
+// pair in tgt ("linux-amd64", etc.), embeds the resulting binaries into a
+// target-stamped copy of the project's .tskp archive, and — when
+// [publish].installers is set — additionally produces a native installer
+// for that platform (see installer.go). It returns the .tskp path and the
+// path of any installer produced alongside it.
+func buildForTarget(projectDir string, m *manifest.Manifest, tgt string) (string, []string, error) {
 	parts := strings.SplitN(tgt, "-", 2)
 	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid target %q, expected os-arch", tgt)
+		return "", nil, fmt.Errorf("invalid target %q, expected os-arch", tgt)
 	}
 	goos, goarch := parts[0], parts[1]
 
@@ -188,46 +346,132 @@ func buildForTarget(projectDir string, m *manifest.Manifest, tgt string) (string
 	archiveName := fmt.Sprintf("%s-%s-%s.tskp", name, version, tgt)
 	outPath := filepath.Join(projectDir, archiveName)
 
-	// For a tsuki project the "build" is packing source + generated C++.
-	// For program-type projects with a Go/Rust binary we'd invoke the compiler.
-	// Here we produce a target-stamped .tskp (the firmware is board-specific,
-	// so we skip cross-compiling C++ for non-native targets unless a Makefile
-	// entrypoint is defined).
-
-	_ = goos
-	_ = goarch
-
-	// Create a simple target-tagged copy of the project archive.
+	// The transpiled sketch + sources don't depend on GOOS/GOARCH, so the
+	// base .tskp is built once (by whichever target runs first) and reused.
 	src := filepath.Join(projectDir, fmt.Sprintf("%s-%s.tskp", name, version))
 	if _, err := os.Stat(src); os.IsNotExist(err) {
-		// .tskp not yet built — build it now (transpile only).
-		res, err := Run(projectDir, m, Options{
-			PreparePackage: true,
-			CoreBin:        "",
-		})
-		if err != nil {
-			// If transpiler not available, just package sources.
-			_ = res
+		if _, err := Run(projectDir, m, Options{PreparePackage: true}); err != nil {
+			return "", nil, fmt.Errorf("building base package: %w", err)
+		}
+	}
+
+	binaries, err := buildNativeBinaries(projectDir, m, goos, goarch)
+	if err != nil {
+		return "", nil, fmt.Errorf("cross-compiling for %s: %w", tgt, err)
+	}
+	defer func() {
+		if len(binaries) > 0 {
+			_ = os.RemoveAll(filepath.Dir(binaries[0]))
+		}
+	}()
+
+	if err := copyArchiveWithBinaries(src, outPath, tgt, binaries); err != nil {
+		return "", nil, fmt.Errorf("packaging %s: %w", tgt, err)
+	}
+
+	var installers []string
+	if m.Publish.Installers {
+		installerPath, native, err := generateInstaller(projectDir, m, tgt, binaries)
+		switch {
+		case err != nil:
+			ui.Warn(fmt.Sprintf("no installer for %s: %v", tgt, err))
+		case !native:
+			ui.Warn(fmt.Sprintf("%s: platform packaging tool not found — shipped a plain archive instead", tgt))
+			installers = append(installers, installerPath)
+		default:
+			installers = append(installers, installerPath)
 		}
 	}
 
-	// Copy/rename to target-stamped file.
-	data, err := os.ReadFile(src)
+	return outPath, installers, nil
+}
+
+// buildNativeBinaries cross-compiles every [[bin]] target with GOOS/GOARCH
+// set from goos/goarch, writing each binary to a scratch directory whose
+// path is returned via the binaries themselves (paths[i] lives under the
+// same parent dir, so callers can os.RemoveAll(filepath.Dir(paths[0]))).
+// Returns (nil, nil) for projects that declare no [[bin]] targets — there
+// is nothing Go-native to cross-compile for a transpile-only sketch.
+func buildNativeBinaries(projectDir string, m *manifest.Manifest, goos, goarch string) ([]string, error) {
+	if len(m.Bins) == 0 {
+		return nil, nil
+	}
+
+	outDir, err := os.MkdirTemp("", "tsuki-bin-*")
 	if err != nil {
-		// Fall back: create a minimal archive with just metadata.
-		if err2 := os.WriteFile(outPath, []byte{}, 0644); err2 != nil {
-			return "", err
+		return nil, err
+	}
+
+	var paths []string
+	for _, bin := range m.Bins {
+		binName := bin.Name
+		if binName == "" {
+			binName = m.Name
+		}
+		if goos == "windows" {
+			binName += ".exe"
+		}
+		outPath := filepath.Join(outDir, binName)
+
+		pkgPath := bin.Path
+		if pkgPath == "" {
+			pkgPath = "."
+		}
+
+		cmd := exec.Command("go", "build", "-o", outPath, pkgPath)
+		cmd.Dir = projectDir
+		cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("go build %s (%s/%s): %w\n%s", binName, goos, goarch, err, out)
 		}
-	} else {
-		if err := os.WriteFile(outPath, data, 0644); err != nil {
-			return "", err
+		paths = append(paths, outPath)
+	}
+	return paths, nil
+}
+
+// copyArchiveWithBinaries copies every entry from the srcZip .tskp into a
+// new archive at dstZip, then adds each binary under bin/<tgt>/<name>.
+// If srcZip can't be opened (e.g. the base package failed to build) the
+// destination still gets written with just the binaries, so a program-type
+// project with no transpiled sketch can still produce a distributable.
+func copyArchiveWithBinaries(srcZip, dstZip, tgt string, binaries []string) error {
+	f, err := os.Create(dstZip)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+
+	if r, err := zip.OpenReader(srcZip); err == nil {
+		defer r.Close()
+		for _, zf := range r.File {
+			w, err := zw.Create(zf.Name)
+			if err != nil {
+				return err
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(w, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	return outPath, nil
+	for _, bp := range binaries {
+		if err := addFileToZip(zw, bp, filepath.Join("bin", tgt, filepath.Base(bp)), nil); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// generateChecksums writes a checksums.txt file listing each artifact's size.
+// generateChecksums writes a SHA256SUMS file in the standard
+// "<hex>  <filename>" format (sha256sum-compatible) covering every artifact.
 func generateChecksums(dir string, artifacts []artifact) (string, error) {
 	var sb strings.Builder
 	for _, a := range artifacts {
@@ -235,89 +479,61 @@ func generateChecksums(dir string, artifacts []artifact) (string, error) {
 		if err != nil {
 			continue
 		}
-		// Simple length-based checksum placeholder
-		// (swap for crypto/sha256 in production).
-		sb.WriteString(fmt.Sprintf("%-60s  %s\n",
-			filepath.Base(a.path),
-			fmt.Sprintf("%x", len(data)),
-		))
+		sum := sha256.Sum256(data)
+		sb.WriteString(fmt.Sprintf("%x  %s\n", sum, filepath.Base(a.path)))
 	}
-	outPath := filepath.Join(dir, "checksums.txt")
+	outPath := filepath.Join(dir, "SHA256SUMS")
 	return outPath, os.WriteFile(outPath, []byte(sb.String()), 0644)
 }
 
-// createGitHubRelease calls the GitHub API to create a release and returns
-// (releaseID, uploadURL, error).
-func createGitHubRelease(repo, tag string, m *manifest.Manifest, token string) (int64, string, error) {
-	body := map[string]interface{}{
-		"tag_name":   tag,
-		"name":       fmt.Sprintf("%s %s", m.Name, tag),
-		"body":       fmt.Sprintf("Release %s\n\n%s", tag, m.Description),
-		"draft":      false,
-		"prerelease": false,
+// signSums produces a detached Ed25519 signature over sumsPath at
+// <sumsPath>.sig, raw 64-byte format (matching pkgmgr's verifier).
+//
+// The signing key is loaded from, in order:
+//  1. TSUKI_SIGNING_KEY env var (path to a PEM-encoded Ed25519 private key)
+//  2. ~/.config/tsuki/signing/release.key
+func signSums(sumsPath string) (string, error) {
+	keyPath := os.Getenv("TSUKI_SIGNING_KEY")
+	if keyPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolving home dir: %w", err)
+		}
+		keyPath = filepath.Join(home, ".config", "tsuki", "signing", "release.key")
 	}
-	payload, _ := json.Marshal(body)
-
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases", repo)
-	req, _ := http.NewRequest("POST", url, bytes.NewReader(payload))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github+json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	priv, err := loadEd25519PrivateKey(keyPath)
 	if err != nil {
-		return 0, "", err
+		return "", fmt.Errorf("loading signing key %s: %w", keyPath, err)
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		ID              int64  `json:"id"`
-		UploadURL       string `json:"upload_url"`
-		HTMLURL         string `json:"html_url"`
-		AlreadyExists   bool
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, "", fmt.Errorf("parsing GitHub response: %w", err)
-	}
-	if resp.StatusCode >= 400 {
-		return 0, "", fmt.Errorf("GitHub API error %d", resp.StatusCode)
-	}
-	// upload_url has a {?name,label} suffix — strip it.
-	uploadURL := strings.Split(result.UploadURL, "{")[0]
-	return result.ID, uploadURL, nil
-}
-
-// uploadAsset uploads a single file to a GitHub release.
-func uploadAsset(uploadURL, filePath, token string) error {
-	f, err := os.Open(filePath)
+	data, err := os.ReadFile(sumsPath)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer f.Close()
 
-	name := filepath.Base(filePath)
-	url := fmt.Sprintf("%s?name=%s", uploadURL, name)
+	sig := ed25519.Sign(priv, data)
+	sigPath := sumsPath + ".sig"
+	return sigPath, os.WriteFile(sigPath, sig, 0644)
+}
 
-	data, err := io.ReadAll(f)
+// loadEd25519PrivateKey parses a PKCS#8 PEM-encoded Ed25519 private key.
+func loadEd25519PrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	req, _ := http.NewRequest("POST", url, bytes.NewReader(data))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/octet-stream")
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	client := &http.Client{Timeout: 120 * time.Second}
-	resp, err := client.Do(req)
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("parsing PKCS8 private key: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("upload error %d for %s", resp.StatusCode, name)
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key is not Ed25519 (got %T)", key)
 	}
-	return nil
-}
\ No newline at end of file
+	return priv, nil
+}
@@ -0,0 +1,150 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: push_workflow  —  resumable step engine behind `push`
+//
+//  push used to run its steps (build × N targets → checksums → sign →
+//  create release → upload × N assets) as one linear function: any failure
+//  partway through meant a re-run redid every target from scratch and could
+//  create a duplicate release draft. This engine gives each step a stable
+//  name, an input hash, and a record of its output, persisted to
+//  .tsuki/push-state/<tag>.json. A step whose input hash matches a prior
+//  completed run is skipped entirely unless --force is passed. Independent
+//  steps (per-target builds, per-asset uploads) run concurrently through a
+//  small worker pool bounded by --concurrency.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// pushStepState is one step's durable record.
+type pushStepState struct {
+	Done       bool   `json:"done"`
+	InputHash  string `json:"input_hash"`
+	OutputHash string `json:"output_hash,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+// pushState is the full persisted state for one push run, keyed by tag.
+type pushState struct {
+	Tag   string                    `json:"tag"`
+	Steps map[string]*pushStepState `json:"steps"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// pushStatePath returns the state file for tag under <dir>/.tsuki/push-state/.
+func pushStatePath(dir, tag string) string {
+	safe := strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(tag)
+	return filepath.Join(dir, ".tsuki", "push-state", safe+".json")
+}
+
+// loadPushState reads the state file at path, or returns a fresh empty state
+// if it doesn't exist or fails to parse.
+func loadPushState(path, tag string) *pushState {
+	s := &pushState{Tag: tag, Steps: map[string]*pushStepState{}, path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	if err := json.Unmarshal(data, s); err != nil || s.Steps == nil {
+		return &pushState{Tag: tag, Steps: map[string]*pushStepState{}, path: path}
+	}
+	s.path = path
+	return s
+}
+
+func (s *pushState) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0644)
+}
+
+// runStep executes fn unless a previous run already completed a step named
+// name with the same inputHash (and force is false), in which case it
+// returns the cached output without calling fn. Safe for concurrent use.
+func (s *pushState) runStep(name, inputHash string, force bool, fn func() (output, outputHash string, err error)) (string, error) {
+	s.mu.Lock()
+	st, ok := s.Steps[name]
+	s.mu.Unlock()
+	if !force && ok && st.Done && st.InputHash == inputHash {
+		return st.Output, nil
+	}
+
+	output, outputHash, err := fn()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.Steps[name] = &pushStepState{Done: true, InputHash: inputHash, OutputHash: outputHash, Output: output}
+	saveErr := s.save()
+	s.mu.Unlock()
+	if saveErr != nil {
+		return output, fmt.Errorf("persisting push state: %w", saveErr)
+	}
+	return output, nil
+}
+
+func hashString(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hashFiles combines the content hash of each path, in order, into one hash.
+func hashFiles(paths []string) (string, error) {
+	hashes := make([]string, len(paths))
+	for i, p := range paths {
+		h, err := hashFile(p)
+		if err != nil {
+			return "", err
+		}
+		hashes[i] = h
+	}
+	return hashString(hashes...), nil
+}
+
+// runPool runs jobs with at most concurrency goroutines in flight at once,
+// collecting each job's error (nil on success) at its original index.
+func runPool(concurrency int, jobs []func() error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, job func() error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = job()
+		}(i, job)
+	}
+	wg.Wait()
+	return errs
+}
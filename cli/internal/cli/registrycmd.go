@@ -0,0 +1,156 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: registry  —  pin signing keys for trusted registries
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsuki/cli/internal/registry"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newRegistryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Manage package registry sources in keys.json",
+	}
+	cmd.AddCommand(
+		newRegistryTrustCmd(),
+		newRegistryLoginCmd(),
+		newRegistryLogoutCmd(),
+	)
+	return cmd
+}
+
+func newRegistryTrustCmd() *cobra.Command {
+	var keyPath, keyURL string
+
+	cmd := &cobra.Command{
+		Use:   "trust <registry-id>",
+		Short: "Pin a signing key for a registry",
+		Long: `trust adds a PEM-encoded ("PUBLIC KEY") Ed25519 public key to a registry's
+signing_keys in keys.json. Packages resolved from a registry marked
+"trusted": true must carry a signature that verifies against one of its
+pinned keys — Resolve refuses to proceed otherwise.
+
+Pass exactly one of --key (read a local PEM file) or --key-url (download
+one). Verify the key's fingerprint out of band before pinning it — trust
+pinned here is trusted for every install from that registry.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  tsuki registry trust tsuki-team --key ./tsuki-team.pub
+  tsuki registry trust tsuki-team --key-url https://tsuki-lang.org/signing-key.pub`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+
+			if (keyPath == "") == (keyURL == "") {
+				return fmt.Errorf("specify exactly one of --key or --key-url")
+			}
+
+			var keyPEM string
+			if keyPath != "" {
+				data, err := os.ReadFile(keyPath)
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", keyPath, err)
+				}
+				keyPEM = string(data)
+			} else {
+				data, err := fetchKeyURL(keyURL)
+				if err != nil {
+					return fmt.Errorf("fetching %s: %w", keyURL, err)
+				}
+				keyPEM = string(data)
+			}
+
+			if err := registry.TrustKey(id, keyPEM); err != nil {
+				return err
+			}
+
+			ui.Success(fmt.Sprintf("pinned signing key for registry %q", id))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyPath, "key", "", "path to a local PEM-encoded public key")
+	cmd.Flags().StringVar(&keyURL, "key-url", "", "URL of a PEM-encoded public key")
+	return cmd
+}
+
+func newRegistryLoginCmd() *cobra.Command {
+	var token string
+
+	cmd := &cobra.Command{
+		Use:   "login <registry-id>",
+		Short: "Store a bearer token for a registry in the OS keyring",
+		Long: `login stores a token for <registry-id> in the OS keychain (macOS
+Keychain, libsecret on Linux, Windows Credential Manager) — Client sends it
+as "Authorization: Bearer <token>" on every request to that registry.
+
+Only needed for a registry with no "auth" block in keys.json; a registry
+with an explicit bearer/basic/helper auth type ignores the keyring.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  tsuki registry login acme --token ghp_xxx
+  echo "$TOKEN" | tsuki registry login acme`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+
+			if token == "" {
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && err != io.EOF {
+					return fmt.Errorf("reading token from stdin: %w", err)
+				}
+				token = strings.TrimSpace(line)
+			}
+			if token == "" {
+				return fmt.Errorf("--token is required (or pipe the token in on stdin)")
+			}
+
+			if err := registry.Login(id, token); err != nil {
+				return fmt.Errorf("storing token for %s: %w", id, err)
+			}
+			ui.Success(fmt.Sprintf("stored a keyring token for registry %q", id))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&token, "token", "", "token to store (or pipe it in on stdin)")
+	return cmd
+}
+
+func newRegistryLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <registry-id>",
+		Short: "Remove a registry's keyring token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			if err := registry.Logout(id); err != nil {
+				return fmt.Errorf("removing token for %s: %w", id, err)
+			}
+			ui.Success(fmt.Sprintf("removed keyring token for registry %q", id))
+			return nil
+		},
+	}
+}
+
+func fetchKeyURL(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(resp.Body)
+}
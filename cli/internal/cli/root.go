@@ -12,12 +12,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/i18n"
+	"github.com/tsuki/cli/internal/manifest"
 	"github.com/tsuki/cli/internal/ui"
 )
 
 var (
 	globalVerbose bool
 	globalNoColor bool
+	globalLang    string
+	globalTheme   string
 	cfg           *config.Config
 )
 
@@ -32,6 +36,14 @@ Run 'tsuki <command> --help' for details on each command.
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		i18n.Init(globalLang)
+
+		manifestTheme := ""
+		if m, err := manifest.Load(projectDir()); err == nil {
+			manifestTheme = m.Project.Theme
+		}
+		ui.InitTheme(globalTheme, manifestTheme)
+
 		if globalNoColor {
 			color.NoColor = true
 		}
@@ -60,6 +72,8 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&globalVerbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&globalNoColor, "no-color", false, "disable colored output")
+	rootCmd.PersistentFlags().StringVar(&globalLang, "lang", "", "override UI locale (e.g. de_DE); defaults to $LC_ALL/$LC_MESSAGES/$LANG")
+	rootCmd.PersistentFlags().StringVar(&globalTheme, "theme", "", "color theme: default, solarized, monochrome, or a path to a theme.toml")
 
 	rootCmd.AddCommand(
 		// original commands
@@ -75,9 +89,17 @@ func init() {
 		// v3 commands
 		newRunCmd(),
 		newInstallCmd(),
+		newSearchCmd(),
+		newThemeCmd(),
 		newPullCmd(),
 		newPushCmd(),
 		newUpdateDBCmd(),
+		newKeysCmd(),
+		newVerifyCmd(),
+		newRegistryCmd(),
+		newDaemonCmd(),
+		newCacheCmd(),
+		newPlatformCmd(),
 	)
 }
 
@@ -102,4 +124,4 @@ func projectDir() string {
 		return "."
 	}
 	return wd
-}
\ No newline at end of file
+}
@@ -0,0 +1,84 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: search  —  search every configured registry and install hits
+//
+//  Usage:
+//    tsuki search neopixel                 list matches, prompt for a selection
+//    tsuki search neopixel --yes           install every match without prompting
+//
+//  Unlike `tsuki pkg search` (list-only), this command puts the results in
+//  front of ui.NumberMenu so a query that turns up several candidates can be
+//  installed in one step instead of a separate `tsuki pkg install <name>`.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newSearchCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search registries and install the packages you pick",
+		Example: `  tsuki search neopixel
+  tsuki search sensor --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := args[0]
+
+			sp := ui.NewSpinner("Searching registries…")
+			sp.Start()
+			entries, err := pkgmgr.SearchRegistry(query)
+			sp.Stop(err == nil, "done")
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				ui.Info(fmt.Sprintf("no packages matching %q", query))
+				return nil
+			}
+
+			ui.SectionTitle("Search results")
+			items := make([]ui.MenuItem, len(entries))
+			for i, e := range entries {
+				items[i] = ui.MenuItem{Columns: []string{e.Name, e.Version, e.Description}}
+			}
+
+			var selected []int
+			if yes {
+				selected = make([]int, len(entries))
+				for i := range entries {
+					selected[i] = i + 1
+				}
+			} else {
+				selected, err = ui.NumberMenu("Install which? (e.g. 1, 2-3):", items)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, idx := range selected {
+				e := entries[idx-1]
+				sp := ui.NewSpinner(e.Name)
+				sp.Start()
+				pkg, err := pkgmgr.InstallFromRegistry(e.Name, e.Version, nil)
+				if err != nil {
+					sp.Stop(false, fmt.Sprintf("%s — %v", e.Name, err))
+					continue
+				}
+				sp.Stop(true, fmt.Sprintf("%s @ %s", pkg.Name, pkg.Version))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&yes, "yes", false, "install every match without prompting")
+	return cmd
+}
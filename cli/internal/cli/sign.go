@@ -0,0 +1,175 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: sign  —  minisign-style signing for .tskp packages
+//
+//  Real minisign encrypts its secret key with scrypt + xsalsa20, neither of
+//  which is in the standard library and this repo carries no third-party
+//  crypto deps (see installer.go). So the envelope shape is minisign's —
+//  salt, checksum, encrypted seed, a short key id — but the KDF is a plain
+//  SHA-256 stretch and the "cipher" is a SHA-256 keystream XOR. Anyone who
+//  needs byte-for-byte compatibility with upstream minisign should verify
+//  packages.json / .tskp signatures with the real tool instead; `tsuki
+//  verify` only needs to agree with itself and with updatedb's fetch path.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const encryptedKeyPEMType = "TSUKI ENCRYPTED ED25519 PRIVATE KEY"
+
+const kdfRounds = 100000
+
+// signedEntry is one archived file's contribution to a .tskp signature: its
+// zip path plus the SHA-256 of its contents (not the raw bytes themselves,
+// so large entries don't need to stay resident in memory for signing).
+type signedEntry struct {
+	name string
+	hash [32]byte
+}
+
+// tskpSignature is the record written both as tsuki-package.sig inside the
+// archive and, base64-free, as the sidecar <name>-<version>.tskp.minisig.
+type tskpSignature struct {
+	KeyID     string `json:"key_id"`
+	Digest    string `json:"digest"`    // hex sha256 over the sorted entry manifest
+	Signature string `json:"signature"` // hex ed25519 signature over Digest
+}
+
+// entryManifestDigest hashes the canonical manifest of a .tskp archive:
+// every entry's path and content hash, sorted by path, concatenated. Signing
+// this (rather than the raw zip bytes) means the signature survives
+// deterministic re-zipping and doesn't depend on zip metadata like
+// timestamps.
+func entryManifestDigest(entries []signedEntry) [32]byte {
+	sorted := make([]signedEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+
+	h := sha256.New()
+	for _, e := range sorted {
+		h.Write([]byte(e.name))
+		h.Write(e.hash[:])
+	}
+	var sum [32]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// signEntries signs the entry manifest of a .tskp archive with the
+// encrypted Ed25519 key at keyPath, returning the record to embed/sidecar.
+func signEntries(entries []signedEntry, keyPath, passphrase string) (tskpSignature, error) {
+	priv, keyID, err := loadEncryptedSigningKey(keyPath, passphrase)
+	if err != nil {
+		return tskpSignature{}, err
+	}
+
+	digest := entryManifestDigest(entries)
+	sig := ed25519.Sign(priv, digest[:])
+
+	return tskpSignature{
+		KeyID:     keyID,
+		Digest:    hex.EncodeToString(digest[:]),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// readSignPassphrase reads the signing-key passphrase from
+// TSUKI_SIGN_PASSPHRASE, falling back to a single line on stdin.
+func readSignPassphrase() (string, error) {
+	if p := os.Getenv("TSUKI_SIGN_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	fmt.Fprint(os.Stderr, "Signing key passphrase: ")
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return "", fmt.Errorf("reading passphrase: %w", err)
+		}
+		return "", fmt.Errorf("no passphrase provided on stdin")
+	}
+	return scanner.Text(), nil
+}
+
+// loadEncryptedSigningKey decrypts a PEM-wrapped, passphrase-protected
+// Ed25519 private key and returns it along with its key id (the first 8
+// hex bytes of sha256(public key) — recorded in every signature so
+// `tsuki verify` knows which trusted key to try).
+func loadEncryptedSigningKey(path, passphrase string) (ed25519.PrivateKey, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != encryptedKeyPEMType {
+		return nil, "", fmt.Errorf("%s: not a %s PEM block", path, encryptedKeyPEMType)
+	}
+	if len(block.Bytes) < 16+32 {
+		return nil, "", fmt.Errorf("%s: truncated key envelope", path)
+	}
+
+	salt := block.Bytes[:16]
+	checksum := block.Bytes[16:48]
+	ciphertext := block.Bytes[48:]
+
+	kek := stretchPassphrase(passphrase, salt)
+	plaintext := xorKeystream(kek, ciphertext)
+
+	sum := sha256.Sum256(plaintext)
+	if subtle.ConstantTimeCompare(sum[:], checksum) != 1 {
+		return nil, "", fmt.Errorf("%s: wrong passphrase (checksum mismatch)", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(plaintext)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: parsing decrypted PKCS8 key: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, "", fmt.Errorf("%s: key is not Ed25519 (got %T)", path, key)
+	}
+
+	return priv, keyIDForPublicKey(priv.Public().(ed25519.PublicKey)), nil
+}
+
+// stretchPassphrase derives a 32-byte key-encryption-key from a passphrase
+// and salt via repeated SHA-256 — a stand-in for scrypt (see file header).
+func stretchPassphrase(passphrase string, salt []byte) []byte {
+	sum := sha256.Sum256(append([]byte(passphrase), salt...))
+	for i := 0; i < kdfRounds; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return sum[:]
+}
+
+// xorKeystream XORs data against a SHA-256-derived keystream seeded by kek.
+func xorKeystream(kek, data []byte) []byte {
+	out := make([]byte, len(data))
+	var counter uint32
+	for offset := 0; offset < len(data); offset += sha256.Size {
+		block := sha256.Sum256(append(kek, byte(counter), byte(counter>>8), byte(counter>>16), byte(counter>>24)))
+		copy(out[offset:], block[:])
+		counter++
+	}
+	for i := range out {
+		out[i] ^= data[i]
+	}
+	return out
+}
+
+// keyIDForPublicKey returns the short hex identifier recorded alongside a
+// signature so a verifier knows which trusted key to check it against.
+func keyIDForPublicKey(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,154 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: sizereport  —  post-build flash/RAM dashboard
+//
+//  Parses arduino-cli compile's own "Sketch uses ... / Global variables
+//  use ..." summary lines into a per-target row, renders them as a small
+//  table, and writes the same data to build/size-report.json so CI can
+//  consume it without scraping terminal output. Used by the init wizard's
+//  optional smoke-build step and by `tsuki build --report`.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tsuki/cli/internal/manifest"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// sizeReportRow is one target's outcome: pass/fail plus, when the compile
+// succeeded, its flash and RAM usage against the board's known capacity.
+type sizeReportRow struct {
+	Target        string `json:"target"`
+	Status        string `json:"status"`
+	FlashBytes    int64  `json:"flash_bytes"`
+	FlashCapacity int64  `json:"flash_capacity"`
+	RAMBytes      int64  `json:"ram_bytes"`
+	RAMCapacity   int64  `json:"ram_capacity"`
+	Error         string `json:"error,omitempty"`
+}
+
+var (
+	flashUsageRe = regexp.MustCompile(`Sketch uses (\d+) bytes`)
+	ramUsageRe   = regexp.MustCompile(`Global variables use (\d+) bytes`)
+)
+
+// parseCompileSizes pulls flash/RAM byte counts out of arduino-cli compile's
+// combined output. ok is false when the output doesn't look like an
+// arduino-cli summary at all (e.g. a platform-pack build, which doesn't
+// go through arduino-cli).
+func parseCompileSizes(output string) (flashUsed, ramUsed int64, ok bool) {
+	fm := flashUsageRe.FindStringSubmatch(output)
+	rm := ramUsageRe.FindStringSubmatch(output)
+	if fm == nil && rm == nil {
+		return 0, 0, false
+	}
+	if fm != nil {
+		flashUsed, _ = strconv.ParseInt(fm[1], 10, 64)
+	}
+	if rm != nil {
+		ramUsed, _ = strconv.ParseInt(rm[1], 10, 64)
+	}
+	return flashUsed, ramUsed, true
+}
+
+// smokeBuildBoard runs a --compile build against board and turns the
+// outcome into a sizeReportRow, never returning an error itself — a
+// compile failure is recorded as a failed row, not a halted smoke build.
+func smokeBuildBoard(dir string, board boardChoice, backend backendChoice) sizeReportRow {
+	row := sizeReportRow{Target: board.id, FlashCapacity: board.flashBytes, RAMCapacity: board.ramBytes}
+
+	m, err := manifest.Load(dir)
+	if err != nil {
+		row.Status = "fail"
+		row.Error = err.Error()
+		return row
+	}
+
+	res, err := Run(dir, m, Options{
+		Board:       board.id,
+		Compile:     true,
+		ArduinoCLI:  cfg.ArduinoCLI,
+		FlashBinary: cfg.FlashBinary,
+	})
+	if err != nil {
+		row.Status = "fail"
+		row.Error = err.Error()
+		return row
+	}
+
+	row.Status = "pass"
+	if flashUsed, ramUsed, ok := parseCompileSizes(res.CompileOutput); ok {
+		row.FlashBytes = flashUsed
+		row.RAMBytes = ramUsed
+	}
+	return row
+}
+
+// smokeBuildAndReport smoke-builds every target in parallel, renders the
+// dashboard, and writes build/size-report.json.
+func smokeBuildAndReport(dir string, targets []boardChoice, backend backendChoice) error {
+	rows := make([]sizeReportRow, len(targets))
+	var wg sync.WaitGroup
+	for i, board := range targets {
+		wg.Add(1)
+		go func(i int, board boardChoice) {
+			defer wg.Done()
+			rows[i] = smokeBuildBoard(dir, board, backend)
+		}(i, board)
+	}
+	wg.Wait()
+
+	renderSizeDashboard(rows)
+	return writeSizeReport(dir, rows)
+}
+
+// renderSizeDashboard prints a target | status | flash | ram table.
+func renderSizeDashboard(rows []sizeReportRow) {
+	fmt.Println()
+	ui.SectionTitle("Smoke build")
+	ui.ColorTitle.Printf("  %-16s  %-6s  %-20s  %s\n", "TARGET", "STATUS", "FLASH (bytes / %)", "RAM (bytes / %)")
+	ui.ColorMuted.Println("  " + strings.Repeat("─", 70))
+	for _, r := range rows {
+		statusColor := ui.ColorSuccess
+		if r.Status != "pass" {
+			statusColor = ui.ColorError
+		}
+		ui.ColorInfo.Printf("  %-16s  ", r.Target)
+		statusColor.Printf("%-6s  ", r.Status)
+		if r.Status == "pass" {
+			fmt.Printf("%-20s  %s\n", sizeCell(r.FlashBytes, r.FlashCapacity), sizeCell(r.RAMBytes, r.RAMCapacity))
+		} else {
+			ui.ColorMuted.Printf("%s\n", r.Error)
+		}
+	}
+}
+
+func sizeCell(used, capacity int64) string {
+	if capacity <= 0 {
+		return fmt.Sprintf("%d", used)
+	}
+	return fmt.Sprintf("%d / %.1f%%", used, 100*float64(used)/float64(capacity))
+}
+
+// writeSizeReport writes rows to dir/build/size-report.json, so CI can
+// gate on flash/RAM usage without scraping the dashboard's terminal output.
+func writeSizeReport(dir string, rows []sizeReportRow) error {
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(buildDir, "size-report.json"), data, 0644)
+}
@@ -0,0 +1,161 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: templatesrc  —  materializing a templates.Template
+//
+//  A builtin template is just its inline Code written to src/main.go. A git
+//  template is shallow-cloned to a temp dir, its Subdir (or clone root) is
+//  copied into the project, and any *.tmpl file is rendered through
+//  text/template with templateData in scope before the .tmpl suffix is
+//  dropped. This is where exec.Command and the project layout live, kept
+//  out of internal/templates so that package stays a plain registry.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/tsuki/cli/internal/templates"
+)
+
+// templateData is the scope exposed to a remote template's *.tmpl files.
+type templateData struct {
+	ProjectName string
+	Board       string
+	BoardFQBN   string
+	Backend     string
+}
+
+// adhocGitTemplate builds a one-off templates.Template from a
+// "github.com/user/repo@ref"-shaped spec, bypassing the registry entirely —
+// the --template flag's escape hatch.
+func adhocGitTemplate(spec string) (templates.Template, error) {
+	repoPath, ref := spec, ""
+	if i := strings.LastIndex(spec, "@"); i != -1 {
+		repoPath, ref = spec[:i], spec[i+1:]
+	}
+	if repoPath == "" {
+		return templates.Template{}, fmt.Errorf("invalid --template %q, want github.com/user/repo@ref", spec)
+	}
+	return templates.Template{
+		ID:   "adhoc",
+		Name: spec,
+		Source: templates.Source{
+			Type: "git",
+			URL:  "https://" + repoPath,
+			Ref:  ref,
+		},
+	}, nil
+}
+
+// writeStarterTemplate materializes tmpl into the new project at dir (with
+// sources under srcDir).
+func writeStarterTemplate(dir, srcDir string, tmpl templates.Template, data templateData) error {
+	if tmpl.Source.Type == "git" {
+		return cloneAndRenderTemplate(dir, srcDir, tmpl.Source, data)
+	}
+
+	p := filepath.Join(srcDir, "main.go")
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return os.WriteFile(p, []byte(tmpl.Source.Code), 0644)
+	}
+	return nil
+}
+
+// cloneAndRenderTemplate shallow-clones src.URL, copies src.Subdir (or the
+// whole clone) into dir, and renders any *.tmpl file it finds with data.
+func cloneAndRenderTemplate(dir, srcDir string, src templates.Source, data templateData) error {
+	tmpDir, err := os.MkdirTemp("", "tsuki-template-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	args := []string{"clone", "--depth", "1"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+	args = append(args, src.URL, tmpDir)
+
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone %s: %w\n%s", src.URL, err, out)
+	}
+
+	from := tmpDir
+	if src.Subdir != "" {
+		from = filepath.Join(tmpDir, src.Subdir)
+	}
+
+	return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+
+		// main.go lives under srcDir; everything else (goduino.json,
+		// README, etc.) lands at the project root and is then
+		// overwritten by scaffold's own steps where they collide.
+		destRoot := dir
+		if strings.HasSuffix(rel, ".go") || strings.HasSuffix(rel, ".go.tmpl") {
+			destRoot = srcDir
+			rel = filepath.Base(rel)
+		}
+		dest := filepath.Join(destRoot, rel)
+
+		if strings.HasSuffix(dest, ".tmpl") {
+			return renderTemplateFile(path, strings.TrimSuffix(dest, ".tmpl"), info.Mode(), data)
+		}
+		return copyFile(path, dest, info.Mode())
+	})
+}
+
+func renderTemplateFile(src, dest string, mode os.FileMode, data templateData) error {
+	t, err := template.ParseFiles(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.Execute(f, data)
+}
+
+func copyFile(src, dest string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
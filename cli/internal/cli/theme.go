@@ -0,0 +1,60 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: theme  —  preview the active color theme
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func newThemeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "theme",
+		Short: "Inspect and preview tsuki's color theme",
+	}
+	cmd.AddCommand(newThemePreviewCmd())
+	return cmd
+}
+
+// newThemePreviewCmd prints a sample Box, Traceback, and config table with
+// the active theme (already resolved by PersistentPreRunE from --theme, the
+// project manifest, $XDG_CONFIG_HOME/tsuki/theme.toml, or the default
+// preset) so a theme can be sanity-checked without triggering a real error.
+func newThemePreviewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "preview",
+		Short: "Print sample output using the active theme",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ui.Box("sample box", "this is a Box panel rendered in the active theme", ui.ColorTitle)
+
+			ui.Traceback("SampleError", "something went wrong", []ui.Frame{
+				{
+					File: "blink.go",
+					Line: 14,
+					Func: "setup",
+					Code: []ui.CodeLine{
+						{Number: 13, Text: "pin := led.Pin(13)"},
+						{Number: 14, Text: "delay(pin)", IsPointer: true},
+						{Number: 15, Text: "pin.High()"},
+					},
+					Locals: map[string]string{"pin": "led.Pin(13)"},
+				},
+			})
+
+			ui.PrintConfig("sample config", []ui.ConfigEntry{
+				{Key: "board", Value: "uno", Comment: "target board"},
+				{Key: "baud", Value: 9600},
+				{Key: "verify_signatures", Value: true},
+			}, false)
+
+			ui.Success("this is ui.Success")
+			ui.Warn("this is ui.Warn")
+			ui.Fail("this is ui.Fail")
+			ui.Info("this is ui.Info")
+
+			return nil
+		},
+	}
+}
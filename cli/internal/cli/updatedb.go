@@ -5,18 +5,27 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/db"
 	"github.com/tsuki/cli/internal/ui"
 )
 
+// cliVersion is reported in the User-Agent sent to registries. It mirrors
+// what `tsuki version` prints; kept local to avoid updatedb depending on a
+// command package for a single string.
+const cliVersion = "0.0.0-dev"
+
 // keysFilePath returns the path to the user's keys.json.
 func keysFilePath() string {
 	home, _ := os.UserHomeDir()
@@ -33,17 +42,72 @@ func dbCacheDir() string {
 type registryKey struct {
 	Name string `json:"name"`
 	URL  string `json:"url"` // base URL; packages.json is fetched from URL/packages.json
+
+	// PublicKey, if set, is the base64-encoded raw Ed25519 public key
+	// updatedb verifies packages.json against before caching it. Registries
+	// without a PublicKey are cached unverified, same as before this field
+	// existed.
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// registryCacheMeta is the conditional-request state persisted alongside
+// each registry's cached packages.json, at <name>.meta.json.
+type registryCacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func registryCacheMetaPath(cacheDir, name string) string {
+	return filepath.Join(cacheDir, name+".meta.json")
+}
+
+func loadRegistryCacheMeta(cacheDir, name string) registryCacheMeta {
+	var meta registryCacheMeta
+	data, err := os.ReadFile(registryCacheMetaPath(cacheDir, name))
+	if err != nil {
+		return meta
+	}
+	_ = json.Unmarshal(data, &meta)
+	return meta
+}
+
+func saveRegistryCacheMeta(cacheDir, name string, meta registryCacheMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(registryCacheMetaPath(cacheDir, name), data, 0644)
+}
+
+// registrySyncResult is one registry's outcome, collected by the worker
+// pool and rendered after all fetches complete.
+type registrySyncResult struct {
+	name         string
+	notModified  bool
+	packageCount int
+	err          error
 }
 
 func newUpdateDBCmd() *cobra.Command {
+	var jobs int
+	var force bool
+
 	cmd := &cobra.Command{
 		Use:   "updatedb",
 		Short: "Refresh the local registry cache from keys.json",
-		Long: `updatedb reads ~/.config/tsuki/keys.json, fetches the packages.json from
-every listed registry, and caches the results in ~/.cache/tsuki/db/.
+		Long: `updatedb reads ~/.config/tsuki/keys.json and fetches the packages.json from
+every listed registry concurrently (bounded by --jobs, default: number of
+CPUs), caching the results in ~/.cache/tsuki/db/.
+
+Each registry's ETag/Last-Modified headers are remembered in
+<name>.meta.json so a repeat run sends If-None-Match/If-Modified-Since and
+treats a 304 response as already up to date, without rewriting the cache
+file. Pass --force to skip conditional requests and re-fetch everything.
 
 Run this after adding a new registry key or when packages seem out of date.`,
-		Example: `  tsuki updatedb`,
+		Example: `  tsuki updatedb
+  tsuki updatedb --jobs 8
+  tsuki updatedb --force`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			keysPath := keysFilePath()
 
@@ -77,62 +141,222 @@ Run this after adding a new registry key or when packages seem out of date.`,
 			cacheDir := dbCacheDir()
 			_ = os.MkdirAll(cacheDir, 0755)
 
-			var ok, fail int
-			for _, key := range keys {
-				sp := ui.NewSpinner(fmt.Sprintf("%-20s  %s", key.Name, key.URL))
-				sp.Start()
+			concurrency := jobs
+			if concurrency < 1 {
+				concurrency = runtime.NumCPU()
+			}
 
-				pkgURL := key.URL
-				if pkgURL[len(pkgURL)-1] != '/' {
-					pkgURL += "/"
-				}
-				pkgURL += "packages.json"
+			sp := ui.NewSpinner(fmt.Sprintf("syncing %d registr(y/ies) — %d parallel job(s)", len(keys), concurrency))
+			sp.Start()
 
-				body, err := httpGet(pkgURL)
-				if err != nil {
-					sp.Stop(false, fmt.Sprintf("%s — %v", key.Name, err))
-					fail++
-					continue
+			results := make([]registrySyncResult, len(keys))
+			syncJobs := make([]func() error, len(keys))
+			for i, key := range keys {
+				i, key := i, key
+				syncJobs[i] = func() error {
+					results[i] = syncRegistry(cacheDir, key, force)
+					return nil // per-registry failures are reported individually, not fatal to the pool
 				}
+			}
+			runPool(concurrency, syncJobs)
+			sp.Stop(true, "sync round-trip complete")
 
-				cacheFile := filepath.Join(cacheDir, key.Name+".json")
-				if err := os.WriteFile(cacheFile, body, 0644); err != nil {
-					sp.Stop(false, fmt.Sprintf("%s — write error: %v", key.Name, err))
+			if indexErr := indexRegistries(cacheDir, results); indexErr != nil {
+				ui.Warn(fmt.Sprintf("indexing package db: %v", indexErr))
+			}
+
+			fmt.Println()
+			var ok, unchanged, fail int
+			for _, r := range results {
+				switch {
+				case r.err != nil:
+					ui.Warn(fmt.Sprintf("%-20s  %v", r.name, r.err))
 					fail++
-					continue
+				case r.notModified:
+					ui.Step(r.name, "not modified (304) — cache already current")
+					unchanged++
+				default:
+					ui.Step(r.name, fmt.Sprintf("%d package(s)", r.packageCount))
+					ok++
 				}
-
-				// Count packages in the response.
-				var pkgs []map[string]interface{}
-				_ = json.Unmarshal(body, &pkgs)
-				sp.Stop(true, fmt.Sprintf("%-20s  %d package(s)", key.Name, len(pkgs)))
-				ok++
 			}
 
 			fmt.Println()
 			if fail > 0 {
-				ui.Warn(fmt.Sprintf("updated %d/%d registries (%d failed)", ok, len(keys), fail))
+				ui.Warn(fmt.Sprintf("updated %d, unchanged %d, failed %d (of %d registries)", ok, unchanged, fail, len(keys)))
 			} else {
-				ui.Success(fmt.Sprintf("updated %d registry sources", ok))
+				ui.Success(fmt.Sprintf("updated %d, unchanged %d (of %d registries)", ok, unchanged, len(keys)))
 			}
 			return nil
 		},
 	}
 
+	cmd.Flags().IntVar(&jobs, "jobs", 0, "parallel registry fetches (default: number of CPUs)")
+	cmd.Flags().BoolVar(&force, "force", false, "bypass conditional If-None-Match/If-Modified-Since caching")
 	return cmd
 }
 
+// syncRegistry fetches one registry's packages.json, honoring conditional
+// headers unless force is set, verifies its signature if the key declares
+// a PublicKey, and writes the cache + meta files on success.
+func syncRegistry(cacheDir string, key registryKey, force bool) registrySyncResult {
+	result := registrySyncResult{name: key.Name}
+
+	pkgURL := key.URL
+	if pkgURL[len(pkgURL)-1] != '/' {
+		pkgURL += "/"
+	}
+	pkgURL += "packages.json"
+
+	var meta registryCacheMeta
+	if !force {
+		meta = loadRegistryCacheMeta(cacheDir, key.Name)
+	}
+
+	body, respMeta, notModified, err := httpGetConditional(pkgURL, meta)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if notModified {
+		result.notModified = true
+		return result
+	}
+
+	if key.PublicKey != "" {
+		if err := verifyPackagesJSON(body, pkgURL, key.PublicKey); err != nil {
+			result.err = fmt.Errorf("signature verification failed: %w (keeping existing cache)", err)
+			return result
+		}
+	}
+
+	cacheFile := filepath.Join(cacheDir, key.Name+".json")
+	if err := os.WriteFile(cacheFile, body, 0644); err != nil {
+		result.err = fmt.Errorf("write error: %w", err)
+		return result
+	}
+	if err := saveRegistryCacheMeta(cacheDir, key.Name, respMeta); err != nil {
+		result.err = fmt.Errorf("write error (meta): %w", err)
+		return result
+	}
+
+	var pkgs []map[string]interface{}
+	_ = json.Unmarshal(body, &pkgs)
+	result.packageCount = len(pkgs)
+	return result
+}
+
+// indexRegistries ingests every registry whose cache file is current (a
+// fresh 200, or an unchanged 304 — either way the cache file on disk
+// reflects what the db should hold) into the local SQLite package index,
+// so resolveSpecURL's indexed query has something to find. A registry that
+// failed to sync is left out — its db rows, if any, carry over from the
+// last successful run rather than being cleared.
+func indexRegistries(cacheDir string, results []registrySyncResult) error {
+	d, err := db.Open(db.DefaultPath())
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		body, err := os.ReadFile(filepath.Join(cacheDir, r.name+".json"))
+		if err != nil {
+			continue
+		}
+		if err := d.IngestRegistry(r.name, body); err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+	}
+	return nil
+}
+
+// userAgent builds the User-Agent tsuki sends to registries, following the
+// same "<tool>/<version> (<os>/<arch>)" shape arduino-cli uses for its own
+// gRPC/HTTP clients.
+func userAgent() string {
+	return fmt.Sprintf("tsuki-cli/%s (%s/%s)", cliVersion, runtime.GOOS, runtime.GOARCH)
+}
+
+// httpGet performs an unconditional GET, failing on HTTP >= 400.
 func httpGet(url string) ([]byte, error) {
+	body, _, _, err := httpGetConditional(url, registryCacheMeta{})
+	return body, err
+}
+
+// httpGetConditional performs a GET against url, sending If-None-Match /
+// If-Modified-Since from meta unless meta is empty. A 304 response returns
+// notModified=true and no body. On a fresh 200, the returned meta carries
+// whatever ETag/Last-Modified headers the server sent back, for the caller
+// to persist for the next run.
+func httpGetConditional(url string, meta registryCacheMeta) (body []byte, respMeta registryCacheMeta, notModified bool, err error) {
 	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, registryCacheMeta{}, false, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, registryCacheMeta{}, false, err
 	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, registryCacheMeta{}, true, nil
+	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+		return nil, registryCacheMeta{}, false, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, registryCacheMeta{}, false, err
 	}
-	return io.ReadAll(resp.Body)
+
+	respMeta = registryCacheMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return body, respMeta, false, nil
+}
+
+// verifyPackagesJSON fetches <pkgURL>.minisig (a raw 64-byte Ed25519
+// signature over the packages.json body, the same detached-signature shape
+// pkgmgr already uses for package manifests) and verifies it against
+// pubKeyB64, a base64-encoded raw Ed25519 public key.
+func verifyPackagesJSON(body []byte, pkgURL, pubKeyB64 string) error {
+	pubBytes, err := base64.StdEncoding.DecodeString(pubKeyB64)
+	if err != nil {
+		return fmt.Errorf("decoding public_key: %w", err)
+	}
+	if len(pubBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("public_key is %d bytes, want %d", len(pubBytes), ed25519.PublicKeySize)
+	}
+
+	sigBytes, err := httpGet(pkgURL + ".minisig")
+	if err != nil {
+		return fmt.Errorf("fetching %s.minisig: %w", pkgURL, err)
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d (expected %d)", len(sigBytes), ed25519.SignatureSize)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pubBytes), body, sigBytes) {
+		return fmt.Errorf("signature does not match packages.json")
+	}
+	return nil
 }
 
 func writeDefaultKeys(path string) error {
@@ -142,4 +366,4 @@ func writeDefaultKeys(path string) error {
 	}
 	data, _ := json.MarshalIndent(defaults, "", "  ")
 	return os.WriteFile(path, append(data, '\n'), 0644)
-}
\ No newline at end of file
+}
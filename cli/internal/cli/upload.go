@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/tsuki/cli/internal/flash"
 	"github.com/tsuki/cli/internal/manifest"
@@ -9,10 +13,15 @@ import (
 
 func newUploadCmd() *cobra.Command {
 	var (
-		port     string
-		board    string
-		buildDir string
-		backend  string
+		port      string
+		board     string
+		buildDir  string
+		backend   string
+		offline   bool
+		retries   int
+		wait      bool
+		inputFile string
+		jsonOut   bool
 	)
 
 	cmd := &cobra.Command{
@@ -23,9 +32,21 @@ func newUploadCmd() *cobra.Command {
   tsuki upload --port COM3 --board uno`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dir := projectDir()
-			_, m, err := manifest.Find(dir)
-			if err != nil {
-				return err
+
+			// --input-file flashes a prebuilt image standalone — no tsuki
+			// project required, so skip the manifest lookup entirely.
+			var m *manifest.Manifest
+			if inputFile != "" {
+				if board == "" {
+					return fmt.Errorf("--board is required together with --input-file (there's no manifest to default it from)")
+				}
+				m = manifest.Default(filepath.Base(dir), board)
+			} else {
+				var err error
+				_, m, _, err = manifest.Find(dir)
+				if err != nil {
+					return err
+				}
 			}
 
 			// Resolve effective backend: flag > manifest > config.
@@ -37,17 +58,37 @@ func newUploadCmd() *cobra.Command {
 				effectiveBackend = cfg.Backend
 			}
 
+			if effectiveBackend != "" {
+				valid := false
+				for _, name := range flash.RegisteredBackends() {
+					if name == effectiveBackend {
+						valid = true
+						break
+					}
+				}
+				if !valid {
+					return fmt.Errorf("unknown backend %q — choose one of: %s", effectiveBackend, strings.Join(flash.RegisteredBackends(), ", "))
+				}
+			}
+
 			// Show the backend badge before uploading.
 			ui.FlashBadge(effectiveBackend)
 
 			return flash.Run(dir, m, flash.Options{
-				Port:        port,
-				Board:       board,
-				BuildDir:    buildDir,
-				ArduinoCLI:  cfg.ArduinoCLI,
-				FlashBinary: cfg.FlashBinary,
-				Backend:     effectiveBackend,
-				Verbose:     cfg.Verbose,
+				Port:                port,
+				Board:               board,
+				BuildDir:            buildDir,
+				ArduinoCLI:          cfg.ArduinoCLI,
+				FlashBinary:         cfg.FlashBinary,
+				Backend:             effectiveBackend,
+				Verbose:             cfg.Verbose,
+				FirmwareIndexURL:    cfg.FirmwareIndexURL,
+				FirmwareIndexKeyURL: cfg.FirmwareIndexKeyURL,
+				Offline:             offline,
+				Retries:             retries,
+				Wait:                wait,
+				InputFile:           inputFile,
+				JSON:                jsonOut,
 			})
 		},
 	}
@@ -55,6 +96,11 @@ func newUploadCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&port, "port", "p", "", "serial port (auto-detect if omitted)")
 	cmd.Flags().StringVarP(&board, "board", "b", "", "target board (overrides manifest)")
 	cmd.Flags().StringVar(&buildDir, "build-dir", "", "directory with compiled firmware")
-	cmd.Flags().StringVar(&backend, "backend", "", "override backend: tsuki-flash | tsuki-flash+cores | arduino-cli")
+	cmd.Flags().StringVar(&backend, "backend", "", "override backend: "+strings.Join(flash.RegisteredBackends(), " | "))
+	cmd.Flags().BoolVar(&offline, "offline", false, "use the cached firmware index only, never fetch")
+	cmd.Flags().IntVar(&retries, "retries", 2, "upload attempts before giving up (SAMD/RP2040 bootloader touch is retried too)")
+	cmd.Flags().BoolVar(&wait, "wait", false, "block until the board is plugged in instead of failing with \"no board detected\"")
+	cmd.Flags().StringVarP(&inputFile, "input-file", "i", "", "flash a prebuilt .hex/.bin/.uf2 (or module blob) standalone, no project required")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "on failure, print diagnostics as NDJSON instead of a rich traceback")
 	return cmd
-}
\ No newline at end of file
+}
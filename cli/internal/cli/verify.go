@@ -0,0 +1,152 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: cli :: verify  —  check a .tskp's embedded signature
+// ─────────────────────────────────────────────────────────────────────────────
+
+package cli
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// trustedKeysDir returns the directory holding trusted Ed25519 public keys
+// for .tskp verification — one base64-encoded raw key per file.
+func trustedKeysDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "tsuki", "trusted-keys")
+}
+
+// loadTrustedKeys reads every key file in trustedKeysDir, keyed by the key
+// id tsuki-package.sig records it under (see sign.go's keyIDForPublicKey).
+func loadTrustedKeys() (map[string]ed25519.PublicKey, error) {
+	dir := trustedKeysDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]ed25519.PublicKey{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]ed25519.PublicKey{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		pubBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(pubBytes) != ed25519.PublicKeySize {
+			continue
+		}
+		pub := ed25519.PublicKey(pubBytes)
+		keys[keyIDForPublicKey(pub)] = pub
+	}
+	return keys, nil
+}
+
+func newVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify <file.tskp>",
+		Short: "Verify a .tskp package's signature",
+		Long: `verify recomputes the entry manifest of a .tskp archive (see
+sign.go / 'tsuki build --sign') and checks the embedded tsuki-package.sig
+— or, if the archive has none, the detached <file>.minisig next to it —
+against the trusted keys in ~/.config/tsuki/trusted-keys/.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  tsuki verify myproject-1.0.0.tskp`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVerify(args[0])
+		},
+	}
+	return cmd
+}
+
+func runVerify(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	var signed []signedEntry
+	var sigBytes []byte
+	for _, f := range zr.File {
+		if f.Name == "tsuki-package.sig" {
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("reading tsuki-package.sig: %w", err)
+			}
+			sigBytes, err = io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("reading tsuki-package.sig: %w", err)
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		signed = append(signed, signedEntry{name: f.Name, hash: sha256.Sum256(data)})
+	}
+
+	if sigBytes == nil {
+		data, err := os.ReadFile(path + ".minisig")
+		if err != nil {
+			return fmt.Errorf("%s has no embedded tsuki-package.sig and no %s.minisig sidecar found", path, path)
+		}
+		sigBytes = data
+	}
+
+	var sig tskpSignature
+	if err := json.Unmarshal(sigBytes, &sig); err != nil {
+		return fmt.Errorf("parsing signature: %w", err)
+	}
+
+	digest := entryManifestDigest(signed)
+	if hex.EncodeToString(digest[:]) != sig.Digest {
+		return fmt.Errorf("signature invalid: archive contents do not match the signed manifest")
+	}
+
+	sigRaw, err := hex.DecodeString(sig.Signature)
+	if err != nil {
+		return fmt.Errorf("parsing signature hex: %w", err)
+	}
+
+	trusted, err := loadTrustedKeys()
+	if err != nil {
+		return fmt.Errorf("loading trusted keys: %w", err)
+	}
+	pub, ok := trusted[sig.KeyID]
+	if !ok {
+		return fmt.Errorf("signed by unknown key %s — not present in %s", sig.KeyID, trustedKeysDir())
+	}
+
+	if !ed25519.Verify(pub, digest[:], sigRaw) {
+		return fmt.Errorf("signature invalid: does not verify against key %s", sig.KeyID)
+	}
+
+	ui.Success(fmt.Sprintf("%s: signature OK (key %s)", path, sig.KeyID))
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 
 const defaultRegistryURL = "https://raw.githubusercontent.com/s7lver2/tsuki/refs/heads/main/pkg/packages.json"
 const defaultKeysIndexURL = "https://raw.githubusercontent.com/s7lver2/tsuki/refs/heads/main/pkg/keys/index.json"
+const defaultAdvisoryURL = "https://raw.githubusercontent.com/s7lver/tsuki-advisories/refs/heads/main/advisories.json"
 
 // Config holds all persistent user-level settings.
 type Config struct {
@@ -52,6 +53,10 @@ type Config struct {
 	// order (first registry wins on name collisions).
 	RegistryURLs []string `json:"registry_urls" comment:"ordered list of package registry URLs"`
 
+	// MaxParallelFetches bounds how many tsukilib.toml downloads + signature
+	// verifications a single Install's dependency-graph batch runs at once.
+	MaxParallelFetches int `json:"max_parallel_fetches,omitempty" comment:"max concurrent package fetches per install (default: 8)"`
+
 	// ── Signing keys ────────────────────────────────────────────────────────
 
 	// KeysDir is where downloaded public signing keys are cached.
@@ -63,26 +68,66 @@ type Config struct {
 
 	// VerifySignatures controls whether package signatures are verified on install.
 	VerifySignatures bool `json:"verify_signatures" comment:"verify package signatures on install"`
+
+	// AdvisoryURL is the signed vulnerability advisory feed consulted by
+	// `tsuki pkg audit` (and automatically after install when
+	// AuditOnInstall is set). The feed is a JSON array of advisories,
+	// Ed25519-signed the same way a package's tsukilib.toml is.
+	AdvisoryURL string `json:"advisory_url,omitempty" comment:"URL of the signed vulnerability advisory feed"`
+
+	// AuditOnInstall runs the advisory audit automatically after every
+	// `tsuki pkg install`, so known-vulnerable versions are flagged before
+	// the user builds against them.
+	AuditOnInstall bool `json:"audit_on_install" comment:"run tsuki pkg audit automatically after every install"`
+
+	// ── Firmware index ──────────────────────────────────────────────────────
+
+	// FirmwareIndexURL pins the firmware/plugin index JSON used to resolve
+	// board -> uploader tool (avrdude, bossac, picotool, esptool, dfu-util)
+	// without requiring arduino-cli or tsuki-flash on PATH. Empty disables
+	// the feature entirely — tsuki upload keeps using Backend as before.
+	FirmwareIndexURL string `json:"firmware_index_url,omitempty" comment:"URL of the firmware/plugin index JSON (leave empty to disable)"`
+
+	// FirmwareIndexKeyURL is the Ed25519 public key (PEM) used to verify
+	// FirmwareIndexURL's ".sig" detached signature. Empty skips verification.
+	FirmwareIndexKeyURL string `json:"firmware_index_key_url,omitempty" comment:"public key URL used to verify the firmware index signature"`
+
+	// TUFMetadataURL is the base directory URL serving the TUF-style
+	// root/timestamp/snapshot/targets metadata (see internal/tuf). Set by
+	// `tsuki keys init --root <url>`; empty means TUF is not bootstrapped and
+	// package installs fall back to the legacy flat key index.
+	TUFMetadataURL string `json:"tuf_metadata_url,omitempty" comment:"base URL for TUF root/timestamp/snapshot/targets metadata, set by tsuki keys init"`
+
+	// ── Credentials ─────────────────────────────────────────────────────────
+
+	// CredentialHelpers maps a registry host (e.g. "github.com") to a
+	// docker-credential-* helper binary name (e.g. "pass", without the
+	// "docker-credential-" prefix). Set via: tsuki config login <host>
+	CredentialHelpers map[string]string `json:"credential_helpers" comment:"host -> docker-credential-* helper name, set via tsuki config login"`
 }
 
 // Default returns a Config with sensible defaults.
 func Default() *Config {
 	return &Config{
-		CoreBinary:       "",
-		ArduinoCLI:       "arduino-cli",
-		FlashBinary:      "tsuki-flash",
-		Backend:          "arduino-cli",
-		DefaultBoard:     "uno",
-		DefaultBaud:      9600,
-		Color:            true,
-		Verbose:          false,
-		AutoDetect:       true,
-		LibsDir:          "",
-		RegistryURL:      "",
-		RegistryURLs:     []string{}, // empty: falls through to registry_url or env var
-		KeysDir:          "",
-		KeysIndexURL:     defaultKeysIndexURL,
-		VerifySignatures: false,
+		CoreBinary:         "",
+		ArduinoCLI:         "arduino-cli",
+		FlashBinary:        "tsuki-flash",
+		Backend:            "arduino-cli",
+		DefaultBoard:       "uno",
+		DefaultBaud:        9600,
+		Color:              true,
+		Verbose:            false,
+		AutoDetect:         true,
+		LibsDir:            "",
+		RegistryURL:        "",
+		RegistryURLs:       []string{}, // empty: falls through to registry_url or env var
+		MaxParallelFetches: 0,          // 0: falls through to ResolvedMaxParallelFetches' default
+		KeysDir:            "",
+		KeysIndexURL:       defaultKeysIndexURL,
+		VerifySignatures:   false,
+		AdvisoryURL:        "",
+		AuditOnInstall:     false,
+		CredentialHelpers:  map[string]string{},
 	}
 }
 
@@ -139,6 +184,17 @@ func (c *Config) ResolvedRegistryURLs() []string {
 	return urls
 }
 
+// defaultMaxParallelFetches is ResolvedMaxParallelFetches' fallback.
+const defaultMaxParallelFetches = 8
+
+// ResolvedMaxParallelFetches returns the effective fetch concurrency cap.
+func (c *Config) ResolvedMaxParallelFetches() int {
+	if c.MaxParallelFetches > 0 {
+		return c.MaxParallelFetches
+	}
+	return defaultMaxParallelFetches
+}
+
 // ResolvedKeysDir returns the effective signing-keys directory.
 func (c *Config) ResolvedKeysDir() string {
 	if c.KeysDir != "" {
@@ -150,6 +206,19 @@ func (c *Config) ResolvedKeysDir() string {
 	return defaultKeysDir()
 }
 
+// ResolvedCredentialHelper returns the configured docker-credential-* helper
+// name for host, or "" if none is configured (callers should then fall back
+// to the OS keychain).
+func (c *Config) ResolvedCredentialHelper(host string) string {
+	return c.CredentialHelpers[host]
+}
+
+// ResolvedTUFMetadataURL returns the configured TUF metadata base URL, or ""
+// if trust has not been bootstrapped via `tsuki keys init`.
+func (c *Config) ResolvedTUFMetadataURL() string {
+	return c.TUFMetadataURL
+}
+
 // ResolvedKeysIndexURL returns the effective global key-index URL.
 func (c *Config) ResolvedKeysIndexURL() string {
 	if c.KeysIndexURL != "" {
@@ -161,6 +230,17 @@ func (c *Config) ResolvedKeysIndexURL() string {
 	return defaultKeysIndexURL
 }
 
+// ResolvedAdvisoryURL returns the effective vulnerability advisory feed URL.
+func (c *Config) ResolvedAdvisoryURL() string {
+	if c.AdvisoryURL != "" {
+		return c.AdvisoryURL
+	}
+	if env := os.Getenv("tsuki_ADVISORY_URL"); env != "" {
+		return env
+	}
+	return defaultAdvisoryURL
+}
+
 // ── OS-specific default paths ─────────────────────────────────────────────────
 
 func defaultLibsDir() string {
@@ -226,6 +306,9 @@ func Load() (*Config, error) {
 	if len(c.RegistryURLs) == 0 && c.RegistryURL != "" {
 		c.RegistryURLs = []string{c.RegistryURL}
 	}
+	if c.CredentialHelpers == nil {
+		c.CredentialHelpers = map[string]string{}
+	}
 	return c, nil
 }
 
@@ -329,4 +412,4 @@ func (c *Config) AllEntries() []Entry {
 
 func Path() (string, error) {
 	return configPath()
-}
\ No newline at end of file
+}
@@ -7,12 +7,13 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
-	"github.com/godotino/cli/internal/ui"
+	"github.com/tsuki/cli/internal/ui"
 )
 
 const defaultBinary = "godotino-core"
@@ -38,16 +39,143 @@ type TranspileRequest struct {
 	SourceMap  bool
 	// Optional: root directory where external libs are installed.
 	// Passed as --libs-dir to godotino-core.
-	LibsDir  string
+	LibsDir string
 	// Optional: names of packages declared in goduino.json.
 	// Passed as --packages ws2812,dht to godotino-core.
 	PkgNames []string
+	// DiagnosticsJSON requests the --diagnostics=json protocol from
+	// godotino-core instead of the legacy freeform stderr format. Ignored
+	// (silently falls back to the legacy scraper) when Version() reports a
+	// core binary older than the minimum that understands the flag.
+	DiagnosticsJSON bool
 }
 
 // TranspileResult holds the output of a transpilation run.
 type TranspileResult struct {
 	OutputFile string
 	Warnings   []string
+	// Diagnostics is populated when the run used the --diagnostics=json
+	// protocol. Unlike the legacy path, Transpile does not stop at the
+	// first error — every diagnostic godotino-core emitted for the run is
+	// decoded here, even when Transpile also returns an error.
+	Diagnostics []Diagnostic
+}
+
+// Severity is the severity level of a Diagnostic, as reported by
+// godotino-core's --diagnostics=json protocol.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+	SeverityHelp    Severity = "help"
+)
+
+// Span marks a location in a source file. Line and Column are 1-indexed;
+// End{Line,Column} are exclusive.
+type Span struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+}
+
+// LabeledSpan is a secondary span shown alongside a Diagnostic's primary
+// span, with a short label explaining its relevance (e.g. "defined here").
+type LabeledSpan struct {
+	Span  Span   `json:"span"`
+	Label string `json:"label"`
+}
+
+// Fix is a suggested source replacement for a span.
+type Fix struct {
+	Span        Span   `json:"span"`
+	Replacement string `json:"replacement"`
+}
+
+// Diagnostic is one record of godotino-core's --diagnostics=json protocol:
+// one NDJSON line on stderr per diagnostic.
+type Diagnostic struct {
+	Severity    Severity      `json:"severity"`
+	Code        string        `json:"code"`
+	Message     string        `json:"message"`
+	Primary     Span          `json:"primary"`
+	Secondary   []LabeledSpan `json:"secondary,omitempty"`
+	Suggestions []Fix         `json:"suggestions,omitempty"`
+	Notes       []string      `json:"notes,omitempty"`
+}
+
+// minJSONDiagnosticsVersion is the oldest godotino-core release that
+// understands --diagnostics=json. Older binaries are driven with the legacy
+// freeform stderr format instead.
+const minJSONDiagnosticsVersion = "0.3.0"
+
+// supportsJSONDiagnostics reports whether version is new enough to
+// understand --diagnostics=json. It's a three-field dotted-number
+// comparison deliberately kept self-contained rather than reusing
+// registry.Version/Constraint — core has no reason to depend on the
+// registry package just to compare its own binary's version.
+func supportsJSONDiagnostics(version string) bool {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var maj, min, patch int
+	if _, err := fmt.Sscanf(version, "%d.%d.%d", &maj, &min, &patch); err != nil {
+		return false
+	}
+	var wantMaj, wantMin, wantPatch int
+	fmt.Sscanf(minJSONDiagnosticsVersion, "%d.%d.%d", &wantMaj, &wantMin, &wantPatch)
+
+	got := [3]int{maj, min, patch}
+	want := [3]int{wantMaj, wantMin, wantPatch}
+	for i := range got {
+		if got[i] != want[i] {
+			return got[i] > want[i]
+		}
+	}
+	return true
+}
+
+// parseDiagnosticsNDJSON decodes one JSON Diagnostic per non-empty line.
+// Lines that aren't valid JSON (e.g. the binary still logs a banner or a
+// panic to stderr) are skipped rather than failing the whole run.
+func parseDiagnosticsNDJSON(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var d Diagnostic
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			continue
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+func toUISpan(s Span) ui.DiagnosticSpan {
+	return ui.DiagnosticSpan{
+		File: s.File, Line: s.Line, Column: s.Column,
+		EndLine: s.EndLine, EndColumn: s.EndColumn,
+	}
+}
+
+func toUILabels(secondary []LabeledSpan) []ui.DiagnosticLabel {
+	labels := make([]ui.DiagnosticLabel, len(secondary))
+	for i, s := range secondary {
+		labels[i] = ui.DiagnosticLabel{Span: toUISpan(s.Span), Label: s.Label}
+	}
+	return labels
+}
+
+func toUIFixes(suggestions []Fix) []ui.DiagnosticFix {
+	fixes := make([]ui.DiagnosticFix, len(suggestions))
+	for i, f := range suggestions {
+		fixes[i] = ui.DiagnosticFix{Span: toUISpan(f.Span), Replacement: f.Replacement}
+	}
+	return fixes
 }
 
 // Transpile transpiles a single .go file to C++.
@@ -66,6 +194,14 @@ func (t *Transpiler) Transpile(req TranspileRequest) (*TranspileResult, error) {
 		args = append(args, "--packages", strings.Join(req.PkgNames, ","))
 	}
 
+	jsonDiagnostics := false
+	if req.DiagnosticsJSON {
+		if version, err := t.Version(); err == nil && supportsJSONDiagnostics(version) {
+			args = append(args, "--diagnostics=json")
+			jsonDiagnostics = true
+		}
+	}
+
 	cmd := exec.Command(t.binary, args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -75,18 +211,30 @@ func (t *Transpiler) Transpile(req TranspileRequest) (*TranspileResult, error) {
 		ui.Step("core", strings.Join(append([]string{t.binary}, args...), " "))
 	}
 
-	if err := cmd.Run(); err != nil {
-		errOutput := stderr.String()
-		if errOutput != "" {
-			renderCoreError(errOutput, req.InputFile)
-		}
-		return nil, fmt.Errorf("transpilation failed: %w", err)
-	}
+	runErr := cmd.Run()
 
-	return &TranspileResult{
+	result := &TranspileResult{
 		OutputFile: req.OutputFile,
 		Warnings:   parseWarnings(stderr.String()),
-	}, nil
+	}
+	if jsonDiagnostics {
+		result.Diagnostics = parseDiagnosticsNDJSON(stderr.String())
+	}
+
+	if runErr != nil {
+		switch {
+		case jsonDiagnostics && len(result.Diagnostics) > 0:
+			for _, d := range result.Diagnostics {
+				ui.RenderDiagnostic(string(d.Severity), d.Code, d.Message,
+					toUISpan(d.Primary), toUILabels(d.Secondary), toUIFixes(d.Suggestions), d.Notes)
+			}
+		case stderr.String() != "":
+			renderCoreError(stderr.String(), req.InputFile)
+		}
+		return result, fmt.Errorf("transpilation failed: %w", runErr)
+	}
+
+	return result, nil
 }
 
 // Check validates a .go source file without producing output.
@@ -108,7 +256,7 @@ func (t *Transpiler) Check(inputFile, board, libsDir string, pkgNames []string)
 	combined := stdout.String() + stderr.String()
 
 	warnings := parseWarnings(combined)
-	errors   := parseErrors(stderr.String())
+	errors := parseErrors(stderr.String())
 
 	if err != nil {
 		return warnings, errors, fmt.Errorf("check failed")
@@ -161,7 +309,9 @@ func renderCoreError(raw, inputFile string) {
 			loc := strings.TrimSpace(strings.TrimPrefix(line, "-->"))
 			parts := strings.Split(loc, ":")
 			frame := ui.Frame{File: inputFile, Func: "main"}
-			if len(parts) >= 1 { frame.File = parts[0] }
+			if len(parts) >= 1 {
+				frame.File = parts[0]
+			}
 			if len(parts) >= 2 {
 				fmt.Sscanf(parts[1], "%d", &errorLineNum)
 				frame.Line = errorLineNum
@@ -197,7 +347,7 @@ func renderCoreError(raw, inputFile string) {
 	}
 	if errType == "" {
 		errType = "TranspileError"
-		errMsg  = strings.TrimSpace(raw)
+		errMsg = strings.TrimSpace(raw)
 	}
 	if len(frames) == 0 {
 		frames = []ui.Frame{{
@@ -228,4 +378,4 @@ func parseErrors(output string) []string {
 		}
 	}
 	return e
-}
\ No newline at end of file
+}
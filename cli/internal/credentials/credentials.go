@@ -0,0 +1,113 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: credentials  —  registry token storage (docker-credential-* protocol)
+//
+//  Speaks the same wire protocol as `docker-credential-desktop`,
+//  `docker-credential-pass`, etc: a JSON payload on stdin/stdout and one verb
+//  (get/store/erase) as the sole argument. This lets users reuse any existing
+//  docker-credential-* helper to store tsuki registry tokens, instead of
+//  putting PATs in shell env or plaintext config.
+//
+//  Lookup order for a host's token:
+//    1. Config.CredentialHelpers[host] binary, if configured (or set via
+//       `tsuki config login`)
+//    2. OS keychain fallback (see keychain_*.go)
+//    3. caller-supplied env var fallback (push still checks GITHUB_TOKEN itself)
+// ─────────────────────────────────────────────────────────────────────────────
+
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// helperPrefix is prepended to a bare helper name the way Docker does, e.g.
+// "desktop" → "docker-credential-desktop". A name already starting with
+// "docker-credential-" is used as-is.
+const helperPrefix = "docker-credential-"
+
+// credentials is the JSON payload docker-credential-* helpers exchange.
+type credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// resolveHelperBinary turns a configured helper name into the binary to exec.
+func resolveHelperBinary(name string) string {
+	if name == "" || strings.HasPrefix(name, helperPrefix) {
+		return name
+	}
+	return helperPrefix + name
+}
+
+// runHelper execs the helper binary with verb as its argument, writing payload
+// (if any) to stdin and returning stdout.
+func runHelper(binary, verb, payload string) (string, error) {
+	cmd := exec.Command(binary, verb)
+	cmd.Stdin = strings.NewReader(payload)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(errOut.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s %s: %s", binary, verb, msg)
+	}
+	return out.String(), nil
+}
+
+// Get retrieves the stored token for host using helperName (a configured
+// docker-credential-* helper). If helperName is empty, it falls back to the
+// OS keychain (see keychainGet). Returns ("", nil) on a clean miss.
+func Get(helperName, host string) (string, error) {
+	if helperName != "" {
+		binary := resolveHelperBinary(helperName)
+		out, err := runHelper(binary, "get", host)
+		if err != nil {
+			// "credentials not found in native keychain" is the documented
+			// miss response from docker-credential-helpers — treat as a miss.
+			if strings.Contains(err.Error(), "not found") {
+				return "", nil
+			}
+			return "", err
+		}
+		var creds credentials
+		if err := json.Unmarshal([]byte(out), &creds); err != nil {
+			return "", fmt.Errorf("parsing %s response: %w", binary, err)
+		}
+		return creds.Secret, nil
+	}
+	return keychainGet(host)
+}
+
+// Store saves token for host under username "tsuki" via helperName, or the
+// OS keychain if helperName is empty.
+func Store(helperName, host, token string) error {
+	if helperName != "" {
+		binary := resolveHelperBinary(helperName)
+		payload, err := json.Marshal(credentials{ServerURL: host, Username: "tsuki", Secret: token})
+		if err != nil {
+			return err
+		}
+		_, err = runHelper(binary, "store", string(payload))
+		return err
+	}
+	return keychainStore(host, token)
+}
+
+// Erase removes any stored token for host via helperName, or the OS keychain
+// if helperName is empty.
+func Erase(helperName, host string) error {
+	if helperName != "" {
+		binary := resolveHelperBinary(helperName)
+		_, err := runHelper(binary, "erase", host)
+		return err
+	}
+	return keychainErase(host)
+}
@@ -0,0 +1,49 @@
+//go:build darwin
+
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const keychainService = "tsuki"
+
+// keychainGet reads a generic password item from the macOS Keychain via the
+// `security` CLI (no cgo/Keychain framework bindings required).
+func keychainGet(host string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-a", host, "-s", keychainService, "-w").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && strings.Contains(string(exitErr.Stderr), "could not be found") {
+			return "", nil
+		}
+		return "", fmt.Errorf("security find-generic-password: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainStore(host, token string) error {
+	// -U updates an existing item in place rather than failing with "already exists".
+	cmd := exec.Command("security", "add-generic-password", "-a", host, "-s", keychainService, "-w", token, "-U")
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("security add-generic-password: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
+
+func keychainErase(host string) error {
+	cmd := exec.Command("security", "delete-generic-password", "-a", host, "-s", keychainService)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(errOut.String(), "could not be found") {
+			return nil
+		}
+		return fmt.Errorf("security delete-generic-password: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
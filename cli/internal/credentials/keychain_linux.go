@@ -0,0 +1,45 @@
+//go:build linux
+
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainGet reads a secret from the Secret Service (libsecret) via the
+// `secret-tool` CLI, keyed by a "tsuki-host" attribute.
+func keychainGet(host string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "tsuki-host", host).Output()
+	if err != nil {
+		// secret-tool exits non-zero with empty stdout on a miss.
+		if len(out) == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainStore(host, token string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", "tsuki credential for "+host, "tsuki-host", host)
+	cmd.Stdin = strings.NewReader(token)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("secret-tool store: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
+
+func keychainErase(host string) error {
+	cmd := exec.Command("secret-tool", "clear", "tsuki-host", host)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("secret-tool clear: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
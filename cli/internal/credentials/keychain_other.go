@@ -0,0 +1,17 @@
+//go:build !darwin && !linux && !windows
+
+package credentials
+
+import "fmt"
+
+func keychainGet(host string) (string, error) {
+	return "", nil
+}
+
+func keychainStore(host, token string) error {
+	return fmt.Errorf("no OS keychain support on this platform — configure a credential helper via CredentialHelpers instead")
+}
+
+func keychainErase(host string) error {
+	return fmt.Errorf("no OS keychain support on this platform — configure a credential helper via CredentialHelpers instead")
+}
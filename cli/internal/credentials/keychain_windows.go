@@ -0,0 +1,45 @@
+//go:build windows
+
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const targetPrefix = "tsuki:"
+
+// keychainGet is unsupported on Windows: cmdkey can create and delete generic
+// credentials but cannot read a stored password back out (no public non-cgo
+// API for it). Configure a docker-credential-wincred-style CredentialHelpers
+// entry instead, which this package drives via the normal helper protocol.
+func keychainGet(host string) (string, error) {
+	return "", fmt.Errorf("reading credentials directly from Windows Credential Manager is not supported — configure a credential helper via CredentialHelpers instead")
+}
+
+func keychainStore(host, token string) error {
+	target := targetPrefix + host
+	cmd := exec.Command("cmdkey", "/generic:"+target, "/user:tsuki", "/pass:"+token)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cmdkey /generic: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
+
+func keychainErase(host string) error {
+	target := targetPrefix + host
+	cmd := exec.Command("cmdkey", "/delete:"+target)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(errOut.String(), "not found") {
+			return nil
+		}
+		return fmt.Errorf("cmdkey /delete: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
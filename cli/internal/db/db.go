@@ -0,0 +1,304 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: db  —  SQLite-backed registry package index
+//
+//  resolveSpecURL used to re-read and linearly scan every cached
+//  <registry>.json on every single lookup — O(registries·packages) per
+//  install, and a full re-parse for each dependency in a PullAll batch.
+//  `tsuki updatedb` now ingests each registry's packages.json into
+//  ~/.cache/tsuki/db/registry.db, a small SQLite file indexed on
+//  (registry, name, version), so a lookup is one indexed query instead of
+//  a directory walk plus N json.Unmarshal calls.
+//
+//  The raw entry is kept alongside the indexed columns as a JSON blob
+//  (Entry.Data) so callers that need fields beyond name/version/toml_url
+//  (e.g. a registry-specific extension) don't need a schema migration to
+//  read them — only the columns this package actually queries by need to
+//  be indexed.
+//
+//  schemaVersion guards against a stale DB file left over from an older
+//  build of this package: Open checks it against the schema_info row and,
+//  on any mismatch (including a DB that predates schema_info entirely),
+//  drops and recreates every table rather than trying to migrate in
+//  place — the DB is a disposable cache rebuilt by `tsuki updatedb`, not a
+//  source of truth worth preserving across schema changes.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsuki/cli/internal/registry"
+	_ "modernc.org/sqlite"
+)
+
+// schemaVersion is bumped whenever the table layout below changes.
+const schemaVersion = 2
+
+// DB wraps the underlying *sql.DB with the schema this package expects.
+type DB struct {
+	sql *sql.DB
+}
+
+// DefaultPath returns ~/.cache/tsuki/db/registry.db, where Open is normally
+// pointed.
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "tsuki", "db", "registry.db")
+}
+
+// Open opens (creating if necessary) the SQLite database at path and
+// ensures its schema is current, rebuilding it from scratch if it isn't.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating db directory: %w", err)
+	}
+
+	sqlDB, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	d := &DB{sql: sqlDB}
+	if err := d.ensureSchema(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Close releases the underlying connection.
+func (d *DB) Close() error {
+	return d.sql.Close()
+}
+
+func (d *DB) ensureSchema() error {
+	var version int
+	err := d.sql.QueryRow(`SELECT version FROM schema_info LIMIT 1`).Scan(&version)
+	if err == nil && version == schemaVersion {
+		return nil
+	}
+
+	// Missing schema_info, a read error, or a stale version all mean the
+	// same thing here: rebuild from scratch.
+	stmts := []string{
+		`DROP TABLE IF EXISTS packages`,
+		`DROP TABLE IF EXISTS schema_info`,
+		`CREATE TABLE schema_info (version INTEGER NOT NULL)`,
+		`CREATE TABLE packages (
+			registry TEXT NOT NULL,
+			name     TEXT NOT NULL,
+			version  TEXT NOT NULL,
+			toml_url TEXT NOT NULL,
+			sha256   TEXT NOT NULL DEFAULT '',
+			size     INTEGER NOT NULL DEFAULT 0,
+			sig      TEXT NOT NULL DEFAULT '',
+			data     TEXT NOT NULL,
+			PRIMARY KEY (registry, name, version)
+		)`,
+		`CREATE INDEX idx_packages_name ON packages(name)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := d.sql.Exec(stmt); err != nil {
+			return fmt.Errorf("rebuilding schema: %w", err)
+		}
+	}
+	if _, err := d.sql.Exec(`INSERT INTO schema_info (version) VALUES (?)`, schemaVersion); err != nil {
+		return fmt.Errorf("rebuilding schema: %w", err)
+	}
+	return nil
+}
+
+// Entry is one package@version row, as found either by Candidates or
+// FindPkgs. SHA256/Size/Sig are the integrity metadata a registry may
+// advertise for this exact release — see internal/integrity — and are
+// empty/zero when the registry entry declared none.
+type Entry struct {
+	Registry string
+	Name     string
+	Version  string
+	TomlURL  string
+	SHA256   string
+	Size     int64
+	Sig      string // base64 raw Ed25519 signature over the download, if the registry provides one
+	Data     json.RawMessage
+}
+
+// rawEntry mirrors the shape of one packages.json element — the same
+// name/toml_url/download_url fallback lookupPackagesJSON used to apply by
+// hand against a []map[string]interface{}.
+type rawEntry struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	TomlURL     string `json:"toml_url"`
+	DownloadURL string `json:"download_url"`
+	SHA256      string `json:"sha256,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Sig         string `json:"sig,omitempty"`
+}
+
+// IngestRegistry replaces registry's rows with the contents of body, a
+// registry's packages.json payload. Ingestion is transactional: a malformed
+// entry fails the whole call rather than leaving registry half-updated.
+func (d *DB) IngestRegistry(registry string, body []byte) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return fmt.Errorf("parsing packages.json: %w", err)
+	}
+
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM packages WHERE registry = ?`, registry); err != nil {
+		return fmt.Errorf("clearing old rows for %s: %w", registry, err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO packages (registry, name, version, toml_url, sha256, size, sig, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, raw := range entries {
+		var re rawEntry
+		if err := json.Unmarshal(raw, &re); err != nil {
+			return fmt.Errorf("parsing entry: %w", err)
+		}
+		if re.Name == "" {
+			continue
+		}
+		url := re.TomlURL
+		if url == "" {
+			url = re.DownloadURL
+		}
+		if _, err := stmt.Exec(registry, re.Name, re.Version, url, re.SHA256, re.Size, re.Sig, string(raw)); err != nil {
+			return fmt.Errorf("indexing %s@%s: %w", re.Name, re.Version, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Candidates returns every row matching name (case-insensitive) across all
+// registries, optionally narrowed to an exact version. It's what
+// resolveSpecURL queries when a caller didn't pin a registry and needs to
+// show every registry a bare name resolved in.
+func (d *DB) Candidates(name, version string) ([]Entry, error) {
+	rows, err := d.sql.Query(
+		`SELECT registry, name, version, toml_url, sha256, size, sig, data FROM packages
+		 WHERE name = ? COLLATE NOCASE AND (? = '' OR version = ?)`,
+		name, version, version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Entry
+	for rows.Next() {
+		var e Entry
+		var data string
+		if err := rows.Scan(&e.Registry, &e.Name, &e.Version, &e.TomlURL, &e.SHA256, &e.Size, &e.Sig, &data); err != nil {
+			return nil, err
+		}
+		e.Data = json.RawMessage(data)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// FindPkgs resolves specs (each "name" or "name:version", where version may
+// be an exact version or any range expression registry.ParseConstraint
+// accepts — "^1.2", "~1.2.0", ">=1.2 <2", "1.x" — matching
+// InstallOptions.Spec's convention) in a single batch query instead of one
+// round-trip per spec, which is what PullAll needs when a manifest lists
+// dozens of dependencies. found maps each input spec to every registry row
+// whose highest version satisfies it; notFound lists the specs that
+// matched nothing.
+func (d *DB) FindPkgs(specs []string) (found map[string][]Entry, notFound []string, err error) {
+	found = make(map[string][]Entry, len(specs))
+	for _, spec := range specs {
+		name, version := spec, ""
+		if idx := indexOfColon(spec); idx >= 0 {
+			name, version = spec[:idx], spec[idx+1:]
+		}
+
+		all, qerr := d.Candidates(name, "")
+		if qerr != nil {
+			return nil, nil, fmt.Errorf("looking up %q: %w", spec, qerr)
+		}
+
+		entries, matchErr := highestMatchPerRegistry(all, version)
+		if matchErr != nil {
+			return nil, nil, fmt.Errorf("looking up %q: %w", spec, matchErr)
+		}
+		if len(entries) == 0 {
+			notFound = append(notFound, spec)
+			continue
+		}
+		found[spec] = entries
+	}
+	return found, notFound, nil
+}
+
+// highestMatchPerRegistry filters all down to, for each registry
+// represented in it, the single highest version satisfying constraintExpr
+// (a SemVer range, or "" for "latest").
+func highestMatchPerRegistry(all []Entry, constraintExpr string) ([]Entry, error) {
+	constraint, err := registry.ParseConstraint(normalizeConstraint(constraintExpr))
+	if err != nil {
+		return nil, err
+	}
+
+	byRegistry := make(map[string]Entry)
+	var order []string
+	for _, e := range all {
+		v, verErr := registry.ParseVersion(e.Version)
+		if verErr != nil || !constraint.Check(v) {
+			continue
+		}
+		best, seen := byRegistry[e.Registry]
+		if !seen {
+			order = append(order, e.Registry)
+			byRegistry[e.Registry] = e
+			continue
+		}
+		bestVer, _ := registry.ParseVersion(best.Version)
+		if v.Compare(bestVer) > 0 {
+			byRegistry[e.Registry] = e
+		}
+	}
+
+	out := make([]Entry, len(order))
+	for i, reg := range order {
+		out[i] = byRegistry[reg]
+	}
+	return out, nil
+}
+
+// normalizeConstraint lets a caller write the Cargo/npm-style comma form
+// (">=1.0, <2.0") that registry.ParseConstraint's space-separated AND
+// groups don't accept directly.
+func normalizeConstraint(expr string) string {
+	return strings.ReplaceAll(expr, ",", " ")
+}
+
+func indexOfColon(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' {
+			return i
+		}
+	}
+	return -1
+}
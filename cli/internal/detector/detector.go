@@ -0,0 +1,225 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: detector  —  resolve #include headers in transpiled .cpp output
+//
+//  Mirrors arduino-cli's SketchLibrariesDetector: rather than requiring
+//  every transitive Arduino library to be hand-declared, scan the actual
+//  #include directives tsuki-core emitted and resolve each header against
+//  installed tsuki packages, the target board's bundled core headers, and
+//  a small known-library index — the same three places arduino-cli itself
+//  checks before concluding a library needs installing.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package detector
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Resolution is the outcome of resolving one #include header.
+type Resolution struct {
+	Header       string `json:"header"`
+	Resolved     bool   `json:"resolved"`
+	Via          string `json:"via,omitempty"`           // "tsuki-package", "board-core", "library-index"
+	SuggestedLib string `json:"suggested_lib,omitempty"` // arduino-cli/tsuki-flash lib name to install
+}
+
+// TsukiPackage is the subset of pkgmgr.InstalledPackage the resolver needs.
+// Declared locally so detector doesn't import pkgmgr — this package only
+// ever sees what the caller has already loaded.
+type TsukiPackage struct {
+	Name      string
+	CppHeader string
+}
+
+// includeRe matches a C/C++ #include directive, capturing the header name
+// whether it's angle-bracketed or quoted.
+var includeRe = regexp.MustCompile(`^\s*#include\s*[<"]([^>"]+)[>"]`)
+
+// ExtractIncludes returns the deduplicated, sorted set of headers #include'd
+// by a single .cpp file.
+func ExtractIncludes(cppFile string) ([]string, error) {
+	f, err := os.Open(cppFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := includeRe.FindStringSubmatch(scanner.Text()); m != nil {
+			seen[m[1]] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	headers := make([]string, 0, len(seen))
+	for h := range seen {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	return headers, nil
+}
+
+// ExtractAll returns the deduplicated, sorted union of headers #include'd
+// across every file in cppFiles.
+func ExtractAll(cppFiles []string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, f := range cppFiles {
+		headers, err := ExtractIncludes(f)
+		if err != nil {
+			return nil, fmt.Errorf("scanning includes in %s: %w", f, err)
+		}
+		for _, h := range headers {
+			seen[h] = true
+		}
+	}
+	headers := make([]string, 0, len(seen))
+	for h := range seen {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	return headers, nil
+}
+
+// Resolve classifies a single header as satisfied by an installed tsuki
+// package, by the board core's own bundled headers, by a library known to
+// be installable via arduino-cli/tsuki-flash's library index, or as
+// genuinely unresolved.
+func Resolve(header string, tsukiPackages []TsukiPackage, coreIncludeDirs []string) Resolution {
+	for _, pkg := range tsukiPackages {
+		if pkg.CppHeader == header {
+			return Resolution{Header: header, Resolved: true, Via: "tsuki-package"}
+		}
+	}
+
+	for _, dir := range coreIncludeDirs {
+		if _, err := os.Stat(filepath.Join(dir, header)); err == nil {
+			return Resolution{Header: header, Resolved: true, Via: "board-core"}
+		}
+	}
+
+	if lib, ok := knownLibraries[header]; ok {
+		return Resolution{Header: header, Resolved: false, Via: "library-index", SuggestedLib: lib}
+	}
+
+	return Resolution{Header: header, Resolved: false}
+}
+
+// knownLibraries maps commonly-#include'd Arduino library headers to the
+// library name arduino-cli/tsuki-flash's "lib install" expects. It's a
+// small hand-maintained subset of the real Arduino Library Manager index
+// (the same static-table approach boardFQBN/boardToolchain already use for
+// board data), covering the libraries tsuki sketches reach for most often.
+var knownLibraries = map[string]string{
+	"Adafruit_NeoPixel.h": "Adafruit NeoPixel",
+	"Adafruit_GFX.h":      "Adafruit GFX Library",
+	"Adafruit_SSD1306.h":  "Adafruit SSD1306",
+	"Adafruit_Sensor.h":   "Adafruit Unified Sensor",
+	"ArduinoJson.h":       "ArduinoJson",
+	"Servo.h":             "Servo",
+	"LiquidCrystal.h":     "LiquidCrystal",
+	"LiquidCrystal_I2C.h": "LiquidCrystal I2C",
+	"DHT.h":               "DHT sensor library",
+	"OneWire.h":           "OneWire",
+	"IRremote.h":          "IRremote",
+	"FastLED.h":           "FastLED",
+	"PubSubClient.h":      "PubSubClient",
+}
+
+// ── cache ──────────────────────────────────────────────────────────────────
+
+// Cache persists resolution results between builds, keyed by header, so an
+// unchanged sketch skips re-resolving every header on every build. It's
+// invalidated in bulk: ScannedAt is the newest .cpp mtime seen at save
+// time, and a later Scan with no newer .cpp file reuses Headers outright.
+type Cache struct {
+	ScannedAt int64                 `json:"scanned_at"`
+	Headers   map[string]Resolution `json:"headers"`
+}
+
+// LoadCache reads the cache at path, returning an empty Cache if it doesn't
+// exist or can't be parsed (the same tolerant-empty-default behavior
+// autoinstall.go's importScanCache uses).
+func LoadCache(path string) *Cache {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &Cache{Headers: map[string]Resolution{}}
+	}
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil || c.Headers == nil {
+		return &Cache{Headers: map[string]Resolution{}}
+	}
+	return &c
+}
+
+// Save writes the cache to path, creating parent directories as needed.
+func (c *Cache) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// newestModTime returns the most recent mtime (as unix seconds) among files.
+func newestModTime(files []string) int64 {
+	var newest int64
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil {
+			if t := fi.ModTime().Unix(); t > newest {
+				newest = t
+			}
+		}
+	}
+	return newest
+}
+
+// Scan extracts and resolves every header #include'd across cppFiles,
+// reusing cachePath's cached results when no .cpp file is newer than the
+// last scan. It returns one Resolution per distinct header, sorted by
+// header name.
+func Scan(cppFiles []string, tsukiPackages []TsukiPackage, coreIncludeDirs []string, cachePath string) ([]Resolution, error) {
+	newest := newestModTime(cppFiles)
+	cache := LoadCache(cachePath)
+
+	if cache.ScannedAt >= newest && len(cache.Headers) > 0 {
+		results := make([]Resolution, 0, len(cache.Headers))
+		for _, r := range cache.Headers {
+			results = append(results, r)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Header < results[j].Header })
+		return results, nil
+	}
+
+	headers, err := ExtractAll(cppFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Resolution, 0, len(headers))
+	newHeaders := make(map[string]Resolution, len(headers))
+	for _, h := range headers {
+		r := Resolve(h, tsukiPackages, coreIncludeDirs)
+		results = append(results, r)
+		newHeaders[h] = r
+	}
+
+	cache.ScannedAt = newest
+	cache.Headers = newHeaders
+	if err := cache.Save(cachePath); err != nil {
+		return results, fmt.Errorf("saving include cache: %w", err)
+	}
+	return results, nil
+}
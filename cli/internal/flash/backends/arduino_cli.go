@@ -0,0 +1,111 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  arduino-cli uploader
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(arduinoCLIBackend{})
+}
+
+// arduinoCLIBackend shells out to `arduino-cli upload`, resolving board to
+// an FQBN via boardTable — this is the original, still-default backend.
+// A board value already shaped like an FQBN (anything containing ':', e.g.
+// "arduino:avr:uno" from the init wizard's arduino-cli discovery) is passed
+// straight through instead, the same convention internal/boards.Registry
+// uses.
+type arduinoCLIBackend struct{}
+
+func (arduinoCLIBackend) Name() string { return "arduino-cli" }
+
+func (arduinoCLIBackend) Supports(board string) bool {
+	if strings.Contains(board, ":") {
+		return true
+	}
+	_, ok := LookupBoard(board)
+	return ok
+}
+
+func (arduinoCLIBackend) Detect(opts Options) (string, error) {
+	arduinoCLI := opts.ArduinoCLI
+	if arduinoCLI == "" {
+		arduinoCLI = "arduino-cli"
+	}
+	out, err := exec.Command(arduinoCLI, "board", "list").Output()
+	if err != nil {
+		return "", fmt.Errorf("arduino-cli board list failed: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 1 {
+			port := fields[0]
+			if strings.HasPrefix(port, "/dev/") || strings.HasPrefix(port, "COM") {
+				return port, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no board found on any serial port")
+}
+
+func (b arduinoCLIBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	fqbn := board
+	if !strings.Contains(board, ":") {
+		bi, ok := LookupBoard(board)
+		if !ok {
+			return fmt.Errorf("unknown board %q — run `tsuki boards list` for the full list", board)
+		}
+		fqbn = bi.FQBN
+	}
+
+	port := opts.Port
+	if port == "" {
+		ui.Info("Auto-detecting board on serial ports...")
+		detected, err := b.Detect(opts)
+		if err != nil {
+			return fmt.Errorf(
+				"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+			)
+		}
+		port = detected
+		ui.Success(fmt.Sprintf("Found board on %s", port))
+	}
+
+	arduinoCLI := opts.ArduinoCLI
+	if arduinoCLI == "" {
+		arduinoCLI = "arduino-cli"
+	}
+
+	args := []string{
+		"upload",
+		"--fqbn", fqbn,
+		"--port", port,
+		"--input-dir", buildDir,
+	}
+	if opts.Verbose {
+		args = append(args, "--verbose")
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [%s]", port, fqbn))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, arduinoCLI, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), port, append([]string{arduinoCLI}, args...), arduinoCLI, opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
@@ -0,0 +1,77 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  avrdude uploader (AVR .hex)
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(avrdudeBackend{})
+}
+
+// avrdudeBackend flashes a raw .hex to an AVR board via avrdude directly,
+// using the board's fixed programmer/part id — no arduino-cli or
+// tsuki-flash binary required.
+type avrdudeBackend struct{}
+
+func (avrdudeBackend) Name() string { return "avrdude" }
+
+func (avrdudeBackend) Supports(board string) bool {
+	_, _, ok := AvrdudePart(board)
+	return ok
+}
+
+func (avrdudeBackend) Detect(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	return detectSerialPort()
+}
+
+func (b avrdudeBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	programmer, part, ok := AvrdudePart(board)
+	if !ok {
+		return fmt.Errorf("don't know avrdude's programmer/part for board %q", board)
+	}
+
+	image, err := firstMatch(buildDir, "*.hex")
+	if err != nil {
+		return err
+	}
+
+	port, err := b.Detect(opts)
+	if err != nil {
+		return fmt.Errorf(
+			"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+		)
+	}
+
+	args := []string{
+		"-c", programmer,
+		"-p", part,
+		"-P", port,
+		"-U", "flash:w:" + image + ":i",
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [avrdude]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, "avrdude", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), port, append([]string{"avrdude"}, args...), "avrdude", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
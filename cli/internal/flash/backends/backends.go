@@ -0,0 +1,102 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  pluggable upload backend registry
+//
+//  A Backend is a self-contained uploader: arduino-cli, tsuki-flash,
+//  bossac, avrdude, esptool, picotool-uf2, dfu-util each live in their own
+//  file here and register themselves via init(), so adding a new uploader
+//  never means editing a core switch statement — just adding a file.
+//  flash.Run is a thin dispatcher over Get/All/Names below.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"sort"
+)
+
+// Options carries everything a Backend needs to detect a port and upload
+// firmware. It mirrors flash.Options' backend-relevant fields — flash
+// converts its own Options into this one at the call boundary, since this
+// package can't import flash (flash imports this package for the
+// registry, and Go doesn't allow import cycles).
+type Options struct {
+	Port        string
+	ArduinoCLI  string
+	FlashBinary string
+	Verbose     bool
+	JSON        bool // print upload failures as NDJSON diagnostics instead of a rich traceback
+}
+
+// Backend is one pluggable uploader.
+type Backend interface {
+	// Name is the backend's --backend flag value, e.g. "arduino-cli".
+	Name() string
+
+	// Detect auto-finds the board's port. Returns an error if none is found.
+	Detect(opts Options) (string, error)
+
+	// Upload flashes buildDir's compiled firmware to board.
+	Upload(ctx context.Context, board, buildDir string, opts Options) error
+
+	// Supports reports whether this backend knows how to flash board —
+	// Run uses this to auto-pick a backend when --backend isn't given.
+	Supports(board string) bool
+}
+
+var registry = map[string]Backend{}
+
+// Register adds b to the registry, keyed by its Name(). Called from each
+// backend file's init().
+func Register(b Backend) {
+	registry[b.Name()] = b
+}
+
+// Get looks up a backend by its exact --backend name.
+func Get(name string) (Backend, bool) {
+	b, ok := registry[name]
+	return b, ok
+}
+
+// Names lists every registered backend's name, sorted — used to validate
+// --backend and to print its accepted values.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns every registered backend, in a fixed priority order rather
+// than alphabetically — Run walks this list to auto-pick the first
+// backend whose Supports(board) is true.
+func All() []Backend {
+	order := []string{
+		"arduino-cli",
+		"tsuki-flash",
+		"tsuki-flash+cores",
+		"avrdude",
+		"bossac",
+		"picotool-uf2",
+		"esptool",
+		"dfu-util",
+	}
+	out := make([]Backend, 0, len(registry))
+	seen := make(map[string]bool, len(registry))
+	for _, name := range order {
+		if b, ok := registry[name]; ok {
+			out = append(out, b)
+			seen[name] = true
+		}
+	}
+	// Anything registered outside the fixed list (a future out-of-tree
+	// plugin) still gets picked up, just after the built-ins.
+	for _, name := range Names() {
+		if !seen[name] {
+			out = append(out, registry[name])
+		}
+	}
+	return out
+}
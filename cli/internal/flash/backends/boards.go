@@ -0,0 +1,81 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  board → FQBN/quirks table
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import "strings"
+
+// BoardInfo is one board table entry: its FQBN, and whether uploading to
+// it needs the SAMD/RP2040-style "1200bps touch" reset into a bootloader
+// before the actual upload can proceed.
+type BoardInfo struct {
+	FQBN string
+
+	// UsesBootloaderTouch means the caller should touch the board's serial
+	// port and wait for its bootloader port to (re-)appear before
+	// invoking the uploader — set for boards whose application firmware
+	// doesn't expose a programming interface on its own (SAMD, RP2040).
+	UsesBootloaderTouch bool
+
+	// Wait1200bps means the touch itself must open the port at 1200 baud
+	// (the convention these bootloaders watch for) rather than a plain
+	// open/close at whatever baud the port is already configured at.
+	Wait1200bps bool
+}
+
+// boardTable maps short board IDs to their FQBN and upload quirks.
+var boardTable = map[string]BoardInfo{
+	"uno":       {FQBN: "arduino:avr:uno"},
+	"nano":      {FQBN: "arduino:avr:nano"},
+	"mega":      {FQBN: "arduino:avr:mega"},
+	"leonardo":  {FQBN: "arduino:avr:leonardo"},
+	"micro":     {FQBN: "arduino:avr:micro"},
+	"due":       {FQBN: "arduino:sam:arduino_due_x"},
+	"esp32":     {FQBN: "esp32:esp32:esp32"},
+	"esp8266":   {FQBN: "esp8266:esp8266:generic"},
+	"pico":      {FQBN: "rp2040:rp2040:rpipico", UsesBootloaderTouch: true, Wait1200bps: true},
+	"zero":      {FQBN: "arduino:samd:arduino_zero_edbg", UsesBootloaderTouch: true, Wait1200bps: true},
+	"mkr1000":   {FQBN: "arduino:samd:mkr1000", UsesBootloaderTouch: true, Wait1200bps: true},
+	"mkrzero":   {FQBN: "arduino:samd:mkrzero", UsesBootloaderTouch: true, Wait1200bps: true},
+	"nano33iot": {FQBN: "arduino:samd:nano_33_iot", UsesBootloaderTouch: true, Wait1200bps: true},
+}
+
+// LookupBoard resolves id against boardTable, returning ok=false (zero
+// BoardInfo) when id isn't a known alias — callers that accept raw FQBNs
+// treat that as "pass it through unchanged".
+func LookupBoard(id string) (BoardInfo, bool) {
+	bi, ok := boardTable[strings.ToLower(id)]
+	return bi, ok
+}
+
+// bossacOffset is the flash offset bossac needs for each SAMD/RP2040
+// board's bootloader.
+var bossacOffset = map[string]int64{
+	"zero":      0x2000,
+	"mkrzero":   0x2000,
+	"mkr1000":   0x2000,
+	"nano33iot": 0x2000,
+	"pico":      0x2000,
+}
+
+// BossacOffset returns board's bossac --offset, when one is known.
+func BossacOffset(board string) (int64, bool) {
+	offset, ok := bossacOffset[strings.ToLower(board)]
+	return offset, ok
+}
+
+// avrdudePart is the avrdude -c/-p pair for each AVR board's .hex upload.
+var avrdudePart = map[string]struct{ Programmer, Part string }{
+	"uno":      {"arduino", "atmega328p"},
+	"nano":     {"arduino", "atmega328p"},
+	"mega":     {"wiring", "atmega2560"},
+	"leonardo": {"avr109", "atmega32u4"},
+	"micro":    {"avr109", "atmega32u4"},
+}
+
+// AvrdudePart returns board's avrdude programmer and part id, when known.
+func AvrdudePart(board string) (programmer, part string, ok bool) {
+	p, ok := avrdudePart[strings.ToLower(board)]
+	return p.Programmer, p.Part, ok
+}
@@ -0,0 +1,78 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  bossac uploader (SAMD / RP2040 .bin)
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(bossacBackend{})
+}
+
+// bossacBackend flashes a raw .bin to a SAMD/RP2040 board via bossac
+// directly, using the board's fixed bootloader flash offset — no
+// arduino-cli or tsuki-flash binary required.
+type bossacBackend struct{}
+
+func (bossacBackend) Name() string { return "bossac" }
+
+func (bossacBackend) Supports(board string) bool {
+	_, ok := BossacOffset(board)
+	return ok
+}
+
+func (bossacBackend) Detect(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	return detectSerialPort()
+}
+
+func (b bossacBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	offset, ok := BossacOffset(board)
+	if !ok {
+		return fmt.Errorf("don't know bossac's flash offset for board %q", board)
+	}
+
+	image, err := firstMatch(buildDir, "*.bin")
+	if err != nil {
+		return err
+	}
+
+	port, err := b.Detect(opts)
+	if err != nil {
+		return fmt.Errorf(
+			"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+		)
+	}
+
+	args := []string{
+		"--port", port,
+		"-U", "-i", "-e", "-w", "-v",
+		"--offset", fmt.Sprintf("0x%x", offset),
+		image,
+		"--reset",
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [bossac]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, "bossac", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), port, append([]string{"bossac"}, args...), "bossac", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
@@ -0,0 +1,62 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  dfu-util uploader
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(dfuUtilBackend{})
+}
+
+// dfuUtilBackend flashes a .bin via dfu-util, for boards whose bootloader
+// speaks USB DFU rather than a serial protocol. No board in boardTable
+// uses DFU yet, so Supports always reports false — it's only reachable via
+// an explicit --backend dfu-util override, ahead of a board that needs it
+// being added to the table.
+type dfuUtilBackend struct{}
+
+func (dfuUtilBackend) Name() string { return "dfu-util" }
+
+func (dfuUtilBackend) Supports(board string) bool { return false }
+
+func (dfuUtilBackend) Detect(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	return "", fmt.Errorf("dfu-util addresses boards by USB VID:PID, not a serial port — pass --port <vid>:<pid> if your board needs an explicit alt-setting")
+}
+
+func (b dfuUtilBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	image, err := firstMatch(buildDir, "*.bin")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-D", image}
+	if opts.Port != "" {
+		args = append(args, "-d", opts.Port)
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading  [board: %s]  [dfu-util]", board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, "dfu-util", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), opts.Port, append([]string{"dfu-util"}, args...), "dfu-util", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, "firmware uploaded")
+	return nil
+}
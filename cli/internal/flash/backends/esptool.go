@@ -0,0 +1,71 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  esptool uploader (ESP32 / ESP8266 .bin)
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(esptoolBackend{})
+}
+
+// esptoolBackend flashes a raw .bin to an ESP32/ESP8266 board via
+// esptool.py directly, at the offset those chips' bootloaders expect the
+// application image at.
+type esptoolBackend struct{}
+
+func (esptoolBackend) Name() string { return "esptool" }
+
+func (esptoolBackend) Supports(board string) bool {
+	switch strings.ToLower(board) {
+	case "esp32", "esp8266":
+		return true
+	}
+	return false
+}
+
+func (esptoolBackend) Detect(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	return detectSerialPort()
+}
+
+func (b esptoolBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	image, err := firstMatch(buildDir, "*.bin")
+	if err != nil {
+		return err
+	}
+
+	port, err := b.Detect(opts)
+	if err != nil {
+		return fmt.Errorf(
+			"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+		)
+	}
+
+	args := []string{"--port", port, "write_flash", "0x0", image}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [esptool]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, "esptool.py", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), port, append([]string{"esptool.py"}, args...), "esptool.py", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
@@ -0,0 +1,71 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  shared plumbing for the Backend impls
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/tsuki/cli/internal/flash/diag"
+)
+
+// renderUploadError classifies a failed upload's output via diag.Parse and
+// either prints a rich ui.Traceback (the default) or NDJSON diagnostics
+// (opts.JSON, for editor integrations) on stdout — the same behavior
+// flash.renderFlashError had before the backends split.
+func renderUploadError(output, port string, cmdLine []string, toolPath string, jsonOut bool) {
+	diags := diag.Parse(output, port)
+	if jsonOut {
+		_ = diag.NDJSON(os.Stdout, diags)
+		return
+	}
+	diag.Render(diags, cmdLine, toolPath)
+}
+
+// serialPortGlobs are the OS-specific device-file patterns a plain serial
+// port (as opposed to a tool with its own `detect`/`board list` command)
+// shows up under.
+func serialPortGlobs() []string {
+	return []string{"/dev/ttyUSB*", "/dev/ttyACM*", "/dev/cu.usbserial*", "/dev/cu.usbmodem*"}
+}
+
+// detectSerialPort scans serialPortGlobs for the first match — used by
+// backends (bossac, avrdude, esptool, picotool-uf2) that talk to a raw
+// serial port directly rather than through a tool with its own detect
+// command. Windows doesn't expose COM ports through the filesystem, so
+// auto-detect there needs an explicit --port instead.
+func detectSerialPort() (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("auto-detect isn't supported on Windows for this backend — pass --port COMx")
+	}
+	for _, g := range serialPortGlobs() {
+		if matches, _ := filepath.Glob(g); len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+	return "", fmt.Errorf("no board found on any serial port")
+}
+
+// firstMatch finds the first file in dir matching any of patterns, in
+// order — used to find a backend's expected firmware image without the
+// caller having to know its exact name.
+func firstMatch(dir string, patterns ...string) (string, error) {
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+	return "", fmt.Errorf("no matching firmware image (%s) found in %s", joinPatterns(patterns), dir)
+}
+
+func joinPatterns(patterns []string) string {
+	out := patterns[0]
+	for _, p := range patterns[1:] {
+		out += ", " + p
+	}
+	return out
+}
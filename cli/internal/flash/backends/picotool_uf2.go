@@ -0,0 +1,61 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  picotool-uf2 uploader (RP2040)
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(picotoolUF2Backend{})
+}
+
+// picotoolUF2Backend flashes a .uf2 image via the actual picotool binary
+// (picotool load -x) rather than mounting the RP2040's UF2 drive and
+// copying the file — useful when picotool is already installed and the
+// drive-mount dance (inputfile.go's uploadUF2) isn't wanted.
+type picotoolUF2Backend struct{}
+
+func (picotoolUF2Backend) Name() string { return "picotool-uf2" }
+
+func (picotoolUF2Backend) Supports(board string) bool {
+	return strings.ToLower(board) == "pico"
+}
+
+func (picotoolUF2Backend) Detect(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	return detectSerialPort()
+}
+
+func (b picotoolUF2Backend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	image, err := firstMatch(buildDir, "*.uf2")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"load", "-x", image}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading  [board: %s]  [picotool-uf2]", board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, "picotool", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), "", append([]string{"picotool"}, args...), "picotool", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, "firmware uploaded")
+	return nil
+}
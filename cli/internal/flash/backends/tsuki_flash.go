@@ -0,0 +1,108 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  tsuki-flash uploader
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+func init() {
+	Register(tsukiFlashBackend{})
+}
+
+// tsukiFlashBackend shells out to tsuki-flash, tsuki's own lightweight
+// uploader that doesn't require arduino-cli's full core/package install.
+// It's used for any board — boardTable is consulted only for its
+// UsesBootloaderTouch/Wait1200bps quirks, not for FQBN resolution.
+type tsukiFlashBackend struct{}
+
+func (tsukiFlashBackend) Name() string { return "tsuki-flash" }
+
+func (tsukiFlashBackend) Supports(board string) bool { return true }
+
+func (tsukiFlashBackend) Detect(opts Options) (string, error) {
+	return detectPortTsukiFlash(flashBinaryOf(opts))
+}
+
+func (b tsukiFlashBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	return uploadViaTsukiFlash(ctx, board, buildDir, opts, b.Detect, nil)
+}
+
+// flashBinaryOf returns opts.FlashBinary, defaulting to "tsuki-flash".
+func flashBinaryOf(opts Options) string {
+	if opts.FlashBinary != "" {
+		return opts.FlashBinary
+	}
+	return "tsuki-flash"
+}
+
+// detectPortTsukiFlash uses `tsuki-flash detect` to find the board port.
+func detectPortTsukiFlash(flashBin string) (string, error) {
+	out, err := exec.Command(flashBin, "detect").Output()
+	if err != nil {
+		return "", fmt.Errorf("tsuki-flash detect failed: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 1 {
+			port := fields[0]
+			if strings.HasPrefix(port, "/dev/") || strings.HasPrefix(port, "COM") {
+				return port, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no board found on any serial port")
+}
+
+// uploadViaTsukiFlash is shared between tsuki-flash and tsuki-flash+cores —
+// the two differ only in an extra flag the latter passes, given via
+// extraArgs.
+func uploadViaTsukiFlash(ctx context.Context, board, buildDir string, opts Options, detect func(Options) (string, error), extraArgs []string) error {
+	flashBin := flashBinaryOf(opts)
+
+	port := opts.Port
+	if port == "" {
+		ui.Info("Auto-detecting board on serial ports...")
+		detected, err := detect(opts)
+		if err != nil {
+			return fmt.Errorf(
+				"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+			)
+		}
+		port = detected
+		ui.Success(fmt.Sprintf("Found board on %s", port))
+	}
+
+	args := []string{
+		"upload",
+		"--board", board,
+		"--port", port,
+		"--build-dir", buildDir,
+	}
+	args = append(args, extraArgs...)
+	if opts.Verbose {
+		args = append(args, "--verbose")
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [tsuki-flash]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.CommandContext(ctx, flashBin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderUploadError(string(out), port, append([]string{flashBin}, args...), flashBin, opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
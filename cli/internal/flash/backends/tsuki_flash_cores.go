@@ -0,0 +1,30 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: backends  —  tsuki-flash+cores uploader
+// ─────────────────────────────────────────────────────────────────────────────
+
+package backends
+
+import "context"
+
+func init() {
+	Register(tsukiFlashCoresBackend{})
+}
+
+// tsukiFlashCoresBackend is tsuki-flash with --install-cores added, for a
+// first upload on a fresh machine that doesn't have the board's core
+// installed yet. Kept as its own registered backend (rather than a flag on
+// tsuki-flash) so --backend tsuki-flash+cores is a complete, self-describing
+// choice in `tsuki upload --backend` and `tsuki boards` output.
+type tsukiFlashCoresBackend struct{}
+
+func (tsukiFlashCoresBackend) Name() string { return "tsuki-flash+cores" }
+
+func (tsukiFlashCoresBackend) Supports(board string) bool { return true }
+
+func (tsukiFlashCoresBackend) Detect(opts Options) (string, error) {
+	return detectPortTsukiFlash(flashBinaryOf(opts))
+}
+
+func (b tsukiFlashCoresBackend) Upload(ctx context.Context, board, buildDir string, opts Options) error {
+	return uploadViaTsukiFlash(ctx, board, buildDir, opts, b.Detect, []string{"--install-cores"})
+}
@@ -0,0 +1,172 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: diag  —  structured diagnostics for flashing-tool output
+//
+//  Parse classifies a failed avrdude/bossac/esptool/dfu-util run's raw
+//  output line by line against a small table of known failure signatures,
+//  each carrying a stable Code and a remediation Hint. When no line matches
+//  any signature, the output falls back to the same "looks error-ish"
+//  heuristic renderFlashError used before this package existed, so an
+//  unrecognized tool (or a new error message) still surfaces something
+//  instead of nothing.
+//
+//  internal/check reuses Parse for the same reason: a transpiler error
+//  message benefits from the same classify-then-hint treatment a flashing
+//  tool's output gets here.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// Severity classifies a Diagnostic's severity.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is one structured, classified message parsed out of a tool's
+// raw output.
+type Diagnostic struct {
+	Code     string   `json:"code"` // stable id, e.g. "avrdude.not-responding"
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Port     string   `json:"port,omitempty"`
+	Hint     string   `json:"hint,omitempty"`
+	DocsURL  string   `json:"docs_url,omitempty"`
+}
+
+type rule struct {
+	code     string
+	pattern  *regexp.Regexp
+	severity Severity
+	hint     string
+	docsURL  string
+}
+
+// rules is checked in order; every match on a line produces a Diagnostic,
+// so two overlapping signatures on the same line both surface.
+var rules = []rule{
+	{
+		code:     "avrdude.not-responding",
+		pattern:  regexp.MustCompile(`(?i)stk500_recv\(\):\s*programmer is not responding`),
+		severity: SeverityError,
+		hint:     "the board didn't answer on the port — check nothing else (Serial Monitor, another upload) has it open, and that you're on the right port",
+		docsURL:  "https://docs.arduino.cc/learn/programming/avrdude/",
+	},
+	{
+		code:     "bossac.no-device",
+		pattern:  regexp.MustCompile(`(?i)no device found on`),
+		severity: SeverityError,
+		hint:     "press reset twice quickly (or hold BOOTSEL while plugging in on RP2040) to drop into the bootloader, then retry",
+	},
+	{
+		code:     "esptool.connect-failed",
+		pattern:  regexp.MustCompile(`(?i)a fatal error occurred:\s*failed to connect`),
+		severity: SeverityError,
+		hint:     "hold BOOT while the upload starts, or pass a lower --upload-speed if the board is on a long or flaky USB cable",
+	},
+	{
+		code:     "dfu-util.no-device",
+		pattern:  regexp.MustCompile(`(?i)no dfu capable usb device (available|found)`),
+		severity: SeverityError,
+		hint:     "put the board in DFU mode (its BOOT/DFU button or double-reset sequence) before uploading",
+	},
+	{
+		code:     "serial.permission-denied",
+		pattern:  regexp.MustCompile(`(?i)permission denied.*(tty|com\d)`),
+		severity: SeverityError,
+		hint:     "add your user to the dialout group (sudo usermod -aG dialout $USER, then log back in) — on Linux this also covers the 1200bps-touch permissions on /dev/ttyACM*",
+	},
+}
+
+// Parse classifies output line by line. When no line matches any rule, the
+// lines the pre-diag renderFlashError would have picked (anything
+// containing "error"/"Error"/"not found") are folded into a single
+// fallback Diagnostic; when some line does match, the unmatched lines are
+// not reported.
+func Parse(output, port string) []Diagnostic {
+	var diags []Diagnostic
+	var unmatched []string
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		matched := false
+		for _, r := range rules {
+			if r.pattern.MatchString(line) {
+				diags = append(diags, Diagnostic{
+					Code:     r.code,
+					Severity: r.severity,
+					Message:  line,
+					Port:     port,
+					Hint:     r.hint,
+					DocsURL:  r.docsURL,
+				})
+				matched = true
+			}
+		}
+		if !matched && (strings.Contains(line, "error") || strings.Contains(line, "Error") || strings.Contains(line, "not found")) {
+			unmatched = append(unmatched, line)
+		}
+	}
+
+	if len(diags) == 0 {
+		msg := strings.Join(unmatched, "; ")
+		if msg == "" {
+			msg = strings.TrimSpace(output)
+		}
+		diags = append(diags, Diagnostic{Code: "unclassified", Severity: SeverityError, Message: msg, Port: port})
+	}
+	return diags
+}
+
+// Render prints diags as a rich ui.Traceback, one frame per diagnostic.
+// cmdLine (the failing command's argv) and toolPath (the resolved binary
+// that ran it) are shown as source context alongside the message and
+// hint; both may be left empty when there's no literal command behind
+// the diagnostic (internal/check's transpiler errors, for instance).
+func Render(diags []Diagnostic, cmdLine []string, toolPath string) {
+	frames := make([]ui.Frame, 0, len(diags))
+	for _, d := range diags {
+		var lines []ui.CodeLine
+		n := 0
+		if len(cmdLine) > 0 {
+			lines = append(lines, ui.CodeLine{Number: n, Text: strings.Join(cmdLine, " ")})
+			n++
+		}
+		lines = append(lines, ui.CodeLine{Number: n, Text: d.Message, IsPointer: true})
+		n++
+		if d.Hint != "" {
+			lines = append(lines, ui.CodeLine{Number: n, Text: "hint: " + d.Hint})
+		}
+		frames = append(frames, ui.Frame{File: toolPath, Func: d.Code, Code: lines})
+	}
+	if len(frames) == 0 {
+		return
+	}
+	ui.Traceback("FlashError", fmt.Sprintf("%d diagnostic(s)", len(diags)), frames)
+}
+
+// NDJSON writes diags to w as newline-delimited JSON, one object per
+// line — tsuki upload --json's output shape, meant for editor integrations.
+func NDJSON(w io.Writer, diags []Diagnostic) error {
+	enc := json.NewEncoder(w)
+	for _, d := range diags {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,11 +5,20 @@
 package flash
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tsuki/cli/internal/flash/backends"
+	"github.com/tsuki/cli/internal/flash/diag"
+	"github.com/tsuki/cli/internal/fwindex"
 	"github.com/tsuki/cli/internal/manifest"
 	"github.com/tsuki/cli/internal/ui"
 )
@@ -21,21 +30,76 @@ type Options struct {
 	BuildDir    string // directory with compiled firmware (.hex)
 	ArduinoCLI  string
 	FlashBinary string // path to tsuki-flash binary
-	Backend     string // "tsuki-flash" or "arduino-cli"
+	Backend     string // one of backends.Names(); empty = auto-pick
 	Verbose     bool
+
+	// FirmwareIndexURL pins the firmware/plugin index JSON (cfg's same-named
+	// field) — Run consults it before falling back to Backend, so common
+	// AVR/SAMD/RP2040 boards can be flashed without arduino-cli or
+	// tsuki-flash installed at all. Empty disables the feature.
+	FirmwareIndexURL    string
+	FirmwareIndexKeyURL string
+	Offline             bool // use the cached firmware index only, never fetch
+
+	// Retries is how many times to attempt the upload before giving up.
+	// <= 0 falls back to the default of 2, so a zero-value Options still
+	// retries once.
+	Retries int
+
+	// Wait, when Port is empty, blocks on Watch's first Added event whose
+	// BoardGuess matches Board instead of immediately failing with "no
+	// board detected" — useful for a board that needs to be plugged in
+	// mid-command (e.g. right after a "press reset now" prompt).
+	Wait bool
+
+	// InputFile, when set, flashes this prebuilt .hex/.bin/.uf2 (or signed
+	// WINC/NINA module blob) directly instead of resolving BuildDir from
+	// the manifest — see runInputFile in inputfile.go. Lets a standalone
+	// binary be flashed without a tsuki project at all.
+	InputFile string
+
+	// JSON makes a failed upload print its diag.Diagnostic values as
+	// NDJSON on stdout instead of a rich ui.Traceback — for editor
+	// integrations that want to parse the failure themselves.
+	JSON bool
+}
+
+// RegisteredBackends lists every backend's --backend name, sorted — used
+// by newUploadCmd to validate the flag and print its accepted values.
+func RegisteredBackends() []string {
+	return backends.Names()
 }
 
-// boardFQBN maps short board IDs to FQBNs.
-var boardFQBN = map[string]string{
-	"uno":      "arduino:avr:uno",
-	"nano":     "arduino:avr:nano",
-	"mega":     "arduino:avr:mega",
-	"leonardo": "arduino:avr:leonardo",
-	"micro":    "arduino:avr:micro",
-	"due":      "arduino:sam:arduino_due_x",
-	"esp32":    "esp32:esp32:esp32",
-	"esp8266":  "esp8266:esp8266:generic",
-	"pico":     "rp2040:rp2040:rpipico",
+// toBackendOptions converts Options to the narrower backends.Options a
+// Backend actually needs — the two packages can't share one struct since
+// backends can't import flash (flash imports backends for the registry).
+func toBackendOptions(opts Options) backends.Options {
+	return backends.Options{
+		Port:        opts.Port,
+		ArduinoCLI:  opts.ArduinoCLI,
+		FlashBinary: opts.FlashBinary,
+		Verbose:     opts.Verbose,
+		JSON:        opts.JSON,
+	}
+}
+
+// resolveBackend returns the Backend Run should use: opts.Backend exactly,
+// if given, or else the first registered backend (in backends.All's fixed
+// priority order) that Supports board.
+func resolveBackend(opts Options, board string) (backends.Backend, error) {
+	if opts.Backend != "" {
+		b, ok := backends.Get(opts.Backend)
+		if !ok {
+			return nil, fmt.Errorf("unknown backend %q — choose one of: %s", opts.Backend, strings.Join(backends.Names(), ", "))
+		}
+		return b, nil
+	}
+	for _, b := range backends.All() {
+		if b.Supports(board) {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("no registered backend supports board %q", board)
 }
 
 // Run uploads the firmware to the board.
@@ -45,88 +109,240 @@ func Run(projectDir string, m *manifest.Manifest, opts Options) error {
 		board = m.Board
 	}
 
+	if opts.InputFile != "" {
+		return runInputFile(board, opts)
+	}
+
 	// Firmware lives in build/.cache. Respect explicit --build-dir if given.
 	buildDir := opts.BuildDir
 	if buildDir == "" {
 		buildDir = filepath.Join(projectDir, m.Build.OutputDir, ".cache")
 	}
 
-	backend := opts.Backend
-	if backend == "" {
-		backend = "arduino-cli"
+	if opts.Port == "" && opts.Wait {
+		ui.Info(fmt.Sprintf("Waiting for %s to be connected...", board))
+		port, err := waitForBoard(board, opts)
+		if err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Found board on %s", port))
+		opts.Port = port
 	}
 
-	switch backend {
-	case "tsuki-flash":
-		return uploadTsukiFlash(board, buildDir, opts)
-	default:
-		return uploadArduinoCLI(board, buildDir, opts)
+	// The firmware index, when configured, resolves board -> uploader tool
+	// directly — no arduino-cli or tsuki-flash required. Fall through to
+	// Backend as before when the board isn't listed in it.
+	if opts.FirmwareIndexURL != "" {
+		err := uploadDirect(board, buildDir, opts)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errBoardNotIndexed) {
+			return err
+		}
+	}
+
+	be, err := resolveBackend(opts, board)
+	if err != nil {
+		return err
+	}
+
+	retries := opts.Retries
+	if retries <= 0 {
+		retries = 2
 	}
+
+	bi, _ := backends.LookupBoard(board)
+	ctx := context.Background()
+
+	var lastErr error
+	for attempt := 1; attempt <= retries; attempt++ {
+		attemptOpts := opts
+		if bi.UsesBootloaderTouch {
+			backendOpts := toBackendOptions(attemptOpts)
+			if port, err := be.Detect(backendOpts); err != nil && attemptOpts.Port == "" {
+				if attempt == 1 {
+					ui.Warn(fmt.Sprintf("couldn't resolve a port to touch before uploading: %v", err))
+				}
+			} else {
+				if port == "" {
+					port = attemptOpts.Port
+				}
+				if bootPort, err := touchAndAwaitBootloader(port, bi.Wait1200bps); err != nil {
+					ui.Warn(fmt.Sprintf("1200bps touch failed, uploading without it: %v", err))
+				} else {
+					attemptOpts.Port = bootPort
+				}
+			}
+		}
+
+		lastErr = be.Upload(ctx, board, buildDir, toBackendOptions(attemptOpts))
+		if lastErr == nil {
+			return nil
+		}
+		if attempt < retries {
+			ui.Warn(fmt.Sprintf("upload attempt %d/%d failed, retrying: %v", attempt, retries, lastErr))
+		}
+	}
+	return lastErr
 }
 
-// ─────────────────────────────────────────────────────────────────────────────
-//  Backend: tsuki-flash upload
-// ─────────────────────────────────────────────────────────────────────────────
+// waitForBoard blocks until Watch reports board being connected (matched
+// by alias against PortEvent.BoardGuess), returning the port it appeared
+// on. It never times out on its own — the caller (a CLI command) is
+// expected to let the user Ctrl-C out of it.
+func waitForBoard(board string, opts Options) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-func uploadTsukiFlash(board, buildDir string, opts Options) error {
-	flashBin := opts.FlashBinary
-	if flashBin == "" {
-		flashBin = "tsuki-flash"
+	events, err := Watch(ctx, opts)
+	if err != nil {
+		return "", err
 	}
 
-	port := opts.Port
-	if port == "" {
-		ui.Info("Auto-detecting board on serial ports...")
-		detected, err := detectPortTsukiFlash(flashBin)
-		if err != nil {
-			return fmt.Errorf(
-				"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
-			)
+	alias := strings.ToLower(board)
+	for evt := range events {
+		if evt.Type == PortAdded && evt.BoardGuess == alias {
+			return evt.Port, nil
 		}
-		port = detected
-		ui.Success(fmt.Sprintf("Found board on %s", port))
+	}
+	return "", fmt.Errorf("stopped waiting for %s", board)
+}
+
+// touchAndAwaitBootloader opens port at 1200 baud (when wait1200bps is set)
+// or otherwise just toggles it, which resets SAMD/RP2040-style boards into
+// their bootloader, then polls listSerialPorts for up to ~10s for the
+// bootloader's port to appear. Windows especially tends to re-enumerate the
+// board under a different port than the one touched, so a genuinely new
+// port always wins over reusing the original.
+func touchAndAwaitBootloader(port string, wait1200bps bool) (string, error) {
+	before := listSerialPorts()
+	seen := make(map[string]bool, len(before))
+	for _, p := range before {
+		seen[p] = true
 	}
 
-	args := []string{
-		"upload",
-		"--board", board,
-		"--port", port,
-		"--build-dir", buildDir,
+	baud := 1200
+	if !wait1200bps {
+		baud = 9600
 	}
-	if opts.Verbose {
-		args = append(args, "--verbose")
+	if err := touchSerialPort(port, baud); err != nil {
+		return "", fmt.Errorf("touching %s at %d baud: %w", port, baud, err)
 	}
 
-	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [tsuki-flash]", port, board))
-	sp := ui.NewSpinner("Flashing firmware...")
-	sp.Start()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(300 * time.Millisecond)
+		for _, p := range listSerialPorts() {
+			if !seen[p] {
+				return p, nil
+			}
+		}
+	}
 
-	cmd := exec.Command(flashBin, args...)
-	out, err := cmd.CombinedOutput()
+	// No new port appeared — some boards re-enumerate under their
+	// original name. If it's still there, use it; otherwise give up.
+	for _, p := range listSerialPorts() {
+		if p == port {
+			return port, nil
+		}
+	}
+	return "", fmt.Errorf("no bootloader port appeared after touching %s", port)
+}
+
+// listSerialPorts lists every serial device currently present, used to
+// diff before/after a 1200bps touch and spot the bootloader port it causes
+// to (re-)appear.
+func listSerialPorts() []string {
+	if runtime.GOOS == "windows" {
+		return listSerialPortsWindows()
+	}
+	globs := []string{"/dev/ttyUSB*", "/dev/ttyACM*", "/dev/cu.usbserial*", "/dev/cu.usbmodem*"}
+	var ports []string
+	for _, g := range globs {
+		matches, _ := filepath.Glob(g)
+		ports = append(ports, matches...)
+	}
+	return ports
+}
+
+// listSerialPortsWindows shells out to the `mode` command (no filesystem
+// enumeration of COM ports on Windows), parsing its "Status for device
+// COMn:" header lines.
+func listSerialPortsWindows() []string {
+	out, err := exec.Command("mode").Output()
 	if err != nil {
-		sp.Stop(false, "upload failed")
-		renderFlashError(string(out), port)
-		return fmt.Errorf("upload failed")
+		return nil
 	}
+	var ports []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Status for device COM") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "COM") {
+				ports = append(ports, strings.TrimSuffix(field, ":"))
+			}
+		}
+	}
+	return ports
+}
 
-	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
-	return nil
+// touchSerialPort opens port at baud and immediately releases it — the
+// "1200bps touch" SAMD/RP2040 bootloaders watch for as a request to reset
+// into programming mode.
+func touchSerialPort(port string, baud int) error {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("mode", port+":", fmt.Sprintf("baud=%d", baud), "parity=n", "data=8", "stop=1").Run()
+	case "darwin":
+		return exec.Command("stty", "-f", port, strconv.Itoa(baud)).Run()
+	default:
+		return exec.Command("stty", "-F", port, strconv.Itoa(baud)).Run()
+	}
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
-//  Backend: arduino-cli upload
+//  Backend: firmware-index direct upload (no arduino-cli / tsuki-flash)
 // ─────────────────────────────────────────────────────────────────────────────
 
-func uploadArduinoCLI(board, buildDir string, opts Options) error {
-	fqbn, ok := boardFQBN[strings.ToLower(board)]
+// errBoardNotIndexed means the firmware index doesn't list board — Run
+// falls back to Backend in that case rather than treating it as fatal.
+var errBoardNotIndexed = errors.New("board not found in firmware index")
+
+// uploadDirect resolves board to an uploader tool via the firmware index,
+// downloads/caches that tool, and invokes it directly with its
+// UploadPattern — no arduino-cli or tsuki-flash binary required.
+func uploadDirect(board, buildDir string, opts Options) error {
+	fqbn := board // allow a raw FQBN to be passed straight through
+	if bi, ok := backends.LookupBoard(board); ok {
+		fqbn = bi.FQBN
+	}
+
+	idx, err := fwindex.Load(fwindex.LoadOptions{
+		IndexURL: opts.FirmwareIndexURL,
+		KeyURL:   opts.FirmwareIndexKeyURL,
+		Offline:  opts.Offline,
+	})
+	if err != nil {
+		return fmt.Errorf("loading firmware index: %w", err)
+	}
+
+	tool, entry, ok := idx.ResolveBoard(fqbn)
 	if !ok {
-		return fmt.Errorf("unknown board %q — run `tsuki boards list` for the full list", board)
+		return errBoardNotIndexed
+	}
+
+	toolDir, err := fwindex.EnsureTool(tool)
+	if err != nil {
+		return fmt.Errorf("fetching %s %s: %w", tool.Name, tool.Version, err)
 	}
 
 	port := opts.Port
 	if port == "" {
 		ui.Info("Auto-detecting board on serial ports...")
-		detected, err := detectPortArduinoCLI(opts.ArduinoCLI)
+		detected, err := detectPortDirect()
 		if err != nil {
 			return fmt.Errorf(
 				"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
@@ -136,30 +352,25 @@ func uploadArduinoCLI(board, buildDir string, opts Options) error {
 		ui.Success(fmt.Sprintf("Found board on %s", port))
 	}
 
-	arduinoCLI := opts.ArduinoCLI
-	if arduinoCLI == "" {
-		arduinoCLI = "arduino-cli"
+	image, err := loaderImage(buildDir)
+	if err != nil {
+		return err
 	}
 
-	args := []string{
-		"upload",
-		"--fqbn", fqbn,
-		"--port", port,
-		"--input-dir", buildDir,
-	}
-	if opts.Verbose {
-		args = append(args, "--verbose")
+	cmdLine := expandUploadPattern(tool.UploadPattern, toolDir, port, image, entry.MCU)
+	if len(cmdLine) == 0 {
+		return fmt.Errorf("%s has no upload_pattern configured in the firmware index", tool.Name)
 	}
 
-	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [%s]", port, fqbn))
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [%s]", port, board, tool.Name))
 	sp := ui.NewSpinner("Flashing firmware...")
 	sp.Start()
 
-	cmd := exec.Command(arduinoCLI, args...)
+	cmd := exec.Command(cmdLine[0], cmdLine[1:]...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		sp.Stop(false, "upload failed")
-		renderFlashError(string(out), port)
+		renderFlashError(string(out), port, cmdLine, cmdLine[0], opts.JSON)
 		return fmt.Errorf("upload failed")
 	}
 
@@ -167,48 +378,65 @@ func uploadArduinoCLI(board, buildDir string, opts Options) error {
 	return nil
 }
 
-func renderFlashError(output, port string) {
-	lines := strings.Split(output, "\n")
-	var relevant []string
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l != "" && (strings.Contains(l, "error") || strings.Contains(l, "Error") || strings.Contains(l, "not found")) {
-			relevant = append(relevant, l)
+// expandUploadPattern substitutes a Tool.UploadPattern's placeholders and
+// splits the result on whitespace into an argv, the same way arduino-cli's
+// own platform.txt recipes are tokenized.
+func expandUploadPattern(pattern, toolDir, port, image, mcu string) []string {
+	pattern = strings.NewReplacer(
+		"{tool_dir}", toolDir,
+		"{serial.port.file}", port,
+		"{loader.sketch}", image,
+		"{build.mcu}", mcu,
+	).Replace(pattern)
+	return strings.Fields(pattern)
+}
+
+// loaderImage finds the compiled firmware image to flash in buildDir.
+func loaderImage(buildDir string) (string, error) {
+	for _, pattern := range []string{"*.hex", "*.bin"} {
+		if matches, _ := filepath.Glob(filepath.Join(buildDir, pattern)); len(matches) > 0 {
+			return matches[0], nil
 		}
 	}
-	msg := strings.Join(relevant, "; ")
-	if msg == "" {
-		msg = strings.TrimSpace(output)
-	}
-	ui.Traceback("FlashError", msg, []ui.Frame{
-		{
-			File: port,
-			Func: "upload",
-			Line: 0,
-			Code: []ui.CodeLine{{Number: 0, Text: msg, IsPointer: true}},
-		},
-	})
+	return "", fmt.Errorf("no firmware image (.hex/.bin) found in %s", buildDir)
 }
 
-// detectPortTsukiFlash uses `tsuki-flash detect` to find the board port.
-func detectPortTsukiFlash(flashBin string) (string, error) {
-	out, err := exec.Command(flashBin, "detect").Output()
-	if err != nil {
-		return "", fmt.Errorf("tsuki-flash detect failed: %w", err)
+// detectPortDirect scans the OS's usual serial device globs without
+// shelling out to arduino-cli or tsuki-flash. Windows doesn't expose COM
+// ports through the filesystem, so auto-detect there needs an explicit
+// --port instead of a glob.
+func detectPortDirect() (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("auto-detect isn't supported on Windows for this backend — pass --port COMx")
 	}
-	for _, line := range strings.Split(string(out), "\n") {
-		fields := strings.Fields(line)
-		if len(fields) >= 1 {
-			port := fields[0]
-			if strings.HasPrefix(port, "/dev/") || strings.HasPrefix(port, "COM") {
-				return port, nil
-			}
+	globs := []string{"/dev/ttyUSB*", "/dev/ttyACM*", "/dev/cu.usbserial*", "/dev/cu.usbmodem*"}
+	for _, g := range globs {
+		if matches, _ := filepath.Glob(g); len(matches) > 0 {
+			return matches[0], nil
 		}
 	}
 	return "", fmt.Errorf("no board found on any serial port")
 }
 
-// detectPortArduinoCLI uses `arduino-cli board list` to find the board port.
+// renderFlashError classifies a failed upload's output via diag.Parse and
+// either prints a rich ui.Traceback (the default) or NDJSON diagnostics
+// (opts.JSON, for editor integrations) on stdout. Used by the
+// firmware-index direct path above and by inputfile.go — the registered
+// Backend implementations in internal/flash/backends have their own
+// equivalent (renderUploadError), since that package can't import flash.
+func renderFlashError(output, port string, cmdLine []string, toolPath string, jsonOut bool) {
+	diags := diag.Parse(output, port)
+	if jsonOut {
+		_ = diag.NDJSON(os.Stdout, diags)
+		return
+	}
+	diag.Render(diags, cmdLine, toolPath)
+}
+
+// detectPortArduinoCLI uses `arduino-cli board list` to find the board
+// port — kept here (distinct from the arduino-cli Backend's own copy in
+// backends/arduino_cli.go) for inputfile.go's bossac/avrdude paths, which
+// need a plain serial port rather than a board-table FQBN.
 func detectPortArduinoCLI(arduinoCLI string) (string, error) {
 	if arduinoCLI == "" {
 		arduinoCLI = "arduino-cli"
@@ -227,4 +455,4 @@ func detectPortArduinoCLI(arduinoCLI string) (string, error) {
 		}
 	}
 	return "", fmt.Errorf("no board found on any serial port")
-}
\ No newline at end of file
+}
@@ -0,0 +1,280 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: inputfile  —  flash a prebuilt image, no project needed
+// ─────────────────────────────────────────────────────────────────────────────
+
+package flash
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/tsuki/cli/internal/flash/backends"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// firmwareFormat is the on-disk shape of an --input-file image, resolved
+// from its extension (or, for an extensionless UF2 image, its magic
+// bytes).
+type firmwareFormat string
+
+const (
+	formatUF2    firmwareFormat = "uf2"
+	formatBin    firmwareFormat = "bin"
+	formatHex    firmwareFormat = "hex"
+	formatModule firmwareFormat = "module"
+)
+
+// uf2Magic is the first 4 bytes of every UF2 file (see microsoft/uf2's spec).
+const uf2Magic = "UF2\n"
+
+// moduleFilename recognizes signed WINC/NINA module blobs by filename,
+// e.g. "winc1500-19.6.1.bin" or "nina-fw-1.4.8.bin".
+var moduleFilename = regexp.MustCompile(`(?i)^(winc\d+|nina)[-_]?(?:fw[-_])?(\d+\.\d+\.\d+)\.bin$`)
+
+// runInputFile flashes opts.InputFile directly — no manifest-driven
+// BuildDir resolution at all — detecting its format and dispatching to
+// whichever tool actually flashes it.
+func runInputFile(board string, opts Options) error {
+	path := opts.InputFile
+	format, err := detectFirmwareFormat(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatUF2:
+		return uploadUF2(path, board)
+	case formatModule:
+		return uploadModule(path, board, opts)
+	case formatBin:
+		return uploadBossac(path, board, opts)
+	default: // formatHex
+		return uploadAvrdude(path, board, opts)
+	}
+}
+
+// detectFirmwareFormat resolves path's firmware format from its extension,
+// falling back to its magic bytes for an extensionless UF2 image.
+func detectFirmwareFormat(path string) (firmwareFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".uf2":
+		return formatUF2, nil
+	case ".hex":
+		return formatHex, nil
+	case ".bin":
+		if moduleFilename.MatchString(filepath.Base(path)) {
+			return formatModule, nil
+		}
+		return formatBin, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	header := make([]byte, len(uf2Magic))
+	if _, err := f.Read(header); err == nil && string(header) == uf2Magic {
+		return formatUF2, nil
+	}
+	return "", fmt.Errorf("can't tell %s's firmware format from its extension — pass a .hex, .bin, or .uf2 file", path)
+}
+
+// uploadUF2 copies path onto the board's mounted UF2 bootloader drive —
+// the drive itself triggers the reset into the new firmware once the copy
+// finishes, so there's no separate "reset" step to run here.
+func uploadUF2(path, board string) error {
+	vol, err := findUF2Volume()
+	if err != nil {
+		return fmt.Errorf(
+			"finding the board's UF2 drive: %w\n  Hint: double-tap reset to put it in bootloader mode first", err,
+		)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [uf2]", vol, board))
+	sp := ui.NewSpinner("Copying firmware...")
+	sp.Start()
+
+	dest := filepath.Join(vol, filepath.Base(path))
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		sp.Stop(false, "upload failed")
+		return fmt.Errorf("copying to %s: %w", dest, err)
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware copied to %s — board will reset itself", vol))
+	return nil
+}
+
+// findUF2Volume looks for a mounted UF2 bootloader drive: one containing
+// INFO_UF2.TXT, which is how RP2040/SAMD UF2 bootloaders identify
+// themselves once mounted as USB mass storage.
+func findUF2Volume() (string, error) {
+	var roots []string
+	switch runtime.GOOS {
+	case "windows":
+		for c := 'A'; c <= 'Z'; c++ {
+			roots = append(roots, string(c)+`:\`)
+		}
+	case "darwin":
+		matches, _ := filepath.Glob("/Volumes/*")
+		roots = matches
+	default:
+		matches, _ := filepath.Glob(filepath.Join("/run/media", os.Getenv("USER"), "*"))
+		roots = matches
+	}
+	for _, root := range roots {
+		if _, err := os.Stat(filepath.Join(root, "INFO_UF2.TXT")); err == nil {
+			return root, nil
+		}
+	}
+	return "", fmt.Errorf("no UF2 drive mounted")
+}
+
+// uploadBossac flashes a raw .bin to a SAMD/RP2040 board via bossac,
+// using the board's fixed bootloader flash offset.
+func uploadBossac(path, board string, opts Options) error {
+	offset, ok := backends.BossacOffset(board)
+	if !ok {
+		return fmt.Errorf("don't know bossac's flash offset for board %q — pass a .uf2 image instead, if one's available", board)
+	}
+
+	port, err := resolvedInputFilePort(opts)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"--port", port,
+		"-U", "-i", "-e", "-w", "-v",
+		"--offset", fmt.Sprintf("0x%x", offset),
+		path,
+		"--reset",
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [bossac]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.Command("bossac", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderFlashError(string(out), port, append([]string{"bossac"}, args...), "bossac", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
+
+// uploadAvrdude flashes a raw .hex to an AVR board via avrdude directly
+// (no arduino-cli required), using the board's fixed programmer/part id.
+func uploadAvrdude(path, board string, opts Options) error {
+	programmer, part, ok := backends.AvrdudePart(board)
+	if !ok {
+		return fmt.Errorf("don't know avrdude's programmer/part for board %q", board)
+	}
+
+	port, err := resolvedInputFilePort(opts)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"-c", programmer,
+		"-p", part,
+		"-P", port,
+		"-U", "flash:w:" + path + ":i",
+	}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [avrdude]", port, board))
+	sp := ui.NewSpinner("Flashing firmware...")
+	sp.Start()
+
+	cmd := exec.Command("avrdude", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderFlashError(string(out), port, append([]string{"avrdude"}, args...), "avrdude", opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("firmware uploaded to %s", port))
+	return nil
+}
+
+// uploadModule flashes a signed WINC/NINA module blob via tsuki-flash's
+// own "module" subcommand, identifying it the way arduino-cli's
+// FirmwareUpdater does: "WINC1500@19.6.1"-style identifiers parsed out of
+// the blob's filename.
+func uploadModule(path, board string, opts Options) error {
+	m := moduleFilename.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return fmt.Errorf("%s doesn't look like a WINC/NINA module blob (expected e.g. winc1500-19.6.1.bin)", path)
+	}
+	moduleID := fmt.Sprintf("%s@%s", strings.ToUpper(m[1]), m[2])
+
+	flashBin := opts.FlashBinary
+	if flashBin == "" {
+		flashBin = "tsuki-flash"
+	}
+
+	port := opts.Port
+	if port == "" {
+		ui.Info("Auto-detecting board on serial ports...")
+		tsukiFlash, _ := backends.Get("tsuki-flash")
+		detected, err := tsukiFlash.Detect(backends.Options{FlashBinary: flashBin})
+		if err != nil {
+			return fmt.Errorf(
+				"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+			)
+		}
+		port = detected
+		ui.Success(fmt.Sprintf("Found board on %s", port))
+	}
+
+	args := []string{"module", "--module", moduleID, "--port", port, "--file", path}
+
+	ui.SectionTitle(fmt.Sprintf("Uploading to %s  [board: %s]  [module: %s]", port, board, moduleID))
+	sp := ui.NewSpinner("Flashing module firmware...")
+	sp.Start()
+
+	cmd := exec.Command(flashBin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		sp.Stop(false, "upload failed")
+		renderFlashError(string(out), port, append([]string{flashBin}, args...), flashBin, opts.JSON)
+		return fmt.Errorf("upload failed")
+	}
+
+	sp.Stop(true, fmt.Sprintf("%s flashed to %s", moduleID, port))
+	return nil
+}
+
+// resolvedInputFilePort returns opts.Port, auto-detecting via arduino-cli
+// (bossac/avrdude need a real serial port, not tsuki-flash/arduino-cli
+// themselves) when it's empty.
+func resolvedInputFilePort(opts Options) (string, error) {
+	if opts.Port != "" {
+		return opts.Port, nil
+	}
+	ui.Info("Auto-detecting board on serial ports...")
+	port, err := detectPortArduinoCLI(opts.ArduinoCLI)
+	if err != nil {
+		return "", fmt.Errorf(
+			"no board detected: %w\n  Hint: connect the board and try again, or pass --port /dev/ttyUSBx", err,
+		)
+	}
+	ui.Success(fmt.Sprintf("Found board on %s", port))
+	return port, nil
+}
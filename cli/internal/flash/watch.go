@@ -0,0 +1,241 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: flash :: watch  —  serial port hotplug detection
+// ─────────────────────────────────────────────────────────────────────────────
+
+package flash
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PortEventType distinguishes a port appearing from a port disappearing.
+type PortEventType string
+
+const (
+	PortAdded   PortEventType = "added"
+	PortRemoved PortEventType = "removed"
+)
+
+// PortEvent is one hotplug change Watch reports.
+type PortEvent struct {
+	Type       PortEventType
+	Port       string
+	VID        string
+	PID        string
+	Serial     string
+	BoardGuess string // boardTable id this VID/PID pair is known to match, "" if unrecognized
+}
+
+// usbBoardGuesses maps well-known "vid:pid" pairs (lowercase hex, no "0x"
+// prefix) to the boardTable id they identify. It's just enough to let
+// `tsuki boards watch` and `upload --wait` name a board instead of a bare
+// port — not an exhaustive USB ID database.
+var usbBoardGuesses = map[string]string{
+	"2341:0043": "uno",
+	"2341:0001": "uno",
+	"2341:0010": "mega",
+	"2341:0036": "leonardo",
+	"2341:8036": "leonardo",
+	"2341:0037": "micro",
+	"2341:8037": "micro",
+	"2341:804d": "zero",
+	"2341:804e": "mkrzero",
+	"2341:8052": "mkr1000",
+	"2341:8057": "nano33iot",
+	"2e8a:000a": "pico",
+	"2e8a:0003": "pico",
+	"10c4:ea60": "esp32",
+	"1a86:7523": "esp8266",
+}
+
+// Watch streams serial port hotplug events until ctx is canceled. It
+// prefers `arduino-cli board list --watch`, which already enriches ports
+// with VID/PID and a board guess, falling back to polling listSerialPorts
+// every 500ms and enriching each port itself when arduino-cli isn't on
+// PATH (or opts.ArduinoCLI doesn't resolve to a working binary).
+func Watch(ctx context.Context, opts Options) (<-chan PortEvent, error) {
+	if cli := resolveWatchableArduinoCLI(opts.ArduinoCLI); cli != "" {
+		if events, err := watchArduinoCLI(ctx, cli); err == nil {
+			return events, nil
+		}
+	}
+
+	events := make(chan PortEvent)
+	go pollPorts(ctx, events)
+	return events, nil
+}
+
+func resolveWatchableArduinoCLI(bin string) string {
+	if bin == "" {
+		bin = "arduino-cli"
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return ""
+	}
+	return bin
+}
+
+// arduinoWatchEvent is one line of `arduino-cli board list --watch
+// --format json`'s output.
+type arduinoWatchEvent struct {
+	EventType string `json:"eventType"` // "add" or "remove"
+	Port      struct {
+		Address    string `json:"address"`
+		Properties struct {
+			VID    string `json:"vid"`
+			PID    string `json:"pid"`
+			Serial string `json:"serialNumber"`
+		} `json:"properties"`
+	} `json:"port"`
+}
+
+func watchArduinoCLI(ctx context.Context, cli string) (<-chan PortEvent, error) {
+	cmd := exec.CommandContext(ctx, cli, "board", "list", "--watch", "--format", "json")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	events := make(chan PortEvent)
+	go func() {
+		defer close(events)
+		defer cmd.Wait()
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var raw arduinoWatchEvent
+			if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+				continue
+			}
+			evt := PortEvent{
+				Port:   raw.Port.Address,
+				VID:    raw.Port.Properties.VID,
+				PID:    raw.Port.Properties.PID,
+				Serial: raw.Port.Properties.Serial,
+			}
+			if raw.EventType == "remove" {
+				evt.Type = PortRemoved
+			} else {
+				evt.Type = PortAdded
+			}
+			evt.BoardGuess = guessBoard(evt.VID, evt.PID)
+
+			select {
+			case events <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// pollPorts is the no-arduino-cli fallback: diff listSerialPorts() every
+// 500ms and enrich each newly-seen port with whatever USB identity
+// information the OS exposes without an extra dependency.
+func pollPorts(ctx context.Context, events chan<- PortEvent) {
+	defer close(events)
+
+	prev := make(map[string]bool)
+	for _, p := range listSerialPorts() {
+		prev[p] = true
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cur := make(map[string]bool)
+			for _, p := range listSerialPorts() {
+				cur[p] = true
+				if !prev[p] {
+					if !sendEvent(ctx, events, enrichedEvent(PortAdded, p)) {
+						return
+					}
+				}
+			}
+			for p := range prev {
+				if !cur[p] {
+					if !sendEvent(ctx, events, PortEvent{Type: PortRemoved, Port: p}) {
+						return
+					}
+				}
+			}
+			prev = cur
+		}
+	}
+}
+
+func sendEvent(ctx context.Context, events chan<- PortEvent, evt PortEvent) bool {
+	select {
+	case events <- evt:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func enrichedEvent(t PortEventType, port string) PortEvent {
+	vid, pid, serial := enrichUSB(port)
+	return PortEvent{
+		Type:       t,
+		Port:       port,
+		VID:        vid,
+		PID:        pid,
+		Serial:     serial,
+		BoardGuess: guessBoard(vid, pid),
+	}
+}
+
+func guessBoard(vid, pid string) string {
+	if vid == "" || pid == "" {
+		return ""
+	}
+	return usbBoardGuesses[strings.ToLower(vid+":"+pid)]
+}
+
+// enrichUSB reads a serial port's USB VID/PID/serial number straight out
+// of sysfs. Linux only for now — macOS needs IORegistry and Windows needs
+// SetupAPI, neither of which this tree has a dependency for, so both
+// report an unenriched port rather than a fabricated one.
+func enrichUSB(port string) (vid, pid, serial string) {
+	if runtime.GOOS != "linux" {
+		return "", "", ""
+	}
+
+	devLink := filepath.Join("/sys/class/tty", filepath.Base(port), "device")
+	real, err := filepath.EvalSymlinks(devLink)
+	if err != nil {
+		return "", "", ""
+	}
+
+	// .../device resolves to the tty's interface directory; the USB device
+	// itself (where idVendor/idProduct/serial live) is one level up.
+	usbDir := filepath.Dir(real)
+	return readSysfsTrim(filepath.Join(usbDir, "idVendor")),
+		readSysfsTrim(filepath.Join(usbDir, "idProduct")),
+		readSysfsTrim(filepath.Join(usbDir, "serial"))
+}
+
+func readSysfsTrim(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,263 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: fwindex  —  firmware/plugin index for arduino-cli-free uploads
+//
+//  The index is a single JSON document pinned by URL in config
+//  (firmware_index_url): for each FQBN it names the uploader tool
+//  (avrdude, bossac, picotool, esptool, dfu-util) that flashes it, and for
+//  each tool it lists a per-OS/arch download URL and sha256. EnsureTool
+//  downloads, verifies, and caches the tool binary under
+//  ~/.cache/tsuki/tools/<name>/<version>/<os>-<arch>/ so a later build on
+//  the same machine reuses it without hitting the network again.
+//
+//  The index itself is cached under ~/.cache/tsuki/fwindex/index.json and
+//  is reused as-is when Load is called with Offline true, or when a fetch
+//  fails and a cached copy exists — network flakiness shouldn't block an
+//  upload that was already working an hour ago.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package fwindex
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Tool is one uploader tool's metadata: per-OS/arch download URLs and their
+// sha256 digests, plus the command template used to invoke it.
+type Tool struct {
+	Name    string            `json:"name"` // "avrdude", "bossac", "picotool", "esptool", "dfu-util"
+	Version string            `json:"version"`
+	URLs    map[string]string `json:"urls"`   // "os/arch" (runtime.GOOS/runtime.GOARCH) -> download URL
+	SHA256  map[string]string `json:"sha256"` // "os/arch" -> hex sha256 of that download
+
+	// UploadPattern is the command line used to flash with this tool,
+	// mirroring arduino-cli's own platform.txt recipe placeholders:
+	//   {tool_dir}         — directory EnsureTool cached this tool's binary in
+	//   {serial.port.file} — the board's serial port (e.g. /dev/ttyUSB0)
+	//   {loader.sketch}    — path to the compiled .hex/.bin to flash
+	//   {build.mcu}        — the board's MCU name (BoardTool.MCU)
+	UploadPattern string `json:"upload_pattern"`
+}
+
+// BoardTool is one FQBN's entry in the index: which tool flashes it, and
+// that tool's MCU name (avrdude's -p, for instance, needs this even though
+// the tool itself is shared across many MCUs).
+type BoardTool struct {
+	Tool string `json:"tool"` // key into Index.Tools
+	MCU  string `json:"mcu"`
+}
+
+// Index is the top-level shape of the firmware index JSON.
+type Index struct {
+	Tools  map[string]Tool      `json:"tools"`
+	Boards map[string]BoardTool `json:"boards"` // fqbn -> entry
+}
+
+// ── Paths ─────────────────────────────────────────────────────────────────────
+
+// CacheDir returns ~/.cache/tsuki/fwindex, where the fetched index is cached.
+func CacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".cache", "tsuki", "fwindex")
+	}
+	return filepath.Join(home, ".cache", "tsuki", "fwindex")
+}
+
+func indexCachePath() string {
+	return filepath.Join(CacheDir(), "index.json")
+}
+
+// ToolsDir returns ~/.cache/tsuki/tools, where downloaded uploader tool
+// binaries are cached.
+func ToolsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".cache", "tsuki", "tools")
+	}
+	return filepath.Join(home, ".cache", "tsuki", "tools")
+}
+
+// osArchKey is the Tool.URLs/SHA256 key for the running platform.
+func osArchKey() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// ── Load ──────────────────────────────────────────────────────────────────────
+
+// LoadOptions controls Load.
+type LoadOptions struct {
+	IndexURL string // required — cfg.FirmwareIndexURL
+	KeyURL   string // optional — cfg.FirmwareIndexKeyURL; "" skips signature verification
+	Offline  bool   // use the cached index only, never hit the network
+}
+
+// Load resolves the firmware index: the cached copy when Offline is set,
+// otherwise a fresh fetch (falling back to the cache on network failure),
+// verified against KeyURL's detached signature when one is configured.
+func Load(opts LoadOptions) (*Index, error) {
+	if opts.IndexURL == "" {
+		return nil, fmt.Errorf("no firmware index configured — set firmware_index_url first")
+	}
+
+	if opts.Offline {
+		return loadCached()
+	}
+
+	data, err := httpGet(opts.IndexURL)
+	if err != nil {
+		if idx, cacheErr := loadCached(); cacheErr == nil {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("fetching firmware index: %w", err)
+	}
+
+	if opts.KeyURL != "" {
+		if err := verifySignature(opts.KeyURL, opts.IndexURL, data); err != nil {
+			return nil, fmt.Errorf("firmware index signature verification failed: %w", err)
+		}
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing firmware index: %w", err)
+	}
+
+	if err := os.MkdirAll(CacheDir(), 0755); err == nil {
+		_ = os.WriteFile(indexCachePath(), data, 0644)
+	}
+
+	return &idx, nil
+}
+
+func loadCached() (*Index, error) {
+	data, err := os.ReadFile(indexCachePath())
+	if err != nil {
+		return nil, fmt.Errorf("no cached firmware index available: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing cached firmware index: %w", err)
+	}
+	return &idx, nil
+}
+
+// ResolveBoard looks up fqbn in the index and returns its tool and board entry.
+func (idx *Index) ResolveBoard(fqbn string) (Tool, BoardTool, bool) {
+	entry, ok := idx.Boards[fqbn]
+	if !ok {
+		return Tool{}, BoardTool{}, false
+	}
+	tool, ok := idx.Tools[entry.Tool]
+	if !ok {
+		return Tool{}, BoardTool{}, false
+	}
+	return tool, entry, true
+}
+
+// ── Tool download/cache ───────────────────────────────────────────────────────
+
+// EnsureTool returns the directory containing t's cached binary for the
+// running OS/arch, downloading and sha256-verifying it first if needed.
+func EnsureTool(t Tool) (string, error) {
+	key := osArchKey()
+	url, ok := t.URLs[key]
+	if !ok {
+		return "", fmt.Errorf("%s %s has no download for %s", t.Name, t.Version, key)
+	}
+	wantSHA := t.SHA256[key]
+
+	dir := filepath.Join(ToolsDir(), t.Name, t.Version, strings.ReplaceAll(key, "/", "-"))
+	binPath := filepath.Join(dir, t.Name)
+	if runtime.GOOS == "windows" {
+		binPath += ".exe"
+	}
+
+	if data, err := os.ReadFile(binPath); err == nil {
+		if wantSHA == "" || sha256Hex(data) == wantSHA {
+			return dir, nil
+		}
+		// Cached binary doesn't match — re-download below.
+	}
+
+	data, err := httpGet(url)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s %s: %w", t.Name, t.Version, err)
+	}
+	if wantSHA != "" {
+		if got := sha256Hex(data); got != wantSHA {
+			return "", fmt.Errorf("%s %s: sha256 mismatch (want %s, got %s)", t.Name, t.Version, wantSHA, got)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating tool cache dir: %w", err)
+	}
+	if err := os.WriteFile(binPath, data, 0755); err != nil {
+		return "", fmt.Errorf("writing %s: %w", binPath, err)
+	}
+	return dir, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ── HTTP + signature verification ─────────────────────────────────────────────
+
+func httpGet(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifySignature verifies data against the raw 64-byte Ed25519 signature
+// at "<indexURL>.sig", using the PEM-encoded public key fetched from keyURL.
+func verifySignature(keyURL, indexURL string, data []byte) error {
+	keyPEM, err := httpGet(keyURL)
+	if err != nil {
+		return fmt.Errorf("fetching public key: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return fmt.Errorf("no PEM block in key from %s", keyURL)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("parsing public key: %w", err)
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("key is not Ed25519 (got %T)", pub)
+	}
+
+	sig, err := httpGet(indexURL + ".sig")
+	if err != nil {
+		return fmt.Errorf("fetching signature: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d (expected %d)", len(sig), ed25519.SignatureSize)
+	}
+	if !ed25519.Verify(edPub, data, sig) {
+		return fmt.Errorf("signature invalid")
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: i18n  —  message catalogs for the ui package and CLI commands
+//
+//  Catalogs are TOML files shaped like the ui/install doc comments they
+//  replace: dotted keys ("traceback.title", "install.success") map either
+//  to a plain string carrying %s/%d-style fmt verbs, or to a { one, other }
+//  table for pluralized messages selected by the first numeric argument.
+//
+//  Resolution order, lowest to highest priority:
+//    1. the embedded en_US.toml — always loaded first, so every key has a
+//       fallback even when the active locale's catalog is a partial
+//       skeleton (see catalogs/de_DE.toml)
+//    2. the embedded catalog for the active locale, if one ships with tsuki
+//    3. $XDG_CONFIG_HOME/tsuki/translations/<lang>.toml, if present — lets
+//       a user override or complete a translation without rebuilding
+//
+//  The active locale is chosen by Init, from (highest priority first) the
+//  --lang flag, then $LC_ALL, $LC_MESSAGES, $LANG.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed catalogs/*.toml
+var embeddedCatalogs embed.FS
+
+const embeddedDir = "catalogs"
+
+// DefaultLang is the catalog every locale falls back to.
+const DefaultLang = "en_US"
+
+// pluralForms is a { one, other } pluralization entry — tsuki only ever
+// distinguishes singular from everything else, which covers English and
+// German (the only catalogs shipped today) without needing full CLDR
+// plural-category support.
+type pluralForms struct {
+	One   string `toml:"one"`
+	Other string `toml:"other"`
+}
+
+// catalog is a resolved, flattened set of messages for one locale.
+type catalog struct {
+	messages map[string]string
+	plurals  map[string]pluralForms
+}
+
+var active = mustLoadEmbedded(DefaultLang)
+
+// Init selects the active locale for T: langFlag (the --lang value, "" if
+// unset) takes priority over $LC_ALL, $LC_MESSAGES, and $LANG, in that
+// order. An unrecognized or empty locale leaves en_US active. Init never
+// returns an error — a locale with no catalog, or a malformed override
+// file, just means fewer keys get translated, not a broken CLI.
+func Init(langFlag string) {
+	lang := langFlag
+	if lang == "" {
+		lang = detectLang()
+	}
+	lang = normalizeLang(lang)
+	if lang == "" || lang == DefaultLang {
+		active = mustLoadEmbedded(DefaultLang)
+		return
+	}
+
+	merged := mustLoadEmbedded(DefaultLang)
+	if embedded, err := loadEmbedded(lang); err == nil {
+		merge(merged, embedded)
+	}
+	if override, err := loadOverrideFile(lang); err == nil {
+		merge(merged, override)
+	}
+	active = merged
+}
+
+// detectLang reads the POSIX locale environment variables in the order
+// glibc itself consults them.
+func detectLang() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalizeLang turns a POSIX locale string ("de_DE.UTF-8", "de-DE@euro")
+// into the bare "de_DE" form catalogs are named by.
+func normalizeLang(lang string) string {
+	lang = strings.SplitN(lang, ".", 2)[0]
+	lang = strings.SplitN(lang, "@", 2)[0]
+	lang = strings.ReplaceAll(lang, "-", "_")
+	return lang
+}
+
+// T looks up key in the active catalog and formats it with args via
+// fmt.Sprintf. A pluralized key selects "one" when the first arg is the
+// integer 1, "other" otherwise. A key with no translation in any loaded
+// catalog is returned unchanged, so a missing translation degrades to a
+// visible-but-ugly key rather than a panic or a blank line.
+func T(key string, args ...interface{}) string {
+	if pf, ok := active.plurals[key]; ok {
+		form := pf.Other
+		if isOne(args) && pf.One != "" {
+			form = pf.One
+		}
+		return fmt.Sprintf(form, args...)
+	}
+	if msg, ok := active.messages[key]; ok {
+		return fmt.Sprintf(msg, args...)
+	}
+	return key
+}
+
+func isOne(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch n := args[0].(type) {
+	case int:
+		return n == 1
+	case int64:
+		return n == 1
+	}
+	return false
+}
+
+// mustLoadEmbedded loads an embedded catalog that's expected to always
+// exist (DefaultLang) — a missing or malformed en_US.toml is a build-time
+// packaging bug, not a runtime condition to recover from.
+func mustLoadEmbedded(lang string) *catalog {
+	c, err := loadEmbedded(lang)
+	if err != nil {
+		panic(fmt.Sprintf("i18n: loading embedded catalog %q: %v", lang, err))
+	}
+	return c
+}
+
+func loadEmbedded(lang string) (*catalog, error) {
+	data, err := embeddedCatalogs.ReadFile(filepath.Join(embeddedDir, lang+".toml"))
+	if err != nil {
+		return nil, err
+	}
+	return parseCatalog(data)
+}
+
+// loadOverrideFile reads $XDG_CONFIG_HOME/tsuki/translations/<lang>.toml,
+// falling back to ~/.config when $XDG_CONFIG_HOME is unset.
+func loadOverrideFile(lang string) (*catalog, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	path := filepath.Join(configHome, "tsuki", "translations", lang+".toml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCatalog(data)
+}
+
+func parseCatalog(data []byte) (*catalog, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, err
+	}
+	c := &catalog{
+		messages: make(map[string]string),
+		plurals:  make(map[string]pluralForms),
+	}
+	flatten("", raw, c)
+	return c, nil
+}
+
+// flatten walks a decoded TOML document, turning nested tables into
+// dotted keys ("install.count") except where a table is itself a
+// pluralization entry (exactly the shape { one = "...", other = "..." }),
+// which it records in plurals instead of recursing further.
+func flatten(prefix string, node map[string]interface{}, c *catalog) {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case string:
+			c.messages[key] = val
+		case map[string]interface{}:
+			if _, hasOther := val["other"].(string); hasOther {
+				one, _ := val["one"].(string)
+				other, _ := val["other"].(string)
+				c.plurals[key] = pluralForms{One: one, Other: other}
+				continue
+			}
+			flatten(key, val, c)
+		}
+	}
+}
+
+// merge copies every message/plural in src into dst, overwriting whatever
+// dst already had — used to layer a more specific catalog (an embedded
+// locale, then a user override file) over the en_US base.
+func merge(dst, src *catalog) {
+	for k, v := range src.messages {
+		dst.messages[k] = v
+	}
+	for k, v := range src.plurals {
+		dst.plurals[k] = v
+	}
+}
@@ -0,0 +1,121 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: integrity  —  content-addressed verification for downloads
+//
+//  A registry entry may advertise a sha256 digest (and optionally a size)
+//  for the exact bytes it expects a download to produce. Verify checks an
+//  already-written file against one; VerifyingReader checks a download as
+//  it streams, so a large payload's digest doesn't require a second full
+//  read-through (or buffering the whole thing a second time) just to hash
+//  it — the same shape as io.TeeReader, but purpose-built for "hash while
+//  you go, then compare once EOF is reached".
+// ─────────────────────────────────────────────────────────────────────────────
+
+package integrity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// Digest is the integrity metadata one registry entry may advertise for a
+// download. A zero Digest (SHA256 == "") means the entry declared nothing
+// to check against — Verify and VerifyingReader.Verify both treat that as
+// "nothing to enforce", not "verification failed".
+type Digest struct {
+	SHA256 string
+	Size   int64 // 0 means unknown / not declared
+}
+
+// Empty reports whether d carries no digest to check.
+func (d Digest) Empty() bool { return d.SHA256 == "" }
+
+// Verify hashes the file at path and compares it against expected, failing
+// closed: a declared digest that doesn't match is always an error, never a
+// warning.
+func Verify(path string, expected Digest) error {
+	if expected.Empty() {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	vr := NewVerifyingReader(f)
+	n, err := io.Copy(io.Discard, vr)
+	if err != nil {
+		return fmt.Errorf("hashing %s: %w", path, err)
+	}
+	if expected.Size != 0 && n != expected.Size {
+		return fmt.Errorf("%s: size mismatch: expected %d bytes, got %d", path, expected.Size, n)
+	}
+	return vr.Verify(expected)
+}
+
+// VerifyData hashes data and compares it against expected — the in-memory
+// equivalent of Verify, for a download that's already been read into memory
+// rather than streamed to a file (e.g. a manifest fetched via httpGet).
+func VerifyData(data []byte, expected Digest) error {
+	if expected.Empty() {
+		return nil
+	}
+	vr := NewVerifyingReader(bytes.NewReader(data))
+	if _, err := io.Copy(io.Discard, vr); err != nil {
+		return err
+	}
+	return vr.Verify(expected)
+}
+
+// VerifyingReader wraps an io.Reader, hashing every byte as it passes
+// through. Call Verify once the underlying reader has been fully consumed
+// (e.g. via io.Copy or io.ReadAll) to compare the accumulated digest
+// against an expected one.
+type VerifyingReader struct {
+	r    io.Reader
+	h    hash.Hash
+	size int64
+}
+
+// NewVerifyingReader wraps r so every Read through it also feeds a running
+// SHA-256 hash.
+func NewVerifyingReader(r io.Reader) *VerifyingReader {
+	return &VerifyingReader{r: r, h: sha256.New()}
+}
+
+func (vr *VerifyingReader) Read(p []byte) (int, error) {
+	n, err := vr.r.Read(p)
+	if n > 0 {
+		vr.h.Write(p[:n])
+		vr.size += int64(n)
+	}
+	return n, err
+}
+
+// Sum returns the hex-encoded SHA-256 of everything read so far.
+func (vr *VerifyingReader) Sum() string {
+	return hex.EncodeToString(vr.h.Sum(nil))
+}
+
+// Size returns the number of bytes read so far.
+func (vr *VerifyingReader) Size() int64 { return vr.size }
+
+// Verify compares the digest accumulated so far against expected, failing
+// closed on any declared-but-mismatched field. A zero Digest always passes.
+func (vr *VerifyingReader) Verify(expected Digest) error {
+	if expected.Empty() {
+		return nil
+	}
+	if expected.Size != 0 && vr.size != expected.Size {
+		return fmt.Errorf("size mismatch: expected %d bytes, got %d", expected.Size, vr.size)
+	}
+	if got := vr.Sum(); got != expected.SHA256 {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", expected.SHA256, got)
+	}
+	return nil
+}
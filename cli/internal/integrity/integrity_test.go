@@ -0,0 +1,112 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func digestFor(data []byte) Digest {
+	sum := sha256.Sum256(data)
+	return Digest{SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data))}
+}
+
+func TestVerifyEmptyDigestAlwaysPasses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, []byte("anything"), 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	if err := Verify(path, Digest{}); err != nil {
+		t.Errorf("Verify with empty Digest: unexpected error: %v", err)
+	}
+}
+
+func TestVerifyMatchingDigest(t *testing.T) {
+	data := []byte("hello, tsuki")
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	if err := Verify(path, digestFor(data)); err != nil {
+		t.Errorf("Verify with matching digest: unexpected error: %v", err)
+	}
+}
+
+func TestVerifyMismatchedHashFailsClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, []byte("actual contents"), 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	expected := digestFor([]byte("different contents"))
+	expected.Size = int64(len("actual contents")) // keep size matching so only the hash is under test
+	err := Verify(path, expected)
+	if err == nil {
+		t.Fatal("Verify with mismatched hash: expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("Verify error = %q, want it to mention sha256 mismatch", err.Error())
+	}
+}
+
+func TestVerifyMismatchedSizeFailsClosed(t *testing.T) {
+	data := []byte("actual contents")
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	expected := digestFor(data)
+	expected.Size++ // declare a size that won't match the real file
+	err := Verify(path, expected)
+	if err == nil {
+		t.Fatal("Verify with mismatched size: expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "size mismatch") {
+		t.Errorf("Verify error = %q, want it to mention size mismatch", err.Error())
+	}
+}
+
+func TestVerifyDataMatchesAndMismatches(t *testing.T) {
+	data := []byte("in-memory payload")
+	if err := VerifyData(data, digestFor(data)); err != nil {
+		t.Errorf("VerifyData with matching digest: unexpected error: %v", err)
+	}
+	if err := VerifyData(data, digestFor([]byte("something else"))); err == nil {
+		t.Error("VerifyData with mismatched digest: expected an error, got nil")
+	}
+}
+
+func TestVerifyingReaderTracksSumAndSize(t *testing.T) {
+	data := []byte("streamed content to hash incrementally")
+	vr := NewVerifyingReader(strings.NewReader(string(data)))
+
+	buf := make([]byte, 7) // force multiple short reads
+	var total int64
+	for {
+		n, err := vr.Read(buf)
+		total += int64(n)
+		if err != nil {
+			break
+		}
+	}
+
+	if total != int64(len(data)) {
+		t.Fatalf("read %d bytes, want %d", total, len(data))
+	}
+	if vr.Size() != int64(len(data)) {
+		t.Errorf("Size() = %d, want %d", vr.Size(), len(data))
+	}
+
+	expected := digestFor(data)
+	if vr.Sum() != expected.SHA256 {
+		t.Errorf("Sum() = %s, want %s", vr.Sum(), expected.SHA256)
+	}
+	if err := vr.Verify(expected); err != nil {
+		t.Errorf("Verify: unexpected error: %v", err)
+	}
+	if err := vr.Verify(Digest{SHA256: "not-a-real-hash"}); err == nil {
+		t.Error("Verify with wrong expected hash: expected an error, got nil")
+	}
+}
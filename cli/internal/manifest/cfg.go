@@ -0,0 +1,297 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: manifest :: cfg  —  [target.<predicate>] mini-language
+//
+//  A target predicate is either a bare board name ("esp32") or a cfg(...)
+//  expression, Cargo-style:
+//
+//	cfg(board = "esp32")
+//	cfg(arch = "xtensa")
+//	all(cfg(arch = "avr"), not(cfg(board = "mega")))
+//	any(cfg(board = "uno"), cfg(board = "nano"))
+//
+//  Eval checks a predicate against a CfgEnv built from Manifest.Board and
+//  that board's entry in boardRegistry: "board" matches the literal board
+//  name from [package].board; "arch"/"family"/"mcu" match that board's
+//  BoardInfo fields. A board whose family is itself a recognizable target
+//  (e.g. the "pico" board's RP2040 family) is matched via cfg(family = ...),
+//  not cfg(board = ...) — "board" only ever means the manifest's own literal
+//  board string.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package manifest
+
+import "fmt"
+
+// CfgEnv is the set of key/value facts a TargetPredicate is evaluated
+// against — see Manifest.DependenciesFor, which builds one from a board
+// name and its boardRegistry entry.
+type CfgEnv map[string]string
+
+// TargetPredicate is a parsed [target.<predicate>] key.
+type TargetPredicate interface {
+	Eval(env CfgEnv) bool
+	String() string
+}
+
+// boardLiteral is a bare predicate naming a board directly, e.g.
+// [target.esp32.dependencies].
+type boardLiteral struct {
+	board string
+}
+
+func (p boardLiteral) Eval(env CfgEnv) bool { return env["board"] == p.board }
+func (p boardLiteral) String() string       { return p.board }
+
+// cfgEq is one cfg(key = "value") clause.
+type cfgEq struct {
+	key   string
+	value string
+}
+
+func (p cfgEq) Eval(env CfgEnv) bool { return env[p.key] == p.value }
+func (p cfgEq) String() string       { return fmt.Sprintf("cfg(%s = %q)", p.key, p.value) }
+
+// cfgAll is all(...) — true only when every sub-predicate is true.
+type cfgAll []TargetPredicate
+
+func (p cfgAll) Eval(env CfgEnv) bool {
+	for _, sub := range p {
+		if !sub.Eval(env) {
+			return false
+		}
+	}
+	return true
+}
+func (p cfgAll) String() string { return "all(" + joinPredicates(p) + ")" }
+
+// cfgAny is any(...) — true when at least one sub-predicate is true.
+type cfgAny []TargetPredicate
+
+func (p cfgAny) Eval(env CfgEnv) bool {
+	for _, sub := range p {
+		if sub.Eval(env) {
+			return true
+		}
+	}
+	return false
+}
+func (p cfgAny) String() string { return "any(" + joinPredicates(p) + ")" }
+
+// cfgNot is not(...) — negates a single sub-predicate.
+type cfgNot struct {
+	sub TargetPredicate
+}
+
+func (p cfgNot) Eval(env CfgEnv) bool { return !p.sub.Eval(env) }
+func (p cfgNot) String() string       { return "not(" + p.sub.String() + ")" }
+
+func joinPredicates(preds []TargetPredicate) string {
+	s := ""
+	for i, p := range preds {
+		if i > 0 {
+			s += ", "
+		}
+		s += p.String()
+	}
+	return s
+}
+
+// ── Parser ────────────────────────────────────────────────────────────────────
+
+// ParsePredicate parses one [target.<predicate>] key. raw with no "(" is a
+// bare board-literal; otherwise it must be a cfg(...)/all(...)/any(...)/
+// not(...) expression.
+func ParsePredicate(raw string) (TargetPredicate, error) {
+	if !containsByte(raw, '(') {
+		return boardLiteral{board: raw}, nil
+	}
+	p := &cfgParser{s: raw}
+	p.skipSpace()
+	pred, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("parsing target predicate %q: %w", raw, err)
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("parsing target predicate %q: unexpected trailing input %q", raw, p.s[p.pos:])
+	}
+	return pred, nil
+}
+
+func containsByte(s string, b byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return true
+		}
+	}
+	return false
+}
+
+// cfgParser is a minimal hand-written recursive-descent parser over a
+// single predicate string — the grammar is small enough that pulling in a
+// parser-combinator library or generated grammar would be overkill.
+type cfgParser struct {
+	s   string
+	pos int
+}
+
+func (p *cfgParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *cfgParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *cfgParser) expect(b byte) error {
+	if p.peek() != b {
+		return fmt.Errorf("expected %q at position %d", b, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// parseIdent reads a bare identifier: cfg/all/any/not, or a cfg(...) key.
+func (p *cfgParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == '(' || c == ')' || c == ',' || c == ' ' || c == '\t' || c == '=' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// parseQuoted reads a "double-quoted" string (no escape support — cfg
+// values are board/arch names, never containing a `"`).
+func (p *cfgParser) parseQuoted() (string, error) {
+	if err := p.expect('"'); err != nil {
+		return "", err
+	}
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != '"' {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return "", fmt.Errorf("unterminated string starting at position %d", start)
+	}
+	val := p.s[start:p.pos]
+	p.pos++ // closing quote
+	return val, nil
+}
+
+// parseExpr parses one cfg(...)/all(...)/any(...)/not(...) node.
+func (p *cfgParser) parseExpr() (TargetPredicate, error) {
+	p.skipSpace()
+	name := p.parseIdent()
+	p.skipSpace()
+	if err := p.expect('('); err != nil {
+		return nil, fmt.Errorf("expected \"(\" after %q: %w", name, err)
+	}
+	p.skipSpace()
+
+	switch name {
+	case "cfg":
+		key := p.parseIdent()
+		p.skipSpace()
+		if err := p.expect('='); err != nil {
+			return nil, fmt.Errorf("cfg(%s ...): expected \"=\": %w", key, err)
+		}
+		p.skipSpace()
+		value, err := p.parseQuoted()
+		if err != nil {
+			return nil, fmt.Errorf("cfg(%s = ...): %w", key, err)
+		}
+		p.skipSpace()
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return cfgEq{key: key, value: value}, nil
+
+	case "not":
+		sub, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return cfgNot{sub: sub}, nil
+
+	case "all", "any":
+		var subs []TargetPredicate
+		for {
+			sub, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, sub)
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+				p.skipSpace()
+				continue
+			}
+			break
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		if name == "all" {
+			return cfgAll(subs), nil
+		}
+		return cfgAny(subs), nil
+
+	default:
+		return nil, fmt.Errorf("unknown predicate %q", name)
+	}
+}
+
+// ── Board registry ────────────────────────────────────────────────────────────
+
+// BoardInfo is the metadata known about one board — grounded in the same
+// board ids build.go/compiledb.go already recognize via boardToolchain.
+type BoardInfo struct {
+	Arch   string // toolchain's target architecture, e.g. "avr", "xtensa"
+	Family string // chip family, e.g. "rp2040", "samd"
+	MCU    string // specific part, e.g. "atmega328p"
+}
+
+// boardRegistry maps a board id (as used in [package].board, and by
+// compiledb.go's boardToolchain) to its metadata.
+var boardRegistry = map[string]BoardInfo{
+	"uno":      {Arch: "avr", Family: "avr", MCU: "atmega328p"},
+	"nano":     {Arch: "avr", Family: "avr", MCU: "atmega328p"},
+	"mega":     {Arch: "avr", Family: "avr", MCU: "atmega2560"},
+	"leonardo": {Arch: "avr", Family: "avr", MCU: "atmega32u4"},
+	"micro":    {Arch: "avr", Family: "avr", MCU: "atmega32u4"},
+	"due":      {Arch: "arm", Family: "sam", MCU: "sam3x8e"},
+	"mkr1000":  {Arch: "arm", Family: "samd", MCU: "samd21"},
+	"esp32":    {Arch: "xtensa", Family: "esp32", MCU: "esp32"},
+	"esp8266":  {Arch: "xtensa", Family: "esp8266", MCU: "esp8266"},
+	"pico":     {Arch: "arm", Family: "rp2040", MCU: "rp2040"},
+	"teensy40": {Arch: "arm", Family: "teensy4", MCU: "imxrt1062"},
+}
+
+// boardEnv builds the CfgEnv a target predicate is evaluated against for
+// board — "board" is always set even when board is unrecognized (an
+// unknown board just never matches cfg(arch = ...)/cfg(family = ...)/
+// cfg(mcu = ...), same as it wouldn't match any bare board-literal other
+// than its own name).
+func boardEnv(board string) CfgEnv {
+	env := CfgEnv{"board": board}
+	if info, ok := boardRegistry[board]; ok {
+		env["arch"] = info.Arch
+		env["family"] = info.Family
+		env["mcu"] = info.MCU
+	}
+	return env
+}
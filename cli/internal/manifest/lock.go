@@ -2,20 +2,39 @@
 //  tsuki :: manifest :: lock  —  .tsuki/lock.json management
 //
 //  The lock file pins every resolved dependency to an exact version so that
-//  builds are reproducible.  It lives at <project>/.tsuki/lock.json.
+//  builds are reproducible.  It lives at <project>/.tsuki/lock.json — or,
+//  for a workspace member, at the enclosing workspace root's .tsuki/
+//  instead, pinning the union of every member's transitive graph rather
+//  than each member keeping its own (see LockDir, workspace.go).
+//
+//  v3 is the current on-disk format: a top-level "version" integer, a
+//  "package" array of LockPackage (name, version, source, checksum, and
+//  its own resolved dependency edges), and a "metadata" table for
+//  registry-supplied side data (yanked flags, published timestamps) keyed
+//  however the registry wants. v1 — no "version" field, a flat
+//  "dependencies" array with one checksum per entry — is still read (see
+//  loadLockV1) and upgraded in memory; Save always writes v3.
 // ─────────────────────────────────────────────────────────────────────────────
 
 package manifest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 const LockFileName = "lock.json"
 
+// LockFileVersion is the lock file format Save writes and LoadLock
+// upgrades everything older to.
+const LockFileVersion = 3
+
 // lockDir returns the .tsuki/ directory inside a project.
 func lockDir(projectDir string) string {
 	return filepath.Join(projectDir, ".tsuki")
@@ -26,34 +45,84 @@ func lockPath(projectDir string) string {
 	return filepath.Join(lockDir(projectDir), LockFileName)
 }
 
-// ── LockEntry ─────────────────────────────────────────────────────────────────
+// LockDir returns the directory whose .tsuki/lock.json governs projectDir.
+// A workspace member has no lock file of its own — the workspace root's
+// lock file pins the union of every member's transitive graph instead — so
+// this resolves to the enclosing workspace's Dir when projectDir is a
+// member, or to projectDir itself otherwise.
+func LockDir(projectDir string) string {
+	if ws, err := LoadWorkspace(projectDir); err == nil && ws != nil {
+		return ws.Dir
+	}
+	return projectDir
+}
 
-// LockEntry is one pinned dependency in the lock file.
-type LockEntry struct {
-	Name     string `json:"name"`
-	Version  string `json:"version"`
-	Registry string `json:"registry,omitempty"`
+// ── LockPackage ───────────────────────────────────────────────────────────────
+
+// LockPackage is one pinned dependency in the v3 lock file.
+type LockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Source is the dependency's concrete, reproducible source — the same
+	// string as ResolvedSource.Lock (see source.go): "registry+<id>@<ver>",
+	// "path+file://<abs path>", or "git+<url>#<commit sha>".
+	Source string `json:"source,omitempty"`
+
+	// Checksum is the sha256 of the fetched artifact, "sha256:<hex>"
+	// prefixed, for a registry dependency — or the resolved commit SHA for
+	// a git dependency, pinned here too even though there's no tarball to
+	// hash (Verify skips git dependencies). Empty for a path dependency.
 	Checksum string `json:"checksum,omitempty"`
-	Path     string `json:"path,omitempty"`
+
+	// Dependencies lists this package's own resolved transitive edges by
+	// fully-qualified id (see PackageID) — e.g. "bar 1.2.3 (registry+…)".
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// PackageID returns the fully-qualified id a package is referenced by in
+// LockPackage.Dependencies and LockFile.Graph: "name version (source)", or
+// just "name version" when source is unknown.
+func PackageID(name, version, source string) string {
+	if source == "" {
+		return fmt.Sprintf("%s %s", name, version)
+	}
+	return fmt.Sprintf("%s %s (%s)", name, version, source)
 }
 
 // ── LockFile ──────────────────────────────────────────────────────────────────
 
-// LockFile is the in-memory representation of .tsuki/lock.json.
+// LockFile is the in-memory representation of .tsuki/lock.json (v3).
 type LockFile struct {
-	Entries []LockEntry `json:"dependencies"`
+	Version  int               `json:"version"`
+	Packages []LockPackage     `json:"package"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
-// LoadLock reads the lock file from projectDir/.tsuki/lock.json.
-// Returns an empty LockFile (not an error) when the file does not exist yet.
+// LoadLock reads the lock file governing projectDir — its own
+// .tsuki/lock.json, or its enclosing workspace root's when projectDir is a
+// workspace member (see LockDir). Returns an empty v3 LockFile (not an
+// error) when the file does not exist yet. A pre-v3 file (no top-level
+// "version") is upgraded in memory; re-Save migrates it on disk.
 func LoadLock(projectDir string) (*LockFile, error) {
-	data, err := os.ReadFile(lockPath(projectDir))
+	data, err := os.ReadFile(lockPath(LockDir(projectDir)))
 	if os.IsNotExist(err) {
-		return &LockFile{}, nil
+		return &LockFile{Version: LockFileVersion}, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("reading lock file: %w", err)
 	}
+
+	var probe struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parsing lock file: %w", err)
+	}
+	if probe.Version == 0 {
+		return loadLockV1(data)
+	}
+
 	var lf LockFile
 	if err := json.Unmarshal(data, &lf); err != nil {
 		return nil, fmt.Errorf("parsing lock file: %w", err)
@@ -61,47 +130,153 @@ func LoadLock(projectDir string) (*LockFile, error) {
 	return &lf, nil
 }
 
-// Save writes the lock file to projectDir/.tsuki/lock.json, creating the
-// .tsuki/ directory if necessary.
+// lockV1Entry is the pre-v3 on-disk shape: one checksum field, a single
+// optional registry name, no version field or dependency edges at all.
+type lockV1Entry struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Registry string `json:"registry,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// loadLockV1 parses the pre-v3 flat lock file format and upgrades it to
+// the v3 shape, deriving each entry's Source from whichever of
+// Registry/Path it had (v1 predates git dependencies, so there's nothing
+// to derive a git source from).
+func loadLockV1(data []byte) (*LockFile, error) {
+	var v1 struct {
+		Entries []lockV1Entry `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 lock file: %w", err)
+	}
+
+	lf := &LockFile{Version: LockFileVersion}
+	for _, e := range v1.Entries {
+		var source string
+		switch {
+		case e.Path != "":
+			source = "path+file://" + e.Path
+		case e.Registry != "":
+			source = fmt.Sprintf("registry+%s@%s", e.Registry, e.Version)
+		default:
+			source = fmt.Sprintf("registry+default@%s", e.Version)
+		}
+		checksum := e.Checksum
+		if checksum != "" && !strings.HasPrefix(checksum, "sha256:") {
+			checksum = "sha256:" + checksum
+		}
+		lf.Packages = append(lf.Packages, LockPackage{
+			Name:     e.Name,
+			Version:  e.Version,
+			Source:   source,
+			Checksum: checksum,
+		})
+	}
+	lf.sortPackages()
+	return lf, nil
+}
+
+// Save writes the lock file governing projectDir (see LockDir) in v3
+// format, creating its .tsuki/ directory if necessary. Packages are
+// sorted by name then version first so re-saving an unchanged graph
+// produces an unchanged diff.
 func (lf *LockFile) Save(projectDir string) error {
-	if err := os.MkdirAll(lockDir(projectDir), 0755); err != nil {
+	dir := LockDir(projectDir)
+	if err := os.MkdirAll(lockDir(dir), 0755); err != nil {
 		return fmt.Errorf("creating .tsuki dir: %w", err)
 	}
+	lf.Version = LockFileVersion
+	lf.sortPackages()
 	data, err := json.MarshalIndent(lf, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(lockPath(projectDir), append(data, '\n'), 0644)
+	return os.WriteFile(lockPath(dir), append(data, '\n'), 0644)
+}
+
+func (lf *LockFile) sortPackages() {
+	sort.Slice(lf.Packages, func(i, j int) bool {
+		if lf.Packages[i].Name != lf.Packages[j].Name {
+			return lf.Packages[i].Name < lf.Packages[j].Name
+		}
+		return lf.Packages[i].Version < lf.Packages[j].Version
+	})
 }
 
-// Get returns the LockEntry for name, or nil if not present.
-func (lf *LockFile) Get(name string) *LockEntry {
-	for i := range lf.Entries {
-		if lf.Entries[i].Name == name {
-			return &lf.Entries[i]
+// Get returns the LockPackage for name, or nil if not present.
+func (lf *LockFile) Get(name string) *LockPackage {
+	for i := range lf.Packages {
+		if lf.Packages[i].Name == name {
+			return &lf.Packages[i]
 		}
 	}
 	return nil
 }
 
 // Upsert adds or updates the entry for pkg.Name.
-func (lf *LockFile) Upsert(entry LockEntry) {
-	for i := range lf.Entries {
-		if lf.Entries[i].Name == entry.Name {
-			lf.Entries[i] = entry
+func (lf *LockFile) Upsert(pkg LockPackage) {
+	for i := range lf.Packages {
+		if lf.Packages[i].Name == pkg.Name {
+			lf.Packages[i] = pkg
 			return
 		}
 	}
-	lf.Entries = append(lf.Entries, entry)
+	lf.Packages = append(lf.Packages, pkg)
 }
 
 // Remove deletes the entry for name. Returns true if it was present.
 func (lf *LockFile) Remove(name string) bool {
-	for i, e := range lf.Entries {
-		if e.Name == name {
-			lf.Entries = append(lf.Entries[:i], lf.Entries[i+1:]...)
+	for i, p := range lf.Packages {
+		if p.Name == name {
+			lf.Packages = append(lf.Packages[:i], lf.Packages[i+1:]...)
 			return true
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// Graph returns the pinned dependency DAG, keyed by each package's
+// fully-qualified id (see PackageID) mapping to its own Dependencies —
+// check.go and friends can walk it without re-deriving ids themselves.
+func (lf *LockFile) Graph() map[string][]string {
+	graph := make(map[string][]string, len(lf.Packages))
+	for _, pkg := range lf.Packages {
+		graph[PackageID(pkg.Name, pkg.Version, pkg.Source)] = pkg.Dependencies
+	}
+	return graph
+}
+
+// Verify recomputes the sha256 of each package's cached tarball under
+// cacheDir — named "<name>-<version>.tar.gz", matching registry download
+// URLs (see registry.go) — and reports any mismatch or missing file as
+// drift. Packages with no Checksum (a path dependency, pinned by its
+// source instead) and git dependencies (whose Checksum is a commit SHA,
+// not a tarball hash) are skipped.
+func (lf *LockFile) Verify(cacheDir string) error {
+	var drift []string
+	for _, pkg := range lf.Packages {
+		if pkg.Checksum == "" || strings.HasPrefix(pkg.Source, "git+") {
+			continue
+		}
+		want := strings.TrimPrefix(pkg.Checksum, "sha256:")
+		tarball := filepath.Join(cacheDir, fmt.Sprintf("%s-%s.tar.gz", pkg.Name, pkg.Version))
+		data, err := os.ReadFile(tarball)
+		if err != nil {
+			drift = append(drift, fmt.Sprintf("%s %s: %v", pkg.Name, pkg.Version, err))
+			continue
+		}
+		sum := sha256.Sum256(data)
+		got := hex.EncodeToString(sum[:])
+		if got != want {
+			drift = append(drift, fmt.Sprintf(
+				"%s %s: checksum mismatch (lock file has sha256:%s, cached tarball hashes to sha256:%s)",
+				pkg.Name, pkg.Version, want, got))
+		}
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("lock file verification failed:\n  %s", strings.Join(drift, "\n  "))
+	}
+	return nil
+}
@@ -18,6 +18,10 @@
 //    • m.Libs                  []LibTarget   (from [[lib]])
 //    • m.Dependencies          map[string]DepSpec
 //    • m.DevDependencies       map[string]DepSpec
+//    • m.Features              FeatureSet        (from [features])
+//    • m.ResolveFeatures()     pruned deps + active feature set
+//    • m.TargetDeps            map[predicate]map[string]DepSpec (from [target.*])
+//    • m.DependenciesFor()     base deps + every matching target block
 //    • m.Profile               ProfileConfig
 //    • m.Publish               PublishConfig
 // ─────────────────────────────────────────────────────────────────────────────
@@ -29,6 +33,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 )
 
@@ -77,11 +83,48 @@ type Manifest struct {
 	Dependencies    map[string]DepSpec `json:"-" toml:"dependencies"`
 	DevDependencies map[string]DepSpec `json:"-" toml:"dev-dependencies"`
 
+	// ── v3: [target.<predicate>.dependencies] / [...dev-dependencies] ────
+	// Keyed by the predicate's raw source text (e.g. `cfg(arch = "avr")`),
+	// preserved verbatim so ToTOML round-trips it without reformatting —
+	// see cfg.go for how a predicate string is parsed and evaluated, and
+	// DependenciesFor for how a target block is merged into the base
+	// [dependencies].
+	TargetDeps    map[string]map[string]DepSpec `json:"-" toml:"-"`
+	TargetDevDeps map[string]map[string]DepSpec `json:"-" toml:"-"`
+
+	// ── v3: [features] ────────────────────────────────────────────────────
+	Features FeatureSet `json:"-" toml:"features"`
+
+	// ── v3: [registries] ──────────────────────────────────────────────────
+	Registries map[string]RegistryConfig `json:"-" toml:"registries"`
+
+	// ── v3: [patch."<registry-or-url>"] / [replace] (see overrides.go) ───
+	// Patches substitutes a different fetch source for a dependency, for
+	// any resolved edge whose original source came from the named
+	// registry/URL, keeping whatever SemVer requirement resolved it.
+	// Replacements does the same but keyed by exact "name:version"
+	// (PackageIdSpec-style) rather than by registry, so it only ever
+	// matches one resolved version.
+	Patches      map[string]map[string]DepSpec `json:"-" toml:"-"`
+	Replacements map[string]DepSpec            `json:"-" toml:"-"`
+
 	// ── v3: [profile.release] ─────────────────────────────────────────────
 	Profile ProfileConfig `json:"-" toml:"profile"`
 
 	// ── v3: [publish] ────────────────────────────────────────────────────
 	Publish PublishConfig `json:"-" toml:"publish"`
+
+	// ── v3: [workspace] inheritance (see workspace.go) ───────────────────
+	// WorkspaceInherit records which [package] fields wrote
+	// `<field>.workspace = true` instead of a literal value. Load leaves
+	// them unresolved (zero value); LoadWorkspace fills them in from the
+	// enclosing workspace's [workspace.package].
+	WorkspaceInherit WorkspaceInheritance `json:"-" toml:"-"`
+
+	// rawWorkspace is non-nil when this file itself had a [workspace]
+	// table — set by loadTOML, consumed by findWorkspaceRoot. A manifest
+	// loaded via plain Load()/Find() never needs it.
+	rawWorkspace *rawWorkspace
 }
 
 // ── Sub-types (legacy) ────────────────────────────────────────────────────────
@@ -113,6 +156,10 @@ type ProjectMeta struct {
 	Authors     []string `toml:"authors"`
 	Readme      string   `toml:"readme"`
 	Type        string   `toml:"type"` // "program" | "library"
+	// Theme selects the CLI's color theme (a built-in preset name or a path
+	// to a theme TOML file) for everyone building this project, overridden
+	// per-invocation by --theme. See ui.LoadTheme.
+	Theme string `toml:"theme"`
 }
 
 // BinTarget maps to a [[bin]] entry.
@@ -130,10 +177,71 @@ type LibTarget struct {
 
 // DepSpec can be either a bare version string or a table with extra fields.
 // TOML unmarshalling uses a custom approach — see tomlRawManifest below.
+//
+// A dependency's source is exactly one of three kinds (see source.go's
+// Resolve): a registry lookup (the default — Version, optionally against a
+// named [registries] entry via Registry), a Path dependency, or a Git
+// dependency (Branch/Tag/Rev are mutually exclusive; with none set it locks
+// to the remote's current default-branch HEAD).
 type DepSpec struct {
 	Version  string
 	Features []string
 	Default  bool // default-features
+
+	// Registry names an entry in [registries] this dependency resolves
+	// against instead of the default registry. Ignored for Path/Git deps.
+	Registry string
+
+	// Path, when set, makes this a path dependency — a sibling directory
+	// with its own tsuki-config.toml, resolved relative to the manifest
+	// directory (see Resolve) instead of fetched from a registry.
+	Path string
+
+	// Git, when set, makes this a git dependency, pinned by exactly one of
+	// Branch, Tag, or Rev — or, with none set, the remote's current
+	// default-branch HEAD (see Resolve).
+	Git    string
+	Branch string
+	Tag    string
+	Rev    string
+
+	// Optional marks a dependency that's only pulled in when a [features]
+	// activation string names it — directly ("serde"), explicitly
+	// ("dep:serde"), or via a dep-scoped feature ("serde/derive"). See
+	// ResolveFeatures. A non-optional dependency is always active.
+	Optional bool
+
+	// Workspace is true when the dependency was declared as
+	// `foo.workspace = true` (or `foo = { workspace = true, ... }`) rather
+	// than a literal version — Version is resolved from the enclosing
+	// workspace's [workspace.dependencies] by LoadWorkspace, and Features
+	// declared alongside `workspace = true` are merged into (not replacing)
+	// the pooled dependency's own Features.
+	Workspace bool
+}
+
+// RegistryConfig is one named entry in [registries] — an alternate package
+// index a dependency can opt into via `registry = "name"` instead of the
+// default registry.
+type RegistryConfig struct {
+	Index    string `toml:"index"`
+	TokenEnv string `toml:"token-env"`
+}
+
+// FeatureSet maps to the [features] table: each feature name activates a
+// list of activation strings, Cargo-style — another feature ("foo"), an
+// optional dependency ("serde"), a dep-scoped feature ("serde/derive"), or
+// the explicit "dep:serde" / weak "serde?/derive" forms. See
+// Manifest.ResolveFeatures.
+type FeatureSet map[string][]string
+
+// WorkspaceInheritance records which of a member manifest's [package]
+// fields used `<field>.workspace = true` instead of a literal value.
+type WorkspaceInheritance struct {
+	Version bool
+	Authors bool
+	License bool
+	Edition bool
 }
 
 // ProfileConfig maps to [profile.release].
@@ -143,16 +251,17 @@ type ProfileConfig struct {
 
 // ReleaseProfile holds release-mode compiler settings.
 type ReleaseProfile struct {
-	OptLevel     int    `toml:"opt-level"`
-	LTO          bool   `toml:"lto"`
-	CodegenUnits int    `toml:"codegen-units"`
-	Strip        bool   `toml:"strip"`
+	OptLevel     int  `toml:"opt-level"`
+	LTO          bool `toml:"lto"`
+	CodegenUnits int  `toml:"codegen-units"`
+	Strip        bool `toml:"strip"`
 }
 
 // PublishConfig maps to [publish].
 type PublishConfig struct {
-	Registry string   `toml:"registry"`
-	Targets  []string `toml:"targets"`
+	Registry   string   `toml:"registry"`
+	Targets    []string `toml:"targets"`
+	Installers bool     `toml:"installers"` // also emit a native installer per target (see push.go)
 }
 
 // ── TOML raw intermediate ─────────────────────────────────────────────────────
@@ -163,16 +272,17 @@ type PublishConfig struct {
 
 type tomlRawManifest struct {
 	Package struct {
-		Name        string   `toml:"name"`
-		Version     string   `toml:"version"`
-		Edition     string   `toml:"edition"`
-		Description string   `toml:"description"`
-		License     string   `toml:"license"`
-		Authors     []string `toml:"authors"`
-		Readme      string   `toml:"readme"`
-		Type        string   `toml:"type"`
-		Board       string   `toml:"board"`
-		GoVersion   string   `toml:"go_version"`
+		Name        string      `toml:"name"`
+		Version     interface{} `toml:"version"` // string, or {workspace = true}
+		Edition     interface{} `toml:"edition"` // string, or {workspace = true}
+		Description string      `toml:"description"`
+		License     interface{} `toml:"license"` // string, or {workspace = true}
+		Authors     interface{} `toml:"authors"` // []string, or {workspace = true}
+		Readme      string      `toml:"readme"`
+		Type        string      `toml:"type"`
+		Board       string      `toml:"board"`
+		GoVersion   string      `toml:"go_version"`
+		Theme       string      `toml:"theme"`
 	} `toml:"package"`
 
 	Bins []BinTarget `toml:"bin"`
@@ -181,9 +291,25 @@ type tomlRawManifest struct {
 	RawDeps    map[string]interface{} `toml:"dependencies"`
 	RawDevDeps map[string]interface{} `toml:"dev-dependencies"`
 
+	RawTarget map[string]struct {
+		Dependencies    map[string]interface{} `toml:"dependencies"`
+		DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+	} `toml:"target"`
+
+	Features FeatureSet `toml:"features"`
+
+	Registries map[string]RegistryConfig `toml:"registries"`
+
+	RawPatch   map[string]map[string]interface{} `toml:"patch"`
+	RawReplace map[string]interface{}            `toml:"replace"`
+
 	Profile ProfileConfig `toml:"profile"`
 	Publish PublishConfig `toml:"publish"`
 
+	// Workspace is non-nil only when this file declares a [workspace]
+	// table — see workspace.go.
+	Workspace *rawWorkspace `toml:"workspace"`
+
 	// Legacy build section (ignored in v3 but kept for hybrid files)
 	Build struct {
 		OutputDir  string   `toml:"output_dir"`
@@ -194,7 +320,11 @@ type tomlRawManifest struct {
 	} `toml:"build"`
 }
 
-// parseDeps converts raw TOML dep values into DepSpec.
+// parseDeps converts raw TOML dep values into DepSpec. A dependency written
+// as `foo.workspace = true` (equivalently `foo = { workspace = true }`)
+// decodes to the same map[string]interface{} shape as a table dependency,
+// with a "workspace" key instead of (or alongside) "version" — parseDeps
+// marks DepSpec.Workspace and leaves Version resolution to LoadWorkspace.
 func parseDeps(raw map[string]interface{}) map[string]DepSpec {
 	if raw == nil {
 		return nil
@@ -206,6 +336,9 @@ func parseDeps(raw map[string]interface{}) map[string]DepSpec {
 			out[k] = DepSpec{Version: val}
 		case map[string]interface{}:
 			ds := DepSpec{}
+			if ws, ok := val["workspace"].(bool); ok {
+				ds.Workspace = ws
+			}
 			if ver, ok := val["version"].(string); ok {
 				ds.Version = ver
 			}
@@ -219,12 +352,72 @@ func parseDeps(raw map[string]interface{}) map[string]DepSpec {
 			if df, ok := val["default-features"].(bool); ok {
 				ds.Default = df
 			}
+			if opt, ok := val["optional"].(bool); ok {
+				ds.Optional = opt
+			}
+			if reg, ok := val["registry"].(string); ok {
+				ds.Registry = reg
+			}
+			if p, ok := val["path"].(string); ok {
+				ds.Path = p
+			}
+			if g, ok := val["git"].(string); ok {
+				ds.Git = g
+			}
+			if b, ok := val["branch"].(string); ok {
+				ds.Branch = b
+			}
+			if t, ok := val["tag"].(string); ok {
+				ds.Tag = t
+			}
+			if r, ok := val["rev"].(string); ok {
+				ds.Rev = r
+			}
 			out[k] = ds
 		}
 	}
 	return out
 }
 
+// inheritableString resolves a [package] field that may be either a literal
+// TOML string or `<field>.workspace = true`. ok is false only when raw is
+// some other, unexpected shape.
+func inheritableString(raw interface{}) (value string, isWorkspace bool, ok bool) {
+	switch v := raw.(type) {
+	case nil:
+		return "", false, true
+	case string:
+		return v, false, true
+	case map[string]interface{}:
+		if ws, _ := v["workspace"].(bool); ws {
+			return "", true, true
+		}
+	}
+	return "", false, false
+}
+
+// inheritableStringSlice is inheritableString for array-valued fields
+// (currently just [package].authors).
+func inheritableStringSlice(raw interface{}) (value []string, isWorkspace bool, ok bool) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, false, true
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out, false, true
+	case map[string]interface{}:
+		if ws, _ := v["workspace"].(bool); ws {
+			return nil, true, true
+		}
+	}
+	return nil, false, false
+}
+
 // ── Loaders ───────────────────────────────────────────────────────────────────
 
 // Load reads the manifest from dir, preferring tsuki-config.toml over
@@ -258,9 +451,26 @@ func loadTOML(path string) (*Manifest, error) {
 
 	m := &Manifest{}
 
+	version, versionInherit, ok := inheritableString(raw.Package.Version)
+	if !ok {
+		return nil, fmt.Errorf("parsing %s: [package].version must be a string or { workspace = true }", path)
+	}
+	edition, editionInherit, ok := inheritableString(raw.Package.Edition)
+	if !ok {
+		return nil, fmt.Errorf("parsing %s: [package].edition must be a string or { workspace = true }", path)
+	}
+	license, licenseInherit, ok := inheritableString(raw.Package.License)
+	if !ok {
+		return nil, fmt.Errorf("parsing %s: [package].license must be a string or { workspace = true }", path)
+	}
+	authors, authorsInherit, ok := inheritableStringSlice(raw.Package.Authors)
+	if !ok {
+		return nil, fmt.Errorf("parsing %s: [package].authors must be a string array or { workspace = true }", path)
+	}
+
 	// Identity — prefer [package] fields, but also check top-level for compat.
 	m.Name = raw.Package.Name
-	m.Version = raw.Package.Version
+	m.Version = version
 	m.Description = raw.Package.Description
 	m.Board = raw.Package.Board
 	m.GoVersion = raw.Package.GoVersion
@@ -268,23 +478,55 @@ func loadTOML(path string) (*Manifest, error) {
 	// v3 sub-structs
 	m.Project = ProjectMeta{
 		Name:        raw.Package.Name,
-		Version:     raw.Package.Version,
-		Edition:     raw.Package.Edition,
+		Version:     version,
+		Edition:     edition,
 		Description: raw.Package.Description,
-		License:     raw.Package.License,
-		Authors:     raw.Package.Authors,
+		License:     license,
+		Authors:     authors,
 		Readme:      raw.Package.Readme,
 		Type:        raw.Package.Type,
+		Theme:       raw.Package.Theme,
+	}
+	m.WorkspaceInherit = WorkspaceInheritance{
+		Version: versionInherit,
+		Authors: authorsInherit,
+		License: licenseInherit,
+		Edition: editionInherit,
 	}
+	m.rawWorkspace = raw.Workspace
 
-	m.Bins    = raw.Bins
-	m.Libs    = raw.Libs
+	m.Bins = raw.Bins
+	m.Libs = raw.Libs
 	m.Profile = raw.Profile
 	m.Publish = raw.Publish
 
-	m.Dependencies    = parseDeps(raw.RawDeps)
+	m.Dependencies = parseDeps(raw.RawDeps)
 	m.DevDependencies = parseDeps(raw.RawDevDeps)
 
+	if len(raw.RawTarget) > 0 {
+		m.TargetDeps = make(map[string]map[string]DepSpec, len(raw.RawTarget))
+		m.TargetDevDeps = make(map[string]map[string]DepSpec, len(raw.RawTarget))
+		for predicate, block := range raw.RawTarget {
+			if deps := parseDeps(block.Dependencies); len(deps) > 0 {
+				m.TargetDeps[predicate] = deps
+			}
+			if deps := parseDeps(block.DevDependencies); len(deps) > 0 {
+				m.TargetDevDeps[predicate] = deps
+			}
+		}
+	}
+
+	m.Features = raw.Features
+	m.Registries = raw.Registries
+
+	if len(raw.RawPatch) > 0 {
+		m.Patches = make(map[string]map[string]DepSpec, len(raw.RawPatch))
+		for registryID, deps := range raw.RawPatch {
+			m.Patches[registryID] = parseDeps(deps)
+		}
+	}
+	m.Replacements = parseDeps(raw.RawReplace)
+
 	// Synthesise legacy Build from TOML [build] section or sensible defaults.
 	m.Build = BuildConfig{
 		OutputDir:  raw.Build.OutputDir,
@@ -357,6 +599,60 @@ func (m *Manifest) SaveLegacy(dir string) error {
 	return os.WriteFile(filepath.Join(dir, JSONFileName), append(data, '\n'), 0644)
 }
 
+// depToTOML renders one dependency's TOML value — a bare version string in
+// the common case, or a table when it carries features, is optional, names
+// a registry, is a path or git dependency, or was declared as
+// `foo.workspace = true` (preserved as such rather than expanded to its
+// resolved version, so re-saving a member manifest doesn't bake the pooled
+// version in and desync it from the workspace).
+func depToTOML(dep DepSpec) string {
+	if dep.Workspace {
+		if len(dep.Features) == 0 {
+			return "{ workspace = true }"
+		}
+		return fmt.Sprintf("{ workspace = true, features = [%s] }", tomlQuotedList(dep.Features))
+	}
+
+	var parts []string
+	switch {
+	case dep.Path != "":
+		parts = append(parts, fmt.Sprintf("path = %q", dep.Path))
+	case dep.Git != "":
+		parts = append(parts, fmt.Sprintf("git = %q", dep.Git))
+		switch {
+		case dep.Branch != "":
+			parts = append(parts, fmt.Sprintf("branch = %q", dep.Branch))
+		case dep.Tag != "":
+			parts = append(parts, fmt.Sprintf("tag = %q", dep.Tag))
+		case dep.Rev != "":
+			parts = append(parts, fmt.Sprintf("rev = %q", dep.Rev))
+		}
+	default:
+		if !dep.Optional && dep.Registry == "" && len(dep.Features) == 0 {
+			return fmt.Sprintf("%q", dep.Version)
+		}
+		parts = append(parts, fmt.Sprintf("version = %q", dep.Version))
+		if dep.Registry != "" {
+			parts = append(parts, fmt.Sprintf("registry = %q", dep.Registry))
+		}
+	}
+	if len(dep.Features) > 0 {
+		parts = append(parts, fmt.Sprintf("features = [%s]", tomlQuotedList(dep.Features)))
+	}
+	if dep.Optional {
+		parts = append(parts, "optional = true")
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+func tomlQuotedList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, it := range items {
+		quoted[i] = fmt.Sprintf("%q", it)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // ToTOML serialises the manifest to a readable tsuki-config.toml string.
 func (m *Manifest) ToTOML() string {
 	var sb strings.Builder
@@ -369,8 +665,14 @@ func (m *Manifest) ToTOML() string {
 		}
 	}
 	writeKV("name", m.Name)
-	writeKV("version", m.Version)
-	if m.Project.Edition != "" {
+	if m.WorkspaceInherit.Version {
+		sb.WriteString("version.workspace     = true\n")
+	} else {
+		writeKV("version", m.Version)
+	}
+	if m.WorkspaceInherit.Edition {
+		sb.WriteString("edition.workspace     = true\n")
+	} else if m.Project.Edition != "" {
 		writeKV("edition", m.Project.Edition)
 	}
 	writeKV("description", m.Description)
@@ -378,7 +680,14 @@ func (m *Manifest) ToTOML() string {
 	if m.Project.Type != "" {
 		writeKV("type", m.Project.Type)
 	}
-	if len(m.Project.Authors) > 0 {
+	if m.WorkspaceInherit.License {
+		sb.WriteString("license.workspace     = true\n")
+	} else if m.Project.License != "" {
+		writeKV("license", m.Project.License)
+	}
+	if m.WorkspaceInherit.Authors {
+		sb.WriteString("authors.workspace     = true\n")
+	} else if len(m.Project.Authors) > 0 {
 		sb.WriteString(fmt.Sprintf("%-12s = [", "authors"))
 		for i, a := range m.Project.Authors {
 			if i > 0 {
@@ -407,22 +716,108 @@ func (m *Manifest) ToTOML() string {
 	if len(m.Dependencies) > 0 {
 		sb.WriteString("\n[dependencies]\n")
 		for name, dep := range m.Dependencies {
-			if len(dep.Features) == 0 {
-				sb.WriteString(fmt.Sprintf("%-14s = %q\n", name, dep.Version))
-			} else {
-				sb.WriteString(fmt.Sprintf(
-					"%-14s = { version = %q, features = [%s] }\n",
-					name, dep.Version,
-					`"`+strings.Join(dep.Features, `", "`)+`"`,
-				))
-			}
+			sb.WriteString(fmt.Sprintf("%-14s = %s\n", name, depToTOML(dep)))
 		}
 	}
 
 	if len(m.DevDependencies) > 0 {
 		sb.WriteString("\n[dev-dependencies]\n")
 		for name, dep := range m.DevDependencies {
-			sb.WriteString(fmt.Sprintf("%-14s = %q\n", name, dep.Version))
+			sb.WriteString(fmt.Sprintf("%-14s = %s\n", name, depToTOML(dep)))
+		}
+	}
+
+	if len(m.TargetDeps) > 0 || len(m.TargetDevDeps) > 0 {
+		predicates := make(map[string]bool, len(m.TargetDeps)+len(m.TargetDevDeps))
+		for p := range m.TargetDeps {
+			predicates[p] = true
+		}
+		for p := range m.TargetDevDeps {
+			predicates[p] = true
+		}
+		for _, predicate := range sortedKeys(predicates) {
+			if deps := m.TargetDeps[predicate]; len(deps) > 0 {
+				sb.WriteString(fmt.Sprintf("\n[target.%q.dependencies]\n", predicate))
+				names := make([]string, 0, len(deps))
+				for name := range deps {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					sb.WriteString(fmt.Sprintf("%-14s = %s\n", name, depToTOML(deps[name])))
+				}
+			}
+			if deps := m.TargetDevDeps[predicate]; len(deps) > 0 {
+				sb.WriteString(fmt.Sprintf("\n[target.%q.dev-dependencies]\n", predicate))
+				names := make([]string, 0, len(deps))
+				for name := range deps {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					sb.WriteString(fmt.Sprintf("%-14s = %s\n", name, depToTOML(deps[name])))
+				}
+			}
+		}
+	}
+
+	if len(m.Features) > 0 {
+		sb.WriteString("\n[features]\n")
+		names := make([]string, 0, len(m.Features))
+		for name := range m.Features {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("%-14s = [%s]\n", name, tomlQuotedList(m.Features[name])))
+		}
+	}
+
+	if len(m.Registries) > 0 {
+		names := make([]string, 0, len(m.Registries))
+		for name := range m.Registries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rc := m.Registries[name]
+			sb.WriteString(fmt.Sprintf("\n[registries.%s]\n", name))
+			sb.WriteString(fmt.Sprintf("index = %q\n", rc.Index))
+			if rc.TokenEnv != "" {
+				sb.WriteString(fmt.Sprintf("token-env = %q\n", rc.TokenEnv))
+			}
+		}
+	}
+
+	if len(m.Patches) > 0 {
+		registries := make([]string, 0, len(m.Patches))
+		for r := range m.Patches {
+			registries = append(registries, r)
+		}
+		sort.Strings(registries)
+		for _, r := range registries {
+			sb.WriteString(fmt.Sprintf("\n[patch.%q]\n", r))
+			deps := m.Patches[r]
+			names := make([]string, 0, len(deps))
+			for name := range deps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				sb.WriteString(fmt.Sprintf("%-14s = %s\n", name, depToTOML(deps[name])))
+			}
+		}
+	}
+
+	if len(m.Replacements) > 0 {
+		sb.WriteString("\n[replace]\n")
+		ids := make([]string, 0, len(m.Replacements))
+		for id := range m.Replacements {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			sb.WriteString(fmt.Sprintf("%-16q = %s\n", id, depToTOML(m.Replacements[id])))
 		}
 	}
 
@@ -437,14 +832,23 @@ func (m *Manifest) ToTOML() string {
 
 // ── Search ────────────────────────────────────────────────────────────────────
 
-// Find searches upward from startDir for a tsuki-config.toml or tsuki_package.json.
-func Find(startDir string) (string, *Manifest, error) {
-	dir := startDir
+// Find searches upward from startDir for a tsuki-config.toml or
+// tsuki_package.json, and also returns the enclosing workspace (nil if the
+// member isn't part of one) so callers don't have to call LoadWorkspace
+// separately to e.g. resolve the shared lock file.
+func Find(startDir string) (dir string, m *Manifest, ws *Workspace, err error) {
+	dir = startDir
 	for {
 		for _, name := range []string{TOMLFileName, JSONFileName} {
-			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
-				m, err := Load(dir)
-				return dir, m, err
+			if _, statErr := os.Stat(filepath.Join(dir, name)); statErr == nil {
+				m, err = Load(dir)
+				if err == nil {
+					ws, _ = LoadWorkspace(dir) // no enclosing workspace is not an error
+					if ws != nil {
+						resolveWorkspaceInheritance(m, &ws.Config)
+					}
+				}
+				return dir, m, ws, err
 			}
 		}
 		parent := filepath.Dir(dir)
@@ -453,7 +857,7 @@ func Find(startDir string) (string, *Manifest, error) {
 		}
 		dir = parent
 	}
-	return "", nil, fmt.Errorf(
+	return "", nil, nil, fmt.Errorf(
 		"no %s or %s found (searched upward from %s)",
 		TOMLFileName, JSONFileName, startDir,
 	)
@@ -524,4 +928,206 @@ func (m *Manifest) RemovePackage(name string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// ── Feature resolution ───────────────────────────────────────────────────────
+
+// ResolveFeatures expands root — the explicitly requested feature names —
+// into the full set of active features and the pruned set of dependencies
+// they activate, the way Cargo resolves a [features] table. An activation
+// string (appearing either in root or on the right-hand side of a
+// [features] entry) is one of:
+//
+//	"foo"          another feature declared in this manifest
+//	"serde"        an optional dependency by that name — enables it, and
+//	               (Cargo-style) implies a feature of the same name
+//	"dep:serde"    enables the optional dependency "serde" without also
+//	               implying a same-named feature
+//	"serde/derive" activates feature "derive" on dependency "serde" and
+//	               enables "serde" if it's optional
+//	"serde?/derive" the weak form — applies "derive" to "serde" only if
+//	               something else ends up activating it
+//
+// "default" seeds the active set automatically unless root contains the
+// literal "no-default-features" (this manifest's equivalent of Cargo's
+// --no-default-features flag).
+//
+// Build/check callers use activeDeps in place of m.Dependencies once a
+// project declares [features] — it's the same map shape, just pruned down
+// to what's actually enabled, with each DepSpec.Features expanded to
+// include whatever dep-scoped activations named it.
+func (m *Manifest) ResolveFeatures(root []string) (activeDeps map[string]DepSpec, activeFeats map[string]bool, err error) {
+	noDefault := false
+	requested := make([]string, 0, len(root))
+	for _, f := range root {
+		if f == "no-default-features" {
+			noDefault = true
+			continue
+		}
+		requested = append(requested, f)
+	}
+	if !noDefault {
+		if _, ok := m.Features["default"]; ok {
+			requested = append(requested, "default")
+		}
+	}
+
+	activeFeats = make(map[string]bool)
+	activatedDeps := make(map[string]bool)              // optional deps turned on
+	depFeatures := make(map[string]map[string]bool)     // dep -> dep-scoped features requested of it
+	weakDepFeatures := make(map[string]map[string]bool) // dep -> weak ("?/") dep-scoped features
+	visiting := make(map[string]bool)
+
+	var activate func(name string) error
+	activate = func(name string) error {
+		if idx := strings.Index(name, "/"); idx >= 0 {
+			depName, depFeat := name[:idx], name[idx+1:]
+			if strings.HasSuffix(depName, "?") {
+				depName = strings.TrimSuffix(depName, "?")
+				if weakDepFeatures[depName] == nil {
+					weakDepFeatures[depName] = make(map[string]bool)
+				}
+				weakDepFeatures[depName][depFeat] = true
+				return nil
+			}
+			// A non-optional dependency is always active; only an optional
+			// one (or a same-named feature) has anything to enable.
+			if dep, found := m.Dependencies[depName]; !found || dep.Optional {
+				if err := activate(depName); err != nil {
+					return err
+				}
+			}
+			if depFeatures[depName] == nil {
+				depFeatures[depName] = make(map[string]bool)
+			}
+			depFeatures[depName][depFeat] = true
+			return nil
+		}
+
+		if strings.HasPrefix(name, "dep:") {
+			depName := strings.TrimPrefix(name, "dep:")
+			dep, found := m.Dependencies[depName]
+			if !found {
+				return fmt.Errorf("feature activation %q: no such dependency %q", name, depName)
+			}
+			if !dep.Optional {
+				return fmt.Errorf("feature activation %q: dependency %q is not optional", name, depName)
+			}
+			activatedDeps[depName] = true
+			return nil
+		}
+
+		if activeFeats[name] {
+			return nil
+		}
+		if activation, ok := m.Features[name]; ok {
+			if visiting[name] {
+				return fmt.Errorf("feature cycle detected at %q", name)
+			}
+			visiting[name] = true
+			activeFeats[name] = true
+			for _, next := range activation {
+				if err := activate(next); err != nil {
+					return err
+				}
+			}
+			delete(visiting, name)
+			return nil
+		}
+
+		// Not a declared feature — Cargo implicitly defines a same-named
+		// feature for every optional dependency, so a bare "serde" works
+		// the same as "dep:serde" would, just without the explicit form's
+		// ability to skip the implied feature.
+		if dep, found := m.Dependencies[name]; found && dep.Optional {
+			activeFeats[name] = true
+			activatedDeps[name] = true
+			return nil
+		}
+
+		return fmt.Errorf("unknown feature %q", name)
+	}
+
+	for _, f := range requested {
+		if err := activate(f); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	activeDeps = make(map[string]DepSpec)
+	for name, dep := range m.Dependencies {
+		if dep.Optional && !activatedDeps[name] {
+			continue // optional dependency never activated — pruned
+		}
+		resolved := dep
+		featSet := make(map[string]bool, len(dep.Features))
+		for _, f := range dep.Features {
+			featSet[f] = true
+		}
+		for f := range depFeatures[name] {
+			featSet[f] = true
+		}
+		for f := range weakDepFeatures[name] {
+			featSet[f] = true
+		}
+		resolved.Features = sortedKeys(featSet)
+		activeDeps[name] = resolved
+	}
+
+	return activeDeps, activeFeats, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ── Target-conditional dependencies ──────────────────────────────────────────
+
+// DependenciesFor returns the base [dependencies] merged with every
+// [target.<predicate>.dependencies] block whose predicate evaluates true
+// against board (and arch, when the caller already knows it — an empty
+// arch falls back to boardRegistry's entry for board, so check.go/build.go
+// can call DependenciesFor(m.Board, "") and get the right answer for any
+// recognized board without looking up its architecture themselves).
+//
+// A target block wins over the base [dependencies] for a name declared in
+// both, the same way a more specific Cargo target dependency overrides the
+// general one. An unparsable predicate is skipped rather than failing the
+// whole merge — it was already validated at `tsuki check` time.
+//
+// env also always carries "os" = runtime.GOOS, so a project can gate a
+// host-tooling dependency on cfg(os = "linux") the same way LURE picks a
+// per-distro package variant, independent of the firmware board's own
+// "arch"/"family"/"mcu" facts.
+func (m *Manifest) DependenciesFor(board, arch string) map[string]DepSpec {
+	env := boardEnv(board)
+	if arch != "" {
+		env["arch"] = arch
+	}
+	env["os"] = runtime.GOOS
+
+	merged := make(map[string]DepSpec, len(m.Dependencies))
+	for name, dep := range m.Dependencies {
+		merged[name] = dep
+	}
+
+	for predicate, deps := range m.TargetDeps {
+		pred, err := ParsePredicate(predicate)
+		if err != nil || !pred.Eval(env) {
+			continue
+		}
+		for name, dep := range deps {
+			merged[name] = dep
+		}
+	}
+
+	return merged
+}
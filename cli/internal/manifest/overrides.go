@@ -0,0 +1,124 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: manifest :: overrides  —  [patch] and [replace]
+//
+//  [patch."<registry-or-url>"] substitutes a different fetch source for a
+//  dependency, for any resolved edge whose original source came from that
+//  registry or git URL — the SemVer requirement that resolved it stays put,
+//  only where it's fetched from changes. [replace] does the same thing but
+//  keyed by an exact "name:version" (PackageIdSpec-style) instead of a
+//  registry, so it only ever matches one resolved version.
+//
+//  Cargo treats an unmatched patch/replace entry as a hard build error;
+//  ApplyOverrides reports it as a Warning instead, since it has no way to
+//  know whether its caller wants that to be fatal — a caller that does can
+//  just check len(warnings) > 0 itself.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Warning is a non-fatal message from a manifest operation — currently
+// just ApplyOverrides reporting a [patch]/[replace] entry that never
+// matched an edge in the resolved graph.
+type Warning struct {
+	Message string
+}
+
+// ApplyOverrides walks a resolved dependency graph and rewrites any edge
+// matched by [replace] (checked first — it's keyed to one exact version,
+// more specific than a registry-wide [patch]) or [patch], preserving each
+// LockPackage's pinned Version but substituting the override's source.
+func (m *Manifest) ApplyOverrides(graph []LockPackage) ([]LockPackage, []Warning) {
+	used := make(map[string]bool)
+
+	out := make([]LockPackage, len(graph))
+	copy(out, graph)
+
+	for i := range out {
+		pkg := &out[i]
+
+		replaceKey := fmt.Sprintf("%s:%s", pkg.Name, pkg.Version)
+		if dep, ok := m.Replacements[replaceKey]; ok {
+			applyOverrideSource(pkg, dep)
+			used["replace:"+replaceKey] = true
+			continue
+		}
+
+		for registryID, deps := range m.Patches {
+			dep, ok := deps[pkg.Name]
+			if !ok || !sourceMatchesRegistry(pkg.Source, registryID) {
+				continue
+			}
+			applyOverrideSource(pkg, dep)
+			used["patch:"+registryID+":"+pkg.Name] = true
+			break
+		}
+	}
+
+	var warnings []Warning
+	for replaceKey := range m.Replacements {
+		if !used["replace:"+replaceKey] {
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("[replace] entry %q never matched a resolved dependency", replaceKey),
+			})
+		}
+	}
+	for registryID, deps := range m.Patches {
+		for name := range deps {
+			if !used["patch:"+registryID+":"+name] {
+				warnings = append(warnings, Warning{
+					Message: fmt.Sprintf("[patch.%s] entry %q never matched a resolved dependency", registryID, name),
+				})
+			}
+		}
+	}
+
+	return out, warnings
+}
+
+// sourceMatchesRegistry reports whether a resolved LockPackage.Source
+// (see source.go's ResolvedSource.Lock) came from registryID — the
+// registry id for a "registry+" source, or the literal URL for a "git+"
+// one, matching how a [patch] table is keyed.
+func sourceMatchesRegistry(source, registryID string) bool {
+	switch {
+	case strings.HasPrefix(source, "registry+"):
+		rest := strings.TrimPrefix(source, "registry+")
+		if idx := strings.Index(rest, "@"); idx >= 0 {
+			rest = rest[:idx]
+		}
+		return rest == registryID
+	case strings.HasPrefix(source, "git+"):
+		rest := strings.TrimPrefix(source, "git+")
+		if idx := strings.Index(rest, "#"); idx >= 0 {
+			rest = rest[:idx]
+		}
+		return rest == registryID
+	default:
+		return false
+	}
+}
+
+// applyOverrideSource rewrites pkg's source to dep's, preserving pkg's
+// pinned Version — an override changes where a dependency is fetched
+// from, not what version satisfied the original requirement. The old
+// Checksum no longer applies to whatever the new source provides, so it's
+// cleared; a git override with no explicit ref is resolved to a commit
+// SHA immediately, same as any other git dependency (see Resolve).
+func applyOverrideSource(pkg *LockPackage, dep DepSpec) {
+	resolved, err := Resolve(dep, "")
+	if err != nil {
+		// The override itself doesn't resolve (e.g. an unreachable git
+		// remote) — leave pkg's known-good source alone.
+		return
+	}
+	pkg.Source = resolved.Lock
+	pkg.Checksum = ""
+	if resolved.Kind == SourceGit {
+		pkg.Checksum = resolved.CommitSHA
+	}
+}
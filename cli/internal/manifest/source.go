@@ -0,0 +1,149 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: manifest :: source  —  resolving a DepSpec to a concrete source
+//
+//  A dependency's source is exactly one of three kinds, mirroring Cargo:
+//    • registry — the default, or a named entry from [registries]
+//    • path     — a sibling directory with its own tsuki-config.toml
+//    • git      — a remote repo, pinned by branch/tag/rev, or (with none
+//                 of those set) the remote's current default-branch HEAD
+//
+//  Resolve pins a DepSpec to a ResolvedSource whose Lock string is what
+//  LockPackage.Source records (see lock.go) — reproducing it byte-identically
+//  on another machine without re-querying anything, except for an unpinned
+//  git dependency: "whatever's on the default branch" is only as
+//  reproducible as that branch is static, so resolving it again always
+//  means asking the remote.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package manifest
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DepSourceKind enumerates the ways a dependency's source can be specified.
+type DepSourceKind string
+
+const (
+	SourceRegistry DepSourceKind = "registry"
+	SourcePath     DepSourceKind = "path"
+	SourceGit      DepSourceKind = "git"
+)
+
+// ResolvedSource is a DepSpec pinned to a concrete, reproducible point.
+// Lock is the exact string LockPackage.Source should record, in the form
+// "<kind>+<locator>":
+//
+//	"registry+<registry-id>@<version>"
+//	"path+file://<absolute, cleaned path>"
+//	"git+<url>#<commit sha>"
+type ResolvedSource struct {
+	Kind DepSourceKind
+	Lock string
+
+	Path      string // absolute, normalized — SourcePath only
+	CommitSHA string // SourceGit only
+}
+
+// Resolve determines dep's concrete source.
+//
+// Path dependencies are normalized relative to workspaceRoot — the
+// directory of the manifest that declared them, or the enclosing
+// workspace's root for a dep resolved via `foo.workspace = true` — since a
+// relative path written in one manifest must still resolve correctly when
+// a different member inherits it.
+//
+// Git dependencies with none of Branch/Tag/Rev set lock to the remote's
+// current default-branch HEAD via `git ls-remote`; Branch/Tag/Rev are
+// mutually exclusive.
+func Resolve(dep DepSpec, workspaceRoot string) (ResolvedSource, error) {
+	switch {
+	case dep.Path != "" && dep.Git != "":
+		return ResolvedSource{}, fmt.Errorf("dependency specifies both a path and a git source")
+
+	case dep.Path != "":
+		abs := dep.Path
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(workspaceRoot, dep.Path)
+		}
+		abs = filepath.Clean(abs)
+		return ResolvedSource{
+			Kind: SourcePath,
+			Path: abs,
+			Lock: "path+file://" + filepath.ToSlash(abs),
+		}, nil
+
+	case dep.Git != "":
+		if err := checkExclusiveRef(dep); err != nil {
+			return ResolvedSource{}, err
+		}
+		sha := dep.Rev
+		if sha == "" {
+			ref := dep.Branch
+			if dep.Tag != "" {
+				ref = dep.Tag
+			}
+			resolved, err := resolveGitRef(dep.Git, ref)
+			if err != nil {
+				return ResolvedSource{}, fmt.Errorf("resolving git dependency %s: %w", dep.Git, err)
+			}
+			sha = resolved
+		}
+		return ResolvedSource{
+			Kind:      SourceGit,
+			CommitSHA: sha,
+			Lock:      fmt.Sprintf("git+%s#%s", dep.Git, sha),
+		}, nil
+
+	default:
+		registryID := dep.Registry
+		if registryID == "" {
+			registryID = "default"
+		}
+		return ResolvedSource{
+			Kind: SourceRegistry,
+			Lock: fmt.Sprintf("registry+%s@%s", registryID, dep.Version),
+		}, nil
+	}
+}
+
+// checkExclusiveRef rejects a git dependency naming more than one of
+// branch/tag/rev — like Cargo, since they're different ways of saying the
+// same thing and specifying two is almost always a mistake.
+func checkExclusiveRef(dep DepSpec) error {
+	set := 0
+	for _, v := range []string{dep.Branch, dep.Tag, dep.Rev} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("git dependency %s: branch, tag, and rev are mutually exclusive", dep.Git)
+	}
+	return nil
+}
+
+// resolveGitRef resolves ref (a branch or tag name, or "" for the default
+// branch) against a remote git URL to a commit SHA, via `git ls-remote`.
+func resolveGitRef(url, ref string) (string, error) {
+	target := ref
+	if target == "" {
+		target = "HEAD"
+	}
+	out, err := exec.Command("git", "ls-remote", url, target).Output()
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(out))
+	if line == "" {
+		return "", fmt.Errorf("no ref %q found at %s", target, url)
+	}
+	fields := strings.Fields(strings.SplitN(line, "\n", 2)[0])
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected `git ls-remote` output for %s", url)
+	}
+	return fields[0], nil
+}
@@ -0,0 +1,281 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: manifest :: workspace  —  [workspace] tables, Cargo-style
+//
+//  A workspace root is a tsuki-config.toml with a [workspace] table. It may
+//  also declare its own [package] (the root is then a member too, the way
+//  a Cargo workspace root can be both), or it may be "virtual" — a
+//  [workspace] with no [package] at all, existing only to group members:
+//
+//    [workspace]
+//    members = ["crates/*", "firmware/blinky"]
+//    exclude = ["crates/experimental"]
+//
+//    [workspace.package]
+//    version = "0.4.0"
+//    authors = ["tsuki-team"]
+//    edition = "2024"
+//
+//    [workspace.dependencies]
+//    ws2812 = "1.0.0"
+//
+//  A member opts into the shared values instead of repeating them:
+//
+//    [package]
+//    name    = "blinky"
+//    version.workspace = true
+//
+//    [dependencies]
+//    ws2812.workspace = true
+//
+//  The lock file lives at the workspace root (see lock.go) and pins the
+//  union of every member's transitive graph — members don't get their own.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// WorkspacePackageDefaults maps to [workspace.package] — values a member
+// inherits with `<field>.workspace = true` instead of repeating them.
+type WorkspacePackageDefaults struct {
+	Version string   `toml:"version"`
+	Authors []string `toml:"authors"`
+	License string   `toml:"license"`
+	Edition string   `toml:"edition"`
+}
+
+// rawWorkspace is the [workspace] table as decoded straight off TOML, before
+// RawDependencies is resolved into DepSpecs.
+type rawWorkspace struct {
+	Members         []string                 `toml:"members"`
+	Exclude         []string                 `toml:"exclude"`
+	Package         WorkspacePackageDefaults `toml:"package"`
+	RawDependencies map[string]interface{}   `toml:"dependencies"`
+}
+
+// WorkspaceConfig is the resolved [workspace] table.
+type WorkspaceConfig struct {
+	Members      []string
+	Exclude      []string
+	Package      WorkspacePackageDefaults
+	Dependencies map[string]DepSpec
+}
+
+// Workspace is a loaded workspace root plus its resolved members.
+type Workspace struct {
+	Dir    string
+	Config WorkspaceConfig
+
+	// Root is the root manifest when the workspace root also declares a
+	// [package] of its own; nil for a virtual manifest (members-only).
+	Root *Manifest
+
+	// Members holds every resolved member manifest — Root included, when
+	// the workspace root is itself a package.
+	Members []*Manifest
+}
+
+// LoadWorkspace discovers the workspace enclosing dir by walking upward for
+// a tsuki-config.toml with a [workspace] table, expands Members (glob
+// patterns like "crates/*", relative to the workspace root) minus Exclude,
+// loads every resulting member manifest, and resolves any
+// `<field>.workspace = true` markers against [workspace.package] /
+// [workspace.dependencies].
+func LoadWorkspace(dir string) (*Workspace, error) {
+	rootDir, root, raw, err := findWorkspaceRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{
+		Dir: rootDir,
+		Config: WorkspaceConfig{
+			Members:      raw.Members,
+			Exclude:      raw.Exclude,
+			Package:      raw.Package,
+			Dependencies: parseDeps(raw.RawDependencies),
+		},
+	}
+
+	memberDirs, err := expandMembers(rootDir, raw.Members, raw.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	// The root counts as a member in its own right only if it declares a
+	// real [package] — a virtual manifest has nothing to resolve or build.
+	if root.Name != "" {
+		ws.Root = root
+		resolveWorkspaceInheritance(ws.Root, &ws.Config)
+		ws.Members = append(ws.Members, ws.Root)
+	}
+
+	for _, mdir := range memberDirs {
+		if mdir == rootDir {
+			continue // already added as ws.Root above
+		}
+		m, err := Load(mdir)
+		if err != nil {
+			return nil, fmt.Errorf("loading workspace member %s: %w", mdir, err)
+		}
+		resolveWorkspaceInheritance(m, &ws.Config)
+		ws.Members = append(ws.Members, m)
+	}
+
+	return ws, nil
+}
+
+// findWorkspaceRoot walks upward from dir looking for a tsuki-config.toml
+// with a [workspace] table, returning its directory, the manifest loaded
+// from that same file (possibly a virtual manifest with no [package]), and
+// the raw [workspace] table.
+func findWorkspaceRoot(dir string) (string, *Manifest, *rawWorkspace, error) {
+	d := dir
+	for {
+		path := filepath.Join(d, TOMLFileName)
+		if _, err := os.Stat(path); err == nil {
+			m, err := loadTOML(path)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			if m.rawWorkspace != nil {
+				return d, m, m.rawWorkspace, nil
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	return "", nil, nil, fmt.Errorf(
+		"no [workspace] table found (searched upward from %s)", dir)
+}
+
+// expandMembers resolves a [workspace] members list against root — each
+// entry is a glob pattern (a literal path like "firmware/blinky" matches
+// itself) — dropping anything that also matches an exclude pattern, or
+// that isn't a directory containing a tsuki-config.toml.
+func expandMembers(root string, members, exclude []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var dirs []string
+
+	for _, pattern := range members {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("bad workspace member pattern %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(m, TOMLFileName)); err != nil {
+				continue
+			}
+			if isExcluded(root, m, exclude) {
+				continue
+			}
+			if !seen[m] {
+				seen[m] = true
+				dirs = append(dirs, m)
+			}
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// isExcluded reports whether dir matches one of the [workspace] exclude
+// glob patterns (resolved the same way members are).
+func isExcluded(root, dir string, exclude []string) bool {
+	for _, pattern := range exclude {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			continue
+		}
+		for _, m := range matches {
+			if m == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveWorkspaceInheritance fills in any `<field>.workspace = true`
+// markers loadTOML recorded on m, using ws's [workspace.package] /
+// [workspace.dependencies] as the source of truth.
+func resolveWorkspaceInheritance(m *Manifest, ws *WorkspaceConfig) {
+	if m.WorkspaceInherit.Version {
+		m.Version = ws.Package.Version
+		m.Project.Version = ws.Package.Version
+	}
+	if m.WorkspaceInherit.Authors {
+		m.Project.Authors = ws.Package.Authors
+	}
+	if m.WorkspaceInherit.License {
+		m.Project.License = ws.Package.License
+	}
+	if m.WorkspaceInherit.Edition {
+		m.Project.Edition = ws.Package.Edition
+	}
+
+	resolveWorkspaceDeps(m.Dependencies, ws.Dependencies)
+	resolveWorkspaceDeps(m.DevDependencies, ws.Dependencies)
+
+	// Keep the legacy Packages view (build.go / check.go) in sync now that
+	// any workspace deps have a resolved Version.
+	m.Packages = m.Packages[:0]
+	for name, dep := range m.Dependencies {
+		m.Packages = append(m.Packages, Package{Name: name, Version: dep.Version})
+	}
+}
+
+// resolveWorkspaceDeps fills in Version (and merges Features) for every dep
+// marked Workspace, from the pool's matching entry. A name with no matching
+// pool entry is left as-is — Resolve/pkgmgr will report it as not found,
+// same as any other bad dependency.
+func resolveWorkspaceDeps(deps map[string]DepSpec, pool map[string]DepSpec) {
+	for name, dep := range deps {
+		if !dep.Workspace {
+			continue
+		}
+		base, ok := pool[name]
+		if !ok {
+			continue
+		}
+		resolved := base
+		resolved.Workspace = true
+		if len(dep.Features) > 0 {
+			resolved.Features = mergeFeatures(base.Features, dep.Features)
+		}
+		deps[name] = resolved
+	}
+}
+
+// mergeFeatures unions base and extra, preserving base's order and
+// dropping duplicates.
+func mergeFeatures(base, extra []string) []string {
+	seen := make(map[string]bool, len(base)+len(extra))
+	out := make([]string, 0, len(base)+len(extra))
+	for _, f := range base {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	for _, f := range extra {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: audit  —  vulnerability advisory cross-referencing
+//
+//  Pulls a signed advisory feed (config.Config.AdvisoryURL, defaulting to
+//  s7lver/tsuki-advisories) and cross-references it against ListInstalled()
+//  using the same SemVer machinery the dependency resolver uses. The feed
+//  is Ed25519-signed the same way a package's tsukilib.toml is, so
+//  verification reuses pkgmgr.TryVerifyWithKey rather than standing up a
+//  second trust root.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/pkgmgr"
+	"github.com/tsuki/cli/internal/registry"
+)
+
+// Advisory is one entry in the advisory feed.
+type Advisory struct {
+	ID               string   `json:"id"`
+	Package          string   `json:"package"`
+	AffectedVersions string   `json:"affected_versions"`
+	FixedIn          string   `json:"fixed_in"`
+	Severity         string   `json:"severity"`
+	Summary          string   `json:"summary"`
+	References       []string `json:"references,omitempty"`
+}
+
+// AdvisoryHit is one installed package matched against an advisory.
+type AdvisoryHit struct {
+	Package          string
+	InstalledVersion string
+	Advisory         Advisory
+
+	// UpgradeTo is the nearest version that resolves this advisory — always
+	// Advisory.FixedIn, since the feed already names the fix directly; there's
+	// no intermediate hop to compute the way there is when upgrading across
+	// several independently-released versions.
+	UpgradeTo string
+}
+
+// Audit fetches the configured advisory feed, verifies its signature, and
+// returns one AdvisoryHit per installed package whose version falls inside
+// an advisory's affected range.
+func Audit() ([]AdvisoryHit, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+	feedURL := cfg.ResolvedAdvisoryURL()
+
+	data, err := fetchFeed(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching advisory feed: %w", err)
+	}
+
+	if cfg.VerifySignatures {
+		if err := verifyFeed(feedURL, data, cfg); err != nil {
+			return nil, fmt.Errorf("verifying advisory feed: %w", err)
+		}
+	}
+
+	var advisories []Advisory
+	if err := json.Unmarshal(data, &advisories); err != nil {
+		return nil, fmt.Errorf("parsing advisory feed: %w", err)
+	}
+
+	installed, err := pkgmgr.ListInstalled()
+	if err != nil {
+		return nil, fmt.Errorf("listing installed packages: %w", err)
+	}
+
+	var hits []AdvisoryHit
+	for _, adv := range advisories {
+		constraint, err := registry.ParseConstraint(adv.AffectedVersions)
+		if err != nil {
+			continue // malformed entry in the feed — skip rather than abort the whole audit
+		}
+		for _, pkg := range installed {
+			if pkg.Name != adv.Package {
+				continue
+			}
+			version, err := registry.ParseVersion(pkg.Version)
+			if err != nil {
+				continue
+			}
+			if !constraint.Check(version) {
+				continue
+			}
+			hits = append(hits, AdvisoryHit{
+				Package:          pkg.Name,
+				InstalledVersion: pkg.Version,
+				Advisory:         adv,
+				UpgradeTo:        adv.FixedIn,
+			})
+		}
+	}
+	return hits, nil
+}
+
+// verifyFeed verifies data against feedURL+".sig" using the global key
+// index (cfg.ResolvedKeysIndexURL) that package installs also fall back to.
+// The per-registry key indexes that package installs try first are not
+// consulted.
+func verifyFeed(feedURL string, data []byte, cfg *config.Config) error {
+	var keyIndexURLs []string
+	keyIndexURLs = append(keyIndexURLs, cfg.ResolvedKeysIndexURL())
+
+	var lastErr error
+	for _, idxURL := range keyIndexURLs {
+		keyIdx, err := pkgmgr.FetchKeyIndex(idxURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, entry := range keyIdx.Keys {
+			if err := pkgmgr.TryVerifyWithKey(entry, feedURL, string(data)); err == nil {
+				return nil
+			} else {
+				lastErr = err
+			}
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("no key could verify the advisory feed: %w", lastErr)
+	}
+	return fmt.Errorf("no signing keys found in any key index")
+}
+
+func fetchFeed(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,95 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: checksums  —  verifying a download against SHA256SUMS
+//
+//  `tsuki push` (see cli/push.go's generateChecksums/signSums) writes a
+//  SHA256SUMS file alongside a release's artifacts and signs it with an
+//  Ed25519 key, the same way a package's own tsukilib.toml gets a detached
+//  .sig. verifyChecksumsFile is the install-side half of that: given the
+//  URL an artifact was just downloaded from, it fetches the sibling
+//  SHA256SUMS (and SHA256SUMS.sig) from the same directory, verifies the
+//  sums file's signature against cfg's configured key indexes — the exact
+//  machinery tryVerifyWithKey already uses for a manifest's own .sig — and
+//  then checks the downloaded bytes' digest against what the sums file
+//  declares for that filename.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsuki/cli/internal/config"
+)
+
+// verifyChecksumsFile checks data (downloaded from sourceURL) against the
+// SHA256SUMS file published alongside it. A sourceURL with no sibling
+// SHA256SUMS is not an error — most registry packages don't publish one,
+// and the per-manifest .sig check already covers that shape — but a
+// SHA256SUMS that exists and either doesn't verify or doesn't list the
+// downloaded file fails closed.
+func verifyChecksumsFile(sourceURL string, data []byte, cfg *config.Config) error {
+	idx := strings.LastIndex(sourceURL, "/")
+	if idx < 0 {
+		return nil
+	}
+	base, filename := sourceURL[:idx+1], sourceURL[idx+1:]
+
+	sumsURL := base + "SHA256SUMS"
+	sumsData, err := httpGet(sumsURL)
+	if err != nil {
+		return nil // no sums file published for this release — nothing to enforce
+	}
+
+	if err := verifyChecksumsSignature(sumsURL, sumsData, cfg); err != nil {
+		return err
+	}
+
+	want := sha256Hex(data)
+	for _, line := range strings.Split(string(sumsData), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[1] != filename {
+			continue
+		}
+		if fields[0] != want {
+			return fmt.Errorf("%s: checksum mismatch (SHA256SUMS says %s, downloaded file hashes to %s)", filename, fields[0], want)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: not listed in %s", filename, sumsURL)
+}
+
+// verifyChecksumsSignature verifies sumsData's detached signature (fetched
+// from "<sumsURL>.sig") against every key in cfg's configured key indexes,
+// returning nil on the first key that verifies it.
+func verifyChecksumsSignature(sumsURL string, sumsData []byte, cfg *config.Config) error {
+	var lastErr error
+	for _, idxURL := range []string{cfg.ResolvedKeysIndexURL()} {
+		keyIdx, err := FetchKeyIndex(idxURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, entry := range keyIdx.Keys {
+			keyPath, err := EnsureKeyDownloaded(entry)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			pub, err := loadEd25519PublicKey(keyPath)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if err := verifyDetachedSignatureAt(pub, sumsURL+".sig", string(sumsData)); err != nil {
+				lastErr = fmt.Errorf("key %s: %w", entry.KeyID, err)
+				continue
+			}
+			return nil
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("SHA256SUMS signature verification failed: %w", lastErr)
+	}
+	return fmt.Errorf("no signing keys found to verify SHA256SUMS")
+}
@@ -0,0 +1,261 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: parallel  —  concurrent fetch/verify for a resolved graph
+//
+//  InstallFromRegistry used to materialize ResolveGraph's nodes one at a
+//  time, which meant a 20-package graph paid for 20 sequential downloads
+//  and signature checks even though the nodes have nothing else to wait on.
+//  installNodesParallel instead runs the whole graph in two phases:
+//
+//    Phase 1 (parallel, no disk writes): every not-yet-cached node is
+//    fetched, signature-verified, and var-templated by a bounded pool of
+//    worker goroutines (config's max_parallel_fetches, default 8). Nothing
+//    is written to LibsDir() in this phase — a node's result is held in
+//    memory until every other node in the batch has also succeeded.
+//
+//    Phase 2 (serial, fast): only once every node has cleared phase 1 does
+//    writeStagedNode run for each of them, one at a time, using the same
+//    tmpDir-write-then-rename pattern materializeNodeAtomic already uses
+//    for a single package. This is a stronger guarantee than per-node
+//    atomicity alone: a failure anywhere in the batch (a bad signature on
+//    node 17 of 20, say) leaves the project's libs dir completely
+//    untouched instead of half-upgraded.
+//
+//  Fetches additionally go through a per-host semaphore (maxFetchesPerHost)
+//  so the worker pool's concurrency cap doesn't translate into hammering
+//  one slow registry with 8 simultaneous connections while every other
+//  host sits idle; all fetches share fetchHTTPClient so TCP/TLS connections
+//  are reused across the whole batch instead of being torn down per node.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// fetchHTTPClient is shared by every httpGet call so that fetches issued by
+// installNodesParallel's worker pool (and any other caller) reuse keep-alive
+// connections instead of each dialing its own.
+var fetchHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		MaxIdleConns:        64,
+		MaxIdleConnsPerHost: maxFetchesPerHost,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// maxFetchesPerHost caps how many requests any single worker pool may have
+// in flight against one host at a time, independent of the pool's overall
+// size — a registry with a handful of packages shouldn't get hit with the
+// full max_parallel_fetches burst just because a larger graph also uses it.
+const maxFetchesPerHost = 4
+
+var hostSemaphores sync.Map // host (string) -> chan struct{}
+
+func hostSemaphore(host string) chan struct{} {
+	v, _ := hostSemaphores.LoadOrStore(host, make(chan struct{}, maxFetchesPerHost))
+	return v.(chan struct{})
+}
+
+// acquireHostSlot blocks until rawURL's host has a free slot and returns a
+// func to release it. A URL that doesn't parse (or has no host, e.g. a
+// plain file path handed to fetchTOML under a different code path) is
+// keyed by the raw string instead, so it gets a semaphore of its own
+// (still capped at maxFetchesPerHost) rather than failing the fetch.
+func acquireHostSlot(rawURL string) (release func()) {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	sem := hostSemaphore(host)
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// batchResult carries one ResolveGraph node through installNodesParallel's
+// two phases: phase 1 fills in everything but Pkg, phase 2 fills in Pkg.
+type batchResult struct {
+	node                                    resolvedNode
+	tomlData                                string
+	name, version, desc, header, arduinoLib string
+	pkg                                     *InstalledPackage
+	err                                     error
+}
+
+// installNodesParallel materializes every node ResolveGraph returned,
+// fetching and verifying the not-yet-cached ones concurrently before
+// writing any of them to disk. rootName identifies which node's package to
+// return; vars is applied only to that node (see InstallFromRegistry).
+func installNodesParallel(nodes []resolvedNode, rootName string, vars map[string]string) (*InstalledPackage, error) {
+	cfg, _ := config.Load()
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	results := make([]batchResult, len(nodes))
+	var root *InstalledPackage
+	var pending []int
+	for i, node := range nodes {
+		if cached := findCachedPkg(LibsDir(), node.Name, node.Version); cached != nil {
+			results[i] = batchResult{node: node, pkg: cached}
+			if node.Name == rootName {
+				root = cached
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	if len(pending) > 0 {
+		concurrency := cfg.ResolvedMaxParallelFetches()
+		if concurrency > len(pending) {
+			concurrency = len(pending)
+		}
+
+		group := ui.NewSpinnerGroup(concurrency)
+		work := make(chan int)
+		var wg sync.WaitGroup
+		for slot := 0; slot < concurrency; slot++ {
+			wg.Add(1)
+			go func(slot int) {
+				defer wg.Done()
+				for i := range work {
+					node := nodes[i]
+					group.Update(slot, node.Name)
+					nodeVars := vars
+					if node.Name != rootName {
+						nodeVars = nil
+					}
+					res := fetchAndVerifyNode(node, nodeVars, cfg)
+					results[i] = res
+					group.Finish(slot, res.err == nil, fmt.Sprintf("%s@%s", node.Name, node.Version))
+				}
+			}(slot)
+		}
+		for _, i := range pending {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+		group.Wait()
+
+		for _, i := range pending {
+			if results[i].err != nil {
+				return nil, fmt.Errorf("installing %s@%s: %w", results[i].node.Name, results[i].node.Version, results[i].err)
+			}
+		}
+
+		for _, i := range pending {
+			pkg, err := writeStagedNode(results[i])
+			if err != nil {
+				return nil, fmt.Errorf("installing %s@%s: %w", results[i].node.Name, results[i].node.Version, err)
+			}
+			results[i].pkg = pkg
+			if results[i].node.Name == rootName {
+				root = pkg
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("package %q not found in any registry — run `tsuki pkg search` to see available packages", rootName)
+	}
+	return root, nil
+}
+
+// fetchAndVerifyNode runs phase 1 for one node: downloading its manifest
+// (unless ResolveGraph already carried it inline), verifying its signature,
+// and resolving/applying its {{var}} templates. It never touches disk.
+func fetchAndVerifyNode(node resolvedNode, vars map[string]string, cfg *config.Config) batchResult {
+	tomlData := node.Data
+	if tomlData == "" {
+		data, err := fetchTOML(node.TomlURL)
+		if err != nil {
+			return batchResult{node: node, err: fmt.Errorf("downloading %s: %w", node.Name, err)}
+		}
+		tomlData = data
+	}
+
+	name, version, desc, header, arduinoLib, err := parseTOMLMeta(tomlData)
+	if err != nil || name == "" {
+		name = node.Name
+	}
+	if version == "" {
+		version = node.Version
+	}
+
+	if cfg.VerifySignatures {
+		if err := verifySignature(name, node.TomlURL, tomlData, cfg); err != nil {
+			return batchResult{node: node, err: fmt.Errorf("signature verification failed for %s@%s: %w", name, version, err)}
+		}
+	}
+
+	// Var templating happens only after signature verification — never
+	// verify a substituted manifest.
+	resolvedVars, err := resolveManifestVars(tomlData, vars)
+	if err != nil {
+		return batchResult{node: node, err: fmt.Errorf("resolving vars for %s: %w", name, err)}
+	}
+	tomlData, err = templateString(tomlData, resolvedVars)
+	if err != nil {
+		return batchResult{node: node, err: fmt.Errorf("templating manifest for %s: %w", name, err)}
+	}
+	if _, _, desc, header, arduinoLib, err = parseTOMLMeta(tomlData); err != nil {
+		desc, header, arduinoLib = quickParseMeta(tomlData)
+	}
+
+	return batchResult{
+		node:       node,
+		tomlData:   tomlData,
+		name:       name,
+		version:    version,
+		desc:       desc,
+		header:     header,
+		arduinoLib: arduinoLib,
+	}
+}
+
+// writeStagedNode runs phase 2 for one already-verified node: writing its
+// manifest under PackageDir via a tmpDir-then-rename, the same pattern
+// materializeNodeAtomic uses for a single package.
+func writeStagedNode(res batchResult) (*InstalledPackage, error) {
+	destDir := PackageDir(res.name, res.version)
+	tmpDir := destDir + ".tmp"
+	os.RemoveAll(tmpDir) // leftover from a previous interrupted install
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating package dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "tsukilib.toml"), []byte(res.tomlData), 0644); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("writing tsukilib.toml: %w", err)
+	}
+	os.RemoveAll(destDir) // replace a stale partial dir from a prior failed attempt, if any
+	if err := os.Rename(tmpDir, destDir); err != nil {
+		return nil, err
+	}
+
+	destFile := filepath.Join(destDir, "tsukilib.toml")
+	return &InstalledPackage{
+		Name:         res.name,
+		Version:      res.version,
+		Description:  res.desc,
+		CppHeader:    res.header,
+		ArduinoLib:   res.arduinoLib,
+		Path:         destFile,
+		SHA256:       sha256Hex([]byte(res.tomlData)),
+		SigSHA256:    bestEffortSigSHA256(res.node.TomlURL),
+		RegistryURL:  res.node.TomlURL,
+		Constraint:   res.node.Constraint,
+		Dependencies: res.node.Deps,
+	}, nil
+}
@@ -25,19 +25,23 @@ package pkgmgr
 
 import (
 	"crypto/ed25519"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/db"
+	"github.com/tsuki/cli/internal/integrity"
+	"github.com/tsuki/cli/internal/registry"
+	"github.com/tsuki/cli/internal/tuf"
 	"github.com/tsuki/cli/internal/ui"
 )
 
@@ -82,6 +86,16 @@ type InstalledPackage struct {
 	CppHeader   string
 	ArduinoLib  string
 	Path        string
+
+	// Lock metadata — populated by installers that actually resolved this
+	// package (installFromSpec, materializeNode) so WriteLock can record
+	// it; ListInstalled leaves these at their zero value since nothing on
+	// disk carries them outside of tsuki.lock itself.
+	SHA256       string
+	SigSHA256    string
+	RegistryURL  string
+	Constraint   string
+	Dependencies []LockDependency
 }
 
 func ListInstalled() ([]InstalledPackage, error) {
@@ -142,6 +156,13 @@ type InstallOptions struct {
 	Dir    string // project root for local installs
 	Global bool   // install to global deps dir instead of .tsuki/deps/
 	Dev    bool   // add to [dev-dependencies]
+
+	// Vars supplies values for the manifest's declared [[vars]] (see
+	// vars.go), e.g. {"board": "esp32"} for a firmware package templated
+	// on {{board}}. A var the manifest declares required with no default
+	// must be present here or installation fails before any network I/O
+	// that depends on it.
+	Vars map[string]string
 }
 
 // Install fetches a tsukilib package and installs it.
@@ -159,6 +180,51 @@ func Install(opts InstallOptions) (*InstalledPackage, error) {
 	return installFromSource(opts)
 }
 
+// InstallEventType identifies one InstallEvent's stage in an async install.
+type InstallEventType int
+
+const (
+	EventStarted InstallEventType = iota
+	EventProgress
+	EventFinishedOK
+	EventFinishedErr
+)
+
+// InstallEvent is one step of an InstallAsync install, sent as it happens
+// so a caller (ui.SpinnerGroup, in practice) can render live progress
+// without blocking on the whole install.
+type InstallEvent struct {
+	Type InstallEventType
+
+	// Progress is 0-100, set only on EventProgress. Install doesn't
+	// currently report granular progress internally, so today InstallAsync
+	// only ever emits EventStarted followed by a single finished event —
+	// the field exists so a future download-progress hook has somewhere
+	// to report into without another event-shape change.
+	Progress int
+
+	Pkg *InstalledPackage // set on EventFinishedOK
+	Err error             // set on EventFinishedErr
+}
+
+// InstallAsync runs Install(opts) in its own goroutine and streams its
+// lifecycle over the returned channel, which is closed after the terminal
+// event (EventFinishedOK or EventFinishedErr).
+func InstallAsync(opts InstallOptions) <-chan InstallEvent {
+	ch := make(chan InstallEvent, 4)
+	go func() {
+		defer close(ch)
+		ch <- InstallEvent{Type: EventStarted}
+		pkg, err := Install(opts)
+		if err != nil {
+			ch <- InstallEvent{Type: EventFinishedErr, Err: err}
+			return
+		}
+		ch <- InstallEvent{Type: EventFinishedOK, Pkg: pkg}
+	}()
+	return ch
+}
+
 // installFromSpec handles "registry@name:version" specifiers (v3).
 func installFromSpec(opts InstallOptions) (*InstalledPackage, error) {
 	registry, name, version := parseInstallSpec(opts.Spec)
@@ -169,22 +235,70 @@ func installFromSpec(opts InstallOptions) (*InstalledPackage, error) {
 		installRoot = specGlobalDepDir()
 	}
 
+	// A project-local install consults tsuki.lock first: a locked entry
+	// that still satisfies the requested spec skips straight to the
+	// already-installed file, only re-hashing it to catch tampering or a
+	// manifest that changed upstream without a version bump — no network
+	// round-trip, no re-verification.
+	if !opts.Global {
+		projectDir := opts.Dir
+		if projectDir == "" {
+			projectDir = "."
+		}
+		if entries, _ := ReadLock(projectDir); len(entries) > 0 {
+			if le := findLockEntry(entries, name); le != nil && lockSatisfies(le, version) {
+				if cached := findCachedPkg(installRoot, name, le.Version); cached != nil {
+					data, err := os.ReadFile(cached.Path)
+					if err != nil {
+						return nil, fmt.Errorf("reading locked package %s@%s: %w", name, le.Version, err)
+					}
+					if sha256Hex(data) != le.SHA256 {
+						return nil, fmt.Errorf(
+							"%s@%s: installed tsukilib.toml does not match tsuki.lock (manifest changed upstream) — remove it and reinstall, or update tsuki.lock",
+							name, le.Version)
+					}
+					cached.SHA256, cached.SigSHA256 = le.SHA256, le.SigSHA256
+					cached.RegistryURL, cached.Constraint, cached.Dependencies = le.RegistryURL, le.Constraint, le.Dependencies
+					return cached, nil
+				}
+			}
+		}
+	}
+
 	// Return early if already cached.
 	if cached := findCachedPkg(installRoot, name, version); cached != nil {
 		return cached, nil
 	}
 
 	// Resolve via local DB cache → live registry.
-	tomlURL, resolvedVer, err := resolveSpecURL(registry, name, version)
+	candidate, err := resolveSpecURL(registry, name, version)
 	if err != nil {
 		return nil, err
 	}
+	tomlURL, resolvedVer := candidate.url, candidate.version
+
+	// tomlURL may itself be a versions.* URL template (e.g. a per-board
+	// firmware variant's "{{board}}"); no manifest has been fetched yet to
+	// declare defaults for it, so this can only check against opts.Vars —
+	// a missing placeholder fails here, before the fetch below.
+	tomlURL, err = templateString(tomlURL, opts.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("templating registry URL for %s: %w", name, err)
+	}
 
 	tomlData, err := fetchTOML(tomlURL)
 	if err != nil {
 		return nil, fmt.Errorf("downloading %s: %w", name, err)
 	}
 
+	// The registry entry's digest/signature, when declared, are enforced
+	// fail-closed before anything else touches the download — a declared
+	// sha256 that doesn't match, or a signature that doesn't verify, must
+	// never reach parseTOMLMeta or disk.
+	if err := verifyReleaseIntegrity(candidate, []byte(tomlData)); err != nil {
+		return nil, fmt.Errorf("integrity check failed for %s@%s: %w", name, resolvedVer, err)
+	}
+
 	pkgName, pkgVer, desc, header, lib, parseErr := parseTOMLMeta(tomlData)
 	if parseErr != nil || pkgName == "" {
 		pkgName = name
@@ -193,6 +307,32 @@ func installFromSpec(opts InstallOptions) (*InstalledPackage, error) {
 		pkgVer = resolvedVer
 	}
 
+	// Signature verification (v3 spec path used to be silently skipped here —
+	// VerifySignatures now gates this path the same way it gates installFromSource).
+	cfg, _ := config.Load()
+	if cfg != nil && cfg.VerifySignatures {
+		if err := verifySignature(pkgName, tomlURL, tomlData, cfg); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %s: %w", name, err)
+		}
+		if err := verifyChecksumsFile(tomlURL, []byte(tomlData), cfg); err != nil {
+			return nil, fmt.Errorf("checksum verification failed for %s: %w", name, err)
+		}
+	}
+
+	// Var templating happens only after signature verification — never
+	// verify a substituted manifest.
+	vars, err := resolveManifestVars(tomlData, opts.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("resolving vars for %s: %w", name, err)
+	}
+	tomlData, err = templateString(tomlData, vars)
+	if err != nil {
+		return nil, fmt.Errorf("templating manifest for %s: %w", name, err)
+	}
+	if _, _, desc, header, lib, parseErr = parseTOMLMeta(tomlData); parseErr != nil {
+		desc, header, lib = quickParseMeta(tomlData)
+	}
+
 	destDir := filepath.Join(installRoot, pkgName, pkgVer)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating install dir: %w", err)
@@ -209,6 +349,10 @@ func installFromSpec(opts InstallOptions) (*InstalledPackage, error) {
 		CppHeader:   header,
 		ArduinoLib:  lib,
 		Path:        destFile,
+		SHA256:      sha256Hex([]byte(tomlData)),
+		SigSHA256:   bestEffortSigSHA256(tomlURL),
+		RegistryURL: tomlURL,
+		Constraint:  version,
 	}, nil
 }
 
@@ -230,11 +374,26 @@ func installFromSource(opts InstallOptions) (*InstalledPackage, error) {
 	// Signature verification
 	cfg, _ := config.Load()
 	if cfg != nil && cfg.VerifySignatures {
-		if err := verifySignature(opts.Source, tomlData, cfg); err != nil {
+		if err := verifySignature(name, opts.Source, tomlData, cfg); err != nil {
 			return nil, fmt.Errorf("signature verification failed for %s@%s: %w", name, version, err)
 		}
+		if err := verifyChecksumsFile(opts.Source, []byte(tomlData), cfg); err != nil {
+			return nil, fmt.Errorf("checksum verification failed for %s@%s: %w", name, version, err)
+		}
 	}
 
+	// Var templating happens only after signature verification — never
+	// verify a substituted manifest.
+	vars, err := resolveManifestVars(tomlData, opts.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("resolving vars for %s: %w", name, err)
+	}
+	tomlData, err = templateString(tomlData, vars)
+	if err != nil {
+		return nil, fmt.Errorf("templating manifest for %s: %w", name, err)
+	}
+	description, header, arduinoLib = quickParseMeta(tomlData)
+
 	destDir := PackageDir(name, version)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating package dir: %w", err)
@@ -245,6 +404,18 @@ func installFromSource(opts InstallOptions) (*InstalledPackage, error) {
 		return nil, fmt.Errorf("writing tsukilib.toml: %w", err)
 	}
 
+	// A direct URL/file install has no registry context of its own, but
+	// its [dependencies] table still names registry packages — install
+	// each through the same resolver InstallFromRegistry uses rather than
+	// leaving them for the caller to notice are missing.
+	if m, err := decodeDepManifest(tomlData); err == nil {
+		for depName, depExpr := range m.Dependencies {
+			if _, err := InstallFromRegistry(depName, depExpr, nil); err != nil {
+				return nil, fmt.Errorf("installing dependency %s of %s: %w", depName, name, err)
+			}
+		}
+	}
+
 	return &InstalledPackage{
 		Name:        name,
 		Version:     version,
@@ -255,11 +426,24 @@ func installFromSource(opts InstallOptions) (*InstalledPackage, error) {
 	}, nil
 }
 
-func Remove(name, version string) error {
+// Remove deletes name@version from LibsDir(). Unless cascade is true, it
+// first refuses when another installed package's own tsukilib.toml still
+// lists name in its [dependencies] — removing it out from under that
+// package would leave a dangling include/link.
+func Remove(name, version string, cascade bool) error {
 	dir := PackageDir(name, version)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return fmt.Errorf("package %s@%s is not installed", name, version)
 	}
+
+	if !cascade {
+		if dependents := findDependents(name); len(dependents) > 0 {
+			return fmt.Errorf(
+				"%s is depended on by %s — pass --cascade to remove it anyway",
+				name, strings.Join(dependents, ", "))
+		}
+	}
+
 	if err := os.RemoveAll(dir); err != nil {
 		return fmt.Errorf("removing %s: %w", dir, err)
 	}
@@ -270,6 +454,34 @@ func Remove(name, version string) error {
 	return nil
 }
 
+// findDependents returns the names of every other installed package whose
+// own tsukilib.toml declares name as a dependency.
+func findDependents(name string) []string {
+	pkgs, err := ListInstalled()
+	if err != nil {
+		return nil
+	}
+	var dependents []string
+	for _, p := range pkgs {
+		if p.Name == name {
+			continue
+		}
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			continue
+		}
+		m, err := decodeDepManifest(string(data))
+		if err != nil {
+			continue
+		}
+		if _, ok := m.Dependencies[name]; ok {
+			dependents = append(dependents, p.Name)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
 func IsInstalled(name string) (bool, string) {
 	pkgs, _ := ListInstalled()
 	for _, p := range pkgs {
@@ -348,7 +560,10 @@ func EnsureKeyDownloaded(entry KeyIndexEntry) (string, error) {
 
 // verifySignature verifies the Ed25519 signature of a TOML package file.
 //
-// Algorithm:
+// If trust has been bootstrapped via `tsuki keys init` (cfg.TUFMetadataURL
+// set), the package's public key is resolved from the TUF targets role
+// first — this is the rotation-safe path. Otherwise (or if the TUF target
+// isn't listed), it falls back to the legacy flat key index:
 //  1. Load all configured key indexes (per-registry + global).
 //  2. For each key entry, fetch (or use cached) public key.
 //  3. Derive the signature URL: use SignatureURLTemplate if set,
@@ -356,7 +571,18 @@ func EnsureKeyDownloaded(entry KeyIndexEntry) (string, error) {
 //  4. Fetch the .sig file (raw 64-byte Ed25519 signature).
 //  5. Verify ed25519.Verify(pubkey, []byte(tomlData), sig).
 //  6. Return nil on the first successful verification; error if all fail.
-func verifySignature(tomlURL, tomlData string, cfg *config.Config) error {
+func verifySignature(pkgName, tomlURL, tomlData string, cfg *config.Config) error {
+	if base := cfg.ResolvedTUFMetadataURL(); base != "" {
+		client := tuf.NewClient(base, cfg.ResolvedKeysDir())
+		if pub, err := client.LookupTargetKey(pkgName); err == nil {
+			if err := verifyDetachedSignature(pub, tomlURL, tomlData); err == nil {
+				return nil
+			}
+		}
+		// Fall through to the legacy key index below — a package may not yet
+		// be listed as a TUF target during the migration period.
+	}
+
 	// Collect all key index URLs to try: per-registry indexes + global fallback.
 	var keyIndexURLs []string
 	for _, regURL := range cfg.ResolvedRegistryURLs() {
@@ -399,38 +625,55 @@ func verifySignature(tomlURL, tomlData string, cfg *config.Config) error {
 	return fmt.Errorf("no signing keys found in any key index")
 }
 
+// TryVerifyWithKey attempts to verify data's signature (fetched from
+// "<sourceURL>.sig", or entry.SignatureURLTemplate if set) using one key
+// entry. It isn't package-specific — anything signed the same way a
+// package's tsukilib.toml is (e.g. the audit subpackage's advisory feed)
+// can reuse it rather than standing up a parallel trust root.
+func TryVerifyWithKey(entry KeyIndexEntry, sourceURL, data string) error {
+	return tryVerifyWithKey(entry, sourceURL, data)
+}
+
 // tryVerifyWithKey attempts to verify tomlData's signature using one key entry.
 func tryVerifyWithKey(entry KeyIndexEntry, tomlURL, tomlData string) error {
-	// 1. Determine signature URL
 	sigURL := tomlURL + ".sig"
 	if entry.SignatureURLTemplate != "" {
 		sigURL = strings.ReplaceAll(entry.SignatureURLTemplate, "{toml_url}", tomlURL)
 	}
 
-	// 2. Fetch the signature (raw bytes)
-	sigBytes, err := httpGet(sigURL)
-	if err != nil {
-		return fmt.Errorf("fetching signature from %s: %w", sigURL, err)
-	}
-	if len(sigBytes) != ed25519.SignatureSize {
-		return fmt.Errorf("invalid signature length %d (expected %d)", len(sigBytes), ed25519.SignatureSize)
-	}
-
-	// 3. Fetch (or use cached) public key
 	keyPath, err := EnsureKeyDownloaded(entry)
 	if err != nil {
 		return fmt.Errorf("fetching public key %s: %w", entry.KeyID, err)
 	}
-
-	// 4. Parse PEM-encoded Ed25519 public key
 	pubKey, err := loadEd25519PublicKey(keyPath)
 	if err != nil {
 		return fmt.Errorf("loading public key %s: %w", entry.KeyID, err)
 	}
 
-	// 5. Verify
-	if !ed25519.Verify(pubKey, []byte(tomlData), sigBytes) {
-		return fmt.Errorf("signature invalid for key %s", entry.KeyID)
+	if err := verifyDetachedSignatureAt(pubKey, sigURL, tomlData); err != nil {
+		return fmt.Errorf("key %s: %w", entry.KeyID, err)
+	}
+	return nil
+}
+
+// verifyDetachedSignature verifies tomlData against the raw 64-byte Ed25519
+// signature found at "<tomlURL>.sig" using pub.
+func verifyDetachedSignature(pub ed25519.PublicKey, tomlURL, tomlData string) error {
+	return verifyDetachedSignatureAt(pub, tomlURL+".sig", tomlData)
+}
+
+// verifyDetachedSignatureAt verifies tomlData against the raw 64-byte
+// Ed25519 signature found at sigURL using pub.
+func verifyDetachedSignatureAt(pub ed25519.PublicKey, sigURL, tomlData string) error {
+	sigBytes, err := httpGet(sigURL)
+	if err != nil {
+		return fmt.Errorf("fetching signature from %s: %w", sigURL, err)
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d (expected %d)", len(sigBytes), ed25519.SignatureSize)
+	}
+	if !ed25519.Verify(pub, []byte(tomlData), sigBytes) {
+		return fmt.Errorf("signature invalid")
 	}
 	return nil
 }
@@ -491,8 +734,31 @@ type RegistryEntry struct {
 	RegistryURL string `json:"registry_url"`
 }
 
-// fetchRegistryFromURL downloads and parses a single registry JSON.
+// fetchRegistryFromURL downloads and parses a single registry JSON. URLs
+// using the oci:// scheme are listed via the OCI Distribution Spec client
+// and synthesized into a RegistryIndex (one package per repo, one version
+// per tag, download URLs re-resolved through registry.PullOCIPackage).
 func fetchRegistryFromURL(url string) (*RegistryIndex, error) {
+	if registry.IsOCIURL(url) {
+		db, err := registry.FetchDBFromOCI(url)
+		if err != nil {
+			return nil, fmt.Errorf("fetching OCI registry %s: %w", url, err)
+		}
+		idx := &RegistryIndex{Packages: make(map[string]RegistryPackage, len(db.Packages))}
+		for name, entry := range db.Packages {
+			versions := make(map[string]string, len(entry.Versions))
+			for v, meta := range entry.Versions {
+				versions[v] = meta.DownloadURL
+			}
+			idx.Packages[name] = RegistryPackage{
+				Description: entry.Description,
+				Author:      entry.Author,
+				Latest:      entry.Latest,
+				Versions:    versions,
+			}
+		}
+		return idx, nil
+	}
 	data, err := httpGet(url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching registry from %s: %w", url, err)
@@ -551,7 +817,12 @@ func FetchAllRegistries() (map[string]RegistryPackage, []string, error) {
 	return merged, registryURLs, nil
 }
 
-// SearchRegistry queries all configured registries for packages matching query.
+// SearchRegistry queries all configured registries for packages matching
+// query. This always goes through the legacy monolithic fetch (a sparse
+// index has no "list everything" endpoint by design — see
+// sparseIndexBase) regardless of whether a registry also advertises
+// sparse; InstallFromRegistry is the one that prefers sparse, since it
+// already knows the exact package name it wants.
 func SearchRegistry(query string) ([]RegistryEntry, error) {
 	packages, _, err := FetchAllRegistries()
 	if err != nil {
@@ -578,40 +849,47 @@ func SearchRegistry(query string) ([]RegistryEntry, error) {
 	return results, nil
 }
 
-// InstallFromRegistry installs a package by name from the merged registry.
-func InstallFromRegistry(name, version string) (*InstalledPackage, error) {
-	packages, _, err := FetchAllRegistries()
+// InstallFromRegistry resolves name's full transitive dependency graph
+// (see ResolveGraph — it prefers each registry's sparse index over the
+// legacy monolithic fetch wherever a registry advertises it) and, only
+// once that resolution succeeds, materializes every node it found under
+// LibsDir(). version may be an exact version, a range expression, or ""
+// for "latest"; the returned package is the root (name), not a dependency.
+//
+// Fetching, signature verification, and var templating for every node run
+// concurrently (see parallel.go) — a resolved graph of dozens of packages
+// no longer pays for them one at a time. vars is applied only to the root
+// node: a dependency pulled in transitively isn't expected to share the
+// root's per-board/per-variant vars, and a manifest that itself declares
+// required vars with no default is no more installable as a dependency
+// than it is standalone.
+func InstallFromRegistry(name, version string, vars map[string]string) (*InstalledPackage, error) {
+	nodes, err := ResolveGraph(name, version, nil)
 	if err != nil {
 		return nil, err
 	}
+	return installNodesParallel(nodes, name, vars)
+}
 
-	entry, ok := packages[name]
-	if !ok {
-		return nil, fmt.Errorf(
-			"package %q not found in any registry — run `tsuki pkg search` to see available packages",
-			name,
-		)
-	}
-
-	ver := version
-	if ver == "" {
-		ver = entry.Latest
-	}
+// sha256Hex returns data's sha256 as a lowercase hex string, for recording
+// a package's integrity in tsuki.lock.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
 
-	tomlURL, ok := entry.Versions[ver]
-	if !ok {
-		versions := make([]string, 0, len(entry.Versions))
-		for v := range entry.Versions {
-			versions = append(versions, v)
-		}
-		sort.Strings(versions)
-		return nil, fmt.Errorf(
-			"version %q not found for package %q. Available: %s",
-			ver, name, strings.Join(versions, ", "),
-		)
+// bestEffortSigSHA256 records the hash of a package's detached signature
+// file, when one exists at the conventional <toml_url>.sig location — it's
+// purely informational for the lockfile, so any failure (no signature
+// published, registry unreachable) is silently treated as "none", the
+// same way a missing SignatureURLTemplate falls back to this same URL
+// shape during actual verification (see tryVerifyWithKey).
+func bestEffortSigSHA256(tomlURL string) string {
+	data, err := httpGet(tomlURL + ".sig")
+	if err != nil {
+		return ""
 	}
-
-	return Install(InstallOptions{Source: tomlURL, Version: ver})
+	return sha256Hex(data)
 }
 
 // ── Print helpers ─────────────────────────────────────────────────────────────
@@ -663,6 +941,13 @@ func PrintRegistryResults(entries []RegistryEntry) {
 // ── TOML fetch ────────────────────────────────────────────────────────────────
 
 func fetchTOML(source string) (string, error) {
+	if registry.IsOCIURL(source) {
+		data, err := registry.PullOCIPackage(source)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
 	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
 		data, err := httpGet(source)
 		if err != nil {
@@ -678,8 +963,10 @@ func fetchTOML(source string) (string, error) {
 }
 
 func httpGet(url string) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	release := acquireHostSlot(url)
+	defer release()
+
+	resp, err := fetchHTTPClient.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("GET %s: %w", url, err)
 	}
@@ -753,17 +1040,59 @@ func parseKV(line string) (key, value string, ok bool) {
 
 // ── Lock file ─────────────────────────────────────────────────────────────────
 
+// LockDependency is one resolved dependency recorded against a LockEntry —
+// its name and the exact version the resolver picked for it.
+type LockDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// LockEntry is one package recorded in tsuki.lock — a Cargo.lock analog:
+// enough to reinstall the exact same bytes from the exact same place
+// without re-resolving anything, and enough to detect drift without
+// re-downloading.
 type LockEntry struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 	Path    string `json:"path"`
+
+	// SHA256 is the hash of the installed tsukilib.toml bytes; SigSHA256
+	// is the hash of its detached signature file, when one was found
+	// (empty when signatures aren't in use, or none was published).
+	SHA256    string `json:"sha256,omitempty"`
+	SigSHA256 string `json:"sig_sha256,omitempty"`
+
+	// RegistryURL is the exact URL the package was resolved from, and
+	// Constraint is the version requirement(s) that resolved to it —
+	// both purely informational, for `tsuki pkg info`/auditing.
+	RegistryURL string `json:"registry_url,omitempty"`
+	Constraint  string `json:"constraint,omitempty"`
+
+	Dependencies []LockDependency `json:"dependencies,omitempty"`
 }
 
 func WriteLock(projectDir string, pkgs []InstalledPackage) error {
 	entries := make([]LockEntry, len(pkgs))
 	for i, p := range pkgs {
-		entries[i] = LockEntry{Name: p.Name, Version: p.Version, Path: p.Path}
+		entries[i] = LockEntry{
+			Name:         p.Name,
+			Version:      p.Version,
+			Path:         p.Path,
+			SHA256:       p.SHA256,
+			SigSHA256:    p.SigSHA256,
+			RegistryURL:  p.RegistryURL,
+			Constraint:   p.Constraint,
+			Dependencies: p.Dependencies,
+		}
 	}
+	return WriteLockEntries(projectDir, entries)
+}
+
+// WriteLockEntries writes entries to projectDir/tsuki.lock verbatim,
+// replacing the whole file — the shared primitive behind both WriteLock
+// (a full rebuild from a freshly resolved InstalledPackage list) and
+// mergeLockEntries (an Upgrade's partial, by-name upsert).
+func WriteLockEntries(projectDir string, entries []LockEntry) error {
 	data, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
 		return err
@@ -782,6 +1111,92 @@ func ReadLock(projectDir string) ([]LockEntry, error) {
 	var entries []LockEntry
 	return entries, json.Unmarshal(data, &entries)
 }
+
+// findLockEntry returns name's entry from a tsuki.lock, or nil.
+func findLockEntry(entries []LockEntry, name string) *LockEntry {
+	for i := range entries {
+		if entries[i].Name == name {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// lockSatisfies reports whether le — already locked at le.Version — still
+// satisfies a newly requested spec version or constraint.
+func lockSatisfies(le *LockEntry, requested string) bool {
+	if requested == "" || requested == le.Version {
+		return true
+	}
+	c, err := registry.ParseConstraint(normalizeConstraint(requested))
+	if err != nil {
+		return false
+	}
+	v, err := registry.ParseVersion(le.Version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// checkRegistryTampering compares le's locked digest against what the
+// registry cache currently advertises for le.Name@le.Version. A lock entry
+// and a registry entry for the exact same name@version should always carry
+// the same digest — if they disagree, the registry has republished a
+// version it already shipped, which PullAll refuses to silently reinstall.
+func checkRegistryTampering(le LockEntry) error {
+	if le.SHA256 == "" {
+		return nil
+	}
+	d, err := db.Open(db.DefaultPath())
+	if err != nil {
+		return nil // no local cache to check against — nothing to catch here
+	}
+	defer d.Close()
+
+	entries, err := d.Candidates(le.Name, le.Version)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if e.SHA256 != "" && e.SHA256 != le.SHA256 {
+			return fmt.Errorf(
+				"%s@%s: registry digest %s no longer matches locked digest %s — registry may have been tampered with or force-published; verify before updating tsuki.lock",
+				le.Name, le.Version, e.SHA256, le.SHA256,
+			)
+		}
+	}
+	return nil
+}
+
+// VerifyLock re-hashes every package recorded in projectDir's tsuki.lock
+// against what's actually installed on disk, returning a single error
+// listing every mismatch — drift a CI job can gate on without
+// re-downloading anything.
+func VerifyLock(projectDir string) error {
+	entries, err := ReadLock(projectDir)
+	if err != nil {
+		return fmt.Errorf("reading tsuki.lock: %w", err)
+	}
+	var problems []string
+	for _, e := range entries {
+		if e.SHA256 == "" {
+			continue // locked before integrity fields existed — nothing to check
+		}
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s@%s: %v", e.Name, e.Version, err))
+			continue
+		}
+		if sum := sha256Hex(data); sum != e.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s@%s: sha256 mismatch (have %s, locked %s)", e.Name, e.Version, sum, e.SHA256))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("lock verification failed:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
 // ─────────────────────────────────────────────────────────────────────────────
 //  v3 helpers: spec parsing, local/global dep dirs, cache lookup, PullAll
 // ─────────────────────────────────────────────────────────────────────────────
@@ -845,165 +1260,113 @@ func findCachedPkg(root, name, version string) *InstalledPackage {
 	}
 }
 
-// resolveSpecURL looks up a package in the local DB cache (populated by
-// `tsuki updatedb`) and returns its toml_url and resolved version.
-func resolveSpecURL(registryName, name, version string) (url, resolvedVer string, err error) {
-	home, _ := os.UserHomeDir()
-	cacheDir := filepath.Join(home, ".cache", "tsuki", "db")
+// specCandidate is one cache file's hit for a spec lookup — kept around
+// (rather than resolving straight to a URL) so resolveSpecURL can show the
+// user every registry a bare name resolved in before picking one.
+type specCandidate struct {
+	registry string // cache file name, sans ".json" — e.g. "tsuki-team"
+	url      string
+	version  string
+	digest   integrity.Digest
+	sig      string // base64 raw Ed25519 signature over the download, if the registry declared one
+}
+
+// resolveSpecURL looks up a package in the local db cache (populated by
+// `tsuki updatedb` via db.IngestRegistry) and returns the winning
+// specCandidate — its toml_url, resolved version, and any integrity
+// metadata (sha256/size/sig) the registry declared for that release. This
+// is a single indexed query rather than the directory-of-JSON-files scan
+// it used to be — see internal/db's package doc. version may be an exact
+// version, empty ("latest"), or any range expression
+// registry.ParseConstraint accepts ("^1.2", "~1.2.0", ">=1.2 <2", "1.x") —
+// the highest cached version satisfying it wins, same as ResolveGraph's
+// own pickConstrainedVersion. When the caller didn't pin a registry and
+// the name's highest match differs by registry, the ambiguity is put to
+// the user via ui.NumberMenu instead of silently taking whichever row the
+// query happened to return first.
+func resolveSpecURL(registryName, name, version string) (specCandidate, error) {
+	d, err := db.Open(db.DefaultPath())
+	if err != nil {
+		return specCandidate{}, fmt.Errorf("opening package db: %w", err)
+	}
+	defer d.Close()
 
-	var cacheFiles []string
+	entries, err := d.Candidates(name, "")
+	if err != nil {
+		return specCandidate{}, fmt.Errorf("querying package db: %w", err)
+	}
 	if registryName != "" {
-		cacheFiles = []string{filepath.Join(cacheDir, registryName+".json")}
-	} else {
-		entries, _ := os.ReadDir(cacheDir)
+		filtered := entries[:0]
 		for _, e := range entries {
-			if strings.HasSuffix(e.Name(), ".json") {
-				cacheFiles = append(cacheFiles, filepath.Join(cacheDir, e.Name()))
+			if e.Registry == registryName {
+				filtered = append(filtered, e)
 			}
 		}
+		entries = filtered
 	}
 
-	for _, cf := range cacheFiles {
-		data, readErr := os.ReadFile(cf)
-		if readErr != nil {
-			continue
-		}
-		u, v, lookupErr := lookupPackagesJSON(data, name, version)
-		if lookupErr == nil {
-			return u, v, nil
-		}
+	constraint, err := registry.ParseConstraint(normalizeConstraint(version))
+	if err != nil {
+		return specCandidate{}, fmt.Errorf("parsing version %q for %s: %w", version, name, err)
 	}
-	return "", "", fmt.Errorf(
-		"package %q not found in local registry cache — run `tsuki updatedb` first", name,
-	)
-}
 
-// lookupPackagesJSON finds name@version in a packages.json byte slice.
-// packages.json is an array: [{"name":"ws2812","version":"1.0.0","toml_url":"https://..."}]
-func lookupPackagesJSON(data []byte, name, version string) (url, resolvedVersion string, err error) {
-	var entries []map[string]interface{}
-	if err = json.Unmarshal(data, &entries); err != nil {
-		return
-	}
+	byRegistry := make(map[string]db.Entry)
+	var order []string
 	for _, e := range entries {
-		n, _ := e["name"].(string)
-		v, _ := e["version"].(string)
-		u, _ := e["toml_url"].(string)
-		if u == "" {
-			u, _ = e["download_url"].(string)
+		v, verErr := registry.ParseVersion(e.Version)
+		if verErr != nil || !constraint.Check(v) {
+			continue
 		}
-		if strings.EqualFold(n, name) && (version == "" || version == v) {
-			return u, v, nil
+		best, seen := byRegistry[e.Registry]
+		if !seen {
+			order = append(order, e.Registry)
+			byRegistry[e.Registry] = e
+			continue
 		}
-	}
-	err = fmt.Errorf("package %q @ %q not found", name, version)
-	return
-}
-
-// ── PullAll ───────────────────────────────────────────────────────────────────
-
-// PullResult holds the outcome for a single package during PullAll.
-type PullResult struct {
-	Name    string
-	Version string
-	Err     error
-}
-
-// PullAll installs every dependency from the manifest at dir.
-func PullAll(dir string) ([]PullResult, error) {
-	// Import manifest inline to avoid import cycle — read the file directly.
-	// We use the pkgmgr's own lock file as the source of truth when present.
-	lockEntries, _ := ReadLock(dir)
-	if len(lockEntries) > 0 {
-		var results []PullResult
-		for _, le := range lockEntries {
-			pkg, err := Install(InstallOptions{Spec: le.Name + ":" + le.Version, Dir: dir})
-			if err != nil {
-				results = append(results, PullResult{Name: le.Name, Err: err})
-			} else {
-				results = append(results, PullResult{Name: pkg.Name, Version: pkg.Version})
-			}
+		bestVer, _ := registry.ParseVersion(best.Version)
+		if v.Compare(bestVer) > 0 {
+			byRegistry[e.Registry] = e
 		}
-		return results, nil
 	}
 
-	// No lock file — fall back to reading tsuki-config.toml / tsuki_package.json.
-	deps, err := readManifestDeps(dir)
-	if err != nil {
-		return nil, err
+	candidates := make([]specCandidate, len(order))
+	for i, reg := range order {
+		e := byRegistry[reg]
+		candidates[i] = specCandidate{
+			registry: e.Registry,
+			url:      e.TomlURL,
+			version:  e.Version,
+			digest:   integrity.Digest{SHA256: e.SHA256, Size: e.Size},
+			sig:      e.Sig,
+		}
 	}
 
-	var results []PullResult
-	for name, version := range deps {
-		spec := name
-		if version != "" {
-			spec = name + ":" + version
-		}
-		pkg, err := Install(InstallOptions{Spec: spec, Dir: dir})
-		if err != nil {
-			results = append(results, PullResult{Name: name, Err: err})
-		} else {
-			results = append(results, PullResult{Name: pkg.Name, Version: pkg.Version})
-		}
+	switch len(candidates) {
+	case 0:
+		return specCandidate{}, fmt.Errorf(
+			"package %q not found in local registry cache — run `tsuki updatedb` first", name,
+		)
+	case 1:
+		return candidates[0], nil
+	default:
+		return pickSpecCandidate(name, candidates)
 	}
-	return results, nil
 }
 
-// readManifestDeps returns name→version from the project manifest without
-// importing the manifest package (avoids potential import cycle).
-func readManifestDeps(dir string) (map[string]string, error) {
-	// Try tsuki-config.toml first, then tsuki_package.json.
-	for _, fname := range []string{"tsuki-config.toml", "tsuki_package.json"} {
-		data, err := os.ReadFile(filepath.Join(dir, fname))
-		if err != nil {
-			continue
-		}
-		deps := make(map[string]string)
-		if strings.HasSuffix(fname, ".json") {
-			// Parse "packages": [{"name":"...","version":"..."}]
-			var raw struct {
-				Packages []struct {
-					Name    string `json:"name"`
-					Version string `json:"version"`
-				} `json:"packages"`
-			}
-			if json.Unmarshal(data, &raw) == nil {
-				for _, p := range raw.Packages {
-					deps[p.Name] = p.Version
-				}
-			}
-		} else {
-			// Minimal TOML parse: lines under [dependencies] as  name = "version"
-			inDeps := false
-			for _, line := range strings.Split(string(data), "\n") {
-				line = strings.TrimSpace(line)
-				if line == "[dependencies]" {
-					inDeps = true
-					continue
-				}
-				if strings.HasPrefix(line, "[") {
-					inDeps = false
-					continue
-				}
-				if inDeps {
-					k, v, ok := parseKV(line)
-					if ok {
-						// v may be `"1.0"` or `{ version = "1.0", ... }`
-						v = strings.Trim(v, `"`)
-						if idx := strings.Index(v, `"`); idx >= 0 {
-							v = v[idx+1:]
-							if end := strings.Index(v, `"`); end >= 0 {
-								v = v[:end]
-							}
-						}
-						deps[k] = v
-					}
-				}
-			}
-		}
-		if len(deps) > 0 {
-			return deps, nil
-		}
+// pickSpecCandidate disambiguates a name that resolved in more than one
+// cached registry via ui.NumberMenu, returning the first selected entry —
+// resolveSpecURL only ever installs one package per call, so a selection
+// of more than one just takes the first; the menu still shows every hit so
+// the user can see what they're choosing between.
+func pickSpecCandidate(name string, candidates []specCandidate) (specCandidate, error) {
+	items := make([]ui.MenuItem, len(candidates))
+	for i, c := range candidates {
+		items[i] = ui.MenuItem{Columns: []string{name, c.version, c.registry}}
+	}
+	ui.Info(fmt.Sprintf("%q matched more than one registry:", name))
+	selected, err := ui.NumberMenu("Select a package (e.g. 1, or 2-3):", items)
+	if err != nil {
+		return specCandidate{}, err
 	}
-	return nil, nil
-}
\ No newline at end of file
+	return candidates[selected[0]-1], nil
+}
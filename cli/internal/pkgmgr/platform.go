@@ -0,0 +1,227 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: platform  —  PlatformIO-style platform/variant packs
+//
+//  A "platform" package is a tsukilib package that declares
+//  `kind = "platform"` instead of (or alongside) a #include'able C++
+//  header: it describes how to build for one MCU family — core, variant
+//  folder, linker script, clock speed, extra compiler flags, and a
+//  short-name -> variant-folder mapping so `--board bluepill` resolves
+//  without a pre-installed arduino-cli core.
+//
+//  parseTOMLMeta/quickParseMeta only scan flat scalar key=value pairs, so
+//  they can't represent extra_flags ([]string) or board_mappings
+//  (map[string]string). Platform packs decode the [platform] table with
+//  BurntSushi/toml instead, the same way internal/boards decodes its
+//  [[board]] array-of-tables.
+//
+//  Platform packs install under ~/.tsuki/platforms/<name>/<version>/ — a
+//  fixed path, not routed through LibsDir()'s config/env indirection,
+//  since a platform isn't a project dependency declared in goduino.json,
+//  it's machine-wide build metadata closer in spirit to ~/.tsuki/boards.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/tsuki/cli/internal/config"
+)
+
+// Platform is one installed platform/variant pack.
+type Platform struct {
+	Name    string
+	Version string
+
+	Core       string
+	Variant    string
+	Ldscript   string
+	FCPU       string
+	MCU        string
+	ExtraFlags []string
+
+	// BoardMappings maps a short board id (what --board accepts) to this
+	// platform's variant folder, e.g. "bluepill" -> "BLUEPILL_F103C8".
+	BoardMappings map[string]string
+
+	Path string // tsukilib.toml this was parsed from
+}
+
+type platformToml struct {
+	Package struct {
+		Kind string `toml:"kind"`
+	} `toml:"package"`
+	Platform struct {
+		Core          string            `toml:"core"`
+		Variant       string            `toml:"variant"`
+		Ldscript      string            `toml:"ldscript"`
+		FCPU          string            `toml:"f_cpu"`
+		MCU           string            `toml:"mcu"`
+		ExtraFlags    []string          `toml:"extra_flags"`
+		BoardMappings map[string]string `toml:"board_mappings"`
+	} `toml:"platform"`
+}
+
+// PlatformsDir returns ~/.tsuki/platforms.
+func PlatformsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".tsuki", "platforms")
+	}
+	return filepath.Join(home, ".tsuki", "platforms")
+}
+
+// IsPlatformPackage reports whether a tsukilib.toml declares
+// `kind = "platform"` in its [package] table.
+func IsPlatformPackage(tomlData string) bool {
+	var pt platformToml
+	if _, err := toml.Decode(tomlData, &pt); err != nil {
+		return false
+	}
+	return pt.Package.Kind == "platform"
+}
+
+// decodePlatform parses one tsukilib.toml's [package] + [platform] tables
+// into a Platform.
+func decodePlatform(tomlData, path string) (Platform, error) {
+	name, version, _, _, _, err := parseTOMLMeta(tomlData)
+	if err != nil {
+		return Platform{}, err
+	}
+	var pt platformToml
+	if _, err := toml.Decode(tomlData, &pt); err != nil {
+		return Platform{}, fmt.Errorf("parsing [platform] table: %w", err)
+	}
+	return Platform{
+		Name:          name,
+		Version:       version,
+		Core:          pt.Platform.Core,
+		Variant:       pt.Platform.Variant,
+		Ldscript:      pt.Platform.Ldscript,
+		FCPU:          pt.Platform.FCPU,
+		MCU:           pt.Platform.MCU,
+		ExtraFlags:    pt.Platform.ExtraFlags,
+		BoardMappings: pt.Platform.BoardMappings,
+		Path:          path,
+	}, nil
+}
+
+// ListPlatforms returns every installed platform pack, sorted by name.
+func ListPlatforms() ([]Platform, error) {
+	root := PlatformsDir()
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading platforms dir: %w", err)
+	}
+
+	var platforms []Platform
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		versions, _ := os.ReadDir(filepath.Join(root, e.Name()))
+		for _, v := range versions {
+			if !v.IsDir() {
+				continue
+			}
+			path := filepath.Join(root, e.Name(), v.Name(), "tsukilib.toml")
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			p, err := decodePlatform(string(data), path)
+			if err != nil {
+				continue
+			}
+			platforms = append(platforms, p)
+		}
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].Name < platforms[j].Name })
+	return platforms, nil
+}
+
+// InstallPlatform fetches a tsukilib package declaring kind = "platform"
+// and installs it under PlatformsDir(), mirroring installFromSource's
+// fetch/verify/write steps but targeting the platforms tree instead of
+// LibsDir().
+func InstallPlatform(opts InstallOptions) (*Platform, error) {
+	tomlData, err := fetchTOML(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsPlatformPackage(tomlData) {
+		return nil, fmt.Errorf("%s does not declare kind = \"platform\"", opts.Source)
+	}
+
+	name, version, _, _, _, err := parseTOMLMeta(tomlData)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Version != "" {
+		version = opts.Version
+	}
+
+	cfg, _ := config.Load()
+	if cfg != nil && cfg.VerifySignatures {
+		if err := verifySignature(name, opts.Source, tomlData, cfg); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %s@%s: %w", name, version, err)
+		}
+	}
+
+	destDir := filepath.Join(PlatformsDir(), name, version)
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating platform dir: %w", err)
+	}
+	destFile := filepath.Join(destDir, "tsukilib.toml")
+	if err := os.WriteFile(destFile, []byte(tomlData), 0644); err != nil {
+		return nil, fmt.Errorf("writing tsukilib.toml: %w", err)
+	}
+
+	p, err := decodePlatform(tomlData, destFile)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// RemovePlatform uninstalls one platform pack version.
+func RemovePlatform(name, version string) error {
+	dir := filepath.Join(PlatformsDir(), name, version)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return fmt.Errorf("platform %s@%s is not installed", name, version)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing %s: %w", dir, err)
+	}
+	parent := filepath.Join(PlatformsDir(), name)
+	if entries, _ := os.ReadDir(parent); len(entries) == 0 {
+		os.Remove(parent)
+	}
+	return nil
+}
+
+// ResolveBoardPlatform looks through every installed platform pack's
+// board_mappings for id, returning the first match and the variant folder
+// it maps to. This is how a board id that arduino-cli doesn't know about
+// (BluePill, a Teensy variant, ...) still resolves to something buildable.
+func ResolveBoardPlatform(id string) (Platform, string, bool) {
+	platforms, err := ListPlatforms()
+	if err != nil {
+		return Platform{}, "", false
+	}
+	for _, p := range platforms {
+		if variant, ok := p.BoardMappings[id]; ok {
+			return p, variant, true
+		}
+	}
+	return Platform{}, "", false
+}
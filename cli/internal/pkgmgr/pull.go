@@ -0,0 +1,369 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: pull  —  concurrent PullAll with DAG-aware scheduling
+//
+//  PullAll used to install every dependency one at a time in a for loop,
+//  which is painful for a project with 20+ libs on a slow link. It now
+//  drives a bounded worker pool (PullOptions.Concurrency, default
+//  runtime.NumCPU()) instead, reporting PullStart/Downloaded/Installed/Failed
+//  events to an optional PullOptions.Progress callback so the CLI can render
+//  a live multi-bar the way runPullAll's ui.SpinnerGroup already does for
+//  the manifest-driven `tsuki install` path.
+//
+//  When tsuki.lock is present, each LockEntry already carries its own
+//  Dependencies (recorded by WriteLock from ResolveGraph's resolvedNode.Deps
+//  — see resolve.go), so runPullJobs schedules lock-driven pulls as a real
+//  DAG: a node only starts once every dependency it lists has finished,
+//  so transitive deps install in topological waves instead of racing their
+//  own dependents. The no-lockfile path has no such graph to schedule from
+//  — FindPkgs only resolves the manifest's own top-level deps, not each
+//  match's further dependencies — so those jobs run as an unordered flat
+//  batch, same as before.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/tsuki/cli/internal/db"
+	"github.com/tsuki/cli/internal/manifest"
+	"github.com/tsuki/cli/internal/ui"
+)
+
+// PullResult holds the outcome for a single package during PullAll.
+type PullResult struct {
+	Name    string
+	Version string
+	Err     error
+}
+
+// PullEventKind identifies one PullEvent's stage in a pull.
+type PullEventKind int
+
+const (
+	PullStart PullEventKind = iota
+	PullDownloaded
+	PullInstalled
+	PullFailed
+)
+
+// PullEvent is one step of one job in a PullAll run, sent to
+// PullOptions.Progress as it happens. Install fetches, verifies, and writes
+// a package in a single call, so PullDownloaded and PullInstalled are
+// emitted back-to-back rather than the caller seeing the download finish
+// before the write starts — the split exists for a future finer-grained
+// Install hook to report into without another event-shape change.
+type PullEvent struct {
+	Kind    PullEventKind
+	Name    string
+	Version string
+	Err     error // set on PullFailed
+}
+
+// PullOptions configures PullAll's worker pool.
+type PullOptions struct {
+	// Concurrency caps how many jobs run at once. Defaults to
+	// runtime.NumCPU() when <= 0.
+	Concurrency int
+
+	// Progress, if set, is called for every PullEvent as it happens. It's
+	// called concurrently from worker goroutines — a caller rendering a
+	// multi-bar must synchronize its own state.
+	Progress func(PullEvent)
+
+	// FailFast cancels every not-yet-started job as soon as one fails,
+	// instead of letting the rest of the batch run to completion.
+	FailFast bool
+}
+
+// pullJob is one package PullAll needs to install, plus enough information
+// to schedule it relative to its dependencies.
+type pullJob struct {
+	name    string
+	version string
+	spec    string
+	deps    []string         // names of other jobs in this same batch that must finish first
+	lock    *LockEntry       // non-nil for a lock-driven job — checked for registry tampering before install
+	dep     manifest.DepSpec // non-zero for a manifest-driven job with a path or git source
+}
+
+// PullAll installs every dependency from the manifest at dir.
+func PullAll(dir string, opts PullOptions) ([]PullResult, error) {
+	lockEntries, _ := ReadLock(dir)
+	if len(lockEntries) > 0 {
+		jobs := make([]pullJob, len(lockEntries))
+		for i := range lockEntries {
+			le := lockEntries[i]
+			deps := make([]string, 0, len(le.Dependencies))
+			for _, d := range le.Dependencies {
+				deps = append(deps, d.Name)
+			}
+			jobs[i] = pullJob{name: le.Name, version: le.Version, spec: le.Name + ":" + le.Version, deps: deps, lock: &le}
+		}
+		results, _, err := runPullJobs(jobs, dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	// No lock file — fall back to the project manifest (tsuki-config.toml
+	// or tsuki_package.json), merging in whatever [target.*] override table
+	// matches the active board and host OS/arch (see DependenciesFor).
+	_, m, _, err := manifest.Find(dir)
+	if err != nil {
+		return nil, err
+	}
+	deps := m.DependenciesFor(m.Board, runtime.GOARCH)
+
+	// Path and git dependencies have no registry entry to look up at all —
+	// only registry-sourced deps go through the FindPkgs preflight below.
+	registrySpecs := make([]string, 0, len(deps))
+	specOf := make(map[string]string, len(deps)) // spec -> original dep name
+	sourceJobs := make([]pullJob, 0, len(deps))
+	for name, dep := range deps {
+		if dep.Path != "" || dep.Git != "" {
+			sourceJobs = append(sourceJobs, pullJob{name: name, dep: dep})
+			continue
+		}
+		spec := name
+		if dep.Version != "" {
+			spec = name + ":" + dep.Version
+		}
+		registrySpecs = append(registrySpecs, spec)
+		specOf[spec] = name
+	}
+
+	// One indexed db.FindPkgs batch query up front reports every unresolvable
+	// dependency immediately, instead of each one only surfacing after its
+	// own Install call reaches resolveSpecURL and fails in turn.
+	var preResults []PullResult
+	notFoundSet := make(map[string]bool)
+	if d, dbErr := db.Open(db.DefaultPath()); dbErr == nil {
+		_, notFound, findErr := d.FindPkgs(registrySpecs)
+		d.Close()
+		if findErr == nil {
+			for _, spec := range notFound {
+				notFoundSet[spec] = true
+				preResults = append(preResults, PullResult{
+					Name: specOf[spec],
+					Err:  fmt.Errorf("package %q not found in local registry cache — run `tsuki updatedb` first", specOf[spec]),
+				})
+			}
+		}
+	}
+
+	jobs := make([]pullJob, 0, len(registrySpecs)+len(sourceJobs))
+	for _, spec := range registrySpecs {
+		if notFoundSet[spec] {
+			continue
+		}
+		jobs = append(jobs, pullJob{name: specOf[spec], spec: spec})
+	}
+	jobs = append(jobs, sourceJobs...)
+
+	results, installed, err := runPullJobs(jobs, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	results = append(preResults, results...)
+
+	// Write the concrete versions every range/"latest" spec resolved to, so
+	// a repeat `tsuki pull` is reproducible instead of re-resolving the same
+	// ranges against whatever the registry cache looks like at the time.
+	if len(installed) > 0 {
+		if err := WriteLock(dir, installed); err != nil {
+			ui.Warn(fmt.Sprintf("writing tsuki.lock: %v", err))
+		}
+	}
+	return results, nil
+}
+
+// runPullJobs schedules jobs onto a bounded worker pool, respecting each
+// job's deps as a DAG: a job is only handed to a worker once every
+// in-batch dependency it lists has already finished. A dep naming a
+// package outside this batch (already installed, or not itself part of
+// the pull) is simply not waited on.
+//
+// A cyclic dependency graph (A needs B, B needs A — ResolveGraph doesn't
+// reject these, so a registry bug or a hand-edited tsuki.lock can produce
+// one) would otherwise leave every job in the cycle stuck at indegree >= 1
+// forever: the queue never fills, done never reaches len(jobs), and
+// wg.Wait() below blocks forever with nothing ever started. detectCycle
+// catches that up front, before any worker is spawned.
+func runPullJobs(jobs []pullJob, dir string, opts PullOptions) ([]PullResult, []InstalledPackage, error) {
+	if len(jobs) == 0 {
+		return nil, nil, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	byName := make(map[string]*pullJob, len(jobs))
+	for i := range jobs {
+		byName[jobs[i].name] = &jobs[i]
+	}
+	indegree := make(map[string]int, len(jobs))
+	children := make(map[string][]string)
+	for i := range jobs {
+		for _, dep := range jobs[i].deps {
+			if _, ok := byName[dep]; !ok {
+				continue // not part of this batch — nothing to wait on
+			}
+			indegree[jobs[i].name]++
+			children[dep] = append(children[dep], jobs[i].name)
+		}
+	}
+
+	if cyclic := detectCycle(jobs, indegree, children); len(cyclic) > 0 {
+		return nil, nil, fmt.Errorf("circular dependency among packages: %s", strings.Join(cyclic, ", "))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := make(chan *pullJob, len(jobs))
+	for i := range jobs {
+		if indegree[jobs[i].name] == 0 {
+			queue <- &jobs[i]
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		results   []PullResult
+		installed []InstalledPackage
+		done      int
+		aborted   bool
+		wg        sync.WaitGroup
+	)
+
+	emit := func(ev PullEvent) {
+		if opts.Progress != nil {
+			opts.Progress(ev)
+		}
+	}
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j, ok := <-queue:
+					if !ok {
+						return
+					}
+					res, pkg := runPullJob(j, dir, emit)
+
+					mu.Lock()
+					results = append(results, res)
+					if pkg != nil {
+						installed = append(installed, *pkg)
+					}
+					done++
+					if res.Err != nil && opts.FailFast {
+						aborted = true
+						cancel()
+					}
+					if !aborted {
+						for _, childName := range children[j.name] {
+							indegree[childName]--
+							if indegree[childName] == 0 {
+								queue <- byName[childName]
+							}
+						}
+					}
+					if done == len(jobs) {
+						close(queue)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, installed, nil
+}
+
+// detectCycle runs Kahn's algorithm against a copy of indegree/children —
+// the same topological order runPullJobs schedules by — and returns the
+// names still stuck at indegree > 0 once no more zero-indegree job remains
+// to process. A nil result means the graph is acyclic.
+func detectCycle(jobs []pullJob, indegree map[string]int, children map[string][]string) []string {
+	remaining := make(map[string]int, len(indegree))
+	for name, n := range indegree {
+		remaining[name] = n
+	}
+
+	queue := make([]string, 0, len(jobs))
+	for i := range jobs {
+		if remaining[jobs[i].name] == 0 {
+			queue = append(queue, jobs[i].name)
+		}
+	}
+
+	processed := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		processed++
+		for _, child := range children[name] {
+			remaining[child]--
+			if remaining[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	if processed == len(jobs) {
+		return nil
+	}
+
+	var stuck []string
+	for i := range jobs {
+		if remaining[jobs[i].name] > 0 {
+			stuck = append(stuck, jobs[i].name)
+		}
+	}
+	return stuck
+}
+
+// runPullJob installs one job, emitting its lifecycle to emit.
+func runPullJob(j *pullJob, dir string, emit func(PullEvent)) (PullResult, *InstalledPackage) {
+	emit(PullEvent{Kind: PullStart, Name: j.name, Version: j.version})
+
+	if j.lock != nil {
+		if err := checkRegistryTampering(*j.lock); err != nil {
+			emit(PullEvent{Kind: PullFailed, Name: j.name, Err: err})
+			return PullResult{Name: j.name, Err: err}, nil
+		}
+	}
+
+	var pkg *InstalledPackage
+	var err error
+	if j.dep.Path != "" || j.dep.Git != "" {
+		pkg, err = installSourceDep(j.name, j.dep, dir)
+	} else {
+		pkg, err = Install(InstallOptions{Spec: j.spec, Dir: dir})
+	}
+	if err != nil {
+		emit(PullEvent{Kind: PullFailed, Name: j.name, Err: err})
+		return PullResult{Name: j.name, Err: err}, nil
+	}
+
+	emit(PullEvent{Kind: PullDownloaded, Name: pkg.Name, Version: pkg.Version})
+	emit(PullEvent{Kind: PullInstalled, Name: pkg.Name, Version: pkg.Version})
+	return PullResult{Name: pkg.Name, Version: pkg.Version}, pkg
+}
@@ -0,0 +1,166 @@
+package pkgmgr
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingProgress collects PullStart events in the order workers actually
+// begin each job, guarded by a mutex since Progress is called concurrently.
+func recordingProgress() (func(PullEvent), func() []string) {
+	var mu sync.Mutex
+	var started []string
+	return func(ev PullEvent) {
+			if ev.Kind == PullStart {
+				mu.Lock()
+				started = append(started, ev.Name)
+				mu.Unlock()
+			}
+		}, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), started...)
+		}
+}
+
+// indexOf returns the position of name in started, or -1.
+func indexOf(started []string, name string) int {
+	for i, s := range started {
+		if s == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunPullJobsEmptyBatch(t *testing.T) {
+	results, installed, err := runPullJobs(nil, t.TempDir(), PullOptions{})
+	if results != nil || installed != nil || err != nil {
+		t.Errorf("runPullJobs(nil) = %v, %v, %v; want nil, nil, nil", results, installed, err)
+	}
+}
+
+func TestRunPullJobsRespectsDependencyOrder(t *testing.T) {
+	// C depends on B, B depends on A — every job's Install fails fast (empty
+	// spec), but the DAG scheduling must still only start each job once its
+	// in-batch dependencies have finished, regardless of install outcome.
+	jobs := []pullJob{
+		{name: "a"},
+		{name: "b", deps: []string{"a"}},
+		{name: "c", deps: []string{"b"}},
+	}
+	progress, started := recordingProgress()
+
+	dir := t.TempDir()
+	results, _, err := runPullJobs(jobs, dir, PullOptions{Progress: progress})
+	if err != nil {
+		t.Fatalf("runPullJobs: unexpected error: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			t.Errorf("job %q: expected an install error (empty spec), got nil", r.Name)
+		}
+	}
+
+	order := started()
+	ia, ib, ic := indexOf(order, "a"), indexOf(order, "b"), indexOf(order, "c")
+	if ia < 0 || ib < 0 || ic < 0 {
+		t.Fatalf("not every job started: %v", order)
+	}
+	if !(ia < ib && ib < ic) {
+		t.Errorf("start order = %v, want a before b before c", order)
+	}
+}
+
+func TestRunPullJobsIgnoresOutOfBatchDeps(t *testing.T) {
+	// "missing" isn't itself a job in this batch, so depending on it must
+	// not block "solo" from ever being scheduled.
+	jobs := []pullJob{
+		{name: "solo", deps: []string{"missing"}},
+	}
+	progress, started := recordingProgress()
+
+	results, _, err := runPullJobs(jobs, t.TempDir(), PullOptions{Progress: progress})
+	if err != nil {
+		t.Fatalf("runPullJobs: unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if indexOf(started(), "solo") < 0 {
+		t.Error("job depending on an out-of-batch package never started")
+	}
+}
+
+func TestRunPullJobsFailFastSkipsDownstream(t *testing.T) {
+	// b depends on a, c depends on b. a's install fails immediately; with
+	// FailFast set neither b nor c — both downstream of the failure — should
+	// ever be started.
+	jobs := []pullJob{
+		{name: "a"},
+		{name: "b", deps: []string{"a"}},
+		{name: "c", deps: []string{"b"}},
+	}
+	progress, started := recordingProgress()
+
+	results, _, err := runPullJobs(jobs, t.TempDir(), PullOptions{FailFast: true, Progress: progress})
+	if err != nil {
+		t.Fatalf("runPullJobs: unexpected error: %v", err)
+	}
+
+	ran := make(map[string]bool, len(results))
+	for _, r := range results {
+		ran[r.Name] = true
+	}
+	if !ran["a"] {
+		t.Error("job a never ran")
+	}
+	if ran["b"] || ran["c"] {
+		t.Errorf("FailFast: downstream jobs ran after their dependency failed: %v", results)
+	}
+
+	order := started()
+	if indexOf(order, "b") >= 0 || indexOf(order, "c") >= 0 {
+		t.Errorf("FailFast: downstream jobs were started after their dependency failed: %v", order)
+	}
+}
+
+func TestRunPullJobsRejectsCycle(t *testing.T) {
+	// a depends on b and b depends on a — neither ever reaches indegree 0,
+	// so without up-front cycle detection this call hangs forever instead
+	// of returning an error.
+	jobs := []pullJob{
+		{name: "a", deps: []string{"b"}},
+		{name: "b", deps: []string{"a"}},
+	}
+
+	done := make(chan struct{})
+	var results []PullResult
+	var err error
+	go func() {
+		results, _, err = runPullJobs(jobs, t.TempDir(), PullOptions{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runPullJobs hung on a cyclic dependency graph instead of returning an error")
+	}
+
+	if err == nil {
+		t.Fatal("runPullJobs with a cyclic dependency graph: expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "circular dependency") {
+		t.Errorf("runPullJobs error = %q, want it to mention a circular dependency", err.Error())
+	}
+	if results != nil {
+		t.Errorf("runPullJobs with a cyclic dependency graph: got results %v, want none", results)
+	}
+}
@@ -0,0 +1,123 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: registries  —  per-registry release signatures
+//
+//  A db.Entry's Sig field is a detached Ed25519 signature over one specific
+//  release's bytes, declared inline in packages.json — distinct from (and
+//  verified independently of) verifySignature's ".sig"-file-plus-key-index
+//  mechanism above, which is gated by cfg.VerifySignatures and checks a
+//  separately-published detached signature file. This file's pubkeys come
+//  from ~/.config/tsuki/registries.toml, a small trust store the operator
+//  maintains by hand, the same way keys.json seeds updatedb's per-registry
+//  signing keys.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tsuki/cli/internal/integrity"
+)
+
+// registryPubkey is one [[registries]] entry in registries.toml.
+type registryPubkey struct {
+	Name      string `toml:"name"`
+	PublicKey string `toml:"public_key"` // base64-encoded raw Ed25519 public key
+}
+
+type registriesConfig struct {
+	Registries []registryPubkey `toml:"registries"`
+}
+
+// registriesConfigPath returns ~/.config/tsuki/registries.toml.
+func registriesConfigPath() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "tsuki", "registries.toml")
+}
+
+// loadRegistryPubkeys reads registries.toml and returns its entries keyed by
+// registry name. A missing file is not an error — it just means no registry
+// has a pubkey configured, so release signatures go unverified.
+func loadRegistryPubkeys() (map[string]string, error) {
+	path := registriesConfigPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var cfg registriesConfig
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	pubkeys := make(map[string]string, len(cfg.Registries))
+	for _, r := range cfg.Registries {
+		pubkeys[r.Name] = r.PublicKey
+	}
+	return pubkeys, nil
+}
+
+// verifyReleaseSig verifies sigB64, a base64-encoded raw Ed25519 signature a
+// registry declared for one release's exact bytes, against the pubkey
+// registries.toml configures for registryName. A registry with no configured
+// pubkey is not an error — there's simply nothing to check the signature
+// against, so the declared sig is ignored rather than enforced.
+func verifyReleaseSig(registryName string, data []byte, sigB64 string) error {
+	pubkeys, err := loadRegistryPubkeys()
+	if err != nil {
+		return err
+	}
+	pubKeyB64, ok := pubkeys[registryName]
+	if !ok || pubKeyB64 == "" {
+		return nil
+	}
+
+	pubBytes, err := base64.StdEncoding.DecodeString(pubKeyB64)
+	if err != nil {
+		return fmt.Errorf("decoding public_key for registry %q: %w", registryName, err)
+	}
+	if len(pubBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("public_key for registry %q is %d bytes, want %d", registryName, len(pubBytes), ed25519.PublicKeySize)
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(sigB64)
+	if err != nil {
+		return fmt.Errorf("decoding release signature: %w", err)
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid release signature length %d (expected %d)", len(sigBytes), ed25519.SignatureSize)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pubBytes), data, sigBytes) {
+		return fmt.Errorf("release signature does not match downloaded data")
+	}
+	return nil
+}
+
+// verifyReleaseIntegrity enforces whatever integrity metadata cand's registry
+// entry actually declared: a digest mismatch or a signature that doesn't
+// verify both fail closed, while a field the entry left blank is silently
+// skipped rather than treated as a failure.
+func verifyReleaseIntegrity(cand specCandidate, data []byte) error {
+	if err := integrity.VerifyData(data, cand.digest); err != nil {
+		return err
+	}
+	if cand.sig != "" {
+		if err := verifyReleaseSig(cand.registry, data, cand.sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,560 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: resolve  —  transitive dependency resolution
+//
+//  InstallFromRegistry used to write exactly one tsukilib.toml and never
+//  looked past it. ResolveGraph walks a package's [dependencies] (parsed
+//  out of every fetched tsukilib.toml via BurntSushi/toml — a real table,
+//  not the flat key/value lines parseTOMLMeta handles) and picks, for each
+//  name it encounters, the highest version satisfying every constraint
+//  accumulated on it so far.
+//
+//  This is a worklist, not a backtracking solver: a name's candidate is
+//  only recomputed (and its own dependencies only re-walked) when a newly
+//  arrived constraint actually changes which version wins. Because each
+//  new constraint can only narrow the candidate set, a name's winning
+//  version is non-increasing across revisits, so the worklist always
+//  drains — there's no solver state to abandon and retry, just a sharper
+//  conflict error (the full chain of requirements that left nothing
+//  standing) when nothing satisfies them all.
+//
+//  Versions come from whichever registry source already has the package —
+//  sparseIndexBase's per-package index first (sparseVersion already
+//  carries Deps/Yanked, so a hit there needs no manifest fetch at all),
+//  falling back to the legacy monolithic fetchRegistryFromURL map (which
+//  has neither, so its candidates' dependencies are only known once their
+//  manifest is actually fetched).
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tsuki/cli/internal/config"
+	"github.com/tsuki/cli/internal/manifest"
+	"github.com/tsuki/cli/internal/registry"
+)
+
+// depManifest is the subset of a tsukilib.toml the resolver needs —
+// decoded with BurntSushi/toml since [dependencies] is a real table,
+// unlike the flat lines parseTOMLMeta's line-based parser handles.
+type depManifest struct {
+	Dependencies    map[string]string `toml:"dependencies"`
+	DevDependencies map[string]string `toml:"dev-dependencies"`
+}
+
+func decodeDepManifest(tomlData string) (depManifest, error) {
+	var m depManifest
+	_, err := toml.Decode(tomlData, &m)
+	return m, err
+}
+
+// versionCandidate is one version of a package available to the resolver.
+// Deps is nil when the source that produced it (the legacy monolithic
+// map) doesn't carry per-version dependency data inline — the resolver
+// fetches that candidate's manifest to learn them only if it's chosen.
+type versionCandidate struct {
+	Version string
+	TomlURL string
+	Deps    map[string]string
+	Yanked  bool
+}
+
+// resolvedNode is one package ResolveGraph decided to install. Data holds
+// the already-fetched tsukilib.toml body when resolution needed it anyway
+// (to read its dependencies) — Install reuses it instead of fetching
+// twice; it's empty when the candidate came from a sparse index entry
+// whose Deps were enough on their own. Deps holds this node's own direct
+// dependencies at the versions the resolver actually picked for them —
+// materializeNode carries it straight into InstalledPackage.Dependencies
+// for WriteLock.
+type resolvedNode struct {
+	Name       string
+	Version    string
+	TomlURL    string
+	Data       string
+	Deps       []LockDependency
+	Constraint string // every accumulated requirement, as "<from> requires <expr>", joined for the lockfile
+}
+
+// constraintRecord is one accumulated requirement on a package, kept
+// around so a resolution failure can report the chain that produced it.
+type constraintRecord struct {
+	expr string
+	from string // the package (or "<root>") that required it
+}
+
+// resolveWork is one queued requirement: "from wants name at expr".
+type resolveWork struct {
+	name, expr, from string
+}
+
+// availableVersions looks up name across every configured registry URL, in
+// order — the first one that has the package wins, same as
+// InstallFromRegistry's own ordering. A sparse-capable registry is always
+// preferred over the legacy monolithic fetch for whichever URL offers it.
+func availableVersions(name string) ([]versionCandidate, error) {
+	cfg, _ := config.Load()
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	var lastErr error
+	for _, regURL := range cfg.ResolvedRegistryURLs() {
+		if base, ok := sparseIndexBase(regURL); ok {
+			versions, err := fetchSparsePackage(base, name)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if versions == nil {
+				continue // this registry doesn't have the package
+			}
+			out := make([]versionCandidate, len(versions))
+			for i, v := range versions {
+				out[i] = versionCandidate{Version: v.Version, TomlURL: v.TomlURL, Deps: v.Deps, Yanked: v.Yanked}
+			}
+			return out, nil
+		}
+
+		idx, err := fetchRegistryFromURL(regURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		entry, ok := idx.Packages[name]
+		if !ok {
+			continue
+		}
+		out := make([]versionCandidate, 0, len(entry.Versions))
+		for v, url := range entry.Versions {
+			out = append(out, versionCandidate{Version: v, TomlURL: url})
+		}
+		return out, nil
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("package %q not found in any configured registry", name)
+}
+
+// normalizeConstraint lets a caller write the Cargo/npm-style comma form
+// (">=1.0, <2.0") that registry.ParseConstraint's space-separated AND
+// groups don't accept on their own.
+func normalizeConstraint(expr string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(expr, ",", " ")), " ")
+}
+
+// pickConstrainedVersion picks the highest version in candidates
+// satisfying every requirement in recs, excluding yanked versions unless
+// one is pinned to lockedVersion exactly (an existing lockfile entry
+// should never be invalidated by a version getting yanked out from under
+// it later).
+func pickConstrainedVersion(candidates []versionCandidate, recs []constraintRecord, lockedVersion string) (versionCandidate, error) {
+	constraints := make([]registry.Constraint, 0, len(recs))
+	for _, r := range recs {
+		c, err := registry.ParseConstraint(normalizeConstraint(r.expr))
+		if err != nil {
+			return versionCandidate{}, fmt.Errorf("invalid version requirement %q (from %s): %w", r.expr, r.from, err)
+		}
+		constraints = append(constraints, c)
+	}
+
+	var best *versionCandidate
+	var bestVer registry.Version
+	for i := range candidates {
+		c := candidates[i]
+		if c.Yanked && c.Version != lockedVersion {
+			continue
+		}
+		v, err := registry.ParseVersion(c.Version)
+		if err != nil {
+			continue
+		}
+		satisfies := true
+		for _, constraint := range constraints {
+			if !constraint.Check(v) {
+				satisfies = false
+				break
+			}
+		}
+		if !satisfies {
+			continue
+		}
+		if best == nil || v.Compare(bestVer) > 0 {
+			best, bestVer = &c, v
+		}
+	}
+	if best == nil {
+		return versionCandidate{}, conflictError(recs)
+	}
+	return *best, nil
+}
+
+// conflictError reports the full chain of requirements that left no
+// candidate version standing.
+func conflictError(recs []constraintRecord) error {
+	parts := make([]string, len(recs))
+	for i, r := range recs {
+		parts[i] = fmt.Sprintf("%s requires %s", r.from, r.expr)
+	}
+	return fmt.Errorf("no version satisfies every requirement:\n  %s", strings.Join(parts, "\n  "))
+}
+
+// ResolveGraph resolves name's full transitive dependency graph, starting
+// from rootVersion — an exact version, a range expression, or "" for
+// "latest" — against every registry configured in config.json.
+// lockPins carries a project's existing tsuki.lock entries (name →
+// version) so an already-locked package doesn't get evicted purely
+// because it was yanked after the fact; pass nil when there's no lockfile
+// in play (e.g. a bare `tsuki pkg install`, with no project to lock).
+//
+// A package's own [dev-dependencies] are never walked transitively — same
+// convention npm/Cargo use — so installing a library never silently pulls
+// in the tooling it tests itself with.
+func ResolveGraph(name, rootVersion string, lockPins map[string]string) ([]resolvedNode, error) {
+	rootExpr := rootVersion
+	switch {
+	case rootExpr == "":
+		rootExpr = "*"
+	default:
+		if _, err := registry.ParseVersion(rootExpr); err == nil {
+			rootExpr = "=" + rootExpr // an exact pin, not a range
+		}
+	}
+
+	constraints := map[string][]constraintRecord{}
+	chosen := map[string]versionCandidate{}
+	data := map[string]string{}
+	directDeps := map[string][]string{} // name -> names of its own direct deps, at the winning version
+	var order []string
+
+	work := []resolveWork{{name: name, expr: rootExpr, from: "<root>"}}
+	for len(work) > 0 {
+		item := work[0]
+		work = work[1:]
+
+		constraints[item.name] = append(constraints[item.name], constraintRecord{expr: item.expr, from: item.from})
+
+		candidates, err := availableVersions(item.name)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s (required by %s): %w", item.name, item.from, err)
+		}
+
+		picked, err := pickConstrainedVersion(candidates, constraints[item.name], lockPins[item.name])
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", item.name, err)
+		}
+
+		if prev, ok := chosen[item.name]; ok && prev.Version == picked.Version {
+			continue // unchanged — its dependencies are already queued
+		}
+		if _, ok := chosen[item.name]; !ok {
+			order = append(order, item.name)
+		}
+		chosen[item.name] = picked
+
+		deps := picked.Deps
+		if deps == nil {
+			tomlData, err := fetchTOML(picked.TomlURL)
+			if err != nil {
+				return nil, fmt.Errorf("fetching %s@%s: %w", item.name, picked.Version, err)
+			}
+			m, err := decodeDepManifest(tomlData)
+			if err != nil {
+				return nil, fmt.Errorf("parsing dependencies for %s@%s: %w", item.name, picked.Version, err)
+			}
+			data[item.name] = tomlData
+			deps = m.Dependencies
+		}
+
+		names := make([]string, 0, len(deps))
+		for depName, depExpr := range deps {
+			work = append(work, resolveWork{name: depName, expr: depExpr, from: item.name})
+			names = append(names, depName)
+		}
+		sort.Strings(names)
+		directDeps[item.name] = names
+	}
+
+	nodes := make([]resolvedNode, 0, len(order))
+	for _, n := range order {
+		c := chosen[n]
+		depNames := directDeps[n]
+		nodeDeps := make([]LockDependency, 0, len(depNames))
+		for _, depName := range depNames {
+			nodeDeps = append(nodeDeps, LockDependency{Name: depName, Version: chosen[depName].Version})
+		}
+		reqs := make([]string, len(constraints[n]))
+		for i, r := range constraints[n] {
+			reqs[i] = fmt.Sprintf("%s requires %s", r.from, r.expr)
+		}
+		nodes = append(nodes, resolvedNode{
+			Name: n, Version: c.Version, TomlURL: c.TomlURL, Data: data[n],
+			Deps: nodeDeps, Constraint: strings.Join(reqs, "; "),
+		})
+	}
+	return nodes, nil
+}
+
+// ── Outdated / Upgrade ───────────────────────────────────────────────────────
+
+// OutdatedPackage is one installed package that has a newer version
+// published in a configured registry.
+type OutdatedPackage struct {
+	Name             string
+	CurrentVersion   string
+	LatestVersion    string // highest published, non-yanked version
+	LatestSatisfying string // highest version satisfying dir's manifest constraint, if any; "" if no constraint is recorded
+}
+
+// highestVersion picks the highest non-yanked candidate satisfying expr
+// (or any, for expr == "").
+func highestVersion(candidates []versionCandidate, expr string) (string, bool) {
+	if expr == "" {
+		expr = "*"
+	}
+	v, err := pickConstrainedVersion(candidates, []constraintRecord{{expr: expr, from: "<outdated>"}}, "")
+	if err != nil {
+		return "", false
+	}
+	return v.Version, true
+}
+
+// Outdated compares every package ListInstalled reports against the
+// registries configured in config.json, reporting the newest version
+// published for each — and, when dir's manifest records a [dependencies]
+// entry for that package, the newest version still satisfying it. A
+// package this repo's registries don't know about (a local or ad hoc
+// install) is silently skipped rather than reported as an error.
+func Outdated(dir string) ([]OutdatedPackage, error) {
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, err
+	}
+
+	constraintFor := map[string]string{}
+	if _, m, _, err := manifest.Find(dir); err == nil && m != nil {
+		for name, dep := range m.Dependencies {
+			constraintFor[name] = dep.Version
+		}
+	}
+
+	var out []OutdatedPackage
+	for _, p := range installed {
+		candidates, err := availableVersions(p.Name)
+		if err != nil {
+			continue
+		}
+		latest, ok := highestVersion(candidates, "")
+		if !ok || latest == p.Version {
+			continue
+		}
+
+		op := OutdatedPackage{Name: p.Name, CurrentVersion: p.Version, LatestVersion: latest}
+		if expr, ok := constraintFor[p.Name]; ok {
+			if v, ok := highestVersion(candidates, expr); ok {
+				op.LatestSatisfying = v
+			}
+		}
+		out = append(out, op)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// UpgradeOptions controls a single-package Upgrade.
+type UpgradeOptions struct {
+	Dir     string // project root — consulted for its manifest constraint and tsuki.lock
+	Version string // explicit target version/constraint; "" defers to Dir's manifest, then "latest"
+}
+
+// Upgrade re-resolves name's dependency graph at the newest version
+// satisfying opts.Version (falling back to the project manifest's
+// recorded constraint, then to "latest" with neither), materializing
+// every resolved node through materializeNodeAtomic before touching
+// tsuki.lock. Each node is swapped in atomically, so no single install is
+// ever torn; a failure partway through leaves the nodes materialized before
+// it upgraded, and tsuki.lock is only updated once every node succeeded.
+func Upgrade(name string, opts UpgradeOptions) (*InstalledPackage, error) {
+	target := opts.Version
+	if target == "" {
+		if _, m, _, err := manifest.Find(opts.Dir); err == nil && m != nil {
+			if dep, ok := m.Dependencies[name]; ok {
+				target = dep.Version
+			}
+		}
+	}
+
+	nodes, err := ResolveGraph(name, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *InstalledPackage
+	pkgs := make([]InstalledPackage, 0, len(nodes))
+	for _, node := range nodes {
+		pkg, err := materializeNodeAtomic(node)
+		if err != nil {
+			return nil, fmt.Errorf("upgrading %s@%s: %w", node.Name, node.Version, err)
+		}
+		pkgs = append(pkgs, *pkg)
+		if node.Name == name {
+			root = pkg
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("package %q not found in any registry", name)
+	}
+
+	if opts.Dir != "" {
+		if err := mergeLockEntries(opts.Dir, pkgs); err != nil {
+			return nil, fmt.Errorf("updating tsuki.lock: %w", err)
+		}
+	}
+	return root, nil
+}
+
+// UpgradeAll upgrades every package ListInstalled reports, each to the
+// newest version satisfying its manifest constraint under dir (or
+// "latest" with no manifest), merging every result into tsuki.lock once
+// per package as it completes rather than deferring to the end — a
+// later package's failure still leaves every earlier upgrade locked in.
+func UpgradeAll(dir string) ([]InstalledPackage, error) {
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, err
+	}
+
+	var upgraded []InstalledPackage
+	for _, p := range installed {
+		pkg, err := Upgrade(p.Name, UpgradeOptions{Dir: dir})
+		if err != nil {
+			return upgraded, fmt.Errorf("upgrading %s: %w", p.Name, err)
+		}
+		upgraded = append(upgraded, *pkg)
+	}
+	return upgraded, nil
+}
+
+// materializeNodeAtomic behaves like materializeNode but writes through a
+// ".tmp" sibling directory and renames it into place only once it's
+// fully written, then drops any other installed version of the same
+// package — an upgrade should leave exactly one version on disk, and a
+// crash or failed fetch mid-write should never leave a half-written
+// tsukilib.toml where a later Install would mistake it for already cached.
+func materializeNodeAtomic(node resolvedNode) (*InstalledPackage, error) {
+	if cached := findCachedPkg(LibsDir(), node.Name, node.Version); cached != nil {
+		removeOtherVersions(node.Name, node.Version)
+		return cached, nil
+	}
+
+	tomlData := node.Data
+	if tomlData == "" {
+		data, err := fetchTOML(node.TomlURL)
+		if err != nil {
+			return nil, fmt.Errorf("downloading %s: %w", node.Name, err)
+		}
+		tomlData = data
+	}
+
+	name, version, desc, header, arduinoLib, err := parseTOMLMeta(tomlData)
+	if err != nil || name == "" {
+		name = node.Name
+	}
+	if version == "" {
+		version = node.Version
+	}
+
+	cfg, _ := config.Load()
+	if cfg != nil && cfg.VerifySignatures {
+		if err := verifySignature(name, node.TomlURL, tomlData, cfg); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %s@%s: %w", name, version, err)
+		}
+	}
+
+	finalDir := PackageDir(name, version)
+	tmpDir := finalDir + ".tmp"
+	os.RemoveAll(tmpDir) // leftover from a previous interrupted upgrade
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating package dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "tsukilib.toml"), []byte(tomlData), 0644); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("writing tsukilib.toml: %w", err)
+	}
+	os.RemoveAll(finalDir) // replace a stale partial dir from a prior failed attempt, if any
+	if err := os.Rename(tmpDir, finalDir); err != nil {
+		return nil, fmt.Errorf("installing %s@%s: %w", name, version, err)
+	}
+	removeOtherVersions(name, version)
+
+	return &InstalledPackage{
+		Name:         name,
+		Version:      version,
+		Description:  desc,
+		CppHeader:    header,
+		ArduinoLib:   arduinoLib,
+		Path:         filepath.Join(finalDir, "tsukilib.toml"),
+		SHA256:       sha256Hex([]byte(tomlData)),
+		SigSHA256:    bestEffortSigSHA256(node.TomlURL),
+		RegistryURL:  node.TomlURL,
+		Constraint:   node.Constraint,
+		Dependencies: node.Deps,
+	}, nil
+}
+
+// removeOtherVersions deletes every installed version directory of name
+// except keepVersion.
+func removeOtherVersions(name, keepVersion string) {
+	parent := filepath.Join(LibsDir(), name)
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != keepVersion {
+			os.RemoveAll(filepath.Join(parent, e.Name()))
+		}
+	}
+}
+
+// mergeLockEntries upserts pkgs into projectDir's tsuki.lock by name,
+// leaving every other existing entry untouched — unlike WriteLock, which
+// replaces the whole file, this is for a partial update like Upgrade's.
+func mergeLockEntries(projectDir string, pkgs []InstalledPackage) error {
+	existing, _ := ReadLock(projectDir)
+	byName := make(map[string]LockEntry, len(existing))
+	var order []string
+	for _, e := range existing {
+		byName[e.Name] = e
+		order = append(order, e.Name)
+	}
+	for _, p := range pkgs {
+		if _, ok := byName[p.Name]; !ok {
+			order = append(order, p.Name)
+		}
+		byName[p.Name] = LockEntry{
+			Name:         p.Name,
+			Version:      p.Version,
+			Path:         p.Path,
+			SHA256:       p.SHA256,
+			SigSHA256:    p.SigSHA256,
+			RegistryURL:  p.RegistryURL,
+			Constraint:   p.Constraint,
+			Dependencies: p.Dependencies,
+		}
+	}
+
+	entries := make([]LockEntry, 0, len(order))
+	for _, n := range order {
+		entries = append(entries, byName[n])
+	}
+	return WriteLockEntries(projectDir, entries)
+}
@@ -0,0 +1,93 @@
+package pkgmgr
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePublicKeyPEM(t *testing.T, path string, pub ed25519.PublicKey) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshaling public key: %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+}
+
+func TestLoadEd25519PublicKeyRoundTrips(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pub")
+	writePublicKeyPEM(t, path, pub)
+
+	got, err := loadEd25519PublicKey(path)
+	if err != nil {
+		t.Fatalf("loadEd25519PublicKey: unexpected error: %v", err)
+	}
+	if !got.Equal(pub) {
+		t.Error("loadEd25519PublicKey returned a different key than was written")
+	}
+}
+
+func TestLoadEd25519PublicKeyRejectsWrongPEMType(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pub")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a real cert")})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	if _, err := loadEd25519PublicKey(path); err == nil {
+		t.Error("loadEd25519PublicKey with a non-'PUBLIC KEY' PEM block: expected an error, got nil")
+	}
+}
+
+func TestVerifyDetachedSignatureAt(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	const tomlData = `name = "example"
+version = "1.0.0"
+`
+	sig := ed25519.Sign(priv, []byte(tomlData))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tsukilib.toml.sig") {
+			w.Write(sig)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	if err := verifyDetachedSignatureAt(pub, srv.URL+"/tsukilib.toml.sig", tomlData); err != nil {
+		t.Errorf("verifyDetachedSignatureAt with a valid signature: unexpected error: %v", err)
+	}
+
+	if err := verifyDetachedSignatureAt(pub, srv.URL+"/tsukilib.toml.sig", tomlData+"\n# tampered"); err == nil {
+		t.Error("verifyDetachedSignatureAt with tampered data: expected an error, got nil")
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generating second key: %v", err)
+	}
+	if err := verifyDetachedSignatureAt(otherPub, srv.URL+"/tsukilib.toml.sig", tomlData); err == nil {
+		t.Error("verifyDetachedSignatureAt with the wrong public key: expected an error, got nil")
+	}
+
+	if err := verifyDetachedSignatureAt(pub, srv.URL+"/missing.sig", tomlData); err == nil {
+		t.Error("verifyDetachedSignatureAt with a missing .sig file: expected an error, got nil")
+	}
+}
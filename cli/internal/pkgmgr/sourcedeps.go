@@ -0,0 +1,130 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: sourcedeps  —  installing path and git dependencies
+//
+//  A registry dependency goes through installFromSpec, which downloads and
+//  verifies a tsukilib.toml from the local DB cache. Path and git
+//  dependencies don't have a registry entry at all — manifest.Resolve (see
+//  internal/manifest/source.go) already pins them to a concrete location
+//  (an absolute directory, or a git URL + commit SHA); installSourceDep's
+//  job is just to make sure that location actually has a tsuki-config.toml
+//  and hand back an InstalledPackage describing it, the same shape
+//  installFromSpec returns for a registry dependency.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tsuki/cli/internal/manifest"
+)
+
+// gitDepCacheDir returns ~/.cache/tsuki/git/<name>-<commit sha prefix>, the
+// directory a git dependency is cloned into. Keying on the commit SHA (not
+// just the name) means two dependents pinning the same library at different
+// revisions get separate checkouts instead of clobbering one another, and a
+// repeat install of the same name@sha is a no-op once the clone exists.
+func gitDepCacheDir(name, sha string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	short := sha
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	return filepath.Join(home, ".cache", "tsuki", "git", name+"-"+short), nil
+}
+
+// installSourceDep installs dep, a path or git dependency, into an
+// InstalledPackage describing the resolved source directly — unlike a
+// registry dependency, nothing is copied into installRoot, the same way
+// Cargo references a path/git dependency's own checkout in place rather
+// than vendoring it.
+func installSourceDep(name string, dep manifest.DepSpec, dir string) (*InstalledPackage, error) {
+	resolved, err := manifest.Resolve(dep, dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving dependency %s: %w", name, err)
+	}
+
+	switch resolved.Kind {
+	case manifest.SourcePath:
+		return installPathDep(name, resolved)
+	case manifest.SourceGit:
+		return installGitDep(name, dep, resolved)
+	default:
+		return nil, fmt.Errorf("installSourceDep called for %s, which resolved to a registry source", name)
+	}
+}
+
+// installPathDep loads the manifest at resolved.Path and describes it as an
+// InstalledPackage. A path dependency with no manifest of its own is a hard
+// error — there's nothing to report a name/version for, and nothing build.go
+// could add to the include path.
+func installPathDep(name string, resolved manifest.ResolvedSource) (*InstalledPackage, error) {
+	m, err := manifest.Load(resolved.Path)
+	if err != nil {
+		return nil, fmt.Errorf("path dependency %s at %s: %w", name, resolved.Path, err)
+	}
+	return &InstalledPackage{
+		Name:        firstNonEmpty(m.Name, name),
+		Version:     m.Version,
+		Description: m.Description,
+		Path:        resolved.Path,
+		RegistryURL: resolved.Lock,
+	}, nil
+}
+
+// installGitDep clones dep.Git at resolved.CommitSHA into gitDepCacheDir
+// (skipping the clone if it's already there — the cache key is the commit
+// SHA itself, so an existing directory is always the right content) and
+// then installs it exactly like a path dependency.
+func installGitDep(name string, dep manifest.DepSpec, resolved manifest.ResolvedSource) (*InstalledPackage, error) {
+	cacheDir, err := gitDepCacheDir(name, resolved.CommitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		if err := cloneGitDep(dep.Git, resolved.CommitSHA, cacheDir); err != nil {
+			return nil, fmt.Errorf("cloning git dependency %s: %w", name, err)
+		}
+	}
+
+	pkg, err := installPathDep(name, manifest.ResolvedSource{Kind: manifest.SourcePath, Path: cacheDir})
+	if err != nil {
+		return nil, err
+	}
+	pkg.RegistryURL = resolved.Lock
+	return pkg, nil
+}
+
+// cloneGitDep clones url into dest and checks out commit. A plain `git
+// clone` followed by `git checkout` (rather than a single shallow fetch of
+// the pinned SHA) keeps this working against a dumb HTTP remote that
+// doesn't support fetching an arbitrary commit by hash.
+func cloneGitDep(url, commit, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if out, err := exec.Command("git", "clone", url, dest).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	if out, err := exec.Command("git", "-C", dest, "checkout", "--quiet", commit).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
+
+// firstNonEmpty returns a if it's non-empty, else b — used where a path
+// dependency's own manifest name should win but a missing one shouldn't
+// block the install.
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
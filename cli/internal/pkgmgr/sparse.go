@@ -0,0 +1,250 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: sparse  —  Cargo-style sparse registry index
+//
+//  A monolithic registry.json doesn't scale: SearchRegistry/InstallFromRegistry
+//  both have to download every package's metadata just to resolve one name.
+//  A registry that opts in — either its URL ends in "/" (it's already
+//  naming a directory) or the small JSON it serves at its URL carries
+//  "protocol": "sparse" — is queried per package instead: <base>/index/<N>/<file>.json
+//  where <N> buckets by name length the same way Cargo's sparse index does
+//  (1/2 letters: a single-digit dir; 3 letters: digit dir + first-letter dir;
+//  4+: two two-letter dirs from the name itself), containing one entry per
+//  published version.
+//
+//  Each fetch goes through registry.Client so it gets the same retry/gzip/
+//  zstd handling as every other registry request, and is cached under
+//  registryCacheDir() with its ETag/Last-Modified sidecar so a re-install of
+//  an already-resolved package costs an HTTP 304 instead of a full GET.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/tsuki/cli/internal/registry"
+)
+
+// sparseVersion is one entry in a sparse index file — one per published,
+// non-withdrawn-from-the-index version of a package.
+type sparseVersion struct {
+	Version string            `json:"version"`
+	TomlURL string            `json:"toml_url"`
+	SHA256  string            `json:"sha256"`
+	Deps    map[string]string `json:"deps,omitempty"`
+	Yanked  bool              `json:"yanked,omitempty"`
+}
+
+// registryRootMeta is the shape we peek at when deciding whether a registry
+// speaks the sparse protocol — every other field of its root JSON (packages,
+// for the legacy monolithic shape) is ignored here.
+type registryRootMeta struct {
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// sparseClient is shared by every sparse fetch; sparse registries aren't
+// expected to need the per-registry Auth a keys.json entry carries (that's
+// the registry package's own RegistrySource-based DB, not pkgmgr's
+// URL-list registries), so a RegistrySource with only an ID and no Auth is
+// enough to get Client's retry/redirect/decoding behavior for free.
+var sparseClient = registry.NewClient(registry.RegistrySource{ID: "pkgmgr-sparse"})
+
+// sparseIndexBase decides whether regURL's registry should be queried via
+// the sparse per-package index instead of the legacy monolithic fetch,
+// returning the base URL index files are resolved under when it does.
+//
+// A URL ending in "/" already names a directory — the same shape a sparse
+// index's own base is — so that's taken as an explicit opt-in without a
+// fetch. Otherwise the (small) JSON already served at regURL is inspected
+// for "protocol": "sparse", letting a registry opt in even when it can only
+// serve from a single file URL (e.g. a GitHub raw blob).
+func sparseIndexBase(regURL string) (string, bool) {
+	if strings.HasSuffix(regURL, "/") {
+		return regURL, true
+	}
+	data, err := httpGet(regURL)
+	if err != nil {
+		return "", false
+	}
+	var meta registryRootMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Protocol != "sparse" {
+		return "", false
+	}
+	return regURL[:strings.LastIndex(regURL, "/")+1], true
+}
+
+// sparseIndexPath returns name's path under a sparse index's "index/"
+// directory, bucketing by length the same way Cargo's sparse index does.
+func sparseIndexPath(name string) string {
+	lower := strings.ToLower(name)
+	file := name + ".json"
+	switch {
+	case len(lower) <= 2:
+		return path.Join("index", fmt.Sprint(len(lower)), file)
+	case len(lower) == 3:
+		return path.Join("index", "3", lower[:1], file)
+	default:
+		return path.Join("index", lower[:2], lower[2:4], file)
+	}
+}
+
+// fetchSparsePackage fetches and caches name's index file from base,
+// returning (nil, nil) when the registry simply doesn't have this package
+// (an HTTP 404) rather than an error — the caller is expected to move on to
+// the next configured registry.
+func fetchSparsePackage(base, name string) ([]sparseVersion, error) {
+	target := base + sparseIndexPath(name)
+
+	dataPath, metaPath := sparseCachePaths(target)
+	meta := loadSparseCacheMeta(metaPath)
+
+	result, err := sparseClient.GetConditional(target, meta.ETag, meta.LastModified)
+	if err != nil {
+		if isHTTPNotFound(err) {
+			return nil, nil
+		}
+		// Network/server trouble: fall back to whatever's cached, if any.
+		if cached, cacheErr := os.ReadFile(dataPath); cacheErr == nil {
+			var versions []sparseVersion
+			if jsonErr := json.Unmarshal(cached, &versions); jsonErr == nil {
+				return versions, nil
+			}
+		}
+		return nil, err
+	}
+
+	var data []byte
+	if result.NotModified {
+		data, err = os.ReadFile(dataPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading cached sparse index for %s: %w", name, err)
+		}
+	} else {
+		data = result.Body
+		if err := saveSparseCache(dataPath, metaPath, data, sparseCacheMeta{
+			ETag:         result.ETag,
+			LastModified: result.LastModified,
+		}); err != nil {
+			return nil, fmt.Errorf("caching sparse index for %s: %w", name, err)
+		}
+	}
+
+	var versions []sparseVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("parsing sparse index for %s: %w", name, err)
+	}
+	return versions, nil
+}
+
+// isHTTPNotFound reports whether err is the "HTTP 404 from <url>" shape
+// registry.Client.doGet returns for a non-5xx, non-200 response.
+func isHTTPNotFound(err error) bool {
+	return strings.Contains(err.Error(), "HTTP 404")
+}
+
+// pickSparseVersion resolves version (or, if empty, the highest
+// non-yanked entry) out of a sparse index's versions.
+func pickSparseVersion(versions []sparseVersion, version string) (sparseVersion, bool) {
+	if version != "" {
+		for _, v := range versions {
+			if v.Version == version {
+				return v, true
+			}
+		}
+		return sparseVersion{}, false
+	}
+	var best *sparseVersion
+	var bestParsed registry.Version
+	for i := range versions {
+		v := versions[i]
+		if v.Yanked {
+			continue
+		}
+		parsed, err := registry.ParseVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		if best == nil || parsed.Compare(bestParsed) > 0 {
+			best, bestParsed = &v, parsed
+		}
+	}
+	if best == nil {
+		return sparseVersion{}, false
+	}
+	return *best, true
+}
+
+// ── Cache ─────────────────────────────────────────────────────────────────────
+
+// sparseCacheMeta is the conditional-request state persisted next to a
+// cached sparse index file, mirroring registry.dbCacheMeta.
+type sparseCacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// registryCacheDir returns ~/.local/share/tsuki/registry-cache (or its
+// platform equivalent) — sparse index files are cached under
+// <host>/<path>, one file per package, rather than registry.CacheDir's
+// one-file-per-registry (that cache holds whole monolithic DBs; this one
+// holds individual index entries).
+func registryCacheDir() string {
+	if runtime.GOOS == "windows" {
+		base := os.Getenv("LOCALAPPDATA")
+		if base == "" {
+			base = os.Getenv("APPDATA")
+		}
+		return filepath.Join(base, "tsuki", "registry-cache")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "tsuki", "registry-cache")
+}
+
+// sparseCachePaths maps a sparse index file's URL to its on-disk cache path
+// (and that file's ETag/Last-Modified sidecar), rooted at <host>/<path>.
+func sparseCachePaths(targetURL string) (data, meta string) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", ""
+	}
+	rel := filepath.Join(filepath.FromSlash(u.Host), filepath.FromSlash(strings.TrimPrefix(u.Path, "/")))
+	data = filepath.Join(registryCacheDir(), rel)
+	return data, data + ".meta.json"
+}
+
+func loadSparseCacheMeta(metaPath string) sparseCacheMeta {
+	var meta sparseCacheMeta
+	if metaPath == "" {
+		return meta
+	}
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return meta
+	}
+	_ = json.Unmarshal(data, &meta)
+	return meta
+}
+
+func saveSparseCache(dataPath, metaPath string, data []byte, meta sparseCacheMeta) error {
+	if dataPath == "" {
+		return fmt.Errorf("caching sparse index: could not derive a cache path")
+	}
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPath, data, 0644); err != nil {
+		return err
+	}
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, metaData, 0644)
+}
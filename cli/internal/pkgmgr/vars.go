@@ -0,0 +1,102 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: pkgmgr :: vars  —  {{var}} templating in tsukilib.toml manifests
+//
+//  Borrowed from tool-registry systems like aqua: a manifest declares the
+//  variables its templated fields depend on via [[vars]] entries, and the
+//  caller supplies (or the declaration's own default fills in) the values
+//  that replace each {{name}} placeholder. This lets one registry entry
+//  cover, e.g., per-board firmware variants ({{board}}, {{mcu}}) instead of
+//  exploding into one package name per board.
+//
+//  Templating always runs AFTER signature verification of the raw TOML —
+//  the signature covers exactly the bytes the registry published, and
+//  verifying a caller-substituted manifest would let a caller forge
+//  anything downstream of a placeholder. A registry index's versions.* URL,
+//  templated before its target manifest has even been fetched (so no
+//  [[vars]] declaration exists yet to consult), is resolved against
+//  InstallOptions.Vars alone — a placeholder missing from it fails before
+//  that fetch ever happens, which is as close to "before any network I/O"
+//  as a URL template can get.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package pkgmgr
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+)
+
+// VarDecl is one variable a manifest's templated fields may reference as
+// {{name}}.
+type VarDecl struct {
+	Name     string `toml:"name"`
+	Required bool   `toml:"required"`
+	Default  string `toml:"default"`
+}
+
+// varsManifest is the subset of a tsukilib.toml this file needs — [[vars]]
+// is a real TOML array of tables, so it's decoded with BurntSushi/toml the
+// same way depManifest decodes [dependencies].
+type varsManifest struct {
+	Vars []VarDecl `toml:"vars"`
+}
+
+var varPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// resolveManifestVars decodes tomlData's [[vars]] declarations and resolves
+// each against provided, falling back to the declaration's default. A
+// required var with neither a provided value nor a default is an error.
+// Entries in provided that aren't declared at all pass through unchanged,
+// so a var meant for a dependency further down the chain isn't rejected
+// just because this manifest doesn't itself reference it.
+func resolveManifestVars(tomlData string, provided map[string]string) (map[string]string, error) {
+	var m varsManifest
+	if _, err := toml.Decode(tomlData, &m); err != nil {
+		return nil, fmt.Errorf("parsing [[vars]]: %w", err)
+	}
+
+	resolved := make(map[string]string, len(m.Vars)+len(provided))
+	for _, decl := range m.Vars {
+		if v, ok := provided[decl.Name]; ok {
+			resolved[decl.Name] = v
+		} else if decl.Default != "" {
+			resolved[decl.Name] = decl.Default
+		} else if decl.Required {
+			return nil, fmt.Errorf("missing required var %q (declared in [[vars]] with no default)", decl.Name)
+		} else {
+			resolved[decl.Name] = ""
+		}
+	}
+	for k, v := range provided {
+		if _, ok := resolved[k]; !ok {
+			resolved[k] = v
+		}
+	}
+	return resolved, nil
+}
+
+// templateString substitutes every {{name}} placeholder in s with
+// vars[name], failing on the first placeholder vars has no entry for — a
+// silently-ignored unresolved placeholder would otherwise ship straight
+// through to an arduino_lib name or a download URL.
+func templateString(s string, vars map[string]string) (string, error) {
+	var firstErr error
+	out := varPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		name := varPlaceholder.FindStringSubmatch(match)[1]
+		v, ok := vars[name]
+		if !ok {
+			firstErr = fmt.Errorf("missing value for var %q referenced as %s", name, match)
+			return match
+		}
+		return v
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
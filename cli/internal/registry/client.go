@@ -0,0 +1,315 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: registry :: client  —  authenticated registry HTTP access
+//
+//  Every fetch against a registry's packages.json, a package's
+//  download_url/metadata_url, or its SignatureURL should go through Client
+//  rather than a bare http.Get, so private registries (a self-hosted
+//  mirror behind a bearer token, a GitHub Releases mirror, an S3-signed
+//  URL helper, …) work the same way public ones do.
+//
+//  Auth.Type selects how a request is authenticated:
+//    "bearer" — Authorization: Bearer <$TokenEnv>
+//    "basic"  — Authorization: Basic <Username:$PasswordEnv>
+//    "helper" — Command is run with the request URL on stdin and must print
+//               a JSON {"header": "...", "value": "..."} on stdout, mirroring
+//               the Docker/OCI credential-helper "get" protocol but scoped to
+//               one arbitrary header instead of a fixed username/secret pair.
+//
+//  A RegistrySource with no Auth set falls back to whatever `tsuki registry
+//  login <id>` stored in the OS keyring (credentials package) — logging in
+//  is the path for a human operator; Auth is for scripted/CI environments
+//  that would rather pin an env var or a helper command in keys.json.
+//
+//  GetConditional adds If-None-Match/If-Modified-Since to a request and
+//  reports a 304 back as ConditionalResult.NotModified — UpdateDB uses it to
+//  avoid re-downloading an unchanged packages.json. gzip responses are
+//  decoded transparently by net/http's own transport; zstd is decoded
+//  explicitly in decodedBody since the standard library doesn't know it.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/tsuki/cli/internal/credentials"
+)
+
+// keyringHost is the credentials-package "host" a registry's login token is
+// filed under, namespaced so it can't collide with push/pull's own
+// per-git-host entries in the same keyring.
+func keyringHost(registryID string) string {
+	return "tsuki-registry:" + registryID
+}
+
+// Auth describes how Client authenticates requests for one RegistrySource.
+// The zero value (Type == "") means "no explicit auth" — Client still tries
+// a keyring token stored via `tsuki registry login` before giving up.
+type Auth struct {
+	Type string `json:"type"` // "bearer", "basic", or "helper"
+
+	// "bearer": the token is read from the environment variable named here.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// "basic": Username is literal; the password is read from the
+	// environment variable named PasswordEnv.
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+
+	// "helper": Command is invoked with the request URL on stdin; its
+	// stdout must be a JSON {"header": "...", "value": "..."} object.
+	Command string `json:"command,omitempty"`
+}
+
+// helperResponse is what a `helper` Auth.Command must print on stdout.
+type helperResponse struct {
+	Header string `json:"header"`
+	Value  string `json:"value"`
+}
+
+// header resolves Auth (plus, for the zero value, a keyring lookup) into
+// the single HTTP header a request should carry. Returns ("", "", nil)
+// when there's genuinely no credential to attach.
+func (a Auth) header(registryID, targetURL string) (name, value string, err error) {
+	switch a.Type {
+	case "":
+		token, err := credentials.Get("", keyringHost(registryID))
+		if err != nil || token == "" {
+			return "", "", err
+		}
+		return "Authorization", "Bearer " + token, nil
+
+	case "bearer":
+		token := os.Getenv(a.TokenEnv)
+		if token == "" {
+			return "", "", fmt.Errorf("auth: env var %q (token_env) is not set", a.TokenEnv)
+		}
+		return "Authorization", "Bearer " + token, nil
+
+	case "basic":
+		password := os.Getenv(a.PasswordEnv)
+		if password == "" {
+			return "", "", fmt.Errorf("auth: env var %q (password_env) is not set", a.PasswordEnv)
+		}
+		creds := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + password))
+		return "Authorization", "Basic " + creds, nil
+
+	case "helper":
+		resp, err := runAuthHelper(a.Command, targetURL)
+		if err != nil {
+			return "", "", err
+		}
+		return resp.Header, resp.Value, nil
+
+	default:
+		return "", "", fmt.Errorf("auth: unknown type %q", a.Type)
+	}
+}
+
+// runAuthHelper execs command with targetURL on stdin and decodes its
+// stdout as a helperResponse — the same stdin/stdout shape the
+// credentials package uses for docker-credential-* helpers, but a single
+// free-form header instead of a fixed ServerURL/Username/Secret payload.
+func runAuthHelper(command, targetURL string) (helperResponse, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return helperResponse{}, fmt.Errorf("auth: helper command is empty")
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = strings.NewReader(targetURL)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(errOut.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return helperResponse{}, fmt.Errorf("auth helper %q: %s", command, msg)
+	}
+	var resp helperResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return helperResponse{}, fmt.Errorf("parsing auth helper %q output: %w", command, err)
+	}
+	return resp, nil
+}
+
+// Login stores token in the OS keyring for registryID, so requests against
+// that registry authenticate without an Auth block in keys.json. Used by
+// `tsuki registry login <id>`.
+func Login(registryID, token string) error {
+	return credentials.Store("", keyringHost(registryID), token)
+}
+
+// Logout removes a registryID's keyring token. Used by
+// `tsuki registry logout <id>`.
+func Logout(registryID string) error {
+	return credentials.Erase("", keyringHost(registryID))
+}
+
+const (
+	clientMaxRetries = 3
+	clientTimeout    = 30 * time.Second
+)
+
+// Client issues authenticated HTTP GETs on behalf of one registry source.
+type Client struct {
+	registryID string
+	auth       Auth
+	http       *http.Client
+}
+
+// NewClient builds a Client for src, attaching a CheckRedirect hook that
+// strips the Authorization header on any redirect that changes scheme or
+// host — a token pinned for one origin must never leak to another.
+func NewClient(src RegistrySource) *Client {
+	httpClient := &http.Client{
+		Timeout: clientTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			if !sameOrigin(via[0].URL, req.URL) {
+				req.Header.Del("Authorization")
+			}
+			return nil
+		},
+	}
+	return &Client{registryID: src.ID, auth: src.Auth, http: httpClient}
+}
+
+func sameOrigin(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && a.Host == b.Host
+}
+
+// ConditionalResult is the outcome of GetConditional: either a fresh body
+// plus the caching headers to persist for next time, or NotModified when
+// the server confirmed the caller's cached copy is still good.
+type ConditionalResult struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+	Bytes        int64 // bytes read off the wire; 0 when NotModified
+}
+
+// Get issues an authenticated GET against targetURL. 5xx responses are
+// retried up to clientMaxRetries times with exponential backoff (1s, 2s,
+// 4s, …); a non-5xx error status is returned immediately.
+func (c *Client) Get(targetURL string) ([]byte, error) {
+	result, err := c.getWithRetry(targetURL, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
+
+// GetConditional behaves like Get, but sends If-None-Match/If-Modified-Since
+// when etag/lastModified are non-empty (whatever the caller last persisted
+// from a previous ConditionalResult) and treats an HTTP 304 as "cache still
+// valid" rather than an error.
+func (c *Client) GetConditional(targetURL, etag, lastModified string) (*ConditionalResult, error) {
+	return c.getWithRetry(targetURL, etag, lastModified)
+}
+
+func (c *Client) getWithRetry(targetURL, etag, lastModified string) (*ConditionalResult, error) {
+	var lastErr error
+	for attempt := 0; attempt <= clientMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(math.Pow(2, float64(attempt-1))) * time.Second)
+		}
+
+		result, retryable, err := c.doGet(targetURL, etag, lastModified)
+		if err == nil {
+			return result, nil
+		}
+		if !retryable {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("giving up after %d retries: %w", clientMaxRetries, lastErr)
+}
+
+// doGet performs a single attempt. retryable is true for network errors
+// and 5xx responses — the conditions worth backing off and trying again.
+func (c *Client) doGet(targetURL, etag, lastModified string) (result *ConditionalResult, retryable bool, err error) {
+	req, err := http.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	if name, value, err := c.auth.header(c.registryID, targetURL); err != nil {
+		return nil, false, fmt.Errorf("resolving auth for registry %q: %w", c.registryID, err)
+	} else if name != "" {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &ConditionalResult{NotModified: true}, false, nil
+	}
+	if resp.StatusCode >= 500 {
+		return nil, true, fmt.Errorf("HTTP %d from %s", resp.StatusCode, targetURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("HTTP %d from %s", resp.StatusCode, targetURL)
+	}
+
+	reader, err := decodedBody(resp)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return &ConditionalResult{
+		Body:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Bytes:        int64(len(data)),
+	}, false, nil
+}
+
+// decodedBody wraps resp.Body to decode whatever Content-Encoding the
+// transport itself doesn't already handle. net/http's default transport
+// decodes gzip transparently as long as the caller never sets its own
+// Accept-Encoding (true here); zstd isn't negotiated by net/http at all, so
+// it's decoded explicitly when a server sends it anyway.
+func decodedBody(resp *http.Response) (io.Reader, error) {
+	switch resp.Header.Get("Content-Encoding") {
+	case "zstd":
+		zr, err := zstd.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding zstd response from %s: %w", resp.Request.URL, err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return resp.Body, nil
+	}
+}
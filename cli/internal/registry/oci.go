@@ -0,0 +1,216 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: registry :: oci  —  OCI Distribution Spec backend
+//
+//  Lets RegistryURLs / registry.json entries point at container registries
+//  using the "oci://" scheme, e.g.:
+//
+//    oci://ghcr.io/tsuki-team/registry
+//    oci://registry.example.com/libs
+//
+//  We speak a minimal subset of the OCI Distribution Spec v2:
+//    - GET /v2/<repo>/tags/list            — enumerate available versions
+//    - GET /v2/<repo>/manifests/<tag>       — fetch the image manifest
+//    - GET /v2/<repo>/blobs/<digest>        — pull a layer
+//
+//  .tskp packages are pushed as a single layer with media type
+//  "application/vnd.tsuki.package.v1+tar" (see push.go for the writer side).
+// ─────────────────────────────────────────────────────────────────────────────
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TskpMediaType is the custom media type used for tsuki package blobs.
+const TskpMediaType = "application/vnd.tsuki.package.v1+tar"
+
+// ociRef splits "oci://host/repo" into (host, repo).
+type ociRef struct {
+	Host string
+	Repo string
+}
+
+// parseOCIURL parses an "oci://host/path/to/repo" URL.
+func parseOCIURL(u string) (ociRef, error) {
+	rest := strings.TrimPrefix(u, "oci://")
+	if rest == u {
+		return ociRef{}, fmt.Errorf("not an oci:// URL: %s", u)
+	}
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		return ociRef{}, fmt.Errorf("oci URL %q missing repository path", u)
+	}
+	return ociRef{Host: rest[:slash], Repo: rest[slash+1:]}, nil
+}
+
+// IsOCIURL reports whether u uses the oci:// scheme.
+func IsOCIURL(u string) bool {
+	return strings.HasPrefix(u, "oci://")
+}
+
+// manifestV2 is the subset of the OCI/Docker image manifest we care about.
+type manifestV2 struct {
+	SchemaVersion int `json:"schemaVersion"`
+	Config        struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Size      int64  `json:"size"`
+	} `json:"layers"`
+}
+
+type ociClient struct {
+	ref   ociRef
+	token string // bearer token, sent when non-empty; nothing in this file sets it, so requests go out unauthenticated
+	http  *http.Client
+}
+
+func newOCIClient(u string) (*ociClient, error) {
+	ref, err := parseOCIURL(u)
+	if err != nil {
+		return nil, err
+	}
+	return &ociClient{ref: ref, http: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (c *ociClient) baseURL() string {
+	return fmt.Sprintf("https://%s/v2/%s", c.ref.Host, c.ref.Repo)
+}
+
+func (c *ociClient) do(req *http.Request) (*http.Response, error) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.http.Do(req)
+}
+
+// ListTags enumerates the tags (versions) published under the repository.
+func (c *ociClient) ListTags() ([]string, error) {
+	req, err := http.NewRequest("GET", c.baseURL()+"/tags/list", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", req.URL, resp.StatusCode)
+	}
+	var out struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("parsing tags list: %w", err)
+	}
+	return out.Tags, nil
+}
+
+// FetchManifest fetches the image manifest for the given tag.
+func (c *ociClient) FetchManifest(tag string) (*manifestV2, error) {
+	req, err := http.NewRequest("GET", c.baseURL()+"/manifests/"+tag, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", req.URL, resp.StatusCode)
+	}
+	var m manifestV2
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// PullBlob downloads the blob identified by digest.
+func (c *ociClient) PullBlob(digest string) ([]byte, error) {
+	req, err := http.NewRequest("GET", c.baseURL()+"/blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", req.URL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// PullPackage fetches the .tskp (or tsukilib.toml) blob for a tagged version
+// by finding the first layer whose media type is TskpMediaType.
+func (c *ociClient) PullPackage(tag string) ([]byte, error) {
+	m, err := c.FetchManifest(tag)
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range m.Layers {
+		if layer.MediaType == TskpMediaType {
+			return c.PullBlob(layer.Digest)
+		}
+	}
+	return nil, fmt.Errorf("manifest for %s has no %s layer", tag, TskpMediaType)
+}
+
+// PullOCIPackage pulls the .tskp/tsukilib.toml blob for "oci://host/repo:tag".
+func PullOCIPackage(urlWithTag string) ([]byte, error) {
+	colon := strings.LastIndex(urlWithTag, ":")
+	slash := strings.LastIndex(urlWithTag, "/")
+	if colon <= slash {
+		return nil, fmt.Errorf("oci package ref %q missing :tag", urlWithTag)
+	}
+	base, tag := urlWithTag[:colon], urlWithTag[colon+1:]
+	client, err := newOCIClient(base)
+	if err != nil {
+		return nil, err
+	}
+	return client.PullPackage(tag)
+}
+
+// FetchDBFromOCI lists tags on an OCI repository and synthesizes a
+// PackagesDB from them: the repository name is the package name, and every
+// tag becomes a version whose DownloadURL is the oci:// ref itself (resolved
+// again at install time via PullPackage).
+func FetchDBFromOCI(u string) (*PackagesDB, error) {
+	client, err := newOCIClient(u)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := client.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags for %s: %w", u, err)
+	}
+
+	name := client.ref.Repo
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+
+	entry := PackageEntry{Versions: make(map[string]VersionMeta, len(tags))}
+	for _, tag := range tags {
+		entry.Versions[tag] = VersionMeta{
+			DownloadURL: u + ":" + tag,
+		}
+		entry.Latest = tag // tags.list is unordered; last one wins as a best effort
+	}
+
+	return &PackagesDB{Packages: map[string]PackageEntry{name: entry}}, nil
+}
@@ -12,11 +12,25 @@
 //        "id":           "tsuki-team",
 //        "name":         "Tsuki Official Registry",
 //        "packages_url": "https://raw.githubusercontent.com/.../packages.json",
-//        "trusted":      true
+//        "trusted":      true,
+//        "signing_keys": ["-----BEGIN PUBLIC KEY-----\n...\n-----END PUBLIC KEY-----\n"],
+//        "auth":         {"type": "bearer", "token_env": "TSUKI_ACME_TOKEN"}
 //      }
 //    ]
 //  }
 //
+//  signing_keys is populated by `tsuki registry trust <id> --key <path>` (or
+//  --key-url <url>) — pinning a registry's keys locally, the way a pacman
+//  keyring pins signing keys out of band. A registry marked "trusted" is
+//  refused outright by Resolve when neither these pinned keys nor its
+//  packages.json signing_keys (below) supply a key: there would be nothing
+//  to verify a signature against.
+//
+//  auth is optional and only needed for private registries: see Auth in
+//  client.go for its three forms (bearer/basic/helper). Skip it entirely and
+//  run `tsuki registry login <id>` instead to store a token in the OS
+//  keyring — Client falls back to that when auth is unset.
+//
 //  packages.json  (one per registry, served from that registry's URL)
 //  ──────────────────────────────────────────────────────────────────
 //  {
@@ -27,16 +41,21 @@
 //        "latest":      "1.0.0",
 //        "versions": {
 //          "1.0.0": {
-//            "download_url":  "https://.../ws2812-1.0.0.tar.gz",
-//            "metadata_url":  "https://.../tsuki-package.json",
-//            "checksum":      "sha256:abc...",
-//            "published_at":  "2025-01-01T00:00:00Z"
+//            "download_url":   "https://.../ws2812-1.0.0.tar.gz",
+//            "metadata_url":   "https://.../tsuki-package.json",
+//            "checksum":       "sha256:abc...",
+//            "published_at":   "2025-01-01T00:00:00Z",
+//            "signature_url":  "https://.../ws2812-1.0.0.tar.gz.sig"
 //          }
 //        }
 //      }
-//    }
+//    },
+//    "signing_keys": ["-----BEGIN PUBLIC KEY-----\n...\n-----END PUBLIC KEY-----\n"]
 //  }
 //
+//  signing_keys here lets a registry rotate its keys without every user
+//  editing keys.json; VerifySignature tries the union of both sources.
+//
 //  Spec format for tsuki install:
 //    tsuki install ws2812                     → search all registries
 //    tsuki install ws2812:1.0.0               → specific version
@@ -49,14 +68,15 @@ package registry
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/tsuki/cli/internal/ui"
 )
 
 // ── Keys.json ─────────────────────────────────────────────────────────────────
@@ -67,6 +87,23 @@ type RegistrySource struct {
 	Name        string `json:"name"`
 	PackagesURL string `json:"packages_url"`
 	Trusted     bool   `json:"trusted"`
+
+	// SigningKeys are PEM-encoded ("PUBLIC KEY") Ed25519 public keys pinned
+	// for this registry by `tsuki registry trust`. When Trusted is true,
+	// Resolve requires every version it returns to carry a SignatureURL and
+	// refuses to proceed if both this list and the registry's packages.json
+	// signing_keys are empty — there would be nothing to verify the
+	// signature against.
+	SigningKeys []string `json:"signing_keys,omitempty"`
+
+	// Auth configures how Client authenticates requests to this registry —
+	// packages.json, every version's download_url/metadata_url, and its
+	// SignatureURL. Leave unset for a public registry, or for a private one
+	// a human logs into via `tsuki registry login <id>` (the OS keyring
+	// takes over when Auth.Type is empty); set it to pin a bearer token env
+	// var, a basic-auth env var, or a credential-helper command instead —
+	// the usual choice for CI. See Auth in client.go for the three forms.
+	Auth Auth `json:"auth,omitempty"`
 }
 
 // KeysFile is the structure of ~/.config/tsuki/keys.json.
@@ -171,6 +208,27 @@ func RemoveRegistry(id string) error {
 	return fmt.Errorf("registry %q not found in keys.json", id)
 }
 
+// TrustKey pins a PEM-encoded Ed25519 public key to the registry identified
+// by id, validating it parses before writing keys.json. Used by
+// `tsuki registry trust <id> --key <path>` / `--key-url <url>`.
+func TrustKey(id, keyPEM string) error {
+	if _, err := parsePublicKey(keyPEM); err != nil {
+		return fmt.Errorf("not a valid PEM-encoded Ed25519 public key: %w", err)
+	}
+
+	kf, err := LoadKeys()
+	if err != nil {
+		return err
+	}
+	for i, r := range kf.Registries {
+		if r.ID == id {
+			kf.Registries[i].SigningKeys = append(kf.Registries[i].SigningKeys, keyPEM)
+			return SaveKeys(kf)
+		}
+	}
+	return fmt.Errorf("registry %q not found in keys.json", id)
+}
+
 // ── packages.json ─────────────────────────────────────────────────────────────
 
 // VersionMeta holds metadata for a single package version.
@@ -179,6 +237,19 @@ type VersionMeta struct {
 	MetadataURL string `json:"metadata_url"`
 	Checksum    string `json:"checksum"`
 	PublishedAt string `json:"published_at"`
+
+	// SignatureURL, if set, points at a detached raw 64-byte Ed25519
+	// signature over the tarball at DownloadURL — the same shape pacman
+	// ships a ".sig" next to every package. Defaults to "<download_url>.sig"
+	// when empty, same convention pkgmgr already uses for tsukilib.toml.
+	SignatureURL string `json:"signature_url,omitempty"`
+
+	// Yanked marks a version withdrawn by its publisher (e.g. a security
+	// issue). A yanked version never satisfies a range constraint — it can
+	// only be resolved by an exact pin, and Resolve surfaces YankReason as
+	// a warning when that happens.
+	Yanked     bool   `json:"yanked,omitempty"`
+	YankReason string `json:"yank_reason,omitempty"`
 }
 
 // PackageEntry is a single package in the packages.json index.
@@ -192,6 +263,13 @@ type PackageEntry struct {
 // PackagesDB is the structure of a packages.json file.
 type PackagesDB struct {
 	Packages map[string]PackageEntry `json:"packages"`
+
+	// SigningKeys are PEM-encoded ("PUBLIC KEY") Ed25519 public keys the
+	// registry itself publishes, letting it rotate keys without every user
+	// editing keys.json. They supplement (never replace) the SigningKeys
+	// pinned on the RegistrySource — see VerifySignature.
+	SigningKeys []string `json:"signing_keys,omitempty"`
+
 	// The registry this DB came from (injected after loading, not from file)
 	SourceID string `json:"-"`
 }
@@ -243,45 +321,224 @@ func SaveCachedDB(id string, db *PackagesDB) error {
 	return os.WriteFile(cachedDBPath(id), append(data, '\n'), 0644)
 }
 
-// FetchDB downloads and parses a packages.json from a URL.
-func FetchDB(url string) (*PackagesDB, error) {
-	body, err := httpGet(url)
+// dbCacheMeta is the conditional-request state UpdateDB persists next to a
+// registry's cached packages.json, so the next run can send
+// If-None-Match/If-Modified-Since instead of re-downloading unconditionally.
+type dbCacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// cachedDBMetaPath returns the sidecar cache-meta path for a given registry id.
+func cachedDBMetaPath(id string) string {
+	return filepath.Join(CacheDir(), id+".meta.json")
+}
+
+// loadCachedDBMeta reads a registry's sidecar cache meta, returning the zero
+// value (no conditional headers to send) when it's missing or unreadable —
+// the same "fall back to an unconditional fetch" behavior as a cold cache.
+func loadCachedDBMeta(id string) dbCacheMeta {
+	var meta dbCacheMeta
+	data, err := os.ReadFile(cachedDBMetaPath(id))
 	if err != nil {
-		return nil, fmt.Errorf("fetching packages.json from %s: %w", url, err)
+		return meta
+	}
+	_ = json.Unmarshal(data, &meta)
+	return meta
+}
+
+// saveCachedDBMeta writes a registry's sidecar cache meta to disk.
+func saveCachedDBMeta(id string, meta dbCacheMeta) error {
+	dir := CacheDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cachedDBMetaPath(id), data, 0644)
+}
+
+// FetchDB downloads and parses src's packages.json, authenticated per
+// src.Auth via Client. URLs using the oci:// scheme are dispatched to the
+// OCI Distribution Spec client instead (which isn't yet auth-aware).
+func FetchDB(src RegistrySource) (*PackagesDB, error) {
+	if IsOCIURL(src.PackagesURL) {
+		return FetchDBFromOCI(src.PackagesURL)
+	}
+	body, err := NewClient(src).Get(src.PackagesURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching packages.json from %s: %w", src.PackagesURL, err)
 	}
 	var db PackagesDB
-	if err := json.Unmarshal([]byte(body), &db); err != nil {
-		return nil, fmt.Errorf("parsing packages.json from %s: %w", url, err)
+	if err := json.Unmarshal(body, &db); err != nil {
+		return nil, fmt.Errorf("parsing packages.json from %s: %w", src.PackagesURL, err)
 	}
 	return &db, nil
 }
 
-// UpdateDB downloads all registries from keys.json and saves them to the cache.
-// Returns a map of registry-id → number of packages, plus any per-registry errors.
-func UpdateDB() (map[string]int, []error) {
+// UpdateStatus is one registry's outcome from UpdateDB.
+type UpdateStatus string
+
+const (
+	UpdateFetched     UpdateStatus = "fetched"
+	UpdateNotModified UpdateStatus = "not modified"
+	UpdateFailed      UpdateStatus = "failed"
+)
+
+// UpdateEntry is one registry's result from UpdateDB.
+type UpdateEntry struct {
+	RegistryID   string
+	Status       UpdateStatus
+	PackageCount int
+	Duration     time.Duration
+	Bytes        int64
+	Err          error
+}
+
+// UpdateResult is the outcome of UpdateDB across every registry in keys.json.
+type UpdateResult struct {
+	Entries []UpdateEntry
+}
+
+// defaultUpdateJobs is how many registries UpdateDB fetches concurrently
+// when jobs <= 0.
+const defaultUpdateJobs = 8
+
+// UpdateDB refreshes every registry in keys.json concurrently, bounded by
+// jobs (jobs <= 0 defaults to min(len(registries), defaultUpdateJobs)).
+// Each registry's last ETag/Last-Modified is sent back as
+// If-None-Match/If-Modified-Since (see dbCacheMeta), so an unchanged
+// packages.json costs an HTTP 304 instead of a full re-download. Results
+// are printed as a progress table via ui as they're gathered, and also
+// returned for callers that want to inspect them programmatically.
+func UpdateDB(jobs int) (*UpdateResult, error) {
 	kf, err := LoadKeys()
 	if err != nil {
-		return nil, []error{err}
+		return nil, err
 	}
 
-	results := make(map[string]int)
-	var errs []error
+	if jobs <= 0 {
+		jobs = defaultUpdateJobs
+	}
+	if jobs > len(kf.Registries) {
+		jobs = len(kf.Registries)
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
 
-	for _, src := range kf.Registries {
-		db, err := FetchDB(src.PackagesURL)
+	entries := make([]UpdateEntry, len(kf.Registries))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, src := range kf.Registries {
+		i, src := i, src
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i] = updateOneRegistry(src)
+		}()
+	}
+	wg.Wait()
+
+	ui.PrintRegistryUpdateTable(toUpdateRows(entries))
+	return &UpdateResult{Entries: entries}, nil
+}
+
+// updateOneRegistry fetches and caches a single registry's packages.json,
+// reusing the previous run's cache meta for a conditional GET wherever
+// possible. OCI sources have no ETag/If-None-Match concept, so they always
+// fetch in full.
+func updateOneRegistry(src RegistrySource) UpdateEntry {
+	start := time.Now()
+	entry := UpdateEntry{RegistryID: src.ID}
+
+	if IsOCIURL(src.PackagesURL) {
+		db, err := FetchDBFromOCI(src.PackagesURL)
+		entry.Duration = time.Since(start)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("[%s] %w", src.ID, err))
-			continue
+			entry.Status = UpdateFailed
+			entry.Err = err
+			return entry
 		}
 		db.SourceID = src.ID
 		if err := SaveCachedDB(src.ID, db); err != nil {
-			errs = append(errs, fmt.Errorf("[%s] saving cache: %w", src.ID, err))
-			continue
+			entry.Status = UpdateFailed
+			entry.Err = fmt.Errorf("saving cache: %w", err)
+			return entry
+		}
+		entry.Status = UpdateFetched
+		entry.PackageCount = len(db.Packages)
+		return entry
+	}
+
+	meta := loadCachedDBMeta(src.ID)
+	result, err := NewClient(src).GetConditional(src.PackagesURL, meta.ETag, meta.LastModified)
+	entry.Duration = time.Since(start)
+	if err != nil {
+		entry.Status = UpdateFailed
+		entry.Err = err
+		return entry
+	}
+
+	if result.NotModified {
+		now := time.Now()
+		_ = os.Chtimes(cachedDBPath(src.ID), now, now)
+		entry.Status = UpdateNotModified
+		if db, err := LoadCachedDB(src.ID); err == nil {
+			entry.PackageCount = len(db.Packages)
 		}
-		results[src.ID] = len(db.Packages)
+		return entry
 	}
 
-	return results, errs
+	var db PackagesDB
+	if err := json.Unmarshal(result.Body, &db); err != nil {
+		entry.Status = UpdateFailed
+		entry.Err = fmt.Errorf("parsing packages.json: %w", err)
+		return entry
+	}
+	db.SourceID = src.ID
+	if err := SaveCachedDB(src.ID, &db); err != nil {
+		entry.Status = UpdateFailed
+		entry.Err = fmt.Errorf("saving cache: %w", err)
+		return entry
+	}
+	meta = dbCacheMeta{ETag: result.ETag, LastModified: result.LastModified}
+	if err := saveCachedDBMeta(src.ID, meta); err != nil {
+		entry.Status = UpdateFailed
+		entry.Err = fmt.Errorf("saving cache meta: %w", err)
+		return entry
+	}
+
+	entry.Status = UpdateFetched
+	entry.PackageCount = len(db.Packages)
+	entry.Bytes = result.Bytes
+	return entry
+}
+
+// toUpdateRows converts UpdateEntry (the registry package's own shape) to
+// ui.RegistryUpdateRow (ui's display-only mirror) — ui can't import
+// registry, since registry already imports ui.
+func toUpdateRows(entries []UpdateEntry) []ui.RegistryUpdateRow {
+	rows := make([]ui.RegistryUpdateRow, len(entries))
+	for i, e := range entries {
+		errMsg := ""
+		if e.Err != nil {
+			errMsg = e.Err.Error()
+		}
+		rows[i] = ui.RegistryUpdateRow{
+			RegistryID:   e.RegistryID,
+			Status:       string(e.Status),
+			PackageCount: e.PackageCount,
+			Duration:     e.Duration,
+			Bytes:        e.Bytes,
+			Err:          errMsg,
+		}
+	}
+	return rows
 }
 
 // ── Package resolution ────────────────────────────────────────────────────────
@@ -294,13 +551,45 @@ type ResolvedPackage struct {
 	DownloadURL string
 	MetadataURL string
 	Checksum    string
+
+	// SignatureURL is where the detached signature for DownloadURL lives,
+	// defaulting to "<download_url>.sig" when the registry didn't set one.
+	SignatureURL string
+	// Trusted mirrors the owning RegistrySource's Trusted flag: when true,
+	// the caller MUST call VerifySignature against the bytes at
+	// SignatureURL (using Keyring) after checksum validation and before
+	// extracting DownloadURL, and must refuse to proceed if it errors.
+	Trusted bool
+	// Keyring is the full set of candidate public keys for VerifySignature:
+	// the registry's pinned SigningKeys plus whatever packages.json itself
+	// published under its top-level signing_keys.
+	Keyring []string
+
+	// YankWarning is set when the resolved version is yanked — it can only
+	// happen via an exact pin, since resolveSemver excludes yanked versions
+	// from range matching. Callers should surface it to the user (e.g. via
+	// ui.Warn) rather than failing the install outright.
+	YankWarning string
+
+	// auth is the owning RegistrySource's Auth, carried along so Client()
+	// can authenticate fetches of DownloadURL/MetadataURL/SignatureURL the
+	// same way FetchDB authenticated packages.json.
+	auth Auth
+}
+
+// Client returns an authenticated Client for this package's owning
+// registry. Use it (rather than a bare http.Get) to fetch DownloadURL,
+// MetadataURL, and SignatureURL, so private/auth-gated registries work.
+func (rp *ResolvedPackage) Client() *Client {
+	return NewClient(RegistrySource{ID: rp.RegistryID, Auth: rp.auth})
 }
 
 // ParseSpec parses an install spec into its components:
-//   "ws2812"                  → ("", "ws2812", "")
-//   "ws2812:1.0.0"            → ("", "ws2812", "1.0.0")
-//   "tsuki-team@ws2812"       → ("tsuki-team", "ws2812", "")
-//   "tsuki-team@ws2812:1.0.0" → ("tsuki-team", "ws2812", "1.0.0")
+//
+//	"ws2812"                  → ("", "ws2812", "")
+//	"ws2812:1.0.0"            → ("", "ws2812", "1.0.0")
+//	"tsuki-team@ws2812"       → ("tsuki-team", "ws2812", "")
+//	"tsuki-team@ws2812:1.0.0" → ("tsuki-team", "ws2812", "1.0.0")
 func ParseSpec(spec string) (registryID, name, version string) {
 	// Split on '@' first for registry prefix
 	if at := strings.Index(spec, "@"); at != -1 {
@@ -319,8 +608,12 @@ func ParseSpec(spec string) (registryID, name, version string) {
 
 // Resolve finds a package from the local cache (or optionally fetches live).
 // If registryID is empty, searches all registries in keys.json order.
-// If version is empty, resolves to latest.
-func Resolve(spec string, allowFetch bool) (*ResolvedPackage, error) {
+// If version is empty, resolves to latest. maxStale, when positive, treats
+// a registry's cached DB as unusable once it's older than that — causing
+// Resolve to refresh it (conditionally, via UpdateDB's same ETag/Last-Modified
+// mechanism) before looking the package up, without requiring a separate
+// `tsuki updatedb` beforehand. Pass 0 to use the cache as-is regardless of age.
+func Resolve(spec string, allowFetch bool, maxStale time.Duration) (*ResolvedPackage, error) {
 	regID, name, version := ParseSpec(spec)
 
 	kf, err := LoadKeys()
@@ -345,7 +638,7 @@ func Resolve(spec string, allowFetch bool) (*ResolvedPackage, error) {
 	}
 
 	for _, src := range sources {
-		db, err := loadDB(src, allowFetch)
+		db, err := loadDB(src, allowFetch, maxStale)
 		if err != nil {
 			continue // skip unavailable registries
 		}
@@ -361,6 +654,11 @@ func Resolve(spec string, allowFetch bool) (*ResolvedPackage, error) {
 		}
 
 		vmeta, ok := entry.Versions[resolvedVer]
+		if ok && version == "" && vmeta.Yanked {
+			// A yanked "latest" is not an exact pin; fall through to the
+			// highest non-yanked version instead.
+			ok = false
+		}
 		if !ok {
 			// Try semver resolution (simplified: find highest matching version)
 			resolvedVer, vmeta, ok = resolveSemver(entry.Versions, version)
@@ -371,13 +669,43 @@ func Resolve(spec string, allowFetch bool) (*ResolvedPackage, error) {
 			}
 		}
 
+		var yankWarning string
+		if vmeta.Yanked {
+			reason := vmeta.YankReason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			yankWarning = fmt.Sprintf("%s@%s is yanked: %s", name, resolvedVer, reason)
+		}
+
+		sigURL := vmeta.SignatureURL
+		if sigURL == "" {
+			sigURL = vmeta.DownloadURL + ".sig"
+		}
+
+		if src.Trusted && len(src.SigningKeys) == 0 && len(db.SigningKeys) == 0 {
+			return nil, fmt.Errorf(
+				"registry %q is marked trusted but has no signing keys pinned — "+
+					"run `tsuki registry trust %s --key <path>` before installing from it",
+				src.ID, src.ID)
+		}
+
+		keyring := make([]string, 0, len(src.SigningKeys)+len(db.SigningKeys))
+		keyring = append(keyring, src.SigningKeys...)
+		keyring = append(keyring, db.SigningKeys...)
+
 		return &ResolvedPackage{
-			Name:        name,
-			Version:     resolvedVer,
-			RegistryID:  src.ID,
-			DownloadURL: vmeta.DownloadURL,
-			MetadataURL: vmeta.MetadataURL,
-			Checksum:    vmeta.Checksum,
+			Name:         name,
+			Version:      resolvedVer,
+			RegistryID:   src.ID,
+			DownloadURL:  vmeta.DownloadURL,
+			MetadataURL:  vmeta.MetadataURL,
+			Checksum:     vmeta.Checksum,
+			SignatureURL: sigURL,
+			Trusted:      src.Trusted,
+			Keyring:      keyring,
+			YankWarning:  yankWarning,
+			auth:         src.Auth,
 		}, nil
 	}
 
@@ -387,102 +715,105 @@ func Resolve(spec string, allowFetch bool) (*ResolvedPackage, error) {
 			"        run `tsuki registry list` to see configured sources", name)
 }
 
-// loadDB tries cache first, then fetches if allowFetch is true.
-func loadDB(src RegistrySource, allowFetch bool) (*PackagesDB, error) {
-	db, err := LoadCachedDB(src.ID)
-	if err == nil {
+// loadDB tries cache first, then fetches if allowFetch is true. When
+// maxStale is positive and the cached DB is older than it, the cache is
+// refreshed (conditionally — see updateOneRegistry) before being returned;
+// a refresh failure falls back to the stale cache rather than failing
+// Resolve outright, same as a cold cache with allowFetch false does today.
+func loadDB(src RegistrySource, allowFetch bool, maxStale time.Duration) (*PackagesDB, error) {
+	db, cacheErr := LoadCachedDB(src.ID)
+	if cacheErr == nil && (!allowFetch || !cacheStale(src.ID, maxStale)) {
 		return db, nil
 	}
 	if !allowFetch {
-		return nil, err
-	}
-	db, fetchErr := FetchDB(src.PackagesURL)
-	if fetchErr != nil {
-		return nil, fetchErr
-	}
-	db.SourceID = src.ID
-	_ = SaveCachedDB(src.ID, db) // best-effort cache save
-	return db, nil
-}
-
-// ── Semver resolution (simplified) ───────────────────────────────────────────
-
-// resolveSemver finds the highest version in versions that satisfies the
-// constraint string. Supports:  "1", "1.0", "1.0.0", "^1", "~1.0", ">=1.0"
-func resolveSemver(versions map[string]VersionMeta, constraint string) (string, VersionMeta, bool) {
-	if constraint == "" {
-		// No constraint: pick highest version
-		return pickLatest(versions)
+		return nil, cacheErr
 	}
 
-	// Collect all valid versions
-	var candidates []string
-	for v := range versions {
-		if matchesConstraint(v, constraint) {
-			candidates = append(candidates, v)
+	if result := updateOneRegistry(src); result.Status != UpdateFailed {
+		if refreshed, err := LoadCachedDB(src.ID); err == nil {
+			return refreshed, nil
 		}
 	}
-	if len(candidates) == 0 {
-		return "", VersionMeta{}, false
+	if cacheErr == nil {
+		return db, nil // keep the stale cache rather than fail Resolve outright
 	}
-
-	sort.Slice(candidates, func(i, j int) bool {
-		return compareSemver(candidates[i], candidates[j]) < 0
-	})
-	best := candidates[len(candidates)-1]
-	return best, versions[best], true
+	return nil, cacheErr
 }
 
-func pickLatest(versions map[string]VersionMeta) (string, VersionMeta, bool) {
-	if len(versions) == 0 {
-		return "", VersionMeta{}, false
+// cacheStale reports whether a registry's cached DB is older than maxStale.
+// maxStale <= 0 disables the check entirely (never stale).
+func cacheStale(id string, maxStale time.Duration) bool {
+	if maxStale <= 0 {
+		return false
 	}
-	var keys []string
-	for k := range versions {
-		keys = append(keys, k)
+	info, err := os.Stat(cachedDBPath(id))
+	if err != nil {
+		return true
 	}
-	sort.Slice(keys, func(i, j int) bool {
-		return compareSemver(keys[i], keys[j]) < 0
-	})
-	best := keys[len(keys)-1]
-	return best, versions[best], true
+	return time.Since(info.ModTime()) > maxStale
 }
 
-// matchesConstraint is a minimal semver constraint checker.
-// Supports: "1.2.3" (exact), "^1.2.3" (compatible), "~1.2.3" (patch).
-func matchesConstraint(version, constraint string) bool {
-	// Strip leading ^, ~, >=, etc.
-	constraint = strings.TrimLeft(constraint, "^~>=<")
-	cv := parseSemver(version)
-	cc := parseSemver(constraint)
+// ── Semver resolution ─────────────────────────────────────────────────────────
+//
+// The actual Version/Constraint types and their SemVer 2.0.0 parsing and
+// precedence rules live in semver.go; what follows is just resolving a
+// packages.json versions map against one.
 
-	if cc[0] != cv[0] {
-		return false
+// resolveSemver finds the highest non-yanked version in versions that
+// satisfies the constraint expression (see Constraint for supported forms).
+// An empty constraint picks the highest non-yanked version outright.
+func resolveSemver(versions map[string]VersionMeta, constraint string) (string, VersionMeta, bool) {
+	if constraint == "" {
+		return pickLatest(versions)
 	}
-	if len(constraint) > 0 && constraint[0] == '~' {
-		return cc[1] == cv[1]
+
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return "", VersionMeta{}, false
 	}
-	return true // ^ allows any patch/minor with same major
-}
 
-func parseSemver(s string) [3]int {
-	var major, minor, patch int
-	fmt.Sscanf(s, "%d.%d.%d", &major, &minor, &patch)
-	return [3]int{major, minor, patch}
+	var best *Version
+	var bestKey string
+	for k, meta := range versions {
+		if meta.Yanked {
+			continue // yanked versions never satisfy a range constraint
+		}
+		v, err := ParseVersion(k)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if best == nil || v.Compare(*best) > 0 {
+			vv := v
+			best, bestKey = &vv, k
+		}
+	}
+	if best == nil {
+		return "", VersionMeta{}, false
+	}
+	return bestKey, versions[bestKey], true
 }
 
-func compareSemver(a, b string) int {
-	av := parseSemver(a)
-	bv := parseSemver(b)
-	for i := range av {
-		if av[i] != bv[i] {
-			if av[i] < bv[i] {
-				return -1
-			}
-			return 1
+// pickLatest returns the highest non-yanked version in versions.
+func pickLatest(versions map[string]VersionMeta) (string, VersionMeta, bool) {
+	var best *Version
+	var bestKey string
+	for k, meta := range versions {
+		if meta.Yanked {
+			continue
+		}
+		v, err := ParseVersion(k)
+		if err != nil {
+			continue
 		}
+		if best == nil || v.Compare(*best) > 0 {
+			vv := v
+			best, bestKey = &vv, k
+		}
+	}
+	if best == nil {
+		return "", VersionMeta{}, false
 	}
-	return 0
+	return bestKey, versions[bestKey], true
 }
 
 // ── Search ────────────────────────────────────────────────────────────────────
@@ -536,19 +867,3 @@ func Search(query string) ([]SearchResult, error) {
 	})
 	return results, nil
 }
-
-// ── HTTP helper ───────────────────────────────────────────────────────────────
-
-func httpGet(url string) (string, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
-	}
-	body, err := io.ReadAll(resp.Body)
-	return string(body), err
-}
\ No newline at end of file
@@ -0,0 +1,404 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: registry :: semver  —  SemVer 2.0.0 versions and range constraints
+//
+//  Version parses/orders MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD] per
+//  https://semver.org, including the precedence rules for prerelease
+//  identifiers (numeric compares numerically, alphanumeric lexically,
+//  release > prerelease, build metadata ignored entirely).
+//
+//  Constraint parses npm-style range expressions: comparator sets separated
+//  by whitespace are ANDed, sets separated by "||" are ORed. Supported
+//  comparator forms: "1.2.3" (exact), ">=1.2.0", "<2.0.0", "^1.2.3" (npm
+//  "compatible" ranges, respecting the 0.x.y special cases), "~1.2.3" /
+//  "~1.2" (patch-level), "1.2.x" / "1.x" / "*" (wildcards). A prerelease
+//  version only satisfies a range when some comparator in the matching
+//  group shares its [major, minor, patch] and is itself a prerelease — the
+//  same rule npm's `semver` package uses, so "^1.2.3" doesn't silently let
+//  "2.0.0-alpha" through.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed SemVer 2.0.0 version.
+type Version struct {
+	Major, Minor, Patch uint64
+	Prerelease          []string // dot-separated identifiers, e.g. ["beta", "1"]
+	Build               string   // carried for String(); never affects ordering
+}
+
+// ParseVersion parses a SemVer string such as "1.2.3" or "1.2.3-beta.1+001".
+// A leading "v" (as in git tags) is tolerated.
+func ParseVersion(s string) (Version, error) {
+	orig := s
+	s = strings.TrimPrefix(s, "v")
+
+	core := s
+	var build string
+	if i := strings.Index(core, "+"); i >= 0 {
+		build = core[i+1:]
+		core = core[:i]
+	}
+	var pre string
+	if i := strings.Index(core, "-"); i >= 0 {
+		pre = core[i+1:]
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid semver %q: expected MAJOR.MINOR.PATCH", orig)
+	}
+	var nums [3]uint64
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: %w", orig, err)
+		}
+		nums[i] = n
+	}
+
+	v := Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Build: build}
+	if pre != "" {
+		v.Prerelease = strings.Split(pre, ".")
+	}
+	return v, nil
+}
+
+// String renders v back out in canonical SemVer form.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether v carries a prerelease tag.
+func (v Version) IsPrerelease() bool { return len(v.Prerelease) > 0 }
+
+// Compare returns -1, 0, or 1 per SemVer 2.0.0 precedence rules.
+func (v Version) Compare(o Version) int {
+	if c := cmpUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmpUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmpUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1 // a release outranks any prerelease of the same core version
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func cmpUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease compares dot-separated prerelease identifier lists:
+// numeric identifiers compare numerically and always rank below
+// alphanumeric ones; a prerelease with additional fields after an otherwise
+// equal common prefix outranks the shorter one.
+func comparePrerelease(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		an, aErr := strconv.ParseUint(a[i], 10, 64)
+		bn, bErr := strconv.ParseUint(b[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			return cmpUint(an, bn)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return cmpUint(uint64(len(a)), uint64(len(b)))
+}
+
+// ── Constraint ─────────────────────────────────────────────────────────────────
+
+// comparator is a single "<op><version>" term, e.g. ">= 1.2.0".
+type comparator struct {
+	op  string // "*", "=", ">", ">=", "<", "<="
+	ver Version
+}
+
+func (c comparator) matches(v Version) bool {
+	switch c.op {
+	case "*":
+		return true
+	case "=":
+		return v.Compare(c.ver) == 0
+	case ">":
+		return v.Compare(c.ver) > 0
+	case ">=":
+		return v.Compare(c.ver) >= 0
+	case "<":
+		return v.Compare(c.ver) < 0
+	case "<=":
+		return v.Compare(c.ver) <= 0
+	default:
+		return false
+	}
+}
+
+// Constraint is a SemVer range: an OR of AND-groups of comparators, e.g.
+// ">=1.2.0 <2.0.0 || 2.1.x" parses to two groups of comparators.
+type Constraint struct {
+	groups [][]comparator
+	raw    string
+}
+
+// String returns the original constraint expression.
+func (c Constraint) String() string { return c.raw }
+
+// ParseConstraint parses a range expression. See the package doc comment
+// for the supported comparator forms.
+func ParseConstraint(s string) (Constraint, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = "*"
+	}
+
+	var groups [][]comparator
+	for _, orPart := range strings.Split(s, "||") {
+		orPart = strings.TrimSpace(orPart)
+		if orPart == "" {
+			continue
+		}
+		var comps []comparator
+		for _, tok := range strings.Fields(orPart) {
+			tc, err := parseComparatorToken(tok)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("parsing constraint %q: %w", raw, err)
+			}
+			comps = append(comps, tc...)
+		}
+		if len(comps) > 0 {
+			groups = append(groups, comps)
+		}
+	}
+	if len(groups) == 0 {
+		return Constraint{}, fmt.Errorf("empty constraint %q", raw)
+	}
+	return Constraint{groups: groups, raw: raw}, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics on error — for
+// constraints fixed in code rather than read from registry/user input.
+func MustParseConstraint(s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, group := range c.groups {
+		if groupMatches(group, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func groupMatches(comps []comparator, v Version) bool {
+	for _, c := range comps {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	if !v.IsPrerelease() {
+		return true
+	}
+	// A prerelease only satisfies the group if some comparator targets the
+	// same [major, minor, patch] and is itself a prerelease — otherwise
+	// "^1.2.3" would silently admit "2.0.0-alpha".
+	for _, c := range comps {
+		if c.ver.IsPrerelease() &&
+			c.ver.Major == v.Major && c.ver.Minor == v.Minor && c.ver.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparatorToken expands one whitespace-separated token into one or
+// two comparators (ranges like "^1.2.3" or "1.2.x" become a >= / < pair).
+func parseComparatorToken(tok string) ([]comparator, error) {
+	if tok == "*" || strings.EqualFold(tok, "x") {
+		return []comparator{{op: "*"}}, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(tok, op) {
+			major, minor, patch, hasMinor, hasPatch, pre, err := parsePartial(strings.TrimPrefix(tok, op))
+			if err != nil {
+				return nil, err
+			}
+			v := Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre}
+			switch op {
+			case "<=":
+				if !hasPatch {
+					_, hi := partialRange(major, minor, hasMinor)
+					return []comparator{{op: "<", ver: hi}}, nil
+				}
+				return []comparator{{op: "<=", ver: v}}, nil
+			case "=":
+				if !hasMinor || !hasPatch {
+					lo, hi := partialRange(major, minor, hasMinor)
+					return []comparator{{op: ">=", ver: lo}, {op: "<", ver: hi}}, nil
+				}
+				return []comparator{{op: "=", ver: v}}, nil
+			default:
+				return []comparator{{op: op, ver: v}}, nil
+			}
+		}
+	}
+
+	if strings.HasPrefix(tok, "^") {
+		major, minor, patch, hasMinor, hasPatch, pre, err := parsePartial(tok[1:])
+		if err != nil {
+			return nil, err
+		}
+		lo := Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre}
+		hi := caretCeiling(major, minor, patch, hasMinor, hasPatch)
+		return []comparator{{op: ">=", ver: lo}, {op: "<", ver: hi}}, nil
+	}
+	if strings.HasPrefix(tok, "~") {
+		major, minor, patch, hasMinor, _, pre, err := parsePartial(tok[1:])
+		if err != nil {
+			return nil, err
+		}
+		lo := Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre}
+		hi := tildeCeiling(major, minor, hasMinor)
+		return []comparator{{op: ">=", ver: lo}, {op: "<", ver: hi}}, nil
+	}
+
+	// Bare version, possibly partial ("1", "1.2") or wildcarded ("1.2.x").
+	major, minor, patch, hasMinor, hasPatch, pre, err := parsePartial(tok)
+	if err != nil {
+		return nil, err
+	}
+	if !hasMinor || !hasPatch {
+		lo, hi := partialRange(major, minor, hasMinor)
+		return []comparator{{op: ">=", ver: lo}, {op: "<", ver: hi}}, nil
+	}
+	return []comparator{{op: "=", ver: Version{Major: major, Minor: minor, Patch: patch, Prerelease: pre}}}, nil
+}
+
+// parsePartial parses a (possibly partial or wildcarded) version string —
+// "1", "1.2", "1.2.3", "1.2.x" — reporting which of minor/patch were given
+// an explicit number rather than missing or "x"/"X"/"*".
+func parsePartial(s string) (major, minor, patch uint64, hasMinor, hasPatch bool, pre []string, err error) {
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var preStr string
+	if i := strings.Index(s, "-"); i >= 0 {
+		preStr = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	nums := make([]uint64, 0, 3)
+	specified := make([]bool, 0, 3)
+	for _, p := range parts {
+		if p == "" || p == "x" || p == "X" || p == "*" {
+			nums = append(nums, 0)
+			specified = append(specified, false)
+			continue
+		}
+		n, convErr := strconv.ParseUint(p, 10, 64)
+		if convErr != nil {
+			err = fmt.Errorf("invalid version component %q", p)
+			return
+		}
+		nums = append(nums, n)
+		specified = append(specified, true)
+	}
+	for len(nums) < 3 {
+		nums = append(nums, 0)
+		specified = append(specified, false)
+	}
+
+	major, minor, patch = nums[0], nums[1], nums[2]
+	hasMinor, hasPatch = specified[1], specified[2]
+	if preStr != "" {
+		pre = strings.Split(preStr, ".")
+	}
+	return
+}
+
+// partialRange expands a bare partial version into its [lo, hi) bounds:
+// "1" → [1.0.0, 2.0.0); "1.2" → [1.2.0, 1.3.0).
+func partialRange(major, minor uint64, hasMinor bool) (lo, hi Version) {
+	if !hasMinor {
+		return Version{Major: major}, Version{Major: major + 1}
+	}
+	return Version{Major: major, Minor: minor}, Version{Major: major, Minor: minor + 1}
+}
+
+// tildeCeiling computes the exclusive upper bound for a "~" range:
+// "~1.2.3" / "~1.2" → <1.3.0; "~1" → <2.0.0.
+func tildeCeiling(major, minor uint64, hasMinor bool) Version {
+	if !hasMinor {
+		return Version{Major: major + 1}
+	}
+	return Version{Major: major, Minor: minor + 1}
+}
+
+// caretCeiling computes the exclusive upper bound for a "^" range,
+// following npm's "leftmost nonzero digit" rule: the first nonzero of
+// major/minor/patch is the component that may not change.
+func caretCeiling(major, minor, patch uint64, hasMinor, hasPatch bool) Version {
+	switch {
+	case major > 0:
+		return Version{Major: major + 1}
+	case hasMinor && minor > 0:
+		return Version{Major: 0, Minor: minor + 1}
+	case hasMinor && hasPatch:
+		return Version{Major: 0, Minor: 0, Patch: patch + 1}
+	case hasMinor:
+		// "^0.0" — no patch pinned, allow the rest of the 0.0.x line.
+		return Version{Major: 0, Minor: 1}
+	default:
+		// "^0" — only the major was given and it's 0.
+		return Version{Major: 1}
+	}
+}
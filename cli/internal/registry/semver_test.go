@@ -0,0 +1,101 @@
+package registry
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{"1.2.3", Version{1, 2, 3, nil, ""}, false},
+		{"v1.2.3", Version{1, 2, 3, nil, ""}, false},
+		{"1.2.3-beta.1", Version{1, 2, 3, []string{"beta", "1"}, ""}, false},
+		{"1.2.3-beta.1+001", Version{1, 2, 3, []string{"beta", "1"}, "001"}, false},
+		{"1.2", Version{}, true},
+		{"not-a-version", Version{}, true},
+	}
+	for _, c := range cases {
+		got, err := ParseVersion(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseVersion(%q): expected error, got %v", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersion(%q): unexpected error: %v", c.in, err)
+			continue
+		}
+		if got.Major != c.want.Major || got.Minor != c.want.Minor || got.Patch != c.want.Patch || got.Build != c.want.Build || len(got.Prerelease) != len(c.want.Prerelease) {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1}, // prerelease < release
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha", 1}, // more fields > fewer, when shared prefix
+		{"1.0.0-2", "1.0.0-10", -1},         // numeric identifiers compare numerically
+		{"1.0.0+build1", "1.0.0+build2", 0}, // build metadata ignored for ordering
+	}
+	for _, c := range cases {
+		a, err := ParseVersion(c.a)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q): %v", c.a, err)
+		}
+		b, err := ParseVersion(c.b)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q): %v", c.b, err)
+		}
+		if got := a.Compare(b); got != c.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{">=1.2.0 <2.0.0", "1.5.0", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0 <2.0.0 || 2.1.x", "2.1.3", true},
+		{"^1.2.3", "1.9.9", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false}, // 0.x caret is patch-level only
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"1.2.x", "1.2.9", true},
+		{"1.2.x", "1.3.0", false},
+		{"*", "9.9.9", true},
+		{"^1.2.3", "2.0.0-alpha", false}, // prerelease never satisfies a non-prerelease range
+		{"^1.2.3-beta.1", "1.2.3-beta.2", true},
+	}
+	for _, c := range cases {
+		constraint, err := ParseConstraint(c.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", c.constraint, err)
+		}
+		version, err := ParseVersion(c.version)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q): %v", c.version, err)
+		}
+		if got := constraint.Check(version); got != c.want {
+			t.Errorf("Constraint(%q).Check(%q) = %v, want %v", c.constraint, c.version, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: registry :: verify  —  detached signature verification
+//
+//  Mirrors the minisign/pacman model: a tarball ships alongside a small
+//  detached signature file, verified against a locally-pinned keyring before
+//  the tarball is trusted. Keys are plain PEM ("PUBLIC KEY") Ed25519, the
+//  same encoding pkgmgr and tuf already use elsewhere in this repo — real
+//  minisign additionally supports scrypt-encrypted secret keys, but that
+//  machinery only matters for signing, never for verification, so the
+//  stdlib crypto/ed25519 + encoding/pem pair here is all a verifier needs.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// parsePublicKey decodes one PEM-encoded ("PUBLIC KEY") Ed25519 public key.
+func parsePublicKey(pemData string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("expected PEM type %q, got %q", "PUBLIC KEY", block.Type)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing PKIX public key: %w", err)
+	}
+	ed, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key is not Ed25519 (got %T)", pub)
+	}
+	return ed, nil
+}
+
+// VerifySignature verifies pkgTarball against the raw 64-byte Ed25519
+// signature in sigBytes, trying every key in keyring until one succeeds.
+// Call this after checksum validation and before extracting pkgTarball —
+// a checksum alone only proves the bytes weren't corrupted in transit, not
+// that they came from the registry's publisher.
+func VerifySignature(pkgTarball, sigBytes []byte, keyring []string) error {
+	if len(keyring) == 0 {
+		return fmt.Errorf("no signing keys available to verify against")
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d (expected %d)", len(sigBytes), ed25519.SignatureSize)
+	}
+
+	var lastErr error
+	for _, keyPEM := range keyring {
+		pub, err := parsePublicKey(keyPEM)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ed25519.Verify(pub, pkgTarball, sigBytes) {
+			return nil
+		}
+		lastErr = fmt.Errorf("signature does not match pinned key")
+	}
+	return fmt.Errorf("no pinned key could verify the package signature: %w", lastErr)
+}
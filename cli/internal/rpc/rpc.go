@@ -0,0 +1,209 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: rpc  —  structured request/response + progress types for
+//  editor integrations (tsuki daemon)
+//
+//  This package is transport-agnostic: it defines the message shapes and a
+//  Handler interface the daemon serves, a JSON-lines transport that works
+//  over any io.Reader/io.Writer (stdin/stdout, a unix socket, a TCP pipe —
+//  the daemon command wires stdin/stdout today), and a gRPC transport (see
+//  grpc.go) for editor plugins that want a typed client stub and real
+//  HTTP/2 framing instead of hand-decoding JSON lines off stdout.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TaskProgress is one step of a long-running Handler method, streamed to
+// the client as its own JSON line while the call is still in flight. Name
+// identifies the step (e.g. "transpile", "compile"); Percent is 0-100 and
+// only meaningful when the step reports granular progress, otherwise 0.
+type TaskProgress struct {
+	Name      string  `json:"name"`
+	Completed bool    `json:"completed"`
+	Percent   float64 `json:"percent,omitempty"`
+}
+
+// ProgressFunc is how a Handler method reports TaskProgress back to the
+// client while it's still running, rather than only at the end.
+type ProgressFunc func(TaskProgress)
+
+// ── Request/response payloads ─────────────────────────────────────────────────
+
+type TranspileRequest struct {
+	ProjectDir string `json:"project_dir"`
+	Board      string `json:"board"`
+}
+
+type TranspileResponse struct {
+	CppFiles  []string `json:"cpp_files"`
+	SketchDir string   `json:"sketch_dir"`
+}
+
+type BuildRequest struct {
+	ProjectDir          string   `json:"project_dir"`
+	Board               string   `json:"board"`
+	Compile             bool     `json:"compile"`
+	PreparePackage      bool     `json:"prepare_package"`
+	OutputDir           string   `json:"output_dir"`
+	CompilationDatabase bool     `json:"compilation_database"`
+	NoAutoInstall       bool     `json:"no_auto_install"`
+	BoardOptions        []string `json:"board_options,omitempty"`
+}
+
+type BuildResponse struct {
+	SketchDir         string   `json:"sketch_dir"`
+	FirmwareHex       string   `json:"firmware_hex,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+	PackagePath       string   `json:"package_path,omitempty"`
+	CompileCommandsDB string   `json:"compile_commands_db,omitempty"`
+}
+
+type CompileRequest struct {
+	ProjectDir string `json:"project_dir"`
+	Board      string `json:"board"`
+}
+
+type CompileResponse struct {
+	FirmwareHex string   `json:"firmware_hex"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+type PackageInstallRequest struct {
+	Spec   string `json:"spec"`
+	Dir    string `json:"dir"`
+	Global bool   `json:"global"`
+	Dev    bool   `json:"dev"`
+}
+
+type PackageInstallResponse struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type BoardListRequest struct{}
+
+type BoardInfo struct {
+	ID      string   `json:"id"`
+	FQBN    string   `json:"fqbn"`
+	FCPU    string   `json:"f_cpu,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	Source  string   `json:"source"`
+}
+
+type BoardListResponse struct {
+	Boards []BoardInfo `json:"boards"`
+}
+
+// Handler is the service every transport (JSON-lines, and gRPC via
+// ServeGRPC in grpc.go) dispatches requests to. The cobra commands and the daemon
+// both end up calling the same pipeline functions underneath, so behavior
+// between `tsuki build` and a daemon-driven build is identical by
+// construction rather than by keeping two implementations in sync.
+type Handler interface {
+	Transpile(req TranspileRequest, progress ProgressFunc) (TranspileResponse, error)
+	Build(req BuildRequest, progress ProgressFunc) (BuildResponse, error)
+	Compile(req CompileRequest, progress ProgressFunc) (CompileResponse, error)
+	PackageInstall(req PackageInstallRequest, progress ProgressFunc) (PackageInstallResponse, error)
+	BoardList(req BoardListRequest) (BoardListResponse, error)
+}
+
+// envelope is the JSON-lines wire format. A request line sets ID/Method/
+// Params; the server replies with one or more progress lines (ID set,
+// Progress set) followed by exactly one final line (ID set, Result or
+// Error set).
+type envelope struct {
+	ID       string          `json:"id,omitempty"`
+	Method   string          `json:"method,omitempty"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Progress *TaskProgress   `json:"progress,omitempty"`
+}
+
+// ServeJSONLines reads newline-delimited request envelopes from r and
+// writes newline-delimited response/progress envelopes to w until r is
+// exhausted or a request fails to decode. Exactly one client is served at
+// a time — this is the editor-plugin-over-stdio model (à la an LSP
+// server), not a multi-client daemon.
+func ServeJSONLines(r io.Reader, w io.Writer, h Handler) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req envelope
+		if err := json.Unmarshal(line, &req); err != nil {
+			return fmt.Errorf("decoding request: %w", err)
+		}
+
+		progress := func(p TaskProgress) {
+			_ = enc.Encode(envelope{ID: req.ID, Progress: &p})
+		}
+
+		result, err := dispatch(h, req.Method, req.Params, progress)
+		resp := envelope{ID: req.ID}
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			raw, marshalErr := json.Marshal(result)
+			if marshalErr != nil {
+				resp.Error = marshalErr.Error()
+			} else {
+				resp.Result = raw
+			}
+		}
+		if encErr := enc.Encode(resp); encErr != nil {
+			return fmt.Errorf("writing response: %w", encErr)
+		}
+	}
+	return scanner.Err()
+}
+
+func dispatch(h Handler, method string, params json.RawMessage, progress ProgressFunc) (any, error) {
+	switch method {
+	case "Transpile":
+		var req TranspileRequest
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, err
+		}
+		return h.Transpile(req, progress)
+	case "Build":
+		var req BuildRequest
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, err
+		}
+		return h.Build(req, progress)
+	case "Compile":
+		var req CompileRequest
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, err
+		}
+		return h.Compile(req, progress)
+	case "PackageInstall":
+		var req PackageInstallRequest
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, err
+		}
+		return h.PackageInstall(req, progress)
+	case "BoardList":
+		var req BoardListRequest
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &req); err != nil {
+				return nil, err
+			}
+		}
+		return h.BoardList(req)
+	default:
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+}
@@ -0,0 +1,132 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: templates  —  pluggable starter-template registry
+//
+//  Replaces a hardcoded main.go-per-template table with a merged registry
+//  built from, in increasing precedence:
+//    1. the default registry embedded in this binary (templates.toml)
+//    2. ~/.config/tsuki/templates.toml           — user/community templates
+//
+//  A later source with the same id replaces an earlier one outright, the
+//  same override semantics internal/boards uses. Each entry's source is
+//  either "builtin" (code shipped inline) or "git" (cloned and copied by
+//  cli.writeStarterTemplate at scaffold time — this package only carries
+//  the registry data, not the clone/copy logic, so it stays free of
+//  exec.Command and doesn't need to know about the project layout).
+// ─────────────────────────────────────────────────────────────────────────────
+
+package templates
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed templates.toml
+var embeddedDefaults embed.FS
+
+// Source is where a Template's code comes from.
+type Source struct {
+	Type string `toml:"type"` // "builtin" or "git"
+
+	// git-source fields.
+	URL    string `toml:"url"`
+	Ref    string `toml:"ref"`    // branch or tag; "" lets git clone pick the default
+	Subdir string `toml:"subdir"` // "" copies the whole clone
+
+	// builtin-source field.
+	Code string `toml:"code"`
+}
+
+// Template is one wizard-selectable starter.
+type Template struct {
+	ID          string `toml:"id"`
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+	Source      Source `toml:"source"`
+}
+
+type tomlRegistry struct {
+	Templates []Template `toml:"template"`
+}
+
+// Registry is a merged, queryable set of Templates.
+type Registry struct {
+	byID map[string]*Template
+	all  []*Template // insertion order, for List()
+}
+
+func newRegistry() *Registry {
+	return &Registry{byID: map[string]*Template{}}
+}
+
+func (r *Registry) add(t Template) {
+	if existing, ok := r.byID[t.ID]; ok {
+		*existing = t
+		return
+	}
+	stored := t
+	r.all = append(r.all, &stored)
+	r.byID[t.ID] = &stored
+}
+
+// List returns every template in registry order (embedded defaults first,
+// user-added/overriding entries after).
+func (r *Registry) List() []Template {
+	out := make([]Template, len(r.all))
+	for i, t := range r.all {
+		out[i] = *t
+	}
+	return out
+}
+
+// Resolve looks up a template by id.
+func (r *Registry) Resolve(id string) (Template, bool) {
+	t, ok := r.byID[id]
+	if !ok {
+		return Template{}, false
+	}
+	return *t, true
+}
+
+// Load builds the merged registry from the embedded defaults and the
+// user's ~/.config/tsuki/templates.toml, if present.
+func Load() *Registry {
+	r := newRegistry()
+
+	if data, err := embeddedDefaults.ReadFile("templates.toml"); err == nil {
+		if tr, err := decode(data); err == nil {
+			for _, t := range tr.Templates {
+				r.add(t)
+			}
+		}
+	}
+
+	if path := userRegistryFile(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if tr, err := decode(data); err == nil {
+				for _, t := range tr.Templates {
+					r.add(t)
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+func decode(data []byte) (tomlRegistry, error) {
+	var tr tomlRegistry
+	_, err := toml.Decode(string(data), &tr)
+	return tr, err
+}
+
+func userRegistryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tsuki", "templates.toml")
+}
@@ -0,0 +1,502 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: tuf  —  TUF-inspired trust model for the signing-key index
+//
+//  The plain KeysIndexURL (see pkgmgr's KeyIndex) trusts whatever JSON the URL
+//  returns today, with no rotation or freshness story. This package layers a
+//  subset of The Update Framework (https://theupdateframework.io) on top:
+//  four signed, versioned, expiring metadata files —
+//
+//    root.json       — the trusted key set for every role, itself signed by
+//                       a threshold of root keys (supports root rotation)
+//    timestamp.json  — tiny, fetched every time; points at the current
+//                       snapshot and is the freshness heartbeat
+//    snapshot.json   — lists the expected version/length/hash of targets.json
+//    targets.json    — the actual package → public-key mapping ("targets")
+//
+//  Client.Refresh implements the standard client workflow: verify root
+//  (chaining through any rotated intermediate root versions), verify
+//  timestamp against root, verify snapshot against timestamp, verify targets
+//  against snapshot, then look up individual package keys as TUF targets.
+//
+//  This is a deliberately scoped-down subset — consistent hash/length
+//  checking and root-rotation chaining are implemented; full TUF features
+//  like delegations and multi-role signing thresholds per target are not.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package tuf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ── Metadata shapes ────────────────────────────────────────────────────────────
+
+// Key is a single public key declared in root.json, keyed by KeyID elsewhere.
+type Key struct {
+	KeyType string `json:"keytype"` // "ed25519"
+	Public  string `json:"public"`  // PEM-encoded public key
+}
+
+// Role lists the key IDs authorized for a role and the signature threshold.
+type Role struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// RootMetadata is the signed body of root.json.
+type RootMetadata struct {
+	Type    string          `json:"_type"` // "root"
+	Version int             `json:"version"`
+	Expires time.Time       `json:"expires"`
+	Keys    map[string]Key  `json:"keys"`
+	Roles   map[string]Role `json:"roles"` // "root", "timestamp", "snapshot", "targets"
+}
+
+// FileMeta describes an expected metadata file's version/length/hash, as
+// referenced from timestamp.json (→ snapshot.json) and snapshot.json
+// (→ targets.json).
+type FileMeta struct {
+	Version int               `json:"version"`
+	Length  int64             `json:"length"`
+	Hashes  map[string]string `json:"hashes"` // e.g. {"sha256": "<hex>"}
+}
+
+// TimestampMetadata is the signed body of timestamp.json.
+type TimestampMetadata struct {
+	Type    string              `json:"_type"` // "timestamp"
+	Version int                 `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]FileMeta `json:"meta"` // "snapshot.json" -> FileMeta
+}
+
+// SnapshotMetadata is the signed body of snapshot.json.
+type SnapshotMetadata struct {
+	Type    string              `json:"_type"` // "snapshot"
+	Version int                 `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]FileMeta `json:"meta"` // "targets.json" -> FileMeta
+}
+
+// TargetFile is one package's entry in targets.json: its Ed25519 public key,
+// PEM-encoded, stored as TUF "custom" metadata.
+type TargetFile struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+	Custom TargetFileCustom  `json:"custom"`
+}
+
+// TargetFileCustom carries the package's own Ed25519 public key — the thing
+// pkgmgr actually wants out of all of this.
+type TargetFileCustom struct {
+	PublicKeyPEM string `json:"public_key_pem"`
+}
+
+// TargetsMetadata is the signed body of targets.json.
+type TargetsMetadata struct {
+	Type    string                `json:"_type"` // "targets"
+	Version int                   `json:"version"`
+	Expires time.Time             `json:"expires"`
+	Targets map[string]TargetFile `json:"targets"` // package name -> key
+}
+
+// Signature is one role-key signature over a Signed envelope's canonical body.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // hex-encoded Ed25519 signature
+}
+
+// Envelope wraps any of the metadata bodies above with its signatures, in the
+// same "signed" + "signatures" shape TUF uses on the wire.
+type Envelope struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []Signature     `json:"signatures"`
+}
+
+// ── Client ─────────────────────────────────────────────────────────────────────
+
+// Client fetches and verifies TUF metadata from BaseURL, pinning trust at
+// KeysDir/root.json.
+type Client struct {
+	BaseURL string
+	KeysDir string
+	http    *http.Client
+}
+
+// NewClient returns a Client rooted at baseURL (e.g.
+// "https://raw.githubusercontent.com/s7lver2/tsuki/main/pkg/tuf"), pinning
+// state under keysDir (normally config.ResolvedKeysDir()).
+func NewClient(baseURL, keysDir string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), KeysDir: keysDir, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (c *Client) pinnedRootPath() string {
+	return filepath.Join(c.KeysDir, "root.json")
+}
+
+func (c *Client) fetch(name string) ([]byte, error) {
+	url := c.BaseURL + "/" + name
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Init bootstraps trust: downloads root.json from baseURL (which may differ
+// from c.BaseURL for a one-time out-of-band fetch) and pins it to
+// KeysDir/root.json, WITHOUT verifying it against anything — this is the
+// trust-on-first-use step an operator must audit out of band, matching
+// `tsuki keys init --root <url>`.
+func (c *Client) Init(rootURL string) error {
+	resp, err := c.http.Get(rootURL)
+	if err != nil {
+		return fmt.Errorf("fetching root metadata from %s: %w", rootURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching root metadata: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("parsing root metadata: %w", err)
+	}
+	var root RootMetadata
+	if err := json.Unmarshal(env.Signed, &root); err != nil {
+		return fmt.Errorf("parsing root body: %w", err)
+	}
+	if err := verifyEnvelope(env, root.Roles["root"], root.Keys); err != nil {
+		return fmt.Errorf("root.json is not self-consistently signed: %w", err)
+	}
+
+	if err := os.MkdirAll(c.KeysDir, 0755); err != nil {
+		return fmt.Errorf("creating keys dir: %w", err)
+	}
+	return os.WriteFile(c.pinnedRootPath(), data, 0644)
+}
+
+// loadPinnedRoot reads and parses the locally pinned root.json.
+func (c *Client) loadPinnedRoot() (Envelope, RootMetadata, error) {
+	data, err := os.ReadFile(c.pinnedRootPath())
+	if err != nil {
+		return Envelope{}, RootMetadata{}, fmt.Errorf("no pinned root.json — run `tsuki keys init --root <url>` first: %w", err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, RootMetadata{}, fmt.Errorf("parsing pinned root.json: %w", err)
+	}
+	var root RootMetadata
+	if err := json.Unmarshal(env.Signed, &root); err != nil {
+		return Envelope{}, RootMetadata{}, fmt.Errorf("parsing pinned root body: %w", err)
+	}
+	return env, root, nil
+}
+
+// verifyEnvelope checks that at least role.Threshold of role.KeyIDs produced
+// a valid signature over env.Signed using the corresponding key in keys.
+func verifyEnvelope(env Envelope, role Role, keys map[string]Key) error {
+	if role.Threshold <= 0 {
+		return fmt.Errorf("role has no signature threshold configured")
+	}
+	valid := 0
+	for _, sig := range env.Signatures {
+		key, ok := keys[sig.KeyID]
+		if !ok {
+			continue
+		}
+		authorized := false
+		for _, kid := range role.KeyIDs {
+			if kid == sig.KeyID {
+				authorized = true
+				break
+			}
+		}
+		if !authorized {
+			continue
+		}
+		pub, err := parseEd25519PEM(key.Public)
+		if err != nil {
+			continue
+		}
+		sigBytes, err := hex.DecodeString(sig.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(pub, env.Signed, sigBytes) {
+			valid++
+		}
+	}
+	if valid < role.Threshold {
+		return fmt.Errorf("only %d of required %d valid signatures", valid, role.Threshold)
+	}
+	return nil
+}
+
+func parseEd25519PEM(pemStr string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing PKIX public key: %w", err)
+	}
+	ed, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key is not Ed25519 (got %T)", pub)
+	}
+	return ed, nil
+}
+
+// versionCachePath returns KeysDir/tuf-versions.json, where Refresh persists
+// the last version it accepted for each role so a later call can detect a
+// rollback — a validly-signed-but-stale metadata file that a freeze or
+// rollback attack served up instead of the latest one.
+func (c *Client) versionCachePath() string {
+	return filepath.Join(c.KeysDir, "tuf-versions.json")
+}
+
+// loadCachedVersions reads the last-accepted version of each role. A
+// missing file just means this is the first Refresh ever run against
+// KeysDir — there's nothing yet to compare against, so it returns an
+// empty map rather than an error.
+func (c *Client) loadCachedVersions() (map[string]int, error) {
+	data, err := os.ReadFile(c.versionCachePath())
+	if os.IsNotExist(err) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", c.versionCachePath(), err)
+	}
+	var versions map[string]int
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", c.versionCachePath(), err)
+	}
+	return versions, nil
+}
+
+// saveCachedVersions persists versions so the next Refresh can detect a
+// rollback.
+func (c *Client) saveCachedVersions(versions map[string]int) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.versionCachePath(), data, 0644)
+}
+
+// checkRollback rejects role if version has regressed from the last value
+// Refresh accepted for it. Expires alone doesn't catch this: a metadata
+// file that's signed, internally consistent, and not yet expired is
+// exactly what a captured old version looks like.
+func checkRollback(cached map[string]int, role string, version int) error {
+	if prev, ok := cached[role]; ok && version < prev {
+		return fmt.Errorf("%s version %d is older than the last accepted version %d — possible rollback or freeze attack", role, version, prev)
+	}
+	return nil
+}
+
+func verifyFileMeta(data []byte, meta FileMeta) error {
+	if int64(len(data)) != meta.Length {
+		return fmt.Errorf("length mismatch: got %d, expected %d", len(data), meta.Length)
+	}
+	want, ok := meta.Hashes["sha256"]
+	if !ok {
+		return fmt.Errorf("no sha256 hash listed")
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != want {
+		return fmt.Errorf("sha256 mismatch")
+	}
+	return nil
+}
+
+// Refresh runs the full TUF client workflow and returns the verified targets
+// metadata:
+//  1. load the pinned root, verify it against itself
+//  2. fetch and chain any newer root versions (root rotation)
+//  3. fetch timestamp.json, verify against the (possibly rotated) root,
+//     reject if expired or its version regressed from any cached value
+//  4. fetch snapshot.json (only meaningful when timestamp changed — callers
+//     that poll frequently should cache Refresh's result and compare
+//     TimestampVersion themselves) and verify its hash/length from timestamp
+//  5. fetch targets.json, gated by snapshot's hash/length
+func (c *Client) Refresh() (*TargetsMetadata, error) {
+	rootEnv, root, err := c.loadPinnedRoot()
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyEnvelope(rootEnv, root.Roles["root"], root.Keys); err != nil {
+		return nil, fmt.Errorf("pinned root.json is not self-consistently signed: %w", err)
+	}
+
+	cachedVersions, err := c.loadCachedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err = c.chainRootRotation(root)
+	if err != nil {
+		return nil, fmt.Errorf("chaining root rotation: %w", err)
+	}
+
+	tsData, err := c.fetch("timestamp.json")
+	if err != nil {
+		return nil, err
+	}
+	var tsEnv Envelope
+	if err := json.Unmarshal(tsData, &tsEnv); err != nil {
+		return nil, fmt.Errorf("parsing timestamp.json: %w", err)
+	}
+	if err := verifyEnvelope(tsEnv, root.Roles["timestamp"], root.Keys); err != nil {
+		return nil, fmt.Errorf("timestamp.json signature invalid: %w", err)
+	}
+	var timestamp TimestampMetadata
+	if err := json.Unmarshal(tsEnv.Signed, &timestamp); err != nil {
+		return nil, fmt.Errorf("parsing timestamp body: %w", err)
+	}
+	if time.Now().After(timestamp.Expires) {
+		return nil, fmt.Errorf("timestamp.json expired at %s", timestamp.Expires)
+	}
+	if err := checkRollback(cachedVersions, "timestamp", timestamp.Version); err != nil {
+		return nil, err
+	}
+
+	snapMeta, ok := timestamp.Meta["snapshot.json"]
+	if !ok {
+		return nil, fmt.Errorf("timestamp.json does not reference snapshot.json")
+	}
+	snapData, err := c.fetch("snapshot.json")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyFileMeta(snapData, snapMeta); err != nil {
+		return nil, fmt.Errorf("snapshot.json failed timestamp-pinned integrity check: %w", err)
+	}
+	var snapEnv Envelope
+	if err := json.Unmarshal(snapData, &snapEnv); err != nil {
+		return nil, fmt.Errorf("parsing snapshot.json: %w", err)
+	}
+	if err := verifyEnvelope(snapEnv, root.Roles["snapshot"], root.Keys); err != nil {
+		return nil, fmt.Errorf("snapshot.json signature invalid: %w", err)
+	}
+	var snapshot SnapshotMetadata
+	if err := json.Unmarshal(snapEnv.Signed, &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot body: %w", err)
+	}
+	if time.Now().After(snapshot.Expires) {
+		return nil, fmt.Errorf("snapshot.json expired at %s", snapshot.Expires)
+	}
+	if err := checkRollback(cachedVersions, "snapshot", snapshot.Version); err != nil {
+		return nil, err
+	}
+
+	targetsMeta, ok := snapshot.Meta["targets.json"]
+	if !ok {
+		return nil, fmt.Errorf("snapshot.json does not reference targets.json")
+	}
+	targetsData, err := c.fetch("targets.json")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyFileMeta(targetsData, targetsMeta); err != nil {
+		return nil, fmt.Errorf("targets.json failed snapshot-pinned integrity check: %w", err)
+	}
+	var targetsEnv Envelope
+	if err := json.Unmarshal(targetsData, &targetsEnv); err != nil {
+		return nil, fmt.Errorf("parsing targets.json: %w", err)
+	}
+	if err := verifyEnvelope(targetsEnv, root.Roles["targets"], root.Keys); err != nil {
+		return nil, fmt.Errorf("targets.json signature invalid: %w", err)
+	}
+	var targets TargetsMetadata
+	if err := json.Unmarshal(targetsEnv.Signed, &targets); err != nil {
+		return nil, fmt.Errorf("parsing targets body: %w", err)
+	}
+	if time.Now().After(targets.Expires) {
+		return nil, fmt.Errorf("targets.json expired at %s", targets.Expires)
+	}
+	if err := checkRollback(cachedVersions, "targets", targets.Version); err != nil {
+		return nil, err
+	}
+
+	cachedVersions["timestamp"] = timestamp.Version
+	cachedVersions["snapshot"] = snapshot.Version
+	cachedVersions["targets"] = targets.Version
+	if err := c.saveCachedVersions(cachedVersions); err != nil {
+		return nil, fmt.Errorf("persisting accepted TUF versions: %w", err)
+	}
+
+	return &targets, nil
+}
+
+// chainRootRotation repeatedly fetches root.<version+1>.json, verifying each
+// against the previous root's threshold, until the server has no newer
+// version — then persists the final root as the new pin.
+func (c *Client) chainRootRotation(current RootMetadata) (RootMetadata, error) {
+	for {
+		next := current.Version + 1
+		data, err := c.fetch(fmt.Sprintf("root.%d.json", next))
+		if err != nil {
+			// No newer root published — current is up to date.
+			return current, nil
+		}
+		var env Envelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			return current, fmt.Errorf("parsing root.%d.json: %w", next, err)
+		}
+		if err := verifyEnvelope(env, current.Roles["root"], current.Keys); err != nil {
+			return current, fmt.Errorf("root.%d.json not signed by previous root's threshold: %w", next, err)
+		}
+		var candidate RootMetadata
+		if err := json.Unmarshal(env.Signed, &candidate); err != nil {
+			return current, fmt.Errorf("parsing root.%d.json body: %w", next, err)
+		}
+		if candidate.Version != next {
+			return current, fmt.Errorf("root.%d.json declares version %d", next, candidate.Version)
+		}
+		// The new root must also be self-consistently signed by its own
+		// (possibly rotated) root-role keys/threshold.
+		if err := verifyEnvelope(env, candidate.Roles["root"], candidate.Keys); err != nil {
+			return current, fmt.Errorf("root.%d.json not self-consistently signed: %w", next, err)
+		}
+		if err := os.WriteFile(c.pinnedRootPath(), data, 0644); err != nil {
+			return current, fmt.Errorf("persisting rotated root: %w", err)
+		}
+		current = candidate
+	}
+}
+
+// LookupTargetKey returns the Ed25519 public key TUF has on file for pkgName,
+// refreshing metadata first.
+func (c *Client) LookupTargetKey(pkgName string) (ed25519.PublicKey, error) {
+	targets, err := c.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	target, ok := targets.Targets[pkgName]
+	if !ok {
+		return nil, fmt.Errorf("no TUF target registered for package %q", pkgName)
+	}
+	return parseEd25519PEM(target.Custom.PublicKeyPEM)
+}
@@ -0,0 +1,172 @@
+package tuf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigner signs metadata bodies the same way a real TUF repo would,
+// producing the Envelope shape Client.Refresh expects on the wire.
+type testSigner struct {
+	keyID string
+	pub   ed25519.PublicKey
+	priv  ed25519.PrivateKey
+}
+
+func newTestSigner(t *testing.T, keyID string) testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return testSigner{keyID: keyID, pub: pub, priv: priv}
+}
+
+func (s testSigner) pemPublic(t *testing.T) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(s.pub)
+	if err != nil {
+		t.Fatalf("marshaling public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func (s testSigner) envelope(t *testing.T, body interface{}) []byte {
+	t.Helper()
+	signed, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshaling body: %v", err)
+	}
+	sig := ed25519.Sign(s.priv, signed)
+	env := Envelope{
+		Signed:     signed,
+		Signatures: []Signature{{KeyID: s.keyID, Sig: hex.EncodeToString(sig)}},
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshaling envelope: %v", err)
+	}
+	return data
+}
+
+func fileMetaFor(version int, data []byte) FileMeta {
+	sum := sha256.Sum256(data)
+	return FileMeta{Version: version, Length: int64(len(data)), Hashes: map[string]string{"sha256": hex.EncodeToString(sum[:])}}
+}
+
+// buildMetadataSet produces a fully chained, signed timestamp/snapshot/
+// targets trio at the given version, all signed by signer and expiring
+// well in the future.
+func buildMetadataSet(t *testing.T, signer testSigner, version int) (timestamp, snapshot, targets []byte) {
+	t.Helper()
+	expires := time.Now().Add(24 * time.Hour)
+
+	targetsBody := TargetsMetadata{Type: "targets", Version: version, Expires: expires, Targets: map[string]TargetFile{}}
+	targets = signer.envelope(t, targetsBody)
+
+	snapshotBody := SnapshotMetadata{
+		Type: "snapshot", Version: version, Expires: expires,
+		Meta: map[string]FileMeta{"targets.json": fileMetaFor(version, targets)},
+	}
+	snapshot = signer.envelope(t, snapshotBody)
+
+	timestampBody := TimestampMetadata{
+		Type: "timestamp", Version: version, Expires: expires,
+		Meta: map[string]FileMeta{"snapshot.json": fileMetaFor(version, snapshot)},
+	}
+	timestamp = signer.envelope(t, timestampBody)
+
+	return timestamp, snapshot, targets
+}
+
+// newTestServer serves timestamp/snapshot/targets.json from files, and 404s
+// everything else (in particular root.<n>.json, so chainRootRotation sees
+// no newer root and returns immediately).
+func newTestServer(t *testing.T, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		data, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+// pinRoot writes a self-consistently signed root.json directly to keysDir,
+// bypassing Client.Init (which only adds its own out-of-band fetch+pin
+// step — irrelevant to what Refresh's rollback handling is being tested
+// here).
+func pinRoot(t *testing.T, keysDir string, signer testSigner) {
+	t.Helper()
+	role := Role{KeyIDs: []string{signer.keyID}, Threshold: 1}
+	root := RootMetadata{
+		Type: "root", Version: 1, Expires: time.Now().Add(24 * time.Hour),
+		Keys:  map[string]Key{signer.keyID: {KeyType: "ed25519", Public: signer.pemPublic(t)}},
+		Roles: map[string]Role{"root": role, "timestamp": role, "snapshot": role, "targets": role},
+	}
+	data := signer.envelope(t, root)
+	if err := os.WriteFile(filepath.Join(keysDir, "root.json"), data, 0644); err != nil {
+		t.Fatalf("pinning root: %v", err)
+	}
+}
+
+func TestRefreshAcceptsNewerVersion(t *testing.T) {
+	keysDir := t.TempDir()
+	signer := newTestSigner(t, "key1")
+	pinRoot(t, keysDir, signer)
+
+	ts, snap, tgt := buildMetadataSet(t, signer, 1)
+	srv := newTestServer(t, map[string][]byte{"timestamp.json": ts, "snapshot.json": snap, "targets.json": tgt})
+	defer srv.Close()
+
+	client := NewClient(srv.URL, keysDir)
+	if _, err := client.Refresh(); err != nil {
+		t.Fatalf("Refresh() at version 1: unexpected error: %v", err)
+	}
+}
+
+func TestRefreshRejectsRollback(t *testing.T) {
+	keysDir := t.TempDir()
+	signer := newTestSigner(t, "key1")
+	pinRoot(t, keysDir, signer)
+
+	// First refresh accepts version 2 and persists it to tuf-versions.json.
+	ts2, snap2, tgt2 := buildMetadataSet(t, signer, 2)
+	srv2 := newTestServer(t, map[string][]byte{"timestamp.json": ts2, "snapshot.json": snap2, "targets.json": tgt2})
+	client := NewClient(srv2.URL, keysDir)
+	if _, err := client.Refresh(); err != nil {
+		t.Fatalf("Refresh() at version 2: unexpected error: %v", err)
+	}
+	srv2.Close()
+
+	// A later refresh against a server serving a stale version 1 — a
+	// validly-signed, not-yet-expired freeze/rollback attempt — must be
+	// rejected even though nothing about version 1's own metadata is
+	// individually invalid.
+	ts1, snap1, tgt1 := buildMetadataSet(t, signer, 1)
+	srv1 := newTestServer(t, map[string][]byte{"timestamp.json": ts1, "snapshot.json": snap1, "targets.json": tgt1})
+	defer srv1.Close()
+
+	client = NewClient(srv1.URL, keysDir)
+	_, err := client.Refresh()
+	if err == nil {
+		t.Fatal("Refresh() with a rolled-back timestamp: expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "rollback") {
+		t.Errorf("Refresh() error = %q, want it to mention rollback", err.Error())
+	}
+}
@@ -0,0 +1,273 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: ui :: highlight  —  pluggable syntax highlighting for Traceback
+// ─────────────────────────────────────────────────────────────────────────────
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Highlighter colors a single line of source for display in a Traceback
+// frame. lang is a short name such as "go", "python", "c", or "cpp"; an
+// empty or unrecognized lang should be returned unmodified (or with
+// minimal generic treatment) rather than erroring.
+type Highlighter interface {
+	Highlight(lang, src string) string
+}
+
+// activeHighlighter is used by Traceback to color frame.Code lines. It
+// defaults to tokenHighlighter{}; call SetHighlighter to plug in something
+// richer (e.g. a chroma-backed implementation).
+var activeHighlighter Highlighter = tokenHighlighter{}
+
+// SetHighlighter replaces the highlighter used by Traceback. Passing nil
+// restores the default tokenizer-based one.
+func SetHighlighter(h Highlighter) {
+	if h == nil {
+		h = tokenHighlighter{}
+	}
+	activeHighlighter = h
+}
+
+// tokenHighlighter is the default Highlighter: a small hand-written
+// line-at-a-time scanner covering keywords, strings, numbers, comments,
+// and booleans for the languages tsuki actually emits or transpiles from.
+type tokenHighlighter struct{}
+
+func (tokenHighlighter) Highlight(lang, src string) string {
+	return tokenizeLine(src, keywordsFor(lang), lang)
+}
+
+// ── language inference ────────────────────────────────────────────────────────
+
+// inferLanguage guesses a Highlighter lang string from a file's extension.
+// tsuki projects are primarily Go transpiled to .ino/.cpp, so those map to
+// their closest tokenizable language.
+func inferLanguage(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ino", ".cpp", ".cc", ".cxx", ".hpp":
+		return "cpp"
+	case ".c", ".h":
+		return "c"
+	default:
+		return ""
+	}
+}
+
+// ── keyword sets ──────────────────────────────────────────────────────────────
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+func union(sets ...map[string]bool) map[string]bool {
+	m := make(map[string]bool)
+	for _, s := range sets {
+		for w := range s {
+			m[w] = true
+		}
+	}
+	return m
+}
+
+var goKeywords = set(
+	"break", "case", "chan", "const", "continue", "default", "defer", "else",
+	"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+	"map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+)
+
+var cKeywords = set(
+	"auto", "break", "case", "char", "const", "continue", "default", "do",
+	"double", "else", "enum", "extern", "float", "for", "goto", "if", "inline",
+	"int", "long", "register", "return", "short", "signed", "sizeof", "static",
+	"struct", "switch", "typedef", "union", "unsigned", "void", "volatile", "while",
+)
+
+var cppKeywords = union(cKeywords, set(
+	"class", "namespace", "new", "delete", "public", "private", "protected",
+	"template", "this", "try", "catch", "throw", "virtual", "using", "nullptr",
+	"setup", "loop",
+))
+
+var pythonKeywords = set(
+	"and", "as", "assert", "async", "await", "break", "class", "continue", "def",
+	"del", "elif", "else", "except", "finally", "for", "from", "global", "if",
+	"import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise",
+	"return", "try", "while", "with", "yield",
+)
+
+func keywordsFor(lang string) map[string]bool {
+	switch lang {
+	case "go":
+		return goKeywords
+	case "c":
+		return cKeywords
+	case "cpp":
+		return cppKeywords
+	case "python":
+		return pythonKeywords
+	default:
+		return nil
+	}
+}
+
+func isBoolLiteral(word, lang string) bool {
+	switch lang {
+	case "python":
+		return word == "True" || word == "False" || word == "None"
+	default:
+		return word == "true" || word == "false" || word == "nil" || word == "NULL"
+	}
+}
+
+// ── tokenizer ──────────────────────────────────────────────────────────────────
+
+// tokenizeLine scans src left to right, classifying each run of characters
+// as a line comment, string literal, numeric literal, or identifier
+// (keyword/bool/plain), and wraps each with the matching palette color.
+// It is intentionally simple: one line at a time, no multi-line comment or
+// string tracking, which matches the few-lines-of-context use in Traceback.
+func tokenizeLine(src string, kws map[string]bool, lang string) string {
+	commentPrefix := "//"
+	if lang == "python" {
+		commentPrefix = "#"
+	}
+
+	var b strings.Builder
+	runes := []rune(src)
+	n := len(runes)
+	i := 0
+	for i < n {
+		r := runes[i]
+
+		// line comment — rest of the line.
+		if strings.HasPrefix(string(runes[i:]), commentPrefix) {
+			b.WriteString(ColorComment.Sprint(string(runes[i:])))
+			break
+		}
+
+		// string literal, with backslash-escape awareness.
+		if r == '"' || r == '\'' {
+			j := i + 1
+			for j < n {
+				if runes[j] == '\\' && j+1 < n {
+					j += 2
+					continue
+				}
+				if runes[j] == r {
+					j++
+					break
+				}
+				j++
+			}
+			b.WriteString(ColorString.Sprint(string(runes[i:j])))
+			i = j
+			continue
+		}
+
+		// numeric literal.
+		if isDigit(r) {
+			j := i
+			for j < n && (isDigit(runes[j]) || runes[j] == '.' || runes[j] == 'x' || isHexDigit(runes[j])) {
+				j++
+			}
+			b.WriteString(ColorNumber.Sprint(string(runes[i:j])))
+			i = j
+			continue
+		}
+
+		// identifier / keyword / bool.
+		if isIdentStart(r) {
+			j := i
+			for j < n && isIdentPart(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			switch {
+			case isBoolLiteral(word, lang):
+				b.WriteString(ColorBool.Sprint(word))
+			case kws[word]:
+				b.WriteString(ColorKey.Sprint(word))
+			default:
+				b.WriteString(word)
+			}
+			i = j
+			continue
+		}
+
+		b.WriteRune(r)
+		i++
+	}
+	return b.String()
+}
+
+func isDigit(r rune) bool    { return r >= '0' && r <= '9' }
+func isHexDigit(r rune) bool { return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') }
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+func isIdentPart(r rune) bool { return isIdentStart(r) || isDigit(r) }
+
+// ── pointer-line emphasis / non-pointer dimming ───────────────────────────────
+
+// ansiSGRRe matches one SGR escape sequence, capturing its parameter list
+// (which may be empty, as in a bare "\x1b[m").
+var ansiSGRRe = regexp.MustCompile(`\x1b\[([0-9;]*)m`)
+
+// emphasizeANSI overlays bold onto every SGR sequence in an already-colored
+// string, appending the bold attribute after each sequence's own codes so
+// it survives even a full reset (fatih/color sometimes resets a single
+// attribute rather than emitting a generic "\x1b[0m", so the overlay can't
+// assume any particular reset form — appending bold after whatever codes
+// are already there works regardless).
+func emphasizeANSI(s string) string {
+	return ansiSGRRe.ReplaceAllStringFunc(s, func(seq string) string {
+		params := ansiSGRRe.FindStringSubmatch(seq)[1]
+		if params == "" {
+			return "\x1b[1m"
+		}
+		return "\x1b[" + params + ";1m"
+	})
+}
+
+// ansiTruecolorRe matches a 24-bit truecolor foreground SGR sequence.
+var ansiTruecolorRe = regexp.MustCompile(`\x1b\[38;2;(\d+);(\d+);(\d+)m`)
+
+// supportsTruecolor reports whether the terminal advertises 24-bit color
+// support via $COLORTERM, the same signal most terminal-aware tools use.
+func supportsTruecolor() bool {
+	ct := strings.ToLower(os.Getenv("COLORTERM"))
+	return ct == "truecolor" || ct == "24bit"
+}
+
+// dimANSI lowers the visual weight of an already-colored string by about
+// 30%, for non-pointer source lines. When the terminal supports truecolor
+// it scales each foreground color's RGB channels directly; otherwise it
+// falls back to the generic faint SGR attribute.
+func dimANSI(s string) string {
+	if !supportsTruecolor() {
+		return "\x1b[2m" + s + "\x1b[22m"
+	}
+	return ansiTruecolorRe.ReplaceAllStringFunc(s, func(seq string) string {
+		m := ansiTruecolorRe.FindStringSubmatch(seq)
+		r, _ := strconv.Atoi(m[1])
+		g, _ := strconv.Atoi(m[2])
+		bch, _ := strconv.Atoi(m[3])
+		return "\x1b[38;2;" + strconv.Itoa(int(float64(r)*0.7)) + ";" +
+			strconv.Itoa(int(float64(g)*0.7)) + ";" +
+			strconv.Itoa(int(float64(bch)*0.7)) + "m"
+	})
+}
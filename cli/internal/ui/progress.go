@@ -0,0 +1,106 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: ui :: progress  —  TaskProgress channel + its two renderers
+//
+//  Borrowed from the TaskProgress model arduino-cli's own compile pipeline
+//  uses: name + message + percent + completed, pushed over a channel so the
+//  step doing the work (scaffold, build's arduino-cli compile, a remote
+//  template's git clone) doesn't know or care how progress gets displayed.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// TaskProgress is one progress update for a named unit of work — a scaffold
+// step, an arduino-cli compile, a template's git clone. Percent is 0-100;
+// Completed marks the event that ends Name's row (success or failure is
+// taken from Failed and passed on to Spinner.Stop).
+type TaskProgress struct {
+	Name      string  `json:"name"`
+	Message   string  `json:"message"`
+	Percent   float64 `json:"percent"`
+	Completed bool    `json:"completed"`
+	Failed    bool    `json:"failed,omitempty"`
+}
+
+// UseJSONProgress decides whether progress should render as NDJSON: either
+// the caller explicitly asked for it (--progress=json) or stdout isn't a
+// terminal (editor integrations, CI, `| cat`), in which case the spinner's
+// carriage-return redraws would just come out as garbage lines anyway.
+func UseJSONProgress(flag string) bool {
+	if flag == "json" {
+		return true
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// RenderProgress drains ch, rendering each TaskProgress as either the
+// classic per-step spinner (the default) or one NDJSON line on w (when
+// jsonMode is true). It returns once ch is closed, so callers should run it
+// in its own goroutine alongside whatever is sending to ch.
+func RenderProgress(ch <-chan TaskProgress, jsonMode bool, w io.Writer) {
+	if jsonMode {
+		renderProgressJSON(ch, w)
+		return
+	}
+	renderProgressSpinner(ch)
+}
+
+func renderProgressJSON(ch <-chan TaskProgress, w io.Writer) {
+	enc := json.NewEncoder(w)
+	for p := range ch {
+		_ = enc.Encode(p)
+	}
+}
+
+// renderProgressSpinner reproduces today's per-step spinner UX: a spinner
+// starts the first time a Name is seen and stops on that Name's Completed
+// event, so a long-running child's sub-events (a git clone's "Receiving
+// objects..." lines, arduino-cli compile's own percent) just update the
+// same spinner's message instead of spawning a new line.
+func renderProgressSpinner(ch <-chan TaskProgress) {
+	spinners := map[string]*Spinner{}
+	for p := range ch {
+		sp, ok := spinners[p.Name]
+		if !ok {
+			sp = NewSpinner(p.Name)
+			sp.Start()
+			spinners[p.Name] = sp
+		}
+
+		if p.Completed {
+			sp.Stop(!p.Failed, finalMessage(p))
+			delete(spinners, p.Name)
+			continue
+		}
+
+		// An in-flight sub-event (a git clone's "Receiving objects..."
+		// line, arduino-cli compile's own percent) just updates the
+		// running spinner's message in place.
+		if p.Message != "" {
+			sp.SetMessage(p.Message)
+		}
+	}
+}
+
+func finalMessage(p TaskProgress) string {
+	if p.Message != "" {
+		return p.Message
+	}
+	return p.Name
+}
+
+// PercentOfStep returns how far through a total-step sequence index i
+// (0-based) is, as the 0-100 Percent TaskProgress expects.
+func PercentOfStep(i, total int) float64 {
+	if total <= 0 {
+		return 100
+	}
+	return 100 * float64(i) / float64(total)
+}
@@ -0,0 +1,347 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: ui :: theme  —  pluggable color themes
+//
+//  A Theme holds every semantic color role this package draws with (the
+//  ColorTitle/ColorKey/... globals in ui.go) as *color.Color values. Themes are
+//  parsed from TOML shaped like:
+//
+//    [colors]
+//    success = "bright_green+bold"
+//    error   = "#ff5f5f"
+//
+//  A color spec is a base (a named ANSI color, optionally "bright_"-prefixed
+//  for the Hi* variants, or a "#rrggbb" hex triggering 24-bit color) plus
+//  zero or more "+"-joined attribute modifiers (bold, italic, underline,
+//  faint/dim).
+//
+//  Resolution order (see InitTheme): NO_COLOR / TERM=dumb forces the
+//  "monochrome" preset; otherwise --theme (preset name or file path), then
+//  the project manifest's [package] theme field, then
+//  $XDG_CONFIG_HOME/tsuki/theme.toml, falling back to "default". A theme
+//  that fails to resolve degrades to the default preset rather than erroring
+//  out, the same posture as i18n.Init.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package ui
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fatih/color"
+)
+
+//go:embed themes/*.toml
+var embeddedThemes embed.FS
+
+// Theme holds every semantic color role used across Box, Traceback,
+// PrintConfig, and RenderDiagnostic.
+type Theme struct {
+	Title   *color.Color
+	Key     *color.Color
+	Value   *color.Color
+	String  *color.Color
+	Number  *color.Color
+	Bool    *color.Color
+	Null    *color.Color
+	Comment *color.Color
+
+	Success *color.Color
+	Error   *color.Color
+	Warn    *color.Color
+	Info    *color.Color
+	Muted   *color.Color
+
+	TBBorder  *color.Color
+	TBTitle   *color.Color
+	TBFile    *color.Color
+	TBLine    *color.Color
+	TBFunc    *color.Color
+	TBCode    *color.Color
+	TBHigh    *color.Color
+	TBLocals  *color.Color
+	TBErrType *color.Color
+	TBErrMsg  *color.Color
+
+	DiagError *color.Color
+	DiagWarn  *color.Color
+	DiagNote  *color.Color
+	DiagHelp  *color.Color
+	DiagCode  *color.Color
+}
+
+// themeField is one [colors] key paired with the Theme field it fills in —
+// driving both parseThemeTOML and ApplyTheme off a single table keeps the
+// two from drifting out of sync as roles are added.
+type themeField struct {
+	key string
+	get func(*Theme) **color.Color
+}
+
+var themeFields = []themeField{
+	{"title", func(t *Theme) **color.Color { return &t.Title }},
+	{"key", func(t *Theme) **color.Color { return &t.Key }},
+	{"value", func(t *Theme) **color.Color { return &t.Value }},
+	{"string", func(t *Theme) **color.Color { return &t.String }},
+	{"number", func(t *Theme) **color.Color { return &t.Number }},
+	{"bool", func(t *Theme) **color.Color { return &t.Bool }},
+	{"null", func(t *Theme) **color.Color { return &t.Null }},
+	{"comment", func(t *Theme) **color.Color { return &t.Comment }},
+
+	{"success", func(t *Theme) **color.Color { return &t.Success }},
+	{"error", func(t *Theme) **color.Color { return &t.Error }},
+	{"warn", func(t *Theme) **color.Color { return &t.Warn }},
+	{"info", func(t *Theme) **color.Color { return &t.Info }},
+	{"muted", func(t *Theme) **color.Color { return &t.Muted }},
+
+	{"tb_border", func(t *Theme) **color.Color { return &t.TBBorder }},
+	{"tb_title", func(t *Theme) **color.Color { return &t.TBTitle }},
+	{"tb_file", func(t *Theme) **color.Color { return &t.TBFile }},
+	{"tb_line", func(t *Theme) **color.Color { return &t.TBLine }},
+	{"tb_func", func(t *Theme) **color.Color { return &t.TBFunc }},
+	{"tb_code", func(t *Theme) **color.Color { return &t.TBCode }},
+	{"tb_high", func(t *Theme) **color.Color { return &t.TBHigh }},
+	{"tb_locals", func(t *Theme) **color.Color { return &t.TBLocals }},
+	{"tb_err_type", func(t *Theme) **color.Color { return &t.TBErrType }},
+	{"tb_err_msg", func(t *Theme) **color.Color { return &t.TBErrMsg }},
+
+	{"diag_error", func(t *Theme) **color.Color { return &t.DiagError }},
+	{"diag_warn", func(t *Theme) **color.Color { return &t.DiagWarn }},
+	{"diag_note", func(t *Theme) **color.Color { return &t.DiagNote }},
+	{"diag_help", func(t *Theme) **color.Color { return &t.DiagHelp }},
+	{"diag_code", func(t *Theme) **color.Color { return &t.DiagCode }},
+}
+
+// rawThemeFile is the shape of a theme TOML file: a single [colors] table
+// of role → spec string.
+type rawThemeFile struct {
+	Colors map[string]string `toml:"colors"`
+}
+
+// ApplyTheme points every ColorXxx global at t's roles, so the rest of this
+// package (and every caller that already holds one of those vars) picks up
+// the new theme without any further change.
+func ApplyTheme(t *Theme) {
+	ColorTitle, ColorKey, ColorValue = t.Title, t.Key, t.Value
+	ColorString, ColorNumber, ColorBool = t.String, t.Number, t.Bool
+	ColorNull, ColorComment = t.Null, t.Comment
+
+	ColorSuccess, ColorError, ColorWarn = t.Success, t.Error, t.Warn
+	ColorInfo, ColorMuted = t.Info, t.Muted
+
+	ColorTBBorder, ColorTBTitle, ColorTBFile = t.TBBorder, t.TBTitle, t.TBFile
+	ColorTBLine, ColorTBFunc, ColorTBCode = t.TBLine, t.TBFunc, t.TBCode
+	ColorTBHigh, ColorTBLocals = t.TBHigh, t.TBLocals
+	ColorTBErrType, ColorTBErrMsg = t.TBErrType, t.TBErrMsg
+
+	ColorDiagError, ColorDiagWarn = t.DiagError, t.DiagWarn
+	ColorDiagNote, ColorDiagHelp, ColorDiagCode = t.DiagNote, t.DiagHelp, t.DiagCode
+}
+
+// builtinPresets is the set of theme names InitTheme and --theme resolve
+// without touching the filesystem.
+var builtinPresets = map[string]bool{"default": true, "solarized": true, "monochrome": true}
+
+// LoadTheme reads and parses a theme TOML file at path, layering its
+// [colors] table over the default preset so a file overriding only a few
+// roles still produces a complete Theme.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	base, err := loadPreset("default")
+	if err != nil {
+		return nil, err
+	}
+	return parseThemeTOML(data, base)
+}
+
+// loadPreset loads one of the embedded built-in presets.
+func loadPreset(name string) (*Theme, error) {
+	data, err := embeddedThemes.ReadFile(filepath.Join("themes", name+".toml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown theme preset %q", name)
+	}
+	return parseThemeTOML(data, nil)
+}
+
+// mustLoadPreset loads an embedded preset expected to always exist — a
+// missing or malformed builtin .toml is a packaging bug, not something to
+// recover from at runtime.
+func mustLoadPreset(name string) *Theme {
+	t, err := loadPreset(name)
+	if err != nil {
+		panic(fmt.Sprintf("ui: loading builtin theme %q: %v", name, err))
+	}
+	return t
+}
+
+// parseThemeTOML decodes data's [colors] table into a Theme. base supplies
+// the starting value for every role (nil means "start from zero values",
+// used only when parsing a preset, which is expected to set every role
+// itself); any [colors] key that fails to parse is skipped rather than
+// aborting the whole theme.
+func parseThemeTOML(data []byte, base *Theme) (*Theme, error) {
+	var raw rawThemeFile
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, err
+	}
+
+	t := &Theme{}
+	if base != nil {
+		*t = *base
+	}
+	for _, f := range themeFields {
+		spec, ok := raw.Colors[f.key]
+		if !ok {
+			continue
+		}
+		c, err := parseColorSpec(spec)
+		if err != nil {
+			Warn(fmt.Sprintf("theme: %s: %v — keeping previous color", f.key, err))
+			continue
+		}
+		*f.get(t) = c
+	}
+	return t, nil
+}
+
+var namedColors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+
+	"bright_black":   color.FgHiBlack,
+	"bright_red":     color.FgHiRed,
+	"bright_green":   color.FgHiGreen,
+	"bright_yellow":  color.FgHiYellow,
+	"bright_blue":    color.FgHiBlue,
+	"bright_magenta": color.FgHiMagenta,
+	"bright_cyan":    color.FgHiCyan,
+	"bright_white":   color.FgHiWhite,
+}
+
+var attrModifiers = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"faint":     color.Faint,
+	"dim":       color.Faint,
+}
+
+// parseColorSpec parses one "<base>[+<attr>]*" color spec — a named ANSI
+// color (optionally "bright_"-prefixed) or a "#rrggbb" hex triggering
+// 24-bit color, plus any number of "+"-joined attribute modifiers.
+func parseColorSpec(spec string) (*color.Color, error) {
+	parts := strings.Split(spec, "+")
+	base := strings.ToLower(strings.TrimSpace(parts[0]))
+
+	var attrs []color.Attribute
+	if strings.HasPrefix(base, "#") {
+		r, g, b, err := parseHexColor(base)
+		if err != nil {
+			return nil, err
+		}
+		// 38;2;r;g;b is the SGR sequence for a 24-bit foreground color —
+		// color.Attribute is just an int, and Color.sequence() joins params
+		// with ";" in order, so five attrs produce exactly that sequence.
+		attrs = append(attrs, color.Attribute(38), color.Attribute(2),
+			color.Attribute(r), color.Attribute(g), color.Attribute(b))
+	} else {
+		a, ok := namedColors[base]
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", base)
+		}
+		attrs = append(attrs, a)
+	}
+
+	for _, mod := range parts[1:] {
+		m, ok := attrModifiers[strings.ToLower(strings.TrimSpace(mod))]
+		if !ok {
+			return nil, fmt.Errorf("unknown color attribute %q", mod)
+		}
+		attrs = append(attrs, m)
+	}
+	return color.New(attrs...), nil
+}
+
+func parseHexColor(s string) (r, g, b int, err error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", "#"+s)
+	}
+	n, err := strconv.ParseInt(s, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", "#"+s)
+	}
+	return int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff), nil
+}
+
+// userThemePath returns $XDG_CONFIG_HOME/tsuki/theme.toml, falling back to
+// ~/.config when $XDG_CONFIG_HOME is unset — mirrors i18n's override-file
+// resolution.
+func userThemePath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "tsuki", "theme.toml")
+}
+
+// resolveThemeName loads name as a builtin preset if it is one, otherwise
+// treats it as a path to a theme TOML file.
+func resolveThemeName(name string) (*Theme, error) {
+	if builtinPresets[name] {
+		return loadPreset(name)
+	}
+	return LoadTheme(name)
+}
+
+// InitTheme selects and applies the active color theme. It never returns an
+// error: an unresolvable --theme value logs a warning, an unresolvable
+// manifest theme or user config file is skipped silently, and each falls
+// back to the next source in priority order, ending at the built-in
+// "default" preset.
+func InitTheme(themeFlag, manifestTheme string) {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		ApplyTheme(mustLoadPreset("monochrome"))
+		return
+	}
+
+	if themeFlag != "" {
+		if t, err := resolveThemeName(themeFlag); err == nil {
+			ApplyTheme(t)
+			return
+		}
+		Warn(fmt.Sprintf("unknown theme %q — falling back", themeFlag))
+	}
+
+	if manifestTheme != "" {
+		if t, err := resolveThemeName(manifestTheme); err == nil {
+			ApplyTheme(t)
+			return
+		}
+	}
+
+	if t, err := LoadTheme(userThemePath()); err == nil {
+		ApplyTheme(t)
+		return
+	}
+
+	ApplyTheme(mustLoadPreset("default"))
+}
@@ -0,0 +1,111 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: ui :: tty  —  cross-platform raw-mode terminal input
+//
+//  Built on golang.org/x/term — the same dependency internal/ui already
+//  uses for terminal width detection — rather than hand-rolled per-OS
+//  termios syscalls, so raw mode, cursor control, and size detection all
+//  work correctly on Linux, macOS, and Windows consoles alike.
+// ─────────────────────────────────────────────────────────────────────────────
+
+package tty
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Key is a single logical keypress the interactive wizard prompts care
+// about — ReadKey collapses raw bytes (including multi-byte escape
+// sequences) down to these.
+type Key int
+
+const (
+	KeyOther Key = iota
+	KeyUp
+	KeyDown
+	KeyEnter
+	KeyCtrlC
+)
+
+// Terminal is a raw-mode-capable stdin/stdout, abstracted so callers like
+// promptArrowSelect don't touch syscalls or OS build tags directly.
+type Terminal interface {
+	// MakeRaw puts the terminal into raw mode (no echo, no line
+	// buffering) and returns a restore func that puts it back — callers
+	// should always defer the returned func.
+	MakeRaw() (restore func(), err error)
+
+	// ReadKey blocks for the next keypress, translating arrow-key escape
+	// sequences and Ctrl-C into their Key constants.
+	ReadKey() (Key, error)
+
+	HideCursor()
+	ShowCursor()
+
+	// Size returns the terminal's current width and height, in columns
+	// and rows.
+	Size() (w, h int)
+}
+
+// Open returns the Terminal for stdin. ok is false when stdin isn't an
+// interactive terminal (a pipe, a CI runner, `| cat`), in which case
+// callers should fall back to a non-interactive prompt instead.
+func Open() (t Terminal, ok bool) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, false
+	}
+	return &consoleTerminal{fd: fd}, true
+}
+
+type consoleTerminal struct {
+	fd int
+}
+
+func (t *consoleTerminal) MakeRaw() (func(), error) {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+	return func() { _ = term.Restore(t.fd, state) }, nil
+}
+
+// ReadKey reads up to one escape sequence's worth of bytes from stdin.
+// Arrow keys arrive as the three-byte sequence ESC '[' ('A'|'B'); plain
+// Enter/Ctrl-C are one byte.
+func (t *consoleTerminal) ReadKey() (Key, error) {
+	buf := make([]byte, 3)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return KeyOther, err
+	}
+	switch {
+	case n == 0:
+		return KeyOther, nil
+	case buf[0] == '\r' || buf[0] == '\n':
+		return KeyEnter, nil
+	case buf[0] == 3:
+		return KeyCtrlC, nil
+	case n >= 3 && buf[0] == 27 && buf[1] == '[':
+		switch buf[2] {
+		case 'A':
+			return KeyUp, nil
+		case 'B':
+			return KeyDown, nil
+		}
+	}
+	return KeyOther, nil
+}
+
+func (t *consoleTerminal) HideCursor() { fmt.Print("\033[?25l") }
+func (t *consoleTerminal) ShowCursor() { fmt.Print("\033[?25h") }
+
+func (t *consoleTerminal) Size() (int, int) {
+	w, h, err := term.GetSize(t.fd)
+	if err != nil {
+		return 80, 24
+	}
+	return w, h
+}
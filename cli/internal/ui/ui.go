@@ -7,27 +7,33 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
 	"math"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
+
+	"github.com/tsuki/cli/internal/i18n"
 )
 
 // ── Color palette ─────────────────────────────────────────────────────────────
 
 var (
 	// Primary
-	ColorTitle    = color.New(color.FgHiWhite, color.Bold)
-	ColorKey      = color.New(color.FgHiCyan)
-	ColorValue    = color.New(color.FgHiYellow)
-	ColorString   = color.New(color.FgHiGreen)
-	ColorNumber   = color.New(color.FgHiBlue)
-	ColorBool     = color.New(color.FgHiMagenta)
-	ColorNull     = color.New(color.FgHiBlack)
-	ColorComment  = color.New(color.FgHiBlack, color.Italic)
+	ColorTitle   = color.New(color.FgHiWhite, color.Bold)
+	ColorKey     = color.New(color.FgHiCyan)
+	ColorValue   = color.New(color.FgHiYellow)
+	ColorString  = color.New(color.FgHiGreen)
+	ColorNumber  = color.New(color.FgHiBlue)
+	ColorBool    = color.New(color.FgHiMagenta)
+	ColorNull    = color.New(color.FgHiBlack)
+	ColorComment = color.New(color.FgHiBlack, color.Italic)
 
 	// Status
 	ColorSuccess = color.New(color.FgHiGreen, color.Bold)
@@ -43,7 +49,7 @@ var (
 	ColorTBLine    = color.New(color.FgHiYellow)
 	ColorTBFunc    = color.New(color.FgHiGreen)
 	ColorTBCode    = color.New(color.FgHiWhite)
-	ColorTBHigh    = color.New(color.FgHiRed, color.Bold)  // highlighted error line
+	ColorTBHigh    = color.New(color.FgHiRed, color.Bold) // highlighted error line
 	ColorTBLocals  = color.New(color.FgHiYellow)
 	ColorTBErrType = color.New(color.FgHiRed, color.Bold)
 	ColorTBErrMsg  = color.New(color.FgHiWhite)
@@ -51,11 +57,6 @@ var (
 
 // ── Box drawing ───────────────────────────────────────────────────────────────
 
-func termWidth() int {
-	// default 100 if we can't detect
-	return 100
-}
-
 func hline(width int, ch string) string {
 	if width <= 0 {
 		return ""
@@ -63,13 +64,20 @@ func hline(width int, ch string) string {
 	return strings.Repeat(ch, width)
 }
 
-// Box draws a bordered panel with a title.
+// Box draws a bordered panel with a title. Content lines longer than the
+// inner width wrap with a continuation indent rather than being truncated
+// by the padding math. On a terminal narrower than minBoxWidth, Box skips
+// the border entirely and falls back to printCompactBox.
 //
 //	╭── Title ──────────────────────────────────╮
 //	│  content...                               │
 //	╰───────────────────────────────────────────╯
 func Box(title, content string, titleColor *color.Color) {
 	w := termWidth()
+	if w < minBoxWidth {
+		printCompactBox(title, content, titleColor)
+		return
+	}
 	inner := w - 2 // 2 for side borders
 
 	// top border
@@ -78,32 +86,48 @@ func Box(title, content string, titleColor *color.Color) {
 	left := dashes / 2
 	right := dashes - left
 
-	topLine := "╭" + hline(left, "─") + titleStr + hline(right, "─") + "╮"
 	ColorTBBorder.Fprint(os.Stderr, "╭"+hline(left, "─"))
 	if titleColor != nil {
 		titleColor.Fprint(os.Stderr, titleStr)
 	} else {
 		fmt.Fprint(os.Stderr, titleStr)
 	}
-	_ = topLine
 	ColorTBBorder.Fprintln(os.Stderr, hline(right, "─")+"╮")
 
-	// content lines
+	// content lines, wrapped to leave room for a 2-space continuation indent
 	for _, line := range strings.Split(content, "\n") {
-		// pad/truncate
-		pad := inner - len(stripANSI(line)) - 1 // -1 for leading space
-		if pad < 0 {
-			pad = 0
+		for i, wrapped := range wrapANSILine(line, inner-4) {
+			text := wrapped
+			if i > 0 {
+				text = "  " + text
+			}
+			pad := inner - len(stripANSI(text)) - 1 // -1 for leading space
+			if pad < 0 {
+				pad = 0
+			}
+			ColorTBBorder.Fprint(os.Stderr, "│")
+			fmt.Fprint(os.Stderr, " "+text+strings.Repeat(" ", pad))
+			ColorTBBorder.Fprintln(os.Stderr, "│")
 		}
-		ColorTBBorder.Fprint(os.Stderr, "│")
-		fmt.Fprint(os.Stderr, " "+line+strings.Repeat(" ", pad))
-		ColorTBBorder.Fprintln(os.Stderr, "│")
 	}
 
 	// bottom border
 	ColorTBBorder.Fprintln(os.Stderr, "╰"+hline(inner, "─")+"╯")
 }
 
+// printCompactBox renders title/content as plain lines with no box-drawing
+// border — used when the terminal is too narrow (< minBoxWidth) for a
+// bordered layout to do anything but mangle the content.
+func printCompactBox(title, content string, titleColor *color.Color) {
+	if titleColor != nil {
+		titleColor.Fprintln(os.Stderr, title)
+	} else {
+		fmt.Fprintln(os.Stderr, title)
+	}
+	ColorTBBorder.Fprintln(os.Stderr, strings.Repeat("─", len(stripANSI(title))))
+	fmt.Fprintln(os.Stderr, content)
+}
+
 // stripANSI removes escape sequences for length calculation.
 func stripANSI(s string) string {
 	var b strings.Builder
@@ -124,6 +148,70 @@ func stripANSI(s string) string {
 	return b.String()
 }
 
+// wrapANSILine breaks s, which may contain ANSI color codes, into lines no
+// wider than width (measured by visible, stripANSI length). A break prefers
+// the last space at or before the limit; a run with no breakable space hard
+// wraps at width instead. Escape sequences have zero visible width, so a
+// break point chosen in the stripped text never lands inside one.
+func wrapANSILine(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	if len(stripANSI(s)) <= width {
+		return []string{s}
+	}
+
+	runes := []rune(s)
+	var visible []int // index into runes of each visible (non-escape) rune
+	inEsc := false
+	for i, r := range runes {
+		switch {
+		case r == '\x1b':
+			inEsc = true
+		case inEsc:
+			if r == 'm' {
+				inEsc = false
+			}
+		default:
+			visible = append(visible, i)
+		}
+	}
+
+	var lines []string
+	start := 0
+	for start < len(visible) {
+		end := start + width
+		if end > len(visible) {
+			end = len(visible)
+		}
+		breakAt := end
+		if end < len(visible) {
+			for i := end; i > start; i-- {
+				if runes[visible[i-1]] == ' ' {
+					breakAt = i
+					break
+				}
+			}
+			if breakAt == start {
+				breakAt = end // no space to break on — hard wrap
+			}
+		}
+
+		from := visible[start]
+		to := len(runes)
+		if breakAt < len(visible) {
+			to = visible[breakAt]
+		}
+		lines = append(lines, strings.TrimRight(string(runes[from:to]), " "))
+
+		start = breakAt
+		for start < len(visible) && runes[visible[start]] == ' ' {
+			start++
+		}
+	}
+	return lines
+}
+
 // ── Traceback (rich-style) ────────────────────────────────────────────────────
 
 // Frame represents one stack frame in a traceback.
@@ -131,6 +219,7 @@ type Frame struct {
 	File     string
 	Line     int
 	Func     string
+	Language string     // highlighter lang, e.g. "go"; inferred from File if empty
 	Code     []CodeLine // surrounding source lines
 	Locals   map[string]string
 }
@@ -167,20 +256,27 @@ func Traceback(errType, errMsg string, frames []Frame) {
 	var sb strings.Builder
 
 	// ── header
+	title := " " + i18n.T("traceback.title") + " "
 	ColorTBBorder.Fprint(os.Stderr, "╭"+hline(3, "─"))
-	ColorTBTitle.Fprint(os.Stderr, " Traceback (most recent call last) ")
-	ColorTBBorder.Fprintln(os.Stderr, hline(inner-40, "─")+"╮")
+	ColorTBTitle.Fprint(os.Stderr, title)
+	ColorTBBorder.Fprintln(os.Stderr, hline(inner-3-len(title), "─")+"╮")
 
 	sb.Reset()
 
 	printBorderLine := func(content string) {
-		pad := inner - len(stripANSI(content)) - 1 // -1 for leading space
-		if pad < 0 {
-			pad = 0
+		for i, wrapped := range wrapANSILine(content, inner-4) {
+			text := wrapped
+			if i > 0 {
+				text = "  " + text
+			}
+			pad := inner - len(stripANSI(text)) - 1 // -1 for leading space
+			if pad < 0 {
+				pad = 0
+			}
+			ColorTBBorder.Fprint(os.Stderr, "│")
+			fmt.Fprint(os.Stderr, " "+text+strings.Repeat(" ", pad))
+			ColorTBBorder.Fprintln(os.Stderr, "│")
 		}
-		ColorTBBorder.Fprint(os.Stderr, "│")
-		fmt.Fprint(os.Stderr, " "+content+strings.Repeat(" ", pad))
-		ColorTBBorder.Fprintln(os.Stderr, "│")
 	}
 
 	printEmpty := func() {
@@ -198,18 +294,23 @@ func Traceback(errType, errMsg string, frames []Frame) {
 		printEmpty()
 
 		// source lines
+		lang := frame.Language
+		if lang == "" {
+			lang = inferLanguage(frame.File)
+		}
 		for _, cl := range frame.Code {
 			lineNum := fmt.Sprintf("%4d", cl.Number)
+			highlighted := activeHighlighter.Highlight(lang, cl.Text)
 			if cl.IsPointer {
 				prefix := ColorTBHigh.Sprint(" ❱ ")
 				numStr := ColorTBHigh.Sprint(lineNum)
 				sep := ColorTBBorder.Sprint(" │ ")
-				code := ColorTBHigh.Sprint(cl.Text)
+				code := emphasizeANSI(highlighted)
 				printBorderLine(prefix + numStr + sep + code)
 			} else {
 				numStr := ColorMuted.Sprint(lineNum)
 				sep := ColorTBBorder.Sprint(" │ ")
-				code := ColorTBCode.Sprint(cl.Text)
+				code := dimANSI(highlighted)
 				printBorderLine("   " + numStr + sep + code)
 			}
 		}
@@ -217,7 +318,8 @@ func Traceback(errType, errMsg string, frames []Frame) {
 		// locals
 		if len(frame.Locals) > 0 {
 			printEmpty()
-			locTitle := ColorTBLocals.Sprint(" locals ") + ColorTBBorder.Sprint(hline(inner-12, "─"))
+			localsLabel := " " + i18n.T("traceback.locals") + " "
+			locTitle := ColorTBLocals.Sprint(localsLabel) + ColorTBBorder.Sprint(hline(inner-4-len(localsLabel), "─"))
 			printBorderLine(locTitle)
 			for k, v := range frame.Locals {
 				localLine := ColorTBBorder.Sprint("│  ") + ColorKey.Sprint(k) + " = " + ColorValue.Sprint(v)
@@ -236,6 +338,151 @@ func Traceback(errType, errMsg string, frames []Frame) {
 	ColorTBErrMsg.Fprintln(os.Stderr, errMsg)
 }
 
+// ── Structured diagnostics ─────────────────────────────────────────────────────
+//
+// RenderDiagnostic supersedes Traceback for core binaries that speak the
+// --diagnostics=json protocol (see core.Diagnostic): rather than scraping
+// freeform stderr into a single frame, it pretty-prints one already-decoded
+// diagnostic with a caret underline under the offending span, gutter line
+// numbers, secondary spans, and inline suggested fixes. Traceback remains in
+// place for the legacy path (older core binaries, and check.go).
+//
+// DiagnosticSpan/DiagnosticLabel/DiagnosticFix deliberately mirror
+// core.Span/LabeledSpan/Fix rather than importing the core package — core
+// already imports ui (for Traceback), so the reverse import would cycle.
+
+// DiagnosticSpan marks a location in a source file. Line and Column are
+// 1-indexed; End{Line,Column} are exclusive.
+type DiagnosticSpan struct {
+	File      string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+// DiagnosticLabel is a secondary span shown alongside the primary one, with
+// a short label explaining its relevance (e.g. "defined here").
+type DiagnosticLabel struct {
+	Span  DiagnosticSpan
+	Label string
+}
+
+// DiagnosticFix is a suggested source replacement for a span.
+type DiagnosticFix struct {
+	Span        DiagnosticSpan
+	Replacement string
+}
+
+var (
+	ColorDiagError = color.New(color.FgHiRed, color.Bold)
+	ColorDiagWarn  = color.New(color.FgHiYellow, color.Bold)
+	ColorDiagNote  = color.New(color.FgHiCyan, color.Bold)
+	ColorDiagHelp  = color.New(color.FgHiGreen, color.Bold)
+	ColorDiagCode  = color.New(color.FgHiBlack)
+)
+
+// RenderDiagnostic pretty-prints one structured diagnostic to stderr.
+//
+// Example output:
+//
+//	error[E0308]: mismatched types
+//	 --> blink.go:14:9
+//	   │
+//	12 │ func setup() {
+//	13 │     pin := led.Pin(13)
+//	14 │     delay(pin)
+//	   │           ^^^ expected time.Duration, found led.Pin
+//	   │
+//	   = help: replace with `delay(time.Second)`
+func RenderDiagnostic(severity, code, message string, primary DiagnosticSpan, secondary []DiagnosticLabel, suggestions []DiagnosticFix, notes []string) {
+	sevColor, label := diagSeverityStyle(severity)
+	if code != "" {
+		label = label + "[" + code + "]"
+	}
+	sevColor.Fprint(os.Stderr, label)
+	fmt.Fprint(os.Stderr, ": ")
+	ColorTBErrMsg.Fprintln(os.Stderr, message)
+
+	printDiagSpan(sevColor, primary, "-->")
+
+	for _, s := range secondary {
+		ColorDiagNote.Fprint(os.Stderr, "note")
+		fmt.Fprintf(os.Stderr, ": %s\n", s.Label)
+		printDiagSpan(ColorDiagNote, s.Span, "-->")
+	}
+
+	for _, fix := range suggestions {
+		ColorDiagHelp.Fprint(os.Stderr, "help")
+		fmt.Fprintf(os.Stderr, ": replace with `%s`\n", fix.Replacement)
+		printDiagSpan(ColorDiagHelp, fix.Span, "-->")
+	}
+
+	for _, n := range notes {
+		ColorDiagNote.Fprint(os.Stderr, "note")
+		fmt.Fprintf(os.Stderr, ": %s\n", n)
+	}
+
+	fmt.Fprintln(os.Stderr)
+}
+
+func diagSeverityStyle(severity string) (*color.Color, string) {
+	switch severity {
+	case "warning":
+		return ColorDiagWarn, "warning"
+	case "note":
+		return ColorDiagNote, "note"
+	case "help":
+		return ColorDiagHelp, "help"
+	default:
+		return ColorDiagError, "error"
+	}
+}
+
+// printDiagSpan prints the "--> file:line:col" header, a few lines of gutter
+// source context read off disk, and a caret underline beneath span's
+// columns on its starting line. Source that can't be read (e.g. the file
+// moved since compilation) is silently skipped — the header line alone is
+// still useful.
+func printDiagSpan(c *color.Color, span DiagnosticSpan, marker string) {
+	gutter := fmt.Sprintf(" %s %s:%d:%d", marker, span.File, span.Line, span.Column)
+	fmt.Fprintln(os.Stderr, ColorMuted.Sprint(gutter))
+
+	lines, err := readSourceLines(span.File)
+	if err != nil || span.Line < 1 || span.Line > len(lines) {
+		return
+	}
+
+	gutterWidth := len(fmt.Sprintf("%d", span.Line))
+	blankGutter := strings.Repeat(" ", gutterWidth)
+
+	fmt.Fprintf(os.Stderr, " %s │\n", blankGutter)
+	if span.Line > 1 {
+		fmt.Fprintf(os.Stderr, " %*d │ %s\n", gutterWidth, span.Line-1, ColorDiagCode.Sprint(lines[span.Line-2]))
+	}
+	fmt.Fprintf(os.Stderr, " %*d │ %s\n", gutterWidth, span.Line, lines[span.Line-1])
+
+	col := span.Column
+	if col < 1 {
+		col = 1
+	}
+	width := span.EndColumn - span.Column
+	if span.EndLine != span.Line || width < 1 {
+		width = 1
+	}
+	underline := strings.Repeat(" ", col-1) + c.Sprint(strings.Repeat("^", width))
+	fmt.Fprintf(os.Stderr, " %s │ %s\n", blankGutter, underline)
+	fmt.Fprintf(os.Stderr, " %s │\n", blankGutter)
+}
+
+func readSourceLines(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
 // ── Config display ────────────────────────────────────────────────────────────
 
 // ConfigEntry is one key/value row in the config display.
@@ -349,6 +596,173 @@ func formatConfigValue(v interface{}) string {
 	}
 }
 
+// ── Registry update table ─────────────────────────────────────────────────────
+
+// RegistryUpdateRow is one registry's outcome, shaped for display only — it
+// mirrors registry.UpdateEntry rather than importing it, the same way
+// DiagnosticSpan mirrors core.Span, since registry already imports ui.
+type RegistryUpdateRow struct {
+	RegistryID   string
+	Status       string // "fetched", "not modified", or "failed"
+	PackageCount int
+	Duration     time.Duration
+	Bytes        int64
+	Err          string
+}
+
+// PrintRegistryUpdateTable renders the per-registry results of a
+// `tsuki updatedb`-style run: one line per row, status color-coded, errors
+// printed beneath their row instead of in the aligned columns.
+func PrintRegistryUpdateTable(rows []RegistryUpdateRow) {
+	idWidth := len("registry")
+	for _, r := range rows {
+		if len(r.RegistryID) > idWidth {
+			idWidth = len(r.RegistryID)
+		}
+	}
+
+	header := fmt.Sprintf("  %-*s  %-12s  %8s  %10s  %s", idWidth, "registry", "status", "packages", "duration", "size")
+	ColorMuted.Fprintln(os.Stdout, header)
+
+	for _, r := range rows {
+		statusColor, label := registryStatusStyle(r.Status)
+		line := fmt.Sprintf("  %-*s  ", idWidth, r.RegistryID)
+		fmt.Fprint(os.Stdout, line)
+		statusColor.Fprintf(os.Stdout, "%-12s", label)
+		fmt.Fprintf(os.Stdout, "  %8d  %10s  %s\n", r.PackageCount, r.Duration.Round(time.Millisecond), formatBytes(r.Bytes))
+		if r.Err != "" {
+			ColorError.Fprintf(os.Stdout, "      %s\n", r.Err)
+		}
+	}
+}
+
+func registryStatusStyle(status string) (*color.Color, string) {
+	switch status {
+	case "fetched":
+		return ColorSuccess, "fetched"
+	case "not modified":
+		return ColorMuted, "not modified"
+	case "failed":
+		return ColorError, "failed"
+	default:
+		return ColorMuted, status
+	}
+}
+
+func formatBytes(n int64) string {
+	if n <= 0 {
+		return "-"
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// OutdatedRow is one installed package's outdated-check outcome, shaped
+// for display only — it mirrors pkgmgr.OutdatedPackage rather than
+// importing it, the same way RegistryUpdateRow mirrors registry.UpdateEntry.
+type OutdatedRow struct {
+	Name             string
+	CurrentVersion   string
+	LatestVersion    string
+	LatestSatisfying string
+}
+
+// PrintOutdated renders one line per outdated package. LatestVersion is
+// colored with ColorWarn (yellow by default) when it's a major-version bump
+// over CurrentVersion, ColorSuccess (green by default) otherwise, so a glance at the column tells you which upgrades are safe
+// to take blindly and which need a changelog read first.
+func PrintOutdated(rows []OutdatedRow) {
+	if len(rows) == 0 {
+		Info("Everything is up to date")
+		return
+	}
+
+	nameWidth := len("package")
+	for _, r := range rows {
+		if len(r.Name) > nameWidth {
+			nameWidth = len(r.Name)
+		}
+	}
+
+	header := fmt.Sprintf("  %-*s  %-10s  %-10s  %s", nameWidth, "package", "current", "latest", "satisfying")
+	ColorMuted.Fprintln(os.Stdout, header)
+
+	for _, r := range rows {
+		latestColor := ColorSuccess
+		if isMajorBump(r.CurrentVersion, r.LatestVersion) {
+			latestColor = ColorWarn
+		}
+		fmt.Fprintf(os.Stdout, "  %-*s  %-10s  ", nameWidth, r.Name, r.CurrentVersion)
+		latestColor.Fprintf(os.Stdout, "%-10s", r.LatestVersion)
+		satisfying := r.LatestSatisfying
+		if satisfying == "" {
+			satisfying = "-"
+		}
+		fmt.Fprintf(os.Stdout, "  %s\n", satisfying)
+	}
+}
+
+// isMajorBump reports whether new's leading version component differs
+// from current's — a same-format string compare, not full SemVer parsing,
+// since this only decides which color to print.
+func isMajorBump(current, new string) bool {
+	major := func(v string) string {
+		if i := strings.Index(v, "."); i >= 0 {
+			return v[:i]
+		}
+		return v
+	}
+	return current != "" && new != "" && major(current) != major(new)
+}
+
+// AdvisoryRow is one installed-package/advisory match, shaped for display
+// only — it mirrors audit.AdvisoryHit rather than importing it, the same
+// way OutdatedRow mirrors pkgmgr.OutdatedPackage.
+type AdvisoryRow struct {
+	Package          string
+	InstalledVersion string
+	Severity         string
+	ID               string
+	Summary          string
+	UpgradeTo        string
+}
+
+// PrintAdvisories renders one block per advisory hit, with the severity
+// colored so a scroll-by glance finds the critical ones first.
+func PrintAdvisories(rows []AdvisoryRow) {
+	if len(rows) == 0 {
+		Success("No known vulnerabilities found in installed packages")
+		return
+	}
+
+	for _, r := range rows {
+		severityColor(r.Severity).Fprintf(os.Stdout, "  [%s] ", strings.ToUpper(r.Severity))
+		fmt.Fprintf(os.Stdout, "%s@%s — %s (%s)\n", r.Package, r.InstalledVersion, r.Summary, r.ID)
+		if r.UpgradeTo != "" {
+			fmt.Fprintf(os.Stdout, "           upgrade to %s: tsuki pkg upgrade %s\n", r.UpgradeTo, r.Package)
+		}
+	}
+}
+
+func severityColor(severity string) *color.Color {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return ColorError
+	case "moderate", "medium":
+		return ColorWarn
+	default:
+		return ColorMuted
+	}
+}
+
 // ── Status messages ───────────────────────────────────────────────────────────
 
 func Success(msg string) {
@@ -393,28 +807,41 @@ func SectionTitle(title string) {
 // ── Spinner ───────────────────────────────────────────────────────────────────
 
 type Spinner struct {
+	msgMu  sync.Mutex // guards msg and width, both written from a different goroutine than Start's render loop
 	msg    string
 	frames []string
 	done   chan struct{}
+	width  int // current terminal width, kept current via OnResize
 }
 
 var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 func NewSpinner(msg string) *Spinner {
-	return &Spinner{msg: msg, frames: spinnerFrames, done: make(chan struct{})}
+	return &Spinner{msg: msg, frames: spinnerFrames, done: make(chan struct{}), width: termWidth()}
 }
 
 func (s *Spinner) Start() {
+	OnResize(func(w int) {
+		s.msgMu.Lock()
+		s.width = w
+		s.msgMu.Unlock()
+	})
 	go func() {
 		i := 0
 		for {
 			select {
 			case <-s.done:
-				fmt.Fprintf(os.Stdout, "\r%-80s\r", "")
+				s.msgMu.Lock()
+				width := s.width
+				s.msgMu.Unlock()
+				fmt.Fprintf(os.Stdout, "\r%-*s\r", width, "")
 				return
 			default:
 				frame := ColorInfo.Sprint(s.frames[i%len(s.frames)])
-				fmt.Fprintf(os.Stdout, "\r  %s  %s", frame, s.msg)
+				s.msgMu.Lock()
+				msg := s.msg
+				s.msgMu.Unlock()
+				fmt.Fprintf(os.Stdout, "\r  %s  %s", frame, msg)
 				time.Sleep(80 * time.Millisecond)
 				i++
 			}
@@ -422,6 +849,15 @@ func (s *Spinner) Start() {
 	}()
 }
 
+// SetMessage updates the spinner's in-flight message — used to surface
+// sub-progress (a git clone's "Receiving objects..." line, arduino-cli
+// compile's own percent) without starting a new spinner.
+func (s *Spinner) SetMessage(msg string) {
+	s.msgMu.Lock()
+	s.msg = msg
+	s.msgMu.Unlock()
+}
+
 func (s *Spinner) Stop(ok bool, finalMsg string) {
 	close(s.done)
 	time.Sleep(100 * time.Millisecond)
@@ -432,6 +868,200 @@ func (s *Spinner) Stop(ok bool, finalMsg string) {
 	}
 }
 
+// ── Spinner group ─────────────────────────────────────────────────────────────
+
+// sgCmdKind identifies one message sent to a SpinnerGroup's owning goroutine.
+type sgCmdKind int
+
+const (
+	sgUpdate sgCmdKind = iota
+	sgFinish
+	sgStop
+)
+
+type sgCmd struct {
+	kind  sgCmdKind
+	slot  int
+	label string
+	ok    bool
+}
+
+// SpinnerGroup renders up to n concurrent spinners stacked vertically,
+// redrawn in place via ANSI cursor movement ("\x1b[<n>A" to rewind to the
+// top of the block, "\x1b[K" to clear each line) — one spinner per worker
+// slot, reused as each worker picks up its next job. A single goroutine
+// (run) owns every write to stdout, so concurrent callers never interleave
+// raw terminal output; Update/Finish/Wait only ever send on a channel.
+//
+// On a non-interactive stdout (a pipe, CI, anything isStdoutInteractive
+// says no to) SpinnerGroup skips the live redraw and just prints each
+// Update/Finish as its own line, the same shape a sequential install loop
+// already produced before SpinnerGroup existed.
+//
+// On an interactive stdout, individual successes are not printed as they
+// happen — only failures get a permanent line mid-run, so they aren't lost
+// once their slot is reused. Either way, Wait prints the one coalesced
+// summary line.
+type SpinnerGroup struct {
+	n           int
+	interactive bool
+	cmds        chan sgCmd
+	done        chan struct{}
+	startedAt   time.Time
+}
+
+// NewSpinnerGroup creates a group of n spinner slots and starts its
+// redraw/event loop immediately.
+func NewSpinnerGroup(n int) *SpinnerGroup {
+	if n < 1 {
+		n = 1
+	}
+	g := &SpinnerGroup{
+		n:           n,
+		interactive: isStdoutInteractive(),
+		cmds:        make(chan sgCmd, n*4),
+		done:        make(chan struct{}),
+		startedAt:   time.Now(),
+	}
+	go g.run()
+	return g
+}
+
+// Update sets slot's current label (e.g. "ws2812 — 42%"). Ignored for an
+// out-of-range slot rather than panicking.
+func (g *SpinnerGroup) Update(slot int, label string) {
+	g.cmds <- sgCmd{kind: sgUpdate, slot: slot, label: label}
+}
+
+// Finish marks slot done and records whether it succeeded, freeing the slot
+// for the next job a caller assigns to it.
+func (g *SpinnerGroup) Finish(slot int, ok bool, label string) {
+	g.cmds <- sgCmd{kind: sgFinish, slot: slot, label: label, ok: ok}
+}
+
+// Wait stops the group and prints the coalesced summary — "installed N,
+// failed M in <duration>" — then blocks until that line has been written.
+func (g *SpinnerGroup) Wait() {
+	g.WaitLabeled("installed")
+}
+
+// WaitLabeled is Wait with the summary's verb replaced — e.g. "transpiled
+// N, failed M in <duration>" for a worker pool that isn't installing
+// anything.
+func (g *SpinnerGroup) WaitLabeled(verb string) {
+	g.cmds <- sgCmd{kind: sgStop, label: verb}
+	<-g.done
+}
+
+func (g *SpinnerGroup) run() {
+	slots := make([]string, g.n)
+	var okCount, failCount int
+	frame := 0
+
+	redraw := func() {
+		if !g.interactive {
+			return
+		}
+		fmt.Fprintf(os.Stdout, "\x1b[%dA", g.n)
+		for _, label := range slots {
+			fmt.Fprint(os.Stdout, "\x1b[K")
+			if label == "" {
+				fmt.Fprintln(os.Stdout)
+				continue
+			}
+			spin := ColorInfo.Sprint(spinnerFrames[frame%len(spinnerFrames)])
+			fmt.Fprintf(os.Stdout, "  %s  %s\n", spin, label)
+		}
+	}
+
+	clearBlock := func() {
+		fmt.Fprintf(os.Stdout, "\x1b[%dA", g.n)
+		for range slots {
+			fmt.Fprint(os.Stdout, "\x1b[K\n")
+		}
+		fmt.Fprintf(os.Stdout, "\x1b[%dA", g.n)
+	}
+
+	if g.interactive {
+		for i := 0; i < g.n; i++ {
+			fmt.Fprintln(os.Stdout)
+		}
+	}
+
+	ticker := time.NewTicker(80 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case cmd := <-g.cmds:
+			switch cmd.kind {
+			case sgUpdate:
+				if cmd.slot >= 0 && cmd.slot < g.n {
+					slots[cmd.slot] = cmd.label
+				}
+				if !g.interactive {
+					Info(cmd.label)
+				}
+			case sgFinish:
+				if cmd.slot >= 0 && cmd.slot < g.n {
+					slots[cmd.slot] = ""
+				}
+				if cmd.ok {
+					okCount++
+				} else {
+					failCount++
+				}
+				if !g.interactive {
+					if cmd.ok {
+						Success(cmd.label)
+					} else {
+						Fail(cmd.label)
+					}
+					continue
+				}
+				if !cmd.ok {
+					// Reopen the block below it so the next redraw still
+					// has its n reserved lines.
+					clearBlock()
+					Fail(cmd.label)
+					for i := 0; i < g.n; i++ {
+						fmt.Fprintln(os.Stdout)
+					}
+				}
+			case sgStop:
+				if g.interactive {
+					clearBlock()
+				}
+				verb := cmd.label
+				if verb == "" {
+					verb = "installed"
+				}
+				Success(fmt.Sprintf("%s %d, failed %d in %s",
+					verb, okCount, failCount, time.Since(g.startedAt).Round(time.Millisecond)))
+				close(g.done)
+				return
+			}
+			redraw()
+		case <-ticker.C:
+			if g.interactive {
+				frame++
+				redraw()
+			}
+		}
+	}
+}
+
+// isStdoutInteractive reports whether stdout is an interactive terminal —
+// the SpinnerGroup analogue of isStdinInteractive, which NumberMenu uses
+// for the same "pipe/CI degrades gracefully" purpose on the input side.
+func isStdoutInteractive() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
 // ── Flash Backend Badge ───────────────────────────────────────────────────────
 // FlashBadge prints a bold orange inline tag showing the active flash backend.
 // Printed before the "Compiling" / "Uploading" section titles.
@@ -471,4 +1101,173 @@ func ProgressBar(label string, done, total int) {
 	bar := ColorSuccess.Sprint(strings.Repeat("█", filled)) +
 		ColorMuted.Sprint(strings.Repeat("░", w-filled))
 	fmt.Printf("  %s  [%s]  %d%%\n", label, bar, int(pct*100))
-}
\ No newline at end of file
+}
+
+// ── Number menu ───────────────────────────────────────────────────────────────
+
+// MenuItem is one selectable, numbered row in a NumberMenu — each string in
+// Columns is rendered whitespace-aligned against the same column in every
+// other item (e.g. name, version, registry, size/popularity).
+type MenuItem struct {
+	Columns []string
+}
+
+// NumberMenu renders items as a numbered table and prompts for a selection
+// expression: space/comma-separated numbers and inclusive ranges ("1-3"),
+// optionally excluded with a "^" prefix ("^4") — "1 2 5-7 ^6" selects
+// 1, 2, 5, 7. A parse error or out-of-range selection re-prompts rather
+// than failing outright.
+//
+// When stdin isn't a terminal (a pipe, a CI runner, `--yes`-style
+// non-interactive callers that never set up a TTY) every item is selected
+// without prompting.
+func NumberMenu(prompt string, items []MenuItem) ([]int, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items to choose from")
+	}
+
+	widths := menuColumnWidths(items)
+	for i, item := range items {
+		ColorMuted.Fprintf(os.Stdout, "  %2d  ", i+1)
+		for c, col := range item.Columns {
+			fmt.Fprintf(os.Stdout, "%-*s  ", widths[c], col)
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+
+	if !isStdinInteractive() {
+		all := make([]int, len(items))
+		for i := range items {
+			all[i] = i + 1
+		}
+		return all, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		ColorInfo.Fprint(os.Stdout, "  "+prompt+" ")
+		line, _ := reader.ReadString('\n')
+		sel, err := parseMenuSelection(line, len(items))
+		if err == nil {
+			return sel, nil
+		}
+		Warn(err.Error())
+	}
+}
+
+// Confirm asks a yes/no question on stdout and reads a one-line answer from
+// stdin. An empty answer takes defaultYes. When stdin isn't a terminal it
+// returns true without prompting — the same assume-yes fallback NumberMenu
+// uses for non-interactive callers (CI runners, pipes).
+func Confirm(question string, defaultYes bool) bool {
+	if !isStdinInteractive() {
+		return true
+	}
+
+	hint := "Y/n"
+	if !defaultYes {
+		hint = "y/N"
+	}
+	ColorInfo.Fprintf(os.Stdout, "  %s [%s] ", question, hint)
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	line = strings.ToLower(strings.TrimSpace(line))
+	switch line {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+func menuColumnWidths(items []MenuItem) []int {
+	var widths []int
+	for _, item := range items {
+		for c, col := range item.Columns {
+			for len(widths) <= c {
+				widths = append(widths, 0)
+			}
+			if len(col) > widths[c] {
+				widths[c] = len(col)
+			}
+		}
+	}
+	return widths
+}
+
+// parseMenuSelection parses a NumberMenu selection expression against n
+// items, returning the selected 1-indexed positions in ascending order.
+func parseMenuSelection(expr string, n int) ([]int, error) {
+	fields := strings.Fields(strings.ReplaceAll(expr, ",", " "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("enter at least one number, range (1-3), or exclusion (^4)")
+	}
+
+	include := make(map[int]bool)
+	exclude := make(map[int]bool)
+	for _, field := range fields {
+		exclusion := strings.HasPrefix(field, "^")
+		tok := strings.TrimPrefix(field, "^")
+
+		lo, hi, err := parseMenuRange(tok, n)
+		if err != nil {
+			return nil, err
+		}
+		for i := lo; i <= hi; i++ {
+			if exclusion {
+				exclude[i] = true
+			} else {
+				include[i] = true
+			}
+		}
+	}
+
+	var selected []int
+	for i := range include {
+		if !exclude[i] {
+			selected = append(selected, i)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("selection excludes every item")
+	}
+	sort.Ints(selected)
+	return selected, nil
+}
+
+// parseMenuRange parses one selection token ("3" or "5-7") into an
+// inclusive [lo, hi] bounded to [1, n].
+func parseMenuRange(tok string, n int) (lo, hi int, err error) {
+	if dash := strings.Index(tok, "-"); dash > 0 {
+		lo, err1 := strconv.Atoi(tok[:dash])
+		hi, err2 := strconv.Atoi(tok[dash+1:])
+		if err1 != nil || err2 != nil || lo > hi {
+			return 0, 0, fmt.Errorf("invalid range %q", tok)
+		}
+		if lo < 1 || hi > n {
+			return 0, 0, fmt.Errorf("range %q is outside 1-%d", tok, n)
+		}
+		return lo, hi, nil
+	}
+	v, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid selection %q", tok)
+	}
+	if v < 1 || v > n {
+		return 0, 0, fmt.Errorf("selection %d is outside 1-%d", v, n)
+	}
+	return v, v, nil
+}
+
+// isStdinInteractive reports whether stdin is an interactive terminal — a
+// small helper kept local to this package, the same way this repo keeps
+// other small per-package helpers (see DiagnosticSpan's doc comment).
+func isStdinInteractive() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
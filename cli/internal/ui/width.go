@@ -0,0 +1,78 @@
+// ─────────────────────────────────────────────────────────────────────────────
+//  tsuki :: ui :: width  —  cached terminal width with resize notifications
+// ─────────────────────────────────────────────────────────────────────────────
+
+package ui
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+const (
+	defaultTermWidth = 100
+	// minBoxWidth is the narrowest terminal Box still draws a border for;
+	// below it, Box switches to a compact layout with no box-drawing chars.
+	minBoxWidth = 40
+)
+
+var (
+	widthMu     sync.Mutex
+	cachedWidth int
+	resizeFns   []func(int)
+)
+
+// termWidth returns the current terminal width: golang.org/x/term reading
+// os.Stderr's size, falling back to $COLUMNS and finally defaultTermWidth.
+// The result is cached until a resize invalidates it (see invalidateWidth),
+// so Box/Traceback/PrintConfig/SectionTitle don't each pay for a syscall.
+func termWidth() int {
+	installResizeWatcher()
+
+	widthMu.Lock()
+	defer widthMu.Unlock()
+	if cachedWidth == 0 {
+		cachedWidth = detectTermWidth()
+	}
+	return cachedWidth
+}
+
+// OnResize registers fn to be called with the freshly detected width
+// whenever the terminal is resized — for a spinner or progress bar that's
+// still running and wants to redraw at the current width rather than the
+// one it started with.
+func OnResize(fn func(newWidth int)) {
+	widthMu.Lock()
+	resizeFns = append(resizeFns, fn)
+	widthMu.Unlock()
+}
+
+// invalidateWidth re-detects the terminal width and notifies every OnResize
+// listener. Called by the platform-specific resize watcher (width_unix.go);
+// width_windows.go never calls it, since Windows has no SIGWINCH to hook.
+func invalidateWidth() {
+	widthMu.Lock()
+	cachedWidth = detectTermWidth()
+	w := cachedWidth
+	fns := append([]func(int){}, resizeFns...)
+	widthMu.Unlock()
+
+	for _, fn := range fns {
+		fn(w)
+	}
+}
+
+func detectTermWidth() int {
+	if w, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && w > 0 {
+		return w
+	}
+	if cols := os.Getenv("COLUMNS"); cols != "" {
+		if n, err := strconv.Atoi(cols); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultTermWidth
+}
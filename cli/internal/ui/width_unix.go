@@ -0,0 +1,28 @@
+//go:build !windows
+
+package ui
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var resizeWatcherOnce sync.Once
+
+// installResizeWatcher installs a SIGWINCH handler that invalidates the
+// cached terminal width on every resize. It's installed lazily, on the
+// first call to termWidth(), so a process that never prints to a terminal
+// never touches signal.Notify.
+func installResizeWatcher() {
+	resizeWatcherOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGWINCH)
+		go func() {
+			for range ch {
+				invalidateWidth()
+			}
+		}()
+	})
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package ui
+
+// installResizeWatcher is a no-op on Windows: there is no SIGWINCH
+// equivalent, so the cached width is only ever set once, on first use.
+func installResizeWatcher() {}